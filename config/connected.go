@@ -1,28 +1,50 @@
 package config
 
 import (
-    "database/sql"
-    "log"
-    "os"
+	"database/sql"
+	"log"
+	"os"
+	"time"
 
-    _ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// normalizeDSN memaksa koneksi bekerja dalam UTC: DATETIME dibaca sebagai time.Time UTC
+// dan NOW()/CURRENT_TIMESTAMP di MySQL ikut UTC, terlepas dari zona server
+func normalizeDSN(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	cfg.ParseTime = true
+	cfg.Loc = time.UTC
+	if cfg.Params == nil {
+		cfg.Params = map[string]string{}
+	}
+	cfg.Params["time_zone"] = "'+00:00'"
+	return cfg.FormatDSN(), nil
+}
+
 func ConnectDB() *sql.DB {
-    dsn := os.Getenv("DATABASE_URL")
-    if dsn == "" {
-        log.Fatal("DATABASE_URL is not set")
-    }
-
-    db, err := sql.Open("mysql", dsn)
-    if err != nil {
-        log.Fatal("Failed to open database:", err)
-    }
-
-    if err := db.Ping(); err != nil {
-        log.Fatal("Database unreachable:", err)
-    }
-
-    log.Println("Database connected successfully")
-    return db
-}
\ No newline at end of file
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	dsn, err := normalizeDSN(dsn)
+	if err != nil {
+		log.Fatal("Invalid DATABASE_URL:", err)
+	}
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		log.Fatal("Failed to open database:", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		log.Fatal("Database unreachable:", err)
+	}
+
+	log.Println("Database connected successfully")
+	return db
+}
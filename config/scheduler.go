@@ -0,0 +1,138 @@
+package config
+
+import (
+	"cocopen-backend/services"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// Job adalah tugas latar belakang yang dijalankan berkala oleh scheduler.
+// Jadwal eksekusi disimpan di tabel job_terjadwal sehingga tetap berlaku setelah restart.
+type Job struct {
+	Nama     string
+	Interval time.Duration
+	Run      func(db *sql.DB) error
+}
+
+const (
+	schedulerTick   = time.Minute
+	jobLockDuration = 10 * time.Minute
+)
+
+var errPanicJob = errors.New("job berhenti karena panic")
+
+// DefaultJobs berisi semua job bawaan aplikasi
+func DefaultJobs() []Job {
+	offsets := PengingatOffsets()
+
+	return []Job{
+		{
+			Nama:     "cleanup_akun_belum_verifikasi",
+			Interval: 24 * time.Hour,
+			Run:      runCleanup,
+		},
+		{
+			Nama:     "pengingat_jadwal_test",
+			Interval: 5 * time.Minute,
+			Run: func(db *sql.DB) error {
+				return services.KirimPengingat(db, offsets)
+			},
+		},
+		{
+			Nama:     "finalisasi_sesi_test",
+			Interval: time.Minute,
+			Run:      services.FinalisasiSesiKedaluwarsa,
+		},
+		{
+			Nama:     "terbitkan_pengumuman_terjadwal",
+			Interval: time.Minute,
+			Run:      services.TerbitkanPengumumanTerjadwal,
+		},
+	}
+}
+
+// PengingatOffsets membaca PENGINGAT_OFFSET (mis. "24h,1h") sebagai daftar jarak pengingat sebelum acara
+func PengingatOffsets() []time.Duration {
+	raw := GetEnv("PENGINGAT_OFFSET", "24h,1h")
+
+	var offsets []time.Duration
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d <= 0 {
+			log.Printf("PENGINGAT_OFFSET %q tidak valid, diabaikan", part)
+			continue
+		}
+		offsets = append(offsets, d)
+	}
+	return offsets
+}
+
+// StartScheduler mendaftarkan job ke database lalu memeriksa job yang jatuh tempo setiap menit
+func StartScheduler(db *sql.DB, jobs []Job) {
+	for _, job := range jobs {
+		if err := services.DaftarkanJob(db, job.Nama, job.Interval); err != nil {
+			log.Printf("Gagal mendaftarkan job %s: %v", job.Nama, err)
+		}
+	}
+
+	log.Printf("Background job: scheduler aktif dengan %d job", len(jobs))
+
+	ticker := time.NewTicker(schedulerTick)
+
+	go func() {
+		runDueJobs(db, jobs)
+
+		for range ticker.C {
+			runDueJobs(db, jobs)
+		}
+	}()
+}
+
+func runDueJobs(db *sql.DB, jobs []Job) {
+	for _, job := range jobs {
+		claimed, err := services.KlaimJob(db, job.Nama, jobLockDuration)
+		if err != nil {
+			log.Printf("Gagal klaim job %s: %v", job.Nama, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		errJob := runJob(db, job)
+		if errJob != nil {
+			log.Printf("Job %s gagal: %v", job.Nama, errJob)
+		}
+		if err := services.SelesaikanJob(db, job.Nama, errJob); err != nil {
+			log.Printf("Gagal mencatat hasil job %s: %v", job.Nama, err)
+		}
+	}
+}
+
+func runJob(db *sql.DB, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panic: %v", job.Nama, r)
+			err = errPanicJob
+		}
+	}()
+	return job.Run(db)
+}
+
+func runCleanup(db *sql.DB) error {
+	cutoff := time.Now().Add(-30 * time.Minute)
+	err := services.DeleteUnverifiedUsersBefore(db, cutoff)
+	if err != nil {
+		log.Printf("Gagal membersihkan akun belum diverifikasi: %v", err)
+		return err
+	}
+	log.Printf("Pembersihan selesai: akun dibuat sebelum %v telah dicek", cutoff.Format("2006-01-02 15:04:05"))
+	return nil
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// AkomodasiTestHandler mengelola akomodasi pendaftar pada sebuah tes (?id_test=):
+// GET daftar akomodasi, PUT membuat/mengganti akomodasi, DELETE (&pendaftar_id=) menghapusnya
+func AkomodasiTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			daftar, err := services.GetAkomodasiTest(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil akomodasi tes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"id_test":   idTest,
+				"akomodasi": daftar,
+			})
+
+		case http.MethodPut:
+			var req dto.AkomodasiRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			adminID := claims.IDUser
+			err := services.SimpanAkomodasi(db, models.AkomodasiTest{
+				IDTest:        idTest,
+				PendaftarID:   req.PendaftarID,
+				WaktuMulai:    req.WaktuMulai,
+				WaktuSelesai:  req.WaktuSelesai,
+				TambahanMenit: req.TambahanMenit,
+				Catatan:       req.Catatan,
+				DibuatOleh:    &adminID,
+			})
+			if err != nil {
+				if errors.Is(err, services.ErrAkomodasiTidakValid) {
+					utils.Error(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan akomodasi: "+err.Error())
+				return
+			}
+
+			a, err := services.GetAkomodasi(db, idTest, req.PendaftarID)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil akomodasi: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success":   true,
+				"message":   "Akomodasi tes berhasil disimpan",
+				"akomodasi": a,
+			})
+
+		case http.MethodDelete:
+			pendaftarID, err := strconv.Atoi(r.URL.Query().Get("pendaftar_id"))
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Parameter pendaftar_id tidak valid")
+				return
+			}
+			if err := services.HapusAkomodasi(db, idTest, pendaftarID); err != nil {
+				if err == sql.ErrNoRows {
+					utils.Error(w, http.StatusNotFound, "Akomodasi tidak ditemukan")
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menghapus akomodasi: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Akomodasi tes berhasil dihapus",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+)
+
+// AnalisisTestHandler menampilkan statistik nilai dan analisis butir soal sebuah tes (?id_test=)
+func AnalisisTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat tes: "+err.Error())
+			return
+		}
+
+		analisis, err := services.GetAnalisisTest(db, idTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menghitung analisis tes: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, analisis)
+	}
+}
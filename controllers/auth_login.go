@@ -64,4 +64,4 @@ func Login(w http.ResponseWriter, r *http.Request) {
 			"profile_picture": profilePicture,
 		},
 	})
-}
\ No newline at end of file
+}
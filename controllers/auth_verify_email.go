@@ -1,50 +1,48 @@
 package controllers
 
 import (
-    "cocopen-backend/services"
-    "cocopen-backend/utils"
-    "net/http"
-    "time"
-    "database/sql"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"time"
 )
 
 func VerifyEmail(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        token := r.URL.Query().Get("token")
-        if token == "" {
-            utils.Error(w, http.StatusBadRequest, "Token verifikasi wajib disertakan")
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			utils.Error(w, http.StatusBadRequest, "Token verifikasi wajib disertakan")
 			return
-        }
+		}
 
-        userID, expiresAt, err := services.ValidateVerificationToken(db, token)
-        if err != nil {
-            if err == sql.ErrNoRows {
-                utils.Error(w, http.StatusBadRequest, "Token verifikasi tidak valid")
+		userID, expiresAt, err := services.ValidateVerificationToken(db, token)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusBadRequest, "Token verifikasi tidak valid")
 				return
-            }
-            panic(err)
-        }
+			}
+			panic(err)
+		}
 
-        if expiresAt.Before(time.Now()) {
-            utils.Error(w, http.StatusBadRequest, "Token verifikasi sudah kedaluwarsa")
+		if expiresAt.Before(time.Now()) {
+			utils.Error(w, http.StatusBadRequest, "Token verifikasi sudah kedaluwarsa")
 			return
-        }
+		}
 
-        if err := services.VerifyEmail(db, userID); 
-		err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memverifikasi email")
-            return
-        }
+		if err := services.VerifyEmail(db, userID); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memverifikasi email")
+			return
+		}
 
-        if err := services.DeleteVerificationToken(db, token); 
-		err != nil {
-            panic(err)
-        }
+		if err := services.DeleteVerificationToken(db, token); err != nil {
+			panic(err)
+		}
 
-        utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-            "success": true,
-            "status":  http.StatusOK,
-            "message": "Email berhasil diverifikasi.",
-        })
-    }
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"status":  http.StatusOK,
+			"message": "Email berhasil diverifikasi.",
+		})
+	}
 }
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"bytes"
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// formatBankSoal menentukan format dari parameter format, atau dari ekstensi nama berkas
+func formatBankSoal(format, namaFile string) string {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(namaFile)) {
+	case ".csv":
+		return services.FormatSoalCSV
+	case ".json":
+		return services.FormatSoalJSON
+	case ".gift", ".txt":
+		return services.FormatSoalGIFT
+	}
+	return ""
+}
+
+// ImporSoalHandler mengimpor bank soal dari CSV, JSON, atau GIFT (multipart "file").
+// Dengan dry_run=true berkas hanya divalidasi. Soal disimpan bila seluruh baris valid.
+func ImporSoalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		if err := r.ParseMultipartForm(5 << 20); err != nil {
+			utils.Error(w, http.StatusBadRequest, "Gagal parsing form: "+err.Error())
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "File impor wajib diunggah")
+			return
+		}
+		defer file.Close()
+
+		format := formatBankSoal(r.FormValue("format"), header.Filename)
+		dryRun := r.FormValue("dry_run") == "true" || r.FormValue("dry_run") == "1"
+
+		items, kesalahan, err := services.BacaSoal(format, file)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(items) == 0 && len(kesalahan) == 0 {
+			utils.Error(w, http.StatusBadRequest, "Berkas tidak berisi soal")
+			return
+		}
+
+		kesalahanValidasi, err := services.ValidasiSoalImpor(db, items)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal validasi soal: "+err.Error())
+			return
+		}
+		kesalahan = append(kesalahan, kesalahanValidasi...)
+		sort.SliceStable(kesalahan, func(i, j int) bool { return kesalahan[i].Baris < kesalahan[j].Baris })
+
+		if len(kesalahan) > 0 {
+			utils.JSONResponse(w, http.StatusBadRequest, map[string]interface{}{
+				"success":   false,
+				"message":   fmt.Sprintf("Ditemukan %d kesalahan, tidak ada soal yang disimpan", len(kesalahan)),
+				"dry_run":   dryRun,
+				"format":    format,
+				"kesalahan": kesalahan,
+			})
+			return
+		}
+
+		if dryRun {
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success":     true,
+				"message":     "Validasi berhasil, soal belum disimpan",
+				"dry_run":     true,
+				"format":      format,
+				"jumlah_soal": len(items),
+			})
+			return
+		}
+
+		if err := services.ImporSoal(db, items, claims.IDUser); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal impor soal: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+			"success":     true,
+			"message":     fmt.Sprintf("%d soal berhasil diimpor", len(items)),
+			"dry_run":     false,
+			"format":      format,
+			"jumlah_soal": len(items),
+		})
+	}
+}
+
+// EksporSoalHandler mengunduh seluruh bank soal (?format=csv|json|gift, bawaan json).
+// Hanya json yang membawa berkas gambar; bank soal bergambar ditolak untuk csv dan gift.
+// Bobot psikotes tidak ikut di format apa pun, sehingga ekspor soal psikotes berbobot ditolak
+// kecuali abaikan_bobot_psikotes=true.
+func EksporSoalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format == "" {
+			format = services.FormatSoalJSON
+		}
+
+		soals, err := services.GetAllSoal(db, false)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal: "+err.Error())
+			return
+		}
+
+		abaikanBobot := r.URL.Query().Get("abaikan_bobot_psikotes") == "true"
+		gambar, err := services.SiapkanEksporSoal(db, format, soals, abaikanBobot)
+		if err != nil {
+			if errors.Is(err, services.ErrFormatSoalTidakDikenal) || errors.Is(err, services.ErrEksporGambarTidakDidukung) ||
+				errors.Is(err, services.ErrEksporBobotPsikotes) {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ekspor soal: "+err.Error())
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := services.TulisSoal(format, &buf, soals, gambar); err != nil {
+			if err == services.ErrFormatSoalTidakDikenal {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ekspor soal: "+err.Error())
+			return
+		}
+
+		contentType := map[string]string{
+			services.FormatSoalCSV:  "text/csv; charset=utf-8",
+			services.FormatSoalJSON: "application/json",
+			services.FormatSoalGIFT: "text/plain; charset=utf-8",
+		}[format]
+		namaFile := fmt.Sprintf("bank_soal_%s.%s", time.Now().Format("20060102"), format)
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+namaFile+`"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// kirimGambarSoal melayani berkas gambar tanpa cache bersama agar tidak tersimpan di proxy
+func kirimGambarSoal(w http.ResponseWriter, r *http.Request, g *models.GambarSoal) {
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeFile(w, r, filepath.Join(utils.GambarSoalPath, filepath.Base(g.NamaFile)))
+}
+
+// GambarSoalAdminHandler mengelola gambar soal:
+// GET daftar (atau berkas bila ?id_gambar=), POST unggah (multipart "gambar"), DELETE ?id_gambar=
+func GambarSoalAdminHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Get("id_gambar") == "" {
+				list, err := services.GetAllGambarSoal(db)
+				if err != nil {
+					utils.Error(w, http.StatusInternalServerError, "Gagal ambil gambar: "+err.Error())
+					return
+				}
+				utils.JSONResponse(w, http.StatusOK, list)
+				return
+			}
+			g, ok := gambarSoalDariQuery(w, r, db)
+			if !ok {
+				return
+			}
+			kirimGambarSoal(w, r, g)
+
+		case http.MethodPost:
+			if err := r.ParseMultipartForm(5 << 20); err != nil {
+				utils.Error(w, http.StatusBadRequest, "Gagal parsing form: "+err.Error())
+				return
+			}
+			file, header, err := r.FormFile("gambar")
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "File gambar wajib diunggah")
+				return
+			}
+
+			ext := strings.ToLower(filepath.Ext(header.Filename))
+			allowed := map[string]bool{
+				".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true,
+			}
+			if !allowed[ext] {
+				file.Close()
+				utils.Error(w, http.StatusBadRequest,
+					"Format gambar tidak didukung (hanya .jpg, .jpeg, .png, .gif, .webp)")
+				return
+			}
+			if header.Size > 2<<20 {
+				file.Close()
+				utils.Error(w, http.StatusBadRequest, "Ukuran file maksimal 2 MB")
+				return
+			}
+
+			// Isi berkas harus benar-benar gambar, bukan sekadar berekstensi gambar
+			buf := make([]byte, 512)
+			n, _ := io.ReadFull(file, buf)
+			if !strings.HasPrefix(http.DetectContentType(buf[:n]), "image/") {
+				file.Close()
+				utils.Error(w, http.StatusBadRequest, "Isi file bukan gambar")
+				return
+			}
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				file.Close()
+				utils.Error(w, http.StatusInternalServerError, "Gagal membaca file gambar")
+				return
+			}
+
+			namaFile, err := utils.UploadFoto(file, header, utils.GambarSoalPath)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal mengunggah gambar: "+err.Error())
+				return
+			}
+
+			g := models.GambarSoal{
+				NamaFile:     namaFile,
+				NamaAsli:     header.Filename,
+				Ukuran:       header.Size,
+				DiunggahOleh: &claims.IDUser,
+			}
+			if err := services.CreateGambarSoal(db, &g); err != nil {
+				utils.HapusFoto(utils.GambarSoalPath, namaFile)
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan gambar: "+err.Error())
+				return
+			}
+
+			utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+				"success":   true,
+				"message":   "Gambar berhasil diunggah",
+				"id_gambar": g.IDGambar,
+				"markdown":  fmt.Sprintf("![](%s%d)", utils.SkemaGambarSoal, g.IDGambar),
+			})
+
+		case http.MethodDelete:
+			g, ok := gambarSoalDariQuery(w, r, db)
+			if !ok {
+				return
+			}
+			if err := services.DeleteGambarSoal(db, g); err != nil {
+				if err == services.ErrGambarMasihDipakai {
+					utils.Error(w, http.StatusConflict, "Gambar masih dipakai soal, hapus rujukannya terlebih dahulu")
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal hapus gambar: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Gambar berhasil dihapus",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+		}
+	}
+}
+
+func gambarSoalDariQuery(w http.ResponseWriter, r *http.Request, db *sql.DB) (*models.GambarSoal, bool) {
+	idGambar, err := strconv.Atoi(r.URL.Query().Get("id_gambar"))
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, "Parameter id_gambar tidak valid")
+		return nil, false
+	}
+	g, err := services.GetGambarSoalByID(db, idGambar)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.Error(w, http.StatusNotFound, "Gambar tidak ditemukan")
+			return nil, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "Gagal ambil gambar: "+err.Error())
+		return nil, false
+	}
+	return g, true
+}
+
+// GambarSesiHandler melayani gambar soal untuk peserta lewat token bertanda tangan (?token=).
+// Token hanya diterbitkan bersama soal sesi dan hanya berlaku selama sesi itu masih berlangsung,
+// sehingga gambar tidak bisa diambil sebelum jendela tes dibuka. Setelah sesi selesai, gambar
+// hanya dilayani bila pembahasan tes sudah terbuka untuk peserta.
+func GambarSesiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		idGambar, idHasil, err := utils.VerifyGambarToken(r.URL.Query().Get("token"))
+		if err != nil {
+			utils.Error(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		sesi, err := services.GetHasilByID(db, idHasil)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusForbidden, "Sesi tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa sesi tes")
+			return
+		}
+		now := time.Now()
+		if sesi.Status == services.StatusHasilSelesai {
+			t, err := services.GetTestByID(db, sesi.IDTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa tes")
+				return
+			}
+			if !services.PembahasanTerbuka(t, sesi, now) {
+				utils.Error(w, http.StatusForbidden, "Sesi tes sudah berakhir")
+				return
+			}
+		} else if services.SesiKedaluwarsa(sesi, now) {
+			utils.Error(w, http.StatusForbidden, "Sesi tes sudah berakhir")
+			return
+		}
+
+		g, err := services.GetGambarSoalByID(db, idGambar)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Gambar tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil gambar")
+			return
+		}
+		kirimGambarSoal(w, r, g)
+	}
+}
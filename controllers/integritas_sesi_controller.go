@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// EventSesiHandler menerima event integritas (fokus hilang, salin/tempel, ...) dari klien
+// selama sesi tes berlangsung
+func EventSesiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.EventSesiRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sesi, ok := sesiBerlangsungUser(w, db, claims.IDUser, req.IDTest)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		events := make([]models.EventIntegritas, 0, len(req.Events))
+		for _, e := range req.Events {
+			waktu := now
+			if e.Waktu != nil {
+				waktu = *e.Waktu
+			}
+			events = append(events, models.EventIntegritas{Jenis: e.Jenis, Detail: e.Detail, WaktuKlien: waktu})
+		}
+
+		if err := services.SimpanEventSesi(db, sesi.IDHasil, events); err != nil {
+			switch err {
+			case services.ErrSesiSudahSelesai:
+				utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
+			case services.ErrEventSesiPenuh:
+				utils.Error(w, http.StatusTooManyRequests, err.Error())
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan event: "+err.Error())
+			}
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Event tersimpan",
+		})
+	}
+}
+
+// IntegritasSesiAdminHandler menampilkan ringkasan dan linimasa event integritas sebuah sesi (?id_hasil=)
+func IntegritasSesiAdminHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idHasil, err := strconv.Atoi(r.URL.Query().Get("id_hasil"))
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "Parameter id_hasil tidak valid")
+			return
+		}
+
+		hasil, err := services.GetHasilByID(db, idHasil)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Hasil tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil tes: "+err.Error())
+			return
+		}
+
+		ringkasan, err := services.GetRingkasanIntegritasTest(db, hasil.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil ringkasan integritas: "+err.Error())
+			return
+		}
+		events, err := services.GetEventSesi(db, hasil.IDHasil)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil event sesi: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"id_hasil":   hasil.IDHasil,
+			"user_id":    hasil.UserID,
+			"id_test":    hasil.IDTest,
+			"status":     hasil.Status,
+			"integritas": ringkasan[hasil.IDHasil],
+			"events":     events,
+		})
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"cocopen-backend/services"
 	"cocopen-backend/utils"
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 	"sort"
 	"strconv"
@@ -15,6 +17,45 @@ import (
 	"time"
 )
 
+// waktuJadwalResponse menyusun waktu jadwal dalam UTC dan dalam zona lokal jadwal
+func waktuJadwalResponse(j *models.Jadwal) dto.WaktuJadwalResponse {
+	loc := utils.ZonaWaktuOrUTC(j.ZonaWaktu)
+	mulai := j.WaktuMulai.In(loc)
+	selesai := j.WaktuSelesai.In(loc)
+
+	res := dto.WaktuJadwalResponse{
+		ZonaWaktu:         j.ZonaWaktu,
+		WaktuMulai:        j.WaktuMulai.UTC(),
+		WaktuSelesai:      j.WaktuSelesai.UTC(),
+		WaktuMulaiLokal:   mulai.Format(time.RFC3339),
+		WaktuSelesaiLokal: selesai.Format(time.RFC3339),
+		Tanggal:           mulai.Format("2006-01-02"),
+		JamMulai:          mulai.Format("15:04:05"),
+		JamSelesai:        selesai.Format("15:04:05"),
+	}
+	if j.WaktuMulaiDiajukan != nil {
+		utc := j.WaktuMulaiDiajukan.UTC()
+		lokal := utc.In(loc)
+		tanggal, jam := lokal.Format("2006-01-02"), lokal.Format("15:04:05")
+		res.WaktuMulaiDiajukan = &utc
+		res.TanggalDiajukan = &tanggal
+		res.JamMulaiDiajukan = &jam
+	}
+	if j.WaktuSelesaiDiajukan != nil {
+		utc := j.WaktuSelesaiDiajukan.UTC()
+		jam := utc.In(loc).Format("15:04:05")
+		res.WaktuSelesaiDiajukan = &utc
+		res.JamSelesaiDiajukan = &jam
+	}
+	if j.BatasPendaftaran != nil {
+		utc := j.BatasPendaftaran.UTC()
+		lokal := utc.In(loc).Format(time.RFC3339)
+		res.BatasPendaftaran = &utc
+		res.BatasPendaftaranLokal = &lokal
+	}
+	return res
+}
+
 func GetUserJadwalHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -43,29 +84,37 @@ func GetUserJadwalHandler(db *sql.DB) http.HandlerFunc {
 		allJadwal := append(pribadi, umum...)
 
 		sort.Slice(allJadwal, func(i, j int) bool {
-			if allJadwal[i].Tanggal == allJadwal[j].Tanggal {
-				return allJadwal[i].JamMulai < allJadwal[j].JamMulai
-			}
-			return allJadwal[i].Tanggal.Before(allJadwal[j].Tanggal)
+			return allJadwal[i].WaktuMulai.Before(allJadwal[j].WaktuMulai)
 		})
 
 		var result []dto.JadwalUserResponse
 		for _, j := range allJadwal {
-			result = append(result, dto.JadwalUserResponse{
-				IDJadwal:           j.IDJadwal,
-				Tanggal:            j.Tanggal,
-				JamMulai:           j.JamMulai,
-				JamSelesai:         j.JamSelesai,
-				Tempat:             j.Tempat,
-				KonfirmasiJadwal:   j.KonfirmasiJadwal,
-				Catatan:            j.Catatan,
-				PengajuanPerubahan: j.PengajuanPerubahan,
-				AlasanPerubahan:    j.AlasanPerubahan,
-				TanggalDiajukan:    j.TanggalDiajukan,
-				JamMulaiDiajukan:   j.JamMulaiDiajukan,
-				JamSelesaiDiajukan: j.JamSelesaiDiajukan,
-				JenisJadwal: 		j.JenisJadwal,
-			})
+			item := dto.JadwalUserResponse{
+				IDJadwal:            j.IDJadwal,
+				WaktuJadwalResponse: waktuJadwalResponse(&j),
+				Tempat:              j.Tempat,
+				KonfirmasiJadwal:    j.KonfirmasiJadwal,
+				Catatan:             j.Catatan,
+				PengajuanPerubahan:  j.PengajuanPerubahan,
+				AlasanPerubahan:     j.AlasanPerubahan,
+				JenisJadwal:         j.JenisJadwal,
+				Kapasitas:           j.Kapasitas,
+			}
+			if j.JenisJadwal == "umum" {
+				terdaftar, _, err := services.GetJumlahPesertaJadwal(db, j.IDJadwal)
+				if err != nil {
+					utils.Error(w, http.StatusInternalServerError, "Gagal menghitung peserta jadwal: "+err.Error())
+					return
+				}
+				status, err := services.GetStatusPesertaUser(db, j.IDJadwal, claims.IDUser)
+				if err != nil {
+					utils.Error(w, http.StatusInternalServerError, "Gagal mengambil status pendaftaran: "+err.Error())
+					return
+				}
+				item.JumlahTerdaftar = terdaftar
+				item.StatusPendaftaran = status
+			}
+			result = append(result, item)
 		}
 
 		utils.JSONResponse(w, http.StatusOK, result)
@@ -108,23 +157,27 @@ func GetJadwalByIDHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		response := dto.JadwalAdminResponse{
-			IDJadwal:             jadwal.IDJadwal,
-			UserID:               jadwal.UserID,
-			PendaftarID:          jadwal.PendaftarID,
-			Tanggal:              jadwal.Tanggal,
-			JamMulai:             jadwal.JamMulai,
-			JamSelesai:           jadwal.JamSelesai,
-			Tempat:               jadwal.Tempat,
-			KonfirmasiJadwal:     jadwal.KonfirmasiJadwal,
-			Catatan:              jadwal.Catatan,
-			PengajuanPerubahan:   jadwal.PengajuanPerubahan,
-			AlasanPerubahan:      jadwal.AlasanPerubahan,
-			TanggalDiajukan:      jadwal.TanggalDiajukan,
-			JamMulaiDiajukan:     jadwal.JamMulaiDiajukan,
-			JamSelesaiDiajukan:   jadwal.JamSelesaiDiajukan,
-			JenisJadwal:          jadwal.JenisJadwal,
-			CreatedAt:            jadwal.CreatedAt,
-			UpdatedAt:            jadwal.UpdatedAt,
+			IDJadwal:            jadwal.IDJadwal,
+			UserID:              jadwal.UserID,
+			PendaftarID:         jadwal.PendaftarID,
+			WaktuJadwalResponse: waktuJadwalResponse(jadwal),
+			Tempat:              jadwal.Tempat,
+			KonfirmasiJadwal:    jadwal.KonfirmasiJadwal,
+			Catatan:             jadwal.Catatan,
+			PengajuanPerubahan:  jadwal.PengajuanPerubahan,
+			AlasanPerubahan:     jadwal.AlasanPerubahan,
+			JenisJadwal:         jadwal.JenisJadwal,
+			CreatedAt:           jadwal.CreatedAt,
+			UpdatedAt:           jadwal.UpdatedAt,
+			Kapasitas:           jadwal.Kapasitas,
+		}
+
+		if jadwal.JenisJadwal == "umum" {
+			response.JumlahTerdaftar, response.JumlahWaitlist, err = services.GetJumlahPesertaJadwal(db, jadwal.IDJadwal)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal menghitung peserta jadwal: "+err.Error())
+				return
+			}
 		}
 
 		utils.JSONResponse(w, http.StatusOK, response)
@@ -152,26 +205,30 @@ func GetAllJadwalHandler(db *sql.DB) http.HandlerFunc {
 
 		var result []dto.JadwalAdminResponse
 		for _, j := range jadwals {
-			result = append(result, dto.JadwalAdminResponse{
-				IDJadwal:             j.IDJadwal,
-				UserID:               j.UserID,
-				UserNama: 				j.UserNama,
-				PendaftarID:          j.PendaftarID,
-				Tanggal:              j.Tanggal,
-				JamMulai:             j.JamMulai,
-				JamSelesai:           j.JamSelesai,
-				Tempat:               j.Tempat,
-				KonfirmasiJadwal:     j.KonfirmasiJadwal,
-				Catatan:              j.Catatan,
-				PengajuanPerubahan:   j.PengajuanPerubahan,
-				AlasanPerubahan:      j.AlasanPerubahan,
-				TanggalDiajukan:      j.TanggalDiajukan,
-				JamMulaiDiajukan:     j.JamMulaiDiajukan,
-				JamSelesaiDiajukan:   j.JamSelesaiDiajukan,
-				JenisJadwal:          j.JenisJadwal,
-				CreatedAt:            j.CreatedAt,
-				UpdatedAt:            j.UpdatedAt,
-			})
+			item := dto.JadwalAdminResponse{
+				IDJadwal:            j.IDJadwal,
+				UserID:              j.UserID,
+				UserNama:            j.UserNama,
+				PendaftarID:         j.PendaftarID,
+				WaktuJadwalResponse: waktuJadwalResponse(&j),
+				Tempat:              j.Tempat,
+				KonfirmasiJadwal:    j.KonfirmasiJadwal,
+				Catatan:             j.Catatan,
+				PengajuanPerubahan:  j.PengajuanPerubahan,
+				AlasanPerubahan:     j.AlasanPerubahan,
+				JenisJadwal:         j.JenisJadwal,
+				CreatedAt:           j.CreatedAt,
+				UpdatedAt:           j.UpdatedAt,
+				Kapasitas:           j.Kapasitas,
+			}
+			if j.JenisJadwal == "umum" {
+				item.JumlahTerdaftar, item.JumlahWaitlist, err = services.GetJumlahPesertaJadwal(db, j.IDJadwal)
+				if err != nil {
+					utils.Error(w, http.StatusInternalServerError, "Gagal menghitung peserta jadwal: "+err.Error())
+					return
+				}
+			}
+			result = append(result, item)
 		}
 
 		utils.JSONResponse(w, http.StatusOK, result)
@@ -197,25 +254,29 @@ func CreateJadwalHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tanggal, err := time.Parse("2006-01-02", req.Tanggal)
+		zonaWaktu := utils.DefaultZonaWaktu
+		if req.ZonaWaktu != nil && *req.ZonaWaktu != "" {
+			zonaWaktu = *req.ZonaWaktu
+		}
+		loc, err := utils.LoadZonaWaktu(zonaWaktu)
 		if err != nil {
-			utils.Error(w, http.StatusBadRequest, "Format tanggal tidak valid")
+			utils.Error(w, http.StatusBadRequest, "Zona waktu tidak dikenal, gunakan nama IANA seperti Asia/Makassar")
 			return
 		}
 
-		jamMulai, err := time.Parse("15:04:05", req.JamMulai)
+		waktuMulai, err := utils.WaktuLokalKeUTC(req.Tanggal, req.JamMulai, loc)
 		if err != nil {
-			utils.Error(w, http.StatusBadRequest, "Format jam_mulai tidak valid")
+			utils.Error(w, http.StatusBadRequest, "Format tanggal atau jam_mulai tidak valid")
 			return
 		}
 
-		jamSelesai, err := time.Parse("15:04:05", req.JamSelesai)
+		waktuSelesai, err := utils.WaktuLokalKeUTC(req.Tanggal, req.JamSelesai, loc)
 		if err != nil {
 			utils.Error(w, http.StatusBadRequest, "Format jam_selesai tidak valid")
 			return
 		}
 
-		if !jamSelesai.After(jamMulai) {
+		if !waktuSelesai.After(waktuMulai) {
 			utils.Error(w, http.StatusBadRequest, "Jam selesai harus setelah jam mulai")
 			return
 		}
@@ -241,21 +302,37 @@ func CreateJadwalHandler(db *sql.DB) http.HandlerFunc {
 			userID = claims.IDUser // pembuat jadwal umum
 		}
 
+		var batasPendaftaran *time.Time
+		if req.BatasPendaftaran != nil {
+			batas, err := time.ParseInLocation("2006-01-02 15:04:05", *req.BatasPendaftaran, loc)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Format batas_pendaftaran tidak valid")
+				return
+			}
+			batas = batas.UTC()
+			batasPendaftaran = &batas
+		}
+		if jenisJadwal != "umum" && (req.Kapasitas != nil || batasPendaftaran != nil) {
+			utils.Error(w, http.StatusBadRequest, "Kapasitas dan batas pendaftaran hanya untuk jadwal umum")
+			return
+		}
+
 		jadwal := models.Jadwal{
 			UserID:               userID,
 			PendaftarID:          req.PendaftarID,
-			Tanggal:              tanggal,
-			JamMulai:             req.JamMulai,
-			JamSelesai:           req.JamSelesai,
+			WaktuMulai:           waktuMulai,
+			WaktuSelesai:         waktuSelesai,
+			ZonaWaktu:            zonaWaktu,
 			Tempat:               req.Tempat,
 			KonfirmasiJadwal:     "belum",
 			Catatan:              req.Catatan,
 			PengajuanPerubahan:   false,
 			AlasanPerubahan:      nil,
-			TanggalDiajukan:      nil,
-			JamMulaiDiajukan:     nil,
-			JamSelesaiDiajukan:   nil,
+			WaktuMulaiDiajukan:   nil,
+			WaktuSelesaiDiajukan: nil,
 			JenisJadwal:          jenisJadwal,
+			Kapasitas:            req.Kapasitas,
+			BatasPendaftaran:     batasPendaftaran,
 		}
 
 		if err := services.CreateJadwal(db, jadwal); err != nil {
@@ -263,6 +340,12 @@ func CreateJadwalHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		if jenisJadwal == "pribadi" {
+			if err := services.NotifikasiJadwalPribadi(db, jadwal, true); err != nil {
+				log.Printf("Gagal kirim notifikasi jadwal baru ke user %d: %v", jadwal.UserID, err)
+			}
+		}
+
 		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
 			"success": true,
 			"message": "Jadwal berhasil dibuat",
@@ -316,14 +399,35 @@ func AjukanPerubahanJadwalHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		var tanggalD *time.Time
+		// Usulan dibaca sebagai jam dinding di zona jadwal; bagian yang kosong memakai nilai saat ini
+		loc := utils.ZonaWaktuOrUTC(jadwal.ZonaWaktu)
+		mulaiLokal := jadwal.WaktuMulai.In(loc)
+		tanggalD := mulaiLokal.Format("2006-01-02")
+		jamMulaiD := mulaiLokal.Format("15:04:05")
+		jamSelesaiD := jadwal.WaktuSelesai.In(loc).Format("15:04:05")
 		if req.TanggalDiajukan != nil {
-			t, err := time.Parse("2006-01-02", *req.TanggalDiajukan)
-			if err != nil {
-				utils.Error(w, http.StatusBadRequest, "Format tanggal_diajukan tidak valid")
-				return
-			}
-			tanggalD = &t
+			tanggalD = *req.TanggalDiajukan
+		}
+		if req.JamMulaiDiajukan != nil {
+			jamMulaiD = *req.JamMulaiDiajukan
+		}
+		if req.JamSelesaiDiajukan != nil {
+			jamSelesaiD = *req.JamSelesaiDiajukan
+		}
+
+		waktuMulaiD, err := utils.WaktuLokalKeUTC(tanggalD, jamMulaiD, loc)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "Format tanggal_diajukan atau jam_mulai_diajukan tidak valid")
+			return
+		}
+		waktuSelesaiD, err := utils.WaktuLokalKeUTC(tanggalD, jamSelesaiD, loc)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "Format jam_selesai_diajukan tidak valid")
+			return
+		}
+		if !waktuSelesaiD.After(waktuMulaiD) {
+			utils.Error(w, http.StatusBadRequest, "Jam selesai yang diajukan harus setelah jam mulai")
+			return
 		}
 
 		err = services.UpdatePengajuanPerubahan(
@@ -331,9 +435,8 @@ func AjukanPerubahanJadwalHandler(db *sql.DB) http.HandlerFunc {
 			req.IDJadwal,
 			true,
 			&req.AlasanPerubahan,
-			tanggalD,
-			req.JamMulaiDiajukan,
-			req.JamSelesaiDiajukan,
+			&waktuMulaiD,
+			&waktuSelesaiD,
 		)
 		if err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal ajukan perubahan jadwal: "+err.Error())
@@ -393,19 +496,46 @@ func UpdateJadwalHandler(db *sql.DB) http.HandlerFunc {
 		if req.PendaftarID != nil {
 			jadwal.PendaftarID = req.PendaftarID
 		}
-		if req.Tanggal != nil {
-			tanggal, err := time.Parse("2006-01-02", *req.Tanggal)
+		// Tanggal/jam dikirim sebagai jam dinding; pergantian zona mempertahankan jam dinding yang sama
+		loc := utils.ZonaWaktuOrUTC(jadwal.ZonaWaktu)
+		mulaiLokal := jadwal.WaktuMulai.In(loc)
+		tanggal := mulaiLokal.Format("2006-01-02")
+		jamMulai := mulaiLokal.Format("15:04:05")
+		jamSelesai := jadwal.WaktuSelesai.In(loc).Format("15:04:05")
+		if req.ZonaWaktu != nil && *req.ZonaWaktu != "" {
+			loc, err = utils.LoadZonaWaktu(*req.ZonaWaktu)
 			if err != nil {
-				utils.Error(w, http.StatusBadRequest, "Format tanggal tidak valid")
+				utils.Error(w, http.StatusBadRequest, "Zona waktu tidak dikenal, gunakan nama IANA seperti Asia/Makassar")
 				return
 			}
-			jadwal.Tanggal = tanggal
+			jadwal.ZonaWaktu = *req.ZonaWaktu
 		}
-		if req.JamMulai != nil {
-			jadwal.JamMulai = *req.JamMulai
-		}
-		if req.JamSelesai != nil {
-			jadwal.JamSelesai = *req.JamSelesai
+		if req.Tanggal != nil || req.JamMulai != nil || req.JamSelesai != nil || req.ZonaWaktu != nil {
+			if req.Tanggal != nil {
+				tanggal = *req.Tanggal
+			}
+			if req.JamMulai != nil {
+				jamMulai = *req.JamMulai
+			}
+			if req.JamSelesai != nil {
+				jamSelesai = *req.JamSelesai
+			}
+			waktuMulai, err := utils.WaktuLokalKeUTC(tanggal, jamMulai, loc)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Format tanggal atau jam_mulai tidak valid")
+				return
+			}
+			waktuSelesai, err := utils.WaktuLokalKeUTC(tanggal, jamSelesai, loc)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Format jam_selesai tidak valid")
+				return
+			}
+			if !waktuSelesai.After(waktuMulai) {
+				utils.Error(w, http.StatusBadRequest, "Jam selesai harus setelah jam mulai")
+				return
+			}
+			jadwal.WaktuMulai = waktuMulai
+			jadwal.WaktuSelesai = waktuSelesai
 		}
 		if req.Tempat != nil {
 			jadwal.Tempat = *req.Tempat
@@ -420,22 +550,18 @@ func UpdateJadwalHandler(db *sql.DB) http.HandlerFunc {
 
 			// 🔁 Terapkan perubahan jika dikonfirmasi
 			if status == "dikonfirmasi" && jadwal.PengajuanPerubahan {
-				if jadwal.TanggalDiajukan != nil {
-					jadwal.Tanggal = *jadwal.TanggalDiajukan
-				}
-				if jadwal.JamMulaiDiajukan != nil {
-					jadwal.JamMulai = *jadwal.JamMulaiDiajukan
+				if jadwal.WaktuMulaiDiajukan != nil {
+					jadwal.WaktuMulai = *jadwal.WaktuMulaiDiajukan
 				}
-				if jadwal.JamSelesaiDiajukan != nil {
-					jadwal.JamSelesai = *jadwal.JamSelesaiDiajukan
+				if jadwal.WaktuSelesaiDiajukan != nil {
+					jadwal.WaktuSelesai = *jadwal.WaktuSelesaiDiajukan
 				}
 
 				// Reset pengajuan
 				jadwal.PengajuanPerubahan = false
 				jadwal.AlasanPerubahan = nil
-				jadwal.TanggalDiajukan = nil
-				jadwal.JamMulaiDiajukan = nil
-				jadwal.JamSelesaiDiajukan = nil
+				jadwal.WaktuMulaiDiajukan = nil
+				jadwal.WaktuSelesaiDiajukan = nil
 			}
 		}
 		if req.Catatan != nil {
@@ -448,12 +574,56 @@ func UpdateJadwalHandler(db *sql.DB) http.HandlerFunc {
 			}
 			jadwal.JenisJadwal = *req.JenisJadwal
 		}
+		if req.Kapasitas != nil {
+			jadwal.Kapasitas = req.Kapasitas
+		}
+		if req.BatasPendaftaran != nil {
+			batas, err := time.ParseInLocation("2006-01-02 15:04:05", *req.BatasPendaftaran, loc)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Format batas_pendaftaran tidak valid")
+				return
+			}
+			batas = batas.UTC()
+			jadwal.BatasPendaftaran = &batas
+		}
+		for _, field := range req.Kosongkan {
+			switch field {
+			case "kapasitas":
+				jadwal.Kapasitas = nil
+			case "batas_pendaftaran":
+				jadwal.BatasPendaftaran = nil
+			}
+		}
+		if jadwal.JenisJadwal != "umum" && (jadwal.Kapasitas != nil || jadwal.BatasPendaftaran != nil) {
+			utils.Error(w, http.StatusBadRequest, "Kapasitas dan batas pendaftaran hanya untuk jadwal umum")
+			return
+		}
 
 		if err := services.UpdateJadwal(db, jadwal); err != nil {
+			if errors.Is(err, services.ErrKapasitasDiBawahPeserta) {
+				utils.Error(w, http.StatusConflict, err.Error())
+				return
+			}
 			utils.Error(w, http.StatusInternalServerError, "Gagal memperbarui jadwal: "+err.Error())
 			return
 		}
 
+		if jadwal.JenisJadwal == "pribadi" {
+			if err := services.NotifikasiJadwalPribadi(db, *jadwal, false); err != nil {
+				log.Printf("Gagal kirim notifikasi perubahan jadwal ke user %d: %v", jadwal.UserID, err)
+			}
+		}
+
+		// Kapasitas bertambah: naikkan peserta waitlist yang muat
+		if jadwal.JenisJadwal == "umum" {
+			promoted, err := services.PromosikanWaitlistJadwal(db, jadwal.IDJadwal)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Jadwal diperbarui, tetapi gagal memproses waitlist: "+err.Error())
+				return
+			}
+			notifikasiPromosiWaitlist(db, promoted, jadwal)
+		}
+
 		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
 			"success": true,
 			"message": "Jadwal berhasil diperbarui",
@@ -461,7 +631,6 @@ func UpdateJadwalHandler(db *sql.DB) http.HandlerFunc {
 	}
 }
 
-
 func CancelPengajuanPerubahanHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
@@ -507,7 +676,7 @@ func CancelPengajuanPerubahanHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		err = services.UpdatePengajuanPerubahan(db, idJadwal, false, nil, nil, nil, nil)
+		err = services.UpdatePengajuanPerubahan(db, idJadwal, false, nil, nil, nil)
 		if err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal batalkan pengajuan: "+err.Error())
 			return
@@ -555,6 +724,10 @@ func DeleteJadwalHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		if err := services.DeleteJadwal(db, idJadwal); err != nil {
+			if err == services.ErrJadwalDipakaiPengumuman {
+				utils.Error(w, http.StatusConflict, err.Error())
+				return
+			}
 			utils.Error(w, http.StatusInternalServerError, "Gagal menghapus jadwal: "+err.Error())
 			return
 		}
@@ -603,4 +776,4 @@ func GetUsersForJadwalHandler(db *sql.DB) http.HandlerFunc {
 
 		utils.JSONResponse(w, http.StatusOK, users)
 	}
-}
\ No newline at end of file
+}
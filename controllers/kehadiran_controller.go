@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"strings"
+)
+
+// GetQRCheckinHandler menghasilkan QR PNG berisi token check-in untuk jadwal milik user
+func GetQRCheckinHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		idJadwal, ok := parseIDJadwalQuery(w, r)
+		if !ok {
+			return
+		}
+
+		jadwal, err := services.GetJadwalByID(db, idJadwal)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Jadwal tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil data jadwal: "+err.Error())
+			return
+		}
+
+		diharapkan, err := services.IsPesertaDiharapkan(db, idJadwal, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa peserta jadwal: "+err.Error())
+			return
+		}
+		if !diharapkan {
+			utils.Error(w, http.StatusForbidden, "QR hanya tersedia untuk jadwal yang sudah dikonfirmasi atau acara yang Anda ikuti")
+			return
+		}
+
+		token := utils.GenerateCheckinToken(idJadwal, claims.IDUser, jadwal.WaktuSelesai)
+		png, err := utils.GenerateQRPNG(token, 320)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal membuat QR code")
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		w.Write(png)
+	}
+}
+
+// CheckinHandler mencatat kehadiran dari token QR, atau secara manual dengan id_jadwal dan user_id
+func CheckinHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.CheckinRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var idJadwal, userID int
+		metode := services.MetodeKehadiranQR
+		switch {
+		case req.Token != nil && strings.TrimSpace(*req.Token) != "":
+			var err error
+			idJadwal, userID, err = utils.VerifyCheckinToken(*req.Token)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case req.IDJadwal != nil && req.UserID != nil:
+			idJadwal, userID = *req.IDJadwal, *req.UserID
+			metode = services.MetodeKehadiranManual
+		default:
+			utils.Error(w, http.StatusBadRequest, "Token atau id_jadwal dan user_id wajib diisi")
+			return
+		}
+
+		err := services.CatatKehadiran(db, idJadwal, userID, claims.IDUser, metode)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Jadwal tidak ditemukan")
+			case services.ErrBukanPesertaJadwal:
+				utils.Error(w, http.StatusForbidden, err.Error())
+			case services.ErrDiLuarJendelaCheckin:
+				utils.Error(w, http.StatusBadRequest, err.Error())
+			case services.ErrSudahCheckin:
+				utils.Error(w, http.StatusConflict, err.Error())
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal mencatat kehadiran: "+err.Error())
+			}
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success":   true,
+			"message":   "Kehadiran berhasil dicatat",
+			"id_jadwal": idJadwal,
+			"user_id":   userID,
+		})
+	}
+}
+
+// GetKehadiranJadwalHandler menampilkan status hadir peserta pada satu jadwal
+func GetKehadiranJadwalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idJadwal, ok := parseIDJadwalQuery(w, r)
+		if !ok {
+			return
+		}
+
+		list, err := services.GetKehadiranJadwal(db, idJadwal)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil kehadiran: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, list)
+	}
+}
+
+// GetLaporanKehadiranHandler menampilkan rekap kehadiran dan no-show per user
+func GetLaporanKehadiranHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		laporan, err := services.GetLaporanKehadiran(db)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil laporan kehadiran: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, laporan)
+	}
+}
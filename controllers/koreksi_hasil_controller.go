@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+)
+
+// PenilaianUlangHandler menilai ulang semua hasil tes (?id_test=) setelah kunci soal diperbaiki
+func PenilaianUlangHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.PenilaianUlangRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		berubah, err := services.PenilaianUlangTest(db, idTest, req.Alasan, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menilai ulang tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success":       true,
+			"message":       "Penilaian ulang selesai",
+			"hasil_berubah": berubah,
+		})
+	}
+}
+
+// PenyesuaianNilaiHandler menetapkan penyesuaian poin manual sebuah hasil tes dengan alasan
+func PenyesuaianNilaiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.PenyesuaianNilaiRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		hasil, err := services.SesuaikanPoinHasil(db, req.IDHasil, *req.Penyesuaian, req.Alasan, claims.IDUser)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Hasil tes tidak ditemukan")
+			case services.ErrSesiBelumSelesai:
+				utils.Error(w, http.StatusConflict, "Sesi tes belum selesai")
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyesuaikan nilai: "+err.Error())
+			}
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success":          true,
+			"message":          "Nilai berhasil disesuaikan",
+			"penyesuaian_poin": hasil.PenyesuaianPoin,
+			"poin":             hasil.Poin,
+			"total_poin":       hasil.TotalPoin,
+			"nilai":            hasil.Nilai,
+		})
+	}
+}
+
+// IzinUlangHandler memberi seorang peserta satu percobaan tambahan pada sebuah tes
+func IzinUlangHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.IzinUlangRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		izin, err := services.BeriIzinUlang(db, req.IDTest, req.UserID, req.Alasan, claims.IDUser)
+		if err != nil {
+			switch err {
+			case services.ErrBelumPernahMengikuti:
+				utils.Error(w, http.StatusBadRequest, "Peserta belum pernah menyelesaikan tes ini")
+			case services.ErrIzinUlangSudahAda:
+				utils.Error(w, http.StatusConflict, "Peserta masih memiliki izin ulang yang belum dipakai")
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal memberi izin ulang: "+err.Error())
+			}
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "Peserta dapat mengulang tes satu kali",
+			"data":    izin,
+		})
+	}
+}
+
+// AuditHasilHandler menampilkan jejak audit koreksi hasil sebuah tes (?id_test=)
+func AuditHasilHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		audit, err := services.GetAuditHasilTest(db, idTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil audit hasil tes: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, audit)
+	}
+}
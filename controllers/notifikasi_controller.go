@@ -1,17 +1,46 @@
 package controllers
 
 import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
 	"cocopen-backend/utils"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 )
 
+const (
+	// Interval komentar keep-alive agar proxy tidak menutup koneksi yang diam
+	intervalPingNotifikasi = 30 * time.Second
+	// Jeda sambung ulang yang disarankan ke EventSource (milidetik)
+	jedaSambungUlangNotifikasi = 5000
+)
+
+// tulisEventSSE menulis satu event dengan framing SSE (id, event, data)
+func tulisEventSSE(w http.ResponseWriter, e dto.NotifikasiEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Jenis, data)
+	return err
+}
+
+// NotifikasiStreamHandler mengalirkan notifikasi lewat Server-Sent Events. Klien yang tersambung
+// ulang mengirim Last-Event-ID (header, atau ?last_event_id= bila header tidak bisa diatur) untuk
+// menerima event yang terlewat selama masih ada di riwayat broker.
 func NotifikasiStreamHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("X-Accel-Buffering", "no")
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+	if !ok {
+		utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+		return
+	}
 
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -19,22 +48,293 @@ func NotifikasiStreamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.Write([]byte(":\n\n"))
+	idTerakhir := r.Header.Get("Last-Event-ID")
+	if idTerakhir == "" {
+		idTerakhir = r.URL.Query().Get("last_event_id")
+	}
+	var sejakID int64
+	if idTerakhir != "" {
+		id, err := strconv.ParseInt(idTerakhir, 10, 64)
+		if err != nil || id < 0 {
+			utils.Error(w, http.StatusBadRequest, "Last-Event-ID tidak valid")
+			return
+		}
+		sejakID = id
+	}
+
+	events, putarUlang, batal := utils.Notifikasi.Langganan(claims.IDUser, claims.Role, sejakID)
+	defer batal()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", jedaSambungUlangNotifikasi)
+	for _, e := range putarUlang {
+		if err := tulisEventSSE(w, e); err != nil {
+			return
+		}
+	}
 	flusher.Flush()
 
+	ping := time.NewTicker(intervalPingNotifikasi)
+	defer ping.Stop()
+
 	for {
 		select {
-		case event := <-utils.NotifikasiChan:
-			data, err := json.Marshal(event)
-			if err != nil {
-				continue
+		case <-r.Context().Done():
+			return
+
+		case e, ok := <-events:
+			// Channel ditutup broker karena koneksi tertinggal: akhiri agar klien tersambung ulang
+			if !ok {
+				return
+			}
+			if err := tulisEventSSE(w, e); err != nil {
+				return
 			}
-			w.Write([]byte(" " + string(data) + "\n\n"))
 			flusher.Flush()
 
-		case <-time.After(30 * time.Second):
-			w.Write([]byte(":\n\n"))
+		case <-ping.C:
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
 			flusher.Flush()
 		}
 	}
 }
+
+// NotifikasiHandler menampilkan kotak masuk user dari yang terbaru beserta jumlah belum dibaca.
+// Query: belum_dibaca=true untuk yang belum dibaca saja, limit, dan sebelum=<id_notifikasi>
+// untuk halaman berikutnya.
+func NotifikasiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		q := r.URL.Query()
+		limit := services.DefaultLimitNotifikasi
+		if s := q.Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > services.MaxLimitNotifikasi {
+				utils.Error(w, http.StatusBadRequest, fmt.Sprintf("limit harus 1..%d", services.MaxLimitNotifikasi))
+				return
+			}
+			limit = n
+		}
+		var sebelumID int64
+		if s := q.Get("sebelum"); s != "" {
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || id < 1 {
+				utils.Error(w, http.StatusBadRequest, "Parameter sebelum tidak valid")
+				return
+			}
+			sebelumID = id
+		}
+		hanyaBelumDibaca := q.Get("belum_dibaca") == "true"
+
+		daftar, err := services.GetNotifikasiUser(db, claims.IDUser, claims.Role, hanyaBelumDibaca, sebelumID, limit)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil notifikasi: "+err.Error())
+			return
+		}
+		belumDibaca, err := services.JumlahNotifikasiBelumDibaca(db, claims.IDUser, claims.Role)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menghitung notifikasi: "+err.Error())
+			return
+		}
+
+		// sebelum untuk halaman berikutnya; nil bila sudah habis
+		var berikutnya *int64
+		if len(daftar) == limit {
+			berikutnya = &daftar[len(daftar)-1].IDNotifikasi
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"notifikasi":   daftar,
+			"belum_dibaca": belumDibaca,
+			"berikutnya":   berikutnya,
+		})
+	}
+}
+
+// JumlahNotifikasiBelumDibacaHandler mengembalikan jumlah notifikasi yang belum dibaca (untuk badge)
+func JumlahNotifikasiBelumDibacaHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		jumlah, err := services.JumlahNotifikasiBelumDibaca(db, claims.IDUser, claims.Role)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menghitung notifikasi: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"belum_dibaca": jumlah,
+		})
+	}
+}
+
+// BacaNotifikasiHandler menandai satu notifikasi dibaca
+func BacaNotifikasiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.BacaNotifikasiRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if err := services.BacaNotifikasi(db, req.IDNotifikasi, claims.IDUser, claims.Role); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Notifikasi tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal menandai notifikasi: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Notifikasi ditandai sudah dibaca",
+		})
+	}
+}
+
+// BacaSemuaNotifikasiHandler menandai seluruh notifikasi user dibaca
+func BacaSemuaNotifikasiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		jumlah, err := services.BacaSemuaNotifikasi(db, claims.IDUser, claims.Role)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menandai notifikasi: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Semua notifikasi ditandai sudah dibaca",
+			"jumlah":  jumlah,
+		})
+	}
+}
+
+// KunciPublikPushHandler mengembalikan kunci publik VAPID untuk pushManager.subscribe
+func KunciPublikPushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"aktif":        utils.WebPushAktif(),
+		"kunci_publik": utils.KunciPublikVAPID(),
+	})
+}
+
+// LanggananPushHandler mendaftarkan (POST) atau melepas (DELETE) langganan Web Push browser user
+func LanggananPushHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			if !utils.WebPushAktif() {
+				utils.Error(w, http.StatusServiceUnavailable, "Web Push belum dikonfigurasi di server")
+				return
+			}
+
+			var req dto.LanggananPushRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			langganan := models.LanggananPush{
+				UserID:   claims.IDUser,
+				Endpoint: req.Endpoint,
+				P256dh:   req.Keys.P256dh,
+				Auth:     req.Keys.Auth,
+			}
+			if ua := utils.UserAgent(r); ua != "" {
+				langganan.UserAgent = &ua
+			}
+
+			if err := services.SimpanLanggananPush(db, langganan); err != nil {
+				if errors.Is(err, utils.ErrLanggananPushTidakValid) {
+					utils.Error(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan langganan push: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+				"success": true,
+				"message": "Langganan push berhasil didaftarkan",
+			})
+
+		case http.MethodDelete:
+			var req dto.HapusLanggananPushRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if err := services.HapusLanggananPush(db, claims.IDUser, req.Endpoint); err != nil {
+				if err == sql.ErrNoRows {
+					utils.Error(w, http.StatusNotFound, "Langganan push tidak ditemukan")
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menghapus langganan push: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Langganan push berhasil dihapus",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST dan DELETE yang diizinkan")
+		}
+	}
+}
@@ -2,11 +2,12 @@ package controllers
 
 import (
 	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
 	"cocopen-backend/models"
 	"cocopen-backend/services"
 	"cocopen-backend/utils"
-	"cocopen-backend/middleware"
 	"database/sql"
+	"log"
 	"net/http"
 	"path/filepath"
 	"strconv"
@@ -14,117 +15,115 @@ import (
 )
 
 func CreatePendaftar(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-
-        // Hanya POST
-        if r.Method != http.MethodPost {
-            utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
-            return
-        }
-
-        // Tidak memakai JWT
-        var userID *int = nil
-
-        // Parse multipart (Wajib untuk file upload)
-        if err := r.ParseMultipartForm(10 << 20); err != nil {
-            utils.Error(w, http.StatusBadRequest, "Gagal memproses form-data: "+err.Error())
-            return
-        }
-
-        // Ambil field form sesuai name di frontend
-        req := dto.CreatePendaftarRequest{
-            NamaLengkap:        strings.TrimSpace(r.FormValue("nama_lengkap")),
-            AsalKampus:         strings.TrimSpace(r.FormValue("asal_kampus")),
-            Prodi:              strings.TrimSpace(r.FormValue("prodi")),
-            Semester:           strings.TrimSpace(r.FormValue("semester")),
-            NoWA:               strings.TrimSpace(r.FormValue("no_wa")),
-            Domisili:           r.FormValue("domisili"),
-            AlamatSekarang:     r.FormValue("alamat_sekarang"),
-            TinggalDengan:      r.FormValue("tinggal_dengan"),
-            AlasanMasuk:        r.FormValue("alasan_masuk"),
-            PengetahuanCoconut: r.FormValue("pengetahuan_coconut"),
-        }
-
-        // Validasi field wajib
-        if req.NamaLengkap == "" || req.AsalKampus == "" || req.Prodi == "" || req.NoWA == "" {
-            utils.Error(w, http.StatusBadRequest,
-                "Field wajib belum lengkap: nama_lengkap, asal_kampus, prodi, no_wa")
-            return
-        }
-
-        // --- Ambil file foto ---
-        file, header, err := r.FormFile("foto")
-        if err != nil {
-            if err == http.ErrMissingFile {
-                utils.Error(w, http.StatusBadRequest, "Foto wajib diunggah")
-                return
-            }
-            utils.Error(w, http.StatusBadRequest, "Gagal membaca file foto: "+err.Error())
-            return
-        }
-        defer file.Close()
-
-        // Validasi ekstensi
-        ext := strings.ToLower(filepath.Ext(header.Filename))
-        allowed := map[string]bool{
-            ".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
-        }
-        if !allowed[ext] {
-            utils.Error(w, http.StatusBadRequest,
-                "Format foto tidak didukung (hanya .jpg, .jpeg, .png, .gif)")
-            return
-        }
-
-        // Validasi ukuran (maks 2MB)
-        if header.Size > 2<<20 {
-            utils.Error(w, http.StatusBadRequest, "Ukuran file maksimal 2 MB")
-            return
-        }
-
-        // Upload foto memakai util
-        fotoName, err := utils.UploadFoto(file, header, utils.FotoPendaftarPath)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError,
-                "Gagal mengunggah foto: "+err.Error())
-            return
-        }
-
-        // Buat model untuk database
-        pendaftar := models.Pendaftar{
-            NamaLengkap:        req.NamaLengkap,
-            AsalKampus:         req.AsalKampus,
-            Prodi:              req.Prodi,
-            Semester:           req.Semester,
-            NoWA:               req.NoWA,
-            Domisili:           req.Domisili,
-            AlamatSekarang:     req.AlamatSekarang,
-            TinggalDengan:      req.TinggalDengan,
-            AlasanMasuk:        req.AlasanMasuk,
-            PengetahuanCoconut: req.PengetahuanCoconut,
-            FotoPath:           fotoName,
-            Status:             "pending",
-            UserID:             userID, // tidak perlu login
-        }
-
-        // Simpan ke database
-        if err := services.CreatePendaftar(db, pendaftar); err != nil {
-            utils.Error(w, http.StatusInternalServerError,
-                "Gagal menambahkan pendaftar: "+err.Error())
-            return
-        }
-
-        // Respon sukses
-        utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
-            "success": true,
-            "message": "Pendaftar berhasil dibuat",
-            "data": map[string]string{
-                "foto_url": "/uploads/foto_pendaftar/" + fotoName,
-            },
-        })
-    }
-}
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		// Hanya POST
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		// Tidak memakai JWT
+		var userID *int = nil
+
+		// Parse multipart (Wajib untuk file upload)
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			utils.Error(w, http.StatusBadRequest, "Gagal memproses form-data: "+err.Error())
+			return
+		}
 
+		// Ambil field form sesuai name di frontend
+		req := dto.CreatePendaftarRequest{
+			NamaLengkap:        strings.TrimSpace(r.FormValue("nama_lengkap")),
+			AsalKampus:         strings.TrimSpace(r.FormValue("asal_kampus")),
+			Prodi:              strings.TrimSpace(r.FormValue("prodi")),
+			Semester:           strings.TrimSpace(r.FormValue("semester")),
+			NoWA:               strings.TrimSpace(r.FormValue("no_wa")),
+			Domisili:           r.FormValue("domisili"),
+			AlamatSekarang:     r.FormValue("alamat_sekarang"),
+			TinggalDengan:      r.FormValue("tinggal_dengan"),
+			AlasanMasuk:        r.FormValue("alasan_masuk"),
+			PengetahuanCoconut: r.FormValue("pengetahuan_coconut"),
+		}
 
+		// Validasi field wajib
+		if req.NamaLengkap == "" || req.AsalKampus == "" || req.Prodi == "" || req.NoWA == "" {
+			utils.Error(w, http.StatusBadRequest,
+				"Field wajib belum lengkap: nama_lengkap, asal_kampus, prodi, no_wa")
+			return
+		}
+
+		// --- Ambil file foto ---
+		file, header, err := r.FormFile("foto")
+		if err != nil {
+			if err == http.ErrMissingFile {
+				utils.Error(w, http.StatusBadRequest, "Foto wajib diunggah")
+				return
+			}
+			utils.Error(w, http.StatusBadRequest, "Gagal membaca file foto: "+err.Error())
+			return
+		}
+		defer file.Close()
+
+		// Validasi ekstensi
+		ext := strings.ToLower(filepath.Ext(header.Filename))
+		allowed := map[string]bool{
+			".jpg": true, ".jpeg": true, ".png": true, ".gif": true,
+		}
+		if !allowed[ext] {
+			utils.Error(w, http.StatusBadRequest,
+				"Format foto tidak didukung (hanya .jpg, .jpeg, .png, .gif)")
+			return
+		}
+
+		// Validasi ukuran (maks 2MB)
+		if header.Size > 2<<20 {
+			utils.Error(w, http.StatusBadRequest, "Ukuran file maksimal 2 MB")
+			return
+		}
+
+		// Upload foto memakai util
+		fotoName, err := utils.UploadFoto(file, header, utils.FotoPendaftarPath)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError,
+				"Gagal mengunggah foto: "+err.Error())
+			return
+		}
+
+		// Buat model untuk database
+		pendaftar := models.Pendaftar{
+			NamaLengkap:        req.NamaLengkap,
+			AsalKampus:         req.AsalKampus,
+			Prodi:              req.Prodi,
+			Semester:           req.Semester,
+			NoWA:               req.NoWA,
+			Domisili:           req.Domisili,
+			AlamatSekarang:     req.AlamatSekarang,
+			TinggalDengan:      req.TinggalDengan,
+			AlasanMasuk:        req.AlasanMasuk,
+			PengetahuanCoconut: req.PengetahuanCoconut,
+			FotoPath:           fotoName,
+			Status:             "pending",
+			UserID:             userID, // tidak perlu login
+		}
+
+		// Simpan ke database
+		if err := services.CreatePendaftar(db, pendaftar); err != nil {
+			utils.Error(w, http.StatusInternalServerError,
+				"Gagal menambahkan pendaftar: "+err.Error())
+			return
+		}
+
+		// Respon sukses
+		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "Pendaftar berhasil dibuat",
+			"data": map[string]string{
+				"foto_url": "/uploads/foto_pendaftar/" + fotoName,
+			},
+		})
+	}
+}
 
 func GetAllPendaftar(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -160,23 +159,23 @@ func GetAllPendaftar(db *sql.DB) http.HandlerFunc {
 			}
 
 			result = append(result, map[string]interface{}{
-				"id_pendaftar":         p.IDPendaftar,
-				"nama_lengkap":         p.NamaLengkap,
-				"asal_kampus":          p.AsalKampus,
-				"prodi":                p.Prodi,
-				"semester":             p.Semester,
-				"no_wa":                p.NoWA,
-				"domisili":             p.Domisili,
-				"alamat_sekarang":      p.AlamatSekarang,
-				"tinggal_dengan":       p.TinggalDengan,
-				"alasan_masuk":         p.AlasanMasuk,
-				"pengetahuan_coconut":  p.PengetahuanCoconut,
-				"foto_path":            p.FotoPath,
-				"foto_url":             fotoURL, // 👈 ini yang frontend butuhkan
-				"created_at":           p.CreatedAt,
-				"updated_at":           p.UpdatedAt,
-				"status":               p.Status,
-				"user_id":              p.UserID,
+				"id_pendaftar":        p.IDPendaftar,
+				"nama_lengkap":        p.NamaLengkap,
+				"asal_kampus":         p.AsalKampus,
+				"prodi":               p.Prodi,
+				"semester":            p.Semester,
+				"no_wa":               p.NoWA,
+				"domisili":            p.Domisili,
+				"alamat_sekarang":     p.AlamatSekarang,
+				"tinggal_dengan":      p.TinggalDengan,
+				"alasan_masuk":        p.AlasanMasuk,
+				"pengetahuan_coconut": p.PengetahuanCoconut,
+				"foto_path":           p.FotoPath,
+				"foto_url":            fotoURL, // 👈 ini yang frontend butuhkan
+				"created_at":          p.CreatedAt,
+				"updated_at":          p.UpdatedAt,
+				"status":              p.Status,
+				"user_id":             p.UserID,
 			})
 		}
 
@@ -213,7 +212,54 @@ func GetPendaftarByID(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		utils.JSONResponse(w, http.StatusOK, p)
+		detail := dto.PendaftarDetailResponse{
+			Pendaftar: p,
+			HasilTest: []dto.RingkasanHasilTest{},
+		}
+		if p.FotoPath != "" {
+			detail.FotoURL = "/uploads/foto_pendaftar/" + p.FotoPath
+		}
+
+		hasilList, err := services.GetHasilByPendaftarID(db, p.IDPendaftar)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil hasil tes pendaftar")
+			return
+		}
+		for _, h := range hasilList {
+			ringkasan := dto.RingkasanHasilTest{
+				IDHasil:   h.IDHasil,
+				IDTest:    h.IDTest,
+				JudulTest: h.JudulTest,
+				Nilai:     h.Nilai,
+			}
+			ringkasan.TipeKepribadian = h.TipeKepribadian
+			if h.WaktuSelesai != nil {
+				t := h.WaktuSelesai.Format("2006-01-02 15:04:05")
+				ringkasan.WaktuSelesai = &t
+			}
+			detail.HasilTest = append(detail.HasilTest, ringkasan)
+		}
+
+		jumlah, rata, err := services.GetRingkasanWawancaraPendaftar(db, p.IDPendaftar)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil nilai wawancara pendaftar")
+			return
+		}
+		detail.Wawancara = dto.RingkasanWawancara{
+			JumlahPenilaian: jumlah,
+			RataRata:        rata,
+		}
+
+		if p.UserID != nil {
+			kehadiran, err := services.GetRingkasanKehadiranUser(db, *p.UserID)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal mengambil kehadiran pendaftar")
+				return
+			}
+			detail.Kehadiran = &kehadiran
+		}
+
+		utils.JSONResponse(w, http.StatusOK, detail)
 	}
 }
 
@@ -248,11 +294,25 @@ func UpdatePendaftar(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		lama, err := services.GetPendaftarByID(db, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Pendaftar tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil pendaftar")
+			return
+		}
+
 		if err := services.UpdatePendaftar(db, id, status); err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal memperbarui pendaftar")
 			return
 		}
 
+		if err := services.NotifikasiStatusPendaftar(db, lama, status); err != nil {
+			log.Printf("Gagal kirim notifikasi status pendaftar %d: %v", id, err)
+		}
+
 		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
 			"success": true,
 			"message": "Pendaftar berhasil diperbarui",
@@ -301,25 +361,25 @@ func DeletePendaftar(db *sql.DB) http.HandlerFunc {
 }
 
 func GetMyPendaftar(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
-            return
-        }
-
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok {
-            utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
-            return
-        }
-
-        pendaftarList, err := services.GetPendaftarByUserID(db, claims.IDUser)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal mengambil data pendaftaran")
-            return
-        }
-
-        // Kirim response
-        utils.JSONResponse(w, http.StatusOK, pendaftarList)
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		pendaftarList, err := services.GetPendaftarByUserID(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil data pendaftaran")
+			return
+		}
+
+		// Kirim response
+		utils.JSONResponse(w, http.StatusOK, pendaftarList)
+	}
 }
@@ -1,18 +1,21 @@
 package controllers
 
 import (
-	"database/sql"
 	"cocopen-backend/dto"
 	"cocopen-backend/middleware"
 	"cocopen-backend/models"
 	"cocopen-backend/services"
 	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
 )
 
-// GetPengumumanHandler mengambil semua pengumuman
+// GetPengumumanHandler mengambil pengumuman: admin melihat semua (termasuk draf dan terjadwal),
+// user hanya melihat pengumuman yang sedang tayang dan menyasar dirinya
 func GetPengumumanHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -20,7 +23,19 @@ func GetPengumumanHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		pengumumans, err := services.GetAllPengumuman(db)
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		var pengumumans []models.Pengumuman
+		var err error
+		if claims.Role == "admin" {
+			pengumumans, err = services.GetAllPengumuman(db)
+		} else {
+			pengumumans, err = services.GetPengumumanUntukUser(db, claims.IDUser)
+		}
 		if err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal ambil pengumuman")
 			return
@@ -29,10 +44,17 @@ func GetPengumumanHandler(db *sql.DB) http.HandlerFunc {
 		var results []dto.PengumumanResponse
 		now := time.Now()
 		for _, p := range pengumumans {
-			diff := now.Sub(p.CreatedAt)
+			// Pengumuman terjadwal dihitung sejak waktu tayangnya
+			tayang := p.CreatedAt
+			if p.PublishAt != nil {
+				tayang = *p.PublishAt
+			}
+			diff := now.Sub(tayang)
 
 			var waktuLalu string
 			switch {
+			case diff < 0:
+				waktuLalu = "terjadwal"
 			case diff.Seconds() < 60:
 				waktuLalu = "baru"
 			case diff.Minutes() < 60:
@@ -46,11 +68,18 @@ func GetPengumumanHandler(db *sql.DB) http.HandlerFunc {
 			}
 
 			results = append(results, dto.PengumumanResponse{
-				IDPengumuman: p.IDPengumuman,
-				Judul:        p.Judul,
-				Isi:          p.Isi,
-				CreatedAt:    p.CreatedAt.Format("2006-01-02 15:04:05"),
-				WaktuLalu:    waktuLalu,
+				IDPengumuman:    p.IDPengumuman,
+				Judul:           p.Judul,
+				Isi:             p.Isi,
+				Sasaran:         p.Sasaran,
+				StatusPendaftar: p.StatusPendaftar,
+				IDTest:          p.IDTest,
+				IDJadwal:        p.IDJadwal,
+				Status:          p.Status,
+				PublishAt:       p.PublishAt,
+				ExpireAt:        p.ExpireAt,
+				CreatedAt:       p.CreatedAt.Format("2006-01-02 15:04:05"),
+				WaktuLalu:       waktuLalu,
 			})
 		}
 
@@ -79,21 +108,31 @@ func CreatePengumumanHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		pengumuman := models.Pengumuman{
-			Judul: req.Judul,
-			Isi:   req.Isi,
+			Judul:      req.Judul,
+			Isi:        req.Isi,
+			DibuatOleh: &claims.IDUser,
 		}
+		terapkanPengumumanRequest(&pengumuman, req)
 
-		err := services.CreatePengumuman(db, pengumuman)
+		err := services.CreatePengumuman(db, &pengumuman)
 		if err != nil {
+			if errors.Is(err, services.ErrPengumumanTidakValid) {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			utils.Error(w, http.StatusInternalServerError, "Gagal buat pengumuman")
 			return
 		}
-		
-		utils.BroadcastNotifikasi(req.Judul, req.Isi)
+
+		// Draf dan pengumuman terjadwal dikirim nanti saat diterbitkan / oleh scheduler
+		if _, err := services.KirimNotifikasiPengumuman(db, pengumuman.IDPengumuman); err != nil {
+			log.Printf("Gagal kirim notifikasi pengumuman %d: %v", pengumuman.IDPengumuman, err)
+		}
 
 		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
-			"success": true,
-			"message": "Pengumuman berhasil dibuat",
+			"success":       true,
+			"message":       "Pengumuman berhasil dibuat",
+			"id_pengumuman": pengumuman.IDPengumuman,
 		})
 	}
 }
@@ -137,13 +176,23 @@ func UpdatePengumumanHandler(db *sql.DB) http.HandlerFunc {
 
 		p.Judul = req.Judul
 		p.Isi = req.Isi
+		terapkanPengumumanRequest(p, req)
 
 		err = services.UpdatePengumuman(db, p)
 		if err != nil {
+			if errors.Is(err, services.ErrPengumumanTidakValid) {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			utils.Error(w, http.StatusInternalServerError, "Gagal update pengumuman")
 			return
 		}
 
+		// Draf yang baru diterbitkan langsung dikirim ke sasarannya
+		if _, err := services.KirimNotifikasiPengumuman(db, p.IDPengumuman); err != nil {
+			log.Printf("Gagal kirim notifikasi pengumuman %d: %v", p.IDPengumuman, err)
+		}
+
 		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
 			"success": true,
 			"message": "Pengumuman berhasil diperbarui",
@@ -151,6 +200,45 @@ func UpdatePengumumanHandler(db *sql.DB) http.HandlerFunc {
 	}
 }
 
+// terapkanPengumumanRequest menyalin field opsional yang diisi pada request ke pengumuman
+func terapkanPengumumanRequest(p *models.Pengumuman, req dto.PengumumanCreateRequest) {
+	if req.IdJadwal != nil {
+		p.IDJadwal = req.IdJadwal
+	}
+	if req.Sasaran != nil {
+		p.Sasaran = *req.Sasaran
+	}
+	if req.StatusPendaftar != nil {
+		p.StatusPendaftar = req.StatusPendaftar
+	}
+	if req.IDTest != nil {
+		p.IDTest = req.IDTest
+	}
+	if req.Status != nil {
+		p.Status = *req.Status
+	}
+	if req.PublishAt != nil {
+		p.PublishAt = req.PublishAt
+	}
+	if req.ExpireAt != nil {
+		p.ExpireAt = req.ExpireAt
+	}
+	for _, field := range req.Kosongkan {
+		switch field {
+		case "publish_at":
+			p.PublishAt = nil
+		case "expire_at":
+			p.ExpireAt = nil
+		case "id_jadwal":
+			p.IDJadwal = nil
+		case "status_pendaftar":
+			p.StatusPendaftar = nil
+		case "id_test":
+			p.IDTest = nil
+		}
+	}
+}
+
 // DeletePengumumanHandler
 func DeletePengumumanHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -183,4 +271,4 @@ func DeletePengumumanHandler(db *sql.DB) http.HandlerFunc {
 			"message": "Pengumuman berhasil dihapus",
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"strconv"
+)
+
+// PenilaianEsaiHandler: GET antrean jawaban esai (opsional ?id_test=), POST menilai satu jawaban
+func PenilaianEsaiHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			idTest := 0
+			if idStr := r.URL.Query().Get("id_test"); idStr != "" {
+				var err error
+				if idTest, err = strconv.Atoi(idStr); err != nil {
+					utils.Error(w, http.StatusBadRequest, "ID tes tidak valid")
+					return
+				}
+			}
+
+			antrean, err := services.GetAntrianPenilaian(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil antrean penilaian: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, antrean)
+
+		case http.MethodPost:
+			var req dto.NilaiEsaiRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			hasil, err := services.NilaiJawabanEsai(db, req.IDJawaban, *req.Poin, req.Catatan, claims.IDUser)
+			if err != nil {
+				switch err {
+				case sql.ErrNoRows:
+					utils.Error(w, http.StatusNotFound, "Jawaban tidak ditemukan")
+				case services.ErrBukanJawabanEsai, services.ErrPoinMelebihiBobot, services.ErrSesiBelumSelesai:
+					utils.Error(w, http.StatusBadRequest, err.Error())
+				default:
+					utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan penilaian: "+err.Error())
+				}
+				return
+			}
+
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success":    true,
+				"message":    "Jawaban esai berhasil dinilai",
+				"id_hasil":   hasil.IDHasil,
+				"poin":       hasil.Poin,
+				"total_poin": hasil.TotalPoin,
+				"nilai":      hasil.Nilai,
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan POST yang diizinkan")
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// PreferensiWAHandler menampilkan (GET) atau mengubah (PUT) langganan pesan WhatsApp user
+func PreferensiWAHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+
+		case http.MethodPut:
+			var req dto.PreferensiWARequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if err := services.SetPreferensiWA(db, claims.IDUser, *req.Aktif); err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan preferensi WhatsApp: "+err.Error())
+				return
+			}
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan PUT yang diizinkan")
+			return
+		}
+
+		pref, err := services.GetPreferensiWA(db, claims.IDUser)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "User tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil preferensi WhatsApp: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, pref)
+	}
+}
+
+// PesanWAHandler menampilkan log pesan WhatsApp beserta status pengirimannya (admin).
+// Query opsional: user_id, status, limit.
+func PesanWAHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		q := r.URL.Query()
+		userID := 0
+		if s := q.Get("user_id"); s != "" {
+			id, err := strconv.Atoi(s)
+			if err != nil || id < 1 {
+				utils.Error(w, http.StatusBadRequest, "user_id tidak valid")
+				return
+			}
+			userID = id
+		}
+		status := q.Get("status")
+		switch status {
+		case "", services.StatusPesanWAAntre, services.StatusPesanWATerkirim, services.StatusPesanWADiterima,
+			services.StatusPesanWADibaca, services.StatusPesanWAGagal:
+		default:
+			utils.Error(w, http.StatusBadRequest, "Status tidak valid")
+			return
+		}
+		limit := 50
+		if s := q.Get("limit"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > services.MaxLimitPesanWA {
+				utils.Error(w, http.StatusBadRequest, fmt.Sprintf("limit harus 1..%d", services.MaxLimitPesanWA))
+				return
+			}
+			limit = n
+		}
+
+		daftar, err := services.GetPesanWA(db, userID, status, limit)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil pesan WhatsApp: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, daftar)
+	}
+}
+
+// KirimUlangPesanWAHandler mengirim ulang pesan WhatsApp berstatus gagal (?id_pesan=) ke nomor
+// terbaru penerimanya (admin)
+func KirimUlangPesanWAHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idPesan, err := strconv.ParseInt(r.URL.Query().Get("id_pesan"), 10, 64)
+		if err != nil || idPesan < 1 {
+			utils.Error(w, http.StatusBadRequest, "id_pesan tidak valid")
+			return
+		}
+
+		if err := services.KirimUlangPesanWA(db, idPesan); err != nil {
+			switch {
+			case err == sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Pesan tidak ditemukan")
+			case errors.Is(err, services.ErrKanalWANonaktif):
+				utils.Error(w, http.StatusServiceUnavailable, err.Error())
+			case errors.Is(err, services.ErrPesanWATidakBisaDikirimUlang):
+				utils.Error(w, http.StatusConflict, err.Error())
+			case errors.Is(err, services.ErrPenerimaWATidakAktif), errors.Is(err, utils.ErrNomorTidakValid):
+				utils.Error(w, http.StatusUnprocessableEntity, err.Error())
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal kirim ulang pesan: "+err.Error())
+			}
+			return
+		}
+
+		pesan, err := services.GetPesanWAByID(db, idPesan)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil pesan WhatsApp: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, pesan)
+	}
+}
+
+// StatusPesanWAHandler menerima callback status pengiriman dari gateway WhatsApp. Gateway
+// mengirim header X-Webhook-Token berisi WA_WEBHOOK_SECRET.
+func StatusPesanWAHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		if !utils.TokenWebhookWAValid(r.Header.Get("X-Webhook-Token")) {
+			utils.Error(w, http.StatusUnauthorized, "Token webhook tidak valid")
+			return
+		}
+
+		var req dto.StatusPesanWARequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		kanal := utils.KanalWhatsApp()
+		if kanal == nil {
+			utils.Error(w, http.StatusServiceUnavailable, "Gateway WhatsApp belum dikonfigurasi")
+			return
+		}
+
+		// ID yang belum dikenal tetap diterima: callback bisa tiba sebelum ID provider tersimpan
+		if err := services.PerbaruiStatusPesanWA(db, kanal.Nama(), req.ID, req.Status, req.Error); err != nil {
+			switch {
+			case errors.Is(err, services.ErrStatusPesanWATidakDikenal):
+				utils.Error(w, http.StatusBadRequest, err.Error())
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal memperbarui status pesan: "+err.Error())
+			}
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+	}
+}
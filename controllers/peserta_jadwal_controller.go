@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// notifikasiPromosiWaitlist memberi tahu user yang naik dari waitlist menjadi peserta terdaftar
+func notifikasiPromosiWaitlist(db *sql.DB, userIDs []int, jadwal *models.Jadwal) {
+	isi := fmt.Sprintf("Anda kini terdaftar pada acara %s di %s.",
+		utils.FormatWaktuLokal(jadwal.WaktuMulai, jadwal.ZonaWaktu), jadwal.Tempat)
+	for _, userID := range userIDs {
+		err := services.KirimNotifikasiUser(db, userID, services.KategoriNotifikasiJadwal, "Pendaftaran Jadwal Dikonfirmasi", isi)
+		if err != nil {
+			log.Printf("Gagal kirim notifikasi promosi waitlist ke user %d: %v", userID, err)
+		}
+	}
+}
+
+func parseIDJadwalQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.URL.Query().Get("id_jadwal")
+	if idStr == "" {
+		utils.Error(w, http.StatusBadRequest, "Parameter id_jadwal wajib diisi")
+		return 0, false
+	}
+	idJadwal, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, "ID jadwal tidak valid")
+		return 0, false
+	}
+	return idJadwal, true
+}
+
+// DaftarJadwalUmumHandler mendaftarkan user ke jadwal umum (masuk waitlist jika penuh)
+func DaftarJadwalUmumHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.DaftarJadwalUmumRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		status, err := services.DaftarJadwalUmum(db, req.IDJadwal, claims.IDUser)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Jadwal tidak ditemukan")
+			case services.ErrBukanJadwalUmum, services.ErrPendaftaranDitutup:
+				utils.Error(w, http.StatusBadRequest, err.Error())
+			case services.ErrSudahTerdaftarAcara:
+				utils.Error(w, http.StatusConflict, err.Error())
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal mendaftar jadwal: "+err.Error())
+			}
+			return
+		}
+
+		message := "Berhasil mendaftar jadwal"
+		if status == services.StatusPesertaWaitlist {
+			message = "Kapasitas penuh, Anda masuk daftar tunggu"
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": message,
+			"status":  status,
+		})
+	}
+}
+
+// BatalDaftarJadwalUmumHandler membatalkan pendaftaran user pada jadwal umum
+func BatalDaftarJadwalUmumHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		idJadwal, ok := parseIDJadwalQuery(w, r)
+		if !ok {
+			return
+		}
+
+		promoted, err := services.BatalDaftarJadwalUmum(db, idJadwal, claims.IDUser)
+		if err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Pendaftaran tidak ditemukan")
+			case services.ErrPendaftaranDitutup:
+				utils.Error(w, http.StatusBadRequest, "Acara sudah dimulai, pendaftaran tidak dapat dibatalkan")
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal membatalkan pendaftaran: "+err.Error())
+			}
+			return
+		}
+
+		if len(promoted) > 0 {
+			if jadwal, err := services.GetJadwalByID(db, idJadwal); err == nil {
+				notifikasiPromosiWaitlist(db, promoted, jadwal)
+			}
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Pendaftaran jadwal berhasil dibatalkan",
+		})
+	}
+}
+
+// GetPesertaJadwalHandler menampilkan daftar peserta jadwal umum untuk admin
+func GetPesertaJadwalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idJadwal, ok := parseIDJadwalQuery(w, r)
+		if !ok {
+			return
+		}
+
+		peserta, err := services.GetPesertaJadwal(db, idJadwal)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil peserta jadwal: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, peserta)
+	}
+}
+
+// ExportPesertaJadwalHandler mengunduh daftar peserta jadwal umum dalam format CSV
+func ExportPesertaJadwalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idJadwal, ok := parseIDJadwalQuery(w, r)
+		if !ok {
+			return
+		}
+
+		peserta, err := services.GetPesertaJadwal(db, idJadwal)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil peserta jadwal: "+err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=peserta_jadwal_%d.csv", idJadwal))
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"No", "Nama", "Email", "No WA", "Status", "Waktu Daftar"})
+		for i, p := range peserta {
+			cw.Write([]string{
+				strconv.Itoa(i + 1),
+				p.Nama,
+				p.Email,
+				p.NoWA,
+				p.Status,
+				p.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		cw.Flush()
+	}
+}
@@ -13,52 +13,52 @@ import (
 )
 
 func GetProfile(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
-            return
-        }
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok {
-            utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
-            return
-        }
-
-        userData, err := services.GetProfileData(db, claims.IDUser)
-        if err != nil {
-            if err == sql.ErrNoRows {
-                utils.Error(w, http.StatusNotFound, "User tidak ditemukan")
-            } else {
-                utils.Error(w, http.StatusInternalServerError, "Gagal ambil data")
-            }
-            return
-        }
-
-        status, err := services.GetUserPendaftarStatus(db, claims.IDUser)
-        if err != nil && err != sql.ErrNoRows {
-            utils.Error(w, http.StatusInternalServerError, "Gagal cek status")
-            return
-        }
-
-        statusKeanggotaan := "Bukan Calon Anggota"
-        if status == "diterima" || status == "pending" {
-            if status == "pending" {
-                statusKeanggotaan = "Calon Anggota (Pending)"
-            } else {
-                statusKeanggotaan = "Calon Anggota"
-            }
-        }
-
-        response := dto.UserProfileResponse{
-            FullName:          userData.FullName,
-            Email:             userData.Email,
-            ProfilePicture:    userData.ProfilePicture,
-            TanggalBergabung:  userData.CreatedAt.Format("2006-01-02"),
-            StatusKeanggotaan: statusKeanggotaan,
-        }
-
-        utils.JSONResponse(w, http.StatusOK, response)
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		userData, err := services.GetProfileData(db, claims.IDUser)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "User tidak ditemukan")
+			} else {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil data")
+			}
+			return
+		}
+
+		status, err := services.GetUserPendaftarStatus(db, claims.IDUser)
+		if err != nil && err != sql.ErrNoRows {
+			utils.Error(w, http.StatusInternalServerError, "Gagal cek status")
+			return
+		}
+
+		statusKeanggotaan := "Bukan Calon Anggota"
+		if status == "diterima" || status == "pending" {
+			if status == "pending" {
+				statusKeanggotaan = "Calon Anggota (Pending)"
+			} else {
+				statusKeanggotaan = "Calon Anggota"
+			}
+		}
+
+		response := dto.UserProfileResponse{
+			FullName:          userData.FullName,
+			Email:             userData.Email,
+			ProfilePicture:    userData.ProfilePicture,
+			TanggalBergabung:  userData.CreatedAt.Format("2006-01-02"),
+			StatusKeanggotaan: statusKeanggotaan,
+		}
+
+		utils.JSONResponse(w, http.StatusOK, response)
+	}
 }
 
 func UpdateProfile(db *sql.DB) http.HandlerFunc {
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"net/http"
+)
+
+// ModelPsikotesHandler mengelola model penilaian psikotes sebuah tes (?id_test=):
+// GET menampilkan dimensi & bobot, PUT mengganti seluruhnya lalu menghitung ulang profil peserta
+func ModelPsikotesHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			dimensi, err := services.GetModelPsikotes(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil model psikotes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"id_test": idTest,
+				"dimensi": dimensi,
+			})
+
+		case http.MethodPut:
+			var req dto.ModelPsikotesRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			dimensi := make([]models.DimensiPsikotes, 0, len(req.Dimensi))
+			for _, d := range req.Dimensi {
+				dm := models.DimensiPsikotes{KodeA: d.KodeA, NamaA: d.NamaA, KodeB: d.KodeB, NamaB: d.NamaB}
+				for _, b := range d.Bobot {
+					dm.Bobot = append(dm.Bobot, models.BobotPsikotes{IDSoal: b.IDSoal, Versi: b.Versi, Label: b.Label, Bobot: b.Bobot})
+				}
+				dimensi = append(dimensi, dm)
+			}
+
+			if err := services.SimpanModelPsikotes(db, idTest, dimensi); err != nil {
+				if errors.Is(err, services.ErrModelPsikotesTidakValid) {
+					utils.Error(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan model psikotes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Model psikotes disimpan dan profil peserta dihitung ulang",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Metode tidak diizinkan")
+		}
+	}
+}
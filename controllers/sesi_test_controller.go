@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sesiBerlangsungUser mengambil sesi tes user yang masih boleh dikerjakan.
+// Sesi yang melewati batas waktu + tenggang langsung dinilai dari jawaban tersimpan lalu ditolak.
+func sesiBerlangsungUser(w http.ResponseWriter, db *sql.DB, userID, idTest int) (*models.HasilTest, bool) {
+	sesi, err := services.GetHasilByUserID(db, userID, idTest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.Error(w, http.StatusForbidden, "Anda belum memulai tes")
+			return nil, false
+		}
+		utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa sesi tes")
+		return nil, false
+	}
+	if sesi.Status == services.StatusHasilSelesai {
+		utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
+		return nil, false
+	}
+	if services.SesiKedaluwarsa(sesi, time.Now()) {
+		if _, err := services.SelesaikanSesiTest(db, sesi.IDHasil, nil, *sesi.BatasWaktu); err != nil && err != services.ErrSesiSudahSelesai {
+			log.Printf("Gagal finalisasi sesi tes %d: %v", sesi.IDHasil, err)
+		}
+		utils.Error(w, http.StatusForbidden, "Waktu pengerjaan tes telah habis")
+		return nil, false
+	}
+	return sesi, true
+}
+
+// soalResponseSesi menyusun soal untuk peserta dengan pilihan dalam urutan teracak sesi.
+// Markdown dirender ke HTML tersanitasi; gambar memakai URL bertoken yang berlaku selama sesi.
+func soalResponseSesi(s *models.SoalTest, nomor int, sesi *models.HasilTest) dto.SoalResponse {
+	urlGambar := urlGambarSesi(sesi)
+
+	resp := dto.SoalResponse{
+		IDSoal:         s.IDSoal,
+		Nomor:          nomor,
+		Pertanyaan:     s.Pertanyaan,
+		PertanyaanHTML: utils.RenderMarkdownSoal(s.Pertanyaan, urlGambar),
+		Tipe:           s.Tipe,
+		Poin:           s.Poin,
+	}
+	for _, p := range services.PilihanTampil(s) {
+		resp.Pilihan = append(resp.Pilihan, dto.PilihanResponse{
+			Label:    p.Label,
+			Teks:     p.Teks,
+			TeksHTML: utils.RenderMarkdownSoal(p.Teks, urlGambar),
+		})
+	}
+	return resp
+}
+
+// urlGambarSesi membuat pembentuk URL gambar bertoken untuk sesi. Token sesi berlangsung berlaku
+// sampai batas waktu; token pembahasan (sesi selesai) berlaku satu jam.
+func urlGambarSesi(sesi *models.HasilTest) func(int) string {
+	kedaluwarsa := time.Now().Add(time.Hour)
+	if sesi.Status == services.StatusHasilBerlangsung && sesi.BatasWaktu != nil {
+		kedaluwarsa = sesi.BatasWaktu.Add(services.TenggangSubmit)
+	}
+	return func(idGambar int) string {
+		return utils.URLGambarSesi(idGambar, sesi.IDHasil, kedaluwarsa)
+	}
+}
+
+// nilaiJawabanPeserta menerjemahkan jawaban posisi tampil ke kanonik lalu menilainya
+func nilaiJawabanPeserta(s *models.SoalTest, jawaban string) (models.JawabanUser, error) {
+	return services.NilaiJawaban(s, services.JawabanKanonik(s, jawaban))
+}
+
+// MulaiTestHandler memulai sesi tes; batas waktu dihitung server dari durasi tes
+func MulaiTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.MulaiTestRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran: "+err.Error())
+			return
+		}
+		if pendaftar == nil {
+			utils.Error(w, http.StatusForbidden, "Anda belum melakukan pendaftaran")
+			return
+		}
+
+		testConfig, err := services.GetTestUntukPendaftar(db, req.IDTest, pendaftar.IDPendaftar)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak tersedia untuk Anda")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat konfigurasi tes")
+			return
+		}
+
+		if pesan := pesanJendelaTest(testConfig, time.Now()); pesan != "" {
+			utils.Error(w, http.StatusForbidden, pesan)
+			return
+		}
+		if testConfig.JumlahSoal == 0 {
+			utils.Error(w, http.StatusBadRequest, "Tes belum memiliki soal")
+			return
+		}
+
+		if _, err := services.MulaiSesiTest(db, claims.IDUser, pendaftar.IDPendaftar, testConfig); err != nil {
+			if err == services.ErrSesiSudahSelesai {
+				utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memulai tes: "+err.Error())
+			return
+		}
+
+		// Sesi lama yang ditinggalkan melewati batas waktu langsung difinalisasi di sini
+		sesi, ok := sesiBerlangsungUser(w, db, claims.IDUser, testConfig.IDTest)
+		if !ok {
+			return
+		}
+		if err := services.CatatPerangkatMulai(db, sesi.IDHasil, utils.ClientIP(r), utils.UserAgent(r)); err != nil {
+			log.Printf("Gagal mencatat perangkat sesi tes %d: %v", sesi.IDHasil, err)
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success":      true,
+			"message":      "Tes dimulai",
+			"id_hasil":     sesi.IDHasil,
+			"id_test":      testConfig.IDTest,
+			"percobaan":    sesi.Percobaan,
+			"durasi_menit": testConfig.DurasiMenit,
+			"waktu_mulai":  sesi.WaktuMulai,
+			"batas_waktu":  sesi.BatasWaktu,
+			"sisa_detik":   services.SisaDetikSesi(sesi, time.Now()),
+		})
+	}
+}
+
+// SimpanJawabanHandler menyimpan jawaban per soal selama sesi berlangsung (autosave)
+func SimpanJawabanHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut && r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode PUT atau POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.SimpanJawabanRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		sesi, ok := sesiBerlangsungUser(w, db, claims.IDUser, req.IDTest)
+		if !ok {
+			return
+		}
+
+		soals, err := services.GetSoalSesi(db, sesi.IDHasil, req.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat soal tes")
+			return
+		}
+		var soal *models.SoalTest
+		for i := range soals {
+			if soals[i].IDSoal == req.IDSoal {
+				soal = &soals[i]
+				break
+			}
+		}
+		if soal == nil {
+			utils.Error(w, http.StatusBadRequest, "Soal tidak termasuk dalam tes ini")
+			return
+		}
+
+		jawaban, err := nilaiJawabanPeserta(soal, req.Jawaban)
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		err = services.SimpanJawabanSesi(db, sesi.IDHasil, jawaban)
+		if err != nil {
+			if err == services.ErrSesiSudahSelesai {
+				utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan jawaban: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success":    true,
+			"message":    "Jawaban tersimpan",
+			"sisa_detik": services.SisaDetikSesi(sesi, time.Now()),
+		})
+	}
+}
+
+// GetSoalSesiAdminHandler menampilkan urutan soal & pilihan teracak yang dilihat seorang peserta;
+// soal yang sudah dijawab ditampilkan sesuai versi yang dijawab
+func GetSoalSesiAdminHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idHasil, err := strconv.Atoi(r.URL.Query().Get("id_hasil"))
+		if err != nil {
+			utils.Error(w, http.StatusBadRequest, "Parameter id_hasil tidak valid")
+			return
+		}
+
+		hasil, err := services.GetHasilByID(db, idHasil)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Hasil tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil tes: "+err.Error())
+			return
+		}
+
+		soals, err := services.GetSoalSesiDijawab(db, hasil.IDHasil, hasil.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal sesi: "+err.Error())
+			return
+		}
+
+		jawabans, err := services.GetJawabanByHasilID(db, hasil.IDHasil)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil jawaban: "+err.Error())
+			return
+		}
+		jawabanMap := make(map[int]models.JawabanUser)
+		for _, j := range jawabans {
+			jawabanMap[j.IDSoal] = j
+		}
+
+		response := []dto.SoalSesiAdminResponse{}
+		for i := range soals {
+			s := &soals[i]
+			item := dto.SoalSesiAdminResponse{
+				Nomor:         i + 1,
+				IDSoal:        s.IDSoal,
+				Pertanyaan:    s.Pertanyaan,
+				Tipe:          s.Tipe,
+				Poin:          s.Poin,
+				Pilihan:       services.PilihanTampil(s),
+				UrutanPilihan: s.UrutanPilihan,
+				KunciIsian:    s.KunciIsian,
+			}
+
+			var kunci []string
+			for _, p := range s.Pilihan {
+				if p.IsBenar {
+					kunci = append(kunci, p.Label)
+				}
+			}
+			if len(kunci) > 0 {
+				item.JawabanBenar = strings.Join(kunci, ",")
+				item.JawabanBenarTampil = services.JawabanTampil(s, item.JawabanBenar)
+			}
+
+			if j, ok := jawabanMap[s.IDSoal]; ok {
+				jawabTampil := services.JawabanTampil(s, j.JawabanUser)
+				item.JawabanUser = &j.JawabanUser
+				item.JawabanUserTampil = &jawabTampil
+				item.IsBenar = j.IsBenar
+				item.PoinDiperoleh = j.Poin
+				item.StatusPenilaian = j.StatusPenilaian
+			}
+			response = append(response, item)
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"id_hasil": hasil.IDHasil,
+			"user_id":  hasil.UserID,
+			"id_test":  hasil.IDTest,
+			"status":   hasil.Status,
+			"soal":     response,
+		})
+	}
+}
+
+// ResetSesiAdminHandler menghapus percobaan tes peserta yang rusak agar dapat dimulai ulang
+func ResetSesiAdminHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.ResetSesiRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		hasil, err := services.GetHasilByID(db, req.IDHasil)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Hasil tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil tes: "+err.Error())
+			return
+		}
+
+		if err := services.ResetSesiTest(db, hasil.IDHasil, req.Alasan, claims.IDUser); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Hasil tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal mereset sesi tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Sesi tes direset, peserta dapat memulai ulang tes",
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"cocopen-backend/services"
 	"cocopen-backend/utils"
 	"database/sql"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,213 +16,239 @@ import (
 )
 
 func GetUserSoalHandler(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
-            return
-        }
-
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok {
-            utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
-            return
-        }
-
-        pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran: "+err.Error())
-            return
-        }
-        if pendaftar == nil {
-            utils.Error(w, http.StatusForbidden, "Anda belum melakukan pendaftaran")
-            return
-        }
-
-        testConfig, err := services.GetTestConfig(db)
-        if err != nil {
-            if err == sql.ErrNoRows {
-                utils.Error(w, http.StatusNotFound, "Tes belum tersedia")
-                return
-            }
-            utils.Error(w, http.StatusInternalServerError, "Gagal memuat konfigurasi tes")
-            return
-        }
-
-        now := time.Now()
-        if testConfig.WaktuMulai != nil && now.Before(*testConfig.WaktuMulai) {
-            utils.Error(w, http.StatusForbidden, "Tes belum dimulai")
-            return
-        }
-        if testConfig.WaktuSelesai != nil && now.After(*testConfig.WaktuSelesai) {
-            utils.Error(w, http.StatusForbidden, "Tes telah ditutup")
-            return
-        }
-
-        alreadyTaken, err := services.HasUserTakenTest(db, claims.IDUser, testConfig.IDTest)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa riwayat tes")
-            return
-        }
-        if alreadyTaken {
-            utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
-            return
-        }
-
-        soals, err := services.GetAllSoal(db)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal mengambil soal: "+err.Error())
-            return
-        }
-
-        var response []dto.SoalResponse
-        for _, s := range soals {
-            response = append(response, dto.SoalResponse{
-                IDSoal:     s.IDSoal,
-                Nomor:      s.Nomor,
-                Pertanyaan: s.Pertanyaan,
-                PilihanA:   s.PilihanA,
-                PilihanB:   s.PilihanB,
-                PilihanC:   s.PilihanC,
-                PilihanD:   s.PilihanD,
-            })
-        }
-
-        utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-            "soal":           response,
-            "durasi_menit":   testConfig.DurasiMenit,
-            "judul":          testConfig.Judul,
-            "deskripsi":      testConfig.Deskripsi,
-            "waktu_mulai":    testConfig.WaktuMulai,
-            "waktu_selesai":  testConfig.WaktuSelesai,
-        })
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran: "+err.Error())
+			return
+		}
+		if pendaftar == nil {
+			utils.Error(w, http.StatusForbidden, "Anda belum melakukan pendaftaran")
+			return
+		}
+
+		testConfig, err := services.GetTestUntukPendaftar(db, idTest, pendaftar.IDPendaftar)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak tersedia untuk Anda")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat konfigurasi tes")
+			return
+		}
+
+		sesi, ok := sesiBerlangsungUser(w, db, claims.IDUser, testConfig.IDTest)
+		if !ok {
+			return
+		}
+
+		soals, err := services.GetSoalSesi(db, sesi.IDHasil, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil soal: "+err.Error())
+			return
+		}
+
+		tersimpan, err := services.GetJawabanByHasilID(db, sesi.IDHasil)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil jawaban tersimpan: "+err.Error())
+			return
+		}
+		jawabanKanonik := make(map[int]string)
+		for _, j := range tersimpan {
+			jawabanKanonik[j.IDSoal] = j.JawabanUser
+		}
+
+		// Soal & jawaban tersimpan dikirim dalam urutan teracak milik sesi ini
+		var response []dto.SoalResponse
+		jawabanTersimpan := make(map[int]string)
+		for i := range soals {
+			response = append(response, soalResponseSesi(&soals[i], i+1, sesi))
+			if jawab, ok := jawabanKanonik[soals[i].IDSoal]; ok {
+				jawabanTersimpan[soals[i].IDSoal] = services.JawabanTampil(&soals[i], jawab)
+			}
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"id_test":       testConfig.IDTest,
+			"id_hasil":      sesi.IDHasil,
+			"soal":          response,
+			"jawaban":       jawabanTersimpan,
+			"durasi_menit":  testConfig.DurasiMenit,
+			"judul":         testConfig.Judul,
+			"deskripsi":     testConfig.Deskripsi,
+			"waktu_mulai":   testConfig.WaktuMulai,
+			"waktu_selesai": testConfig.WaktuSelesai,
+			"batas_waktu":   sesi.BatasWaktu,
+			"sisa_detik":    services.SisaDetikSesi(sesi, time.Now()),
+		})
+	}
 }
 
 func SubmitJawabanHandler(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodPost {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode POST yang diizinkan")
-            return
-        }
-
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok {
-            utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
-            return
-        }
-
-        var req dto.SubmitJawabanRequest
-        if err := utils.ParseAndValidate(r, &req); err != nil {
-            utils.Error(w, http.StatusBadRequest, err.Error())
-            return
-        }
-
-        pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran")
-            return
-        }
-        if pendaftar == nil {
-            utils.Error(w, http.StatusForbidden, "Anda belum mendaftar")
-            return
-        }
-        pendaftarID := pendaftar.IDPendaftar
-
-        testConfig, err := services.GetTestConfig(db)
-        if err != nil {
-            utils.Error(w, http.StatusNotFound, "Tes tidak tersedia")
-            return
-        }
-
-        alreadyTaken, err := services.HasUserTakenTest(db, claims.IDUser, testConfig.IDTest)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa riwayat tes")
-            return
-        }
-        if alreadyTaken {
-            utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
-            return
-        }
-
-        soals, err := services.GetAllSoal(db)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal memuat soal untuk penilaian")
-            return
-        }
-
-        jawabanBenarMap := make(map[int]string)
-        for _, s := range soals {
-            jawabanBenarMap[s.IDSoal] = s.JawabanBenar
-        }
-
-        for idSoal := range req.Jawaban {
-            if _, exists := jawabanBenarMap[idSoal]; !exists {
-                utils.Error(w, http.StatusBadRequest, "Soal dengan ID "+strconv.Itoa(idSoal)+" tidak ditemukan")
-                return
-            }
-        }
-
-        skorBenar := 0
-        var jawabans []models.JawabanUser
-
-        for idSoal, jawabUser := range req.Jawaban {
-            isBenar := strings.ToUpper(jawabUser) == jawabanBenarMap[idSoal]
-            if isBenar {
-                skorBenar++
-            }
-            jawabans = append(jawabans, models.JawabanUser{
-                IDSoal:      idSoal,
-                JawabanUser: strings.ToUpper(jawabUser),
-                IsBenar:     isBenar,
-            })
-        }
-
-        totalSoal := len(soals)
-        skorSalah := totalSoal - skorBenar
-        nilai := float64(skorBenar) / float64(totalSoal) * 100
-
-        waktuSekarang := time.Now()
-
-        hasilBaru := models.HasilTest{
-            UserID:         claims.IDUser,
-            PendaftarID:    pendaftarID,
-            IDTest:         testConfig.IDTest,
-            WaktuMulai:     waktuSekarang,
-            WaktuSelesai:   &waktuSekarang,
-            SkorBenar:      skorBenar,
-            SkorSalah:      skorSalah,
-            Nilai:          nilai,
-        }
-
-        err = services.CreateHasilTest(db, hasilBaru)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal membuat hasil tes: "+err.Error())
-            return
-        }
-
-        idHasil, err := services.GetHasilIDByUserAndTest(db, claims.IDUser, testConfig.IDTest)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal ambil ID hasil tes")
-            return
-        }
-
-        for _, j := range jawabans {
-            j.IDHasil = idHasil
-            err = services.CreateJawabanUser(db, j)
-            if err != nil {
-                utils.Error(w, http.StatusInternalServerError, "Gagal simpan jawaban: "+err.Error())
-                return
-            }
-        }
-
-        utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-            "success":    true,
-            "message":    "Jawaban berhasil dikirim dan dinilai",
-            "skor_benar": skorBenar,
-            "skor_salah": skorSalah,
-            "nilai":      nilai,
-        })
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.SubmitJawabanRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran")
+			return
+		}
+		if pendaftar == nil {
+			utils.Error(w, http.StatusForbidden, "Anda belum mendaftar")
+			return
+		}
+		pendaftarID := pendaftar.IDPendaftar
+
+		testConfig, err := services.GetTestUntukPendaftar(db, req.IDTest, pendaftarID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak tersedia untuk Anda")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat konfigurasi tes")
+			return
+		}
+
+		// Retry dengan Idempotency-Key yang sama mengembalikan hasil submit sebelumnya
+		kunci := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if len(kunci) > services.MaxPanjangKunciIdempotensi {
+			utils.Error(w, http.StatusBadRequest, "Header Idempotency-Key terlalu panjang")
+			return
+		}
+		if kunci != "" {
+			hasil, err := services.GetHasilByKunciIdempotensi(db, claims.IDUser, testConfig.IDTest, kunci)
+			if err == nil {
+				balasSubmitJawaban(w, testConfig, hasil, true)
+				return
+			}
+			if err != sql.ErrNoRows {
+				utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa submit sebelumnya")
+				return
+			}
+		}
+
+		sesi, ok := sesiBerlangsungUser(w, db, claims.IDUser, testConfig.IDTest)
+		if !ok {
+			return
+		}
+
+		soals, err := services.GetSoalSesi(db, sesi.IDHasil, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat soal untuk penilaian")
+			return
+		}
+		if len(soals) == 0 {
+			utils.Error(w, http.StatusBadRequest, "Tes belum memiliki soal")
+			return
+		}
+
+		soalMap := make(map[int]*models.SoalTest)
+		for i := range soals {
+			soalMap[soals[i].IDSoal] = &soals[i]
+		}
+
+		for idSoal := range req.Jawaban {
+			if _, exists := soalMap[idSoal]; !exists {
+				utils.Error(w, http.StatusBadRequest, "Soal dengan ID "+strconv.Itoa(idSoal)+" tidak ditemukan")
+				return
+			}
+		}
+
+		// Jawaban dikirim sesuai posisi pilihan teracak; simpan & nilai dalam huruf kanonik
+		var jawabans []models.JawabanUser
+		for idSoal, jawabUser := range req.Jawaban {
+			jawaban, err := nilaiJawabanPeserta(soalMap[idSoal], jawabUser)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "Jawaban soal ID "+strconv.Itoa(idSoal)+": "+err.Error())
+				return
+			}
+			jawabans = append(jawabans, jawaban)
+		}
+
+		hasil, ulangan, err := services.SubmitSesiTest(db, sesi.IDHasil, kunci, jawabans, time.Now())
+		if err != nil {
+			if err == services.ErrSesiSudahSelesai {
+				utils.Error(w, http.StatusForbidden, "Anda sudah pernah mengikuti tes")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan hasil tes: "+err.Error())
+			return
+		}
+		if !ulangan {
+			if err := services.CatatPerangkatSubmit(db, sesi.IDHasil, utils.ClientIP(r), utils.UserAgent(r)); err != nil {
+				log.Printf("Gagal mencatat perangkat submit sesi tes %d: %v", sesi.IDHasil, err)
+			}
+		}
+
+		balasSubmitJawaban(w, testConfig, hasil, ulangan)
+	}
+}
+
+// balasSubmitJawaban mengirim respons submit; nilai hanya ditampilkan bila kebijakan rilis tes
+// mengizinkan. Respons untuk retry idempoten ditandai header Idempotent-Replayed.
+func balasSubmitJawaban(w http.ResponseWriter, t *models.Test, hasil *models.HasilTest, ulangan bool) {
+	if ulangan {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	if !services.HasilDirilis(t, hasil, time.Now()) {
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Jawaban berhasil dikirim. Hasil tes akan diumumkan kemudian",
+			"dirilis": false,
+		})
+		return
+	}
+
+	utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+		"success":    true,
+		"message":    "Jawaban berhasil dikirim dan dinilai",
+		"dirilis":    true,
+		"skor_benar": hasil.SkorBenar,
+		"skor_salah": hasil.SkorSalah,
+		"poin":       hasil.Poin,
+		"total_poin": hasil.TotalPoin,
+		"nilai":      hasil.Nilai,
+	})
+}
+
+func pilihanDariRequest(req []dto.PilihanSoalRequest) []models.PilihanSoal {
+	pilihan := make([]models.PilihanSoal, 0, len(req))
+	for _, p := range req {
+		pilihan = append(pilihan, models.PilihanSoal{Teks: p.Teks, IsBenar: p.IsBenar})
+	}
+	return pilihan
 }
 
 func CreateSoalHandler(db *sql.DB) http.HandlerFunc {
@@ -243,16 +271,37 @@ func CreateSoalHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		soal := models.SoalTest{
-			Nomor:        req.Nomor,
-			Pertanyaan:   req.Pertanyaan,
-			PilihanA:     req.PilihanA,
-			PilihanB:     req.PilihanB,
-			PilihanC:     req.PilihanC,
-			PilihanD:     req.PilihanD,
-			JawabanBenar: req.JawabanBenar,
+			Nomor:      req.Nomor,
+			Pertanyaan: req.Pertanyaan,
+			Tipe:       services.TipeSoalPilihanGanda,
+			Poin:       1,
+			Pilihan:    pilihanDariRequest(req.Pilihan),
+			KunciIsian: req.KunciIsian,
+			ModeIsian:  services.ModeIsianPersis,
+			Pembahasan: req.Pembahasan,
+		}
+		if req.Tipe != "" {
+			soal.Tipe = req.Tipe
+		}
+		if req.Poin != nil {
+			soal.Poin = *req.Poin
+		}
+		if req.ModeIsian != nil {
+			soal.ModeIsian = *req.ModeIsian
+		}
+		if err := services.ValidasiSoal(&soal); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if hilang, err := services.GambarSoalTidakDikenal(db, &soal); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa gambar soal: "+err.Error())
+			return
+		} else if len(hilang) > 0 {
+			utils.Error(w, http.StatusBadRequest, fmt.Sprintf("Gambar %v tidak ditemukan", hilang))
+			return
 		}
 
-		err := services.CreateSoal(db, soal)
+		err := services.CreateSoal(db, soal, claims.IDUser)
 		if err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal membuat soal: "+err.Error())
 			return
@@ -312,24 +361,51 @@ func UpdateSoalHandler(db *sql.DB) http.HandlerFunc {
 		if req.Pertanyaan != nil {
 			soal.Pertanyaan = *req.Pertanyaan
 		}
-		if req.PilihanA != nil {
-			soal.PilihanA = *req.PilihanA
+		if req.Tipe != nil {
+			soal.Tipe = *req.Tipe
 		}
-		if req.PilihanB != nil {
-			soal.PilihanB = *req.PilihanB
+		if req.Poin != nil {
+			soal.Poin = *req.Poin
 		}
-		if req.PilihanC != nil {
-			soal.PilihanC = *req.PilihanC
+		if req.Pilihan != nil {
+			soal.Pilihan = pilihanDariRequest(req.Pilihan)
 		}
-		if req.PilihanD != nil {
-			soal.PilihanD = *req.PilihanD
+		if req.KunciIsian != nil {
+			soal.KunciIsian = req.KunciIsian
 		}
-		if req.JawabanBenar != nil {
-			soal.JawabanBenar = *req.JawabanBenar
+		if req.ModeIsian != nil {
+			soal.ModeIsian = *req.ModeIsian
+		}
+		if req.Pembahasan != nil {
+			soal.Pembahasan = req.Pembahasan
+			if strings.TrimSpace(*req.Pembahasan) == "" {
+				soal.Pembahasan = nil
+			}
+		}
+		if !services.TipeBerpilihan(soal.Tipe) && req.Pilihan == nil {
+			soal.Pilihan = nil
+		}
+		if soal.Tipe != services.TipeSoalIsian {
+			soal.KunciIsian = nil
+		}
+		if err := services.ValidasiSoal(soal); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if hilang, err := services.GambarSoalTidakDikenal(db, soal); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa gambar soal: "+err.Error())
+			return
+		} else if len(hilang) > 0 {
+			utils.Error(w, http.StatusBadRequest, fmt.Sprintf("Gambar %v tidak ditemukan", hilang))
+			return
 		}
 
-		err = services.UpdateSoal(db, soal)
+		err = services.UpdateSoal(db, soal, claims.IDUser)
 		if err != nil {
+			if err == services.ErrSoalDiarsipkan {
+				utils.Error(w, http.StatusConflict, "Soal sudah diarsipkan; pulihkan terlebih dahulu")
+				return
+			}
 			utils.Error(w, http.StatusInternalServerError, "Gagal update soal: "+err.Error())
 			return
 		}
@@ -337,6 +413,7 @@ func UpdateSoalHandler(db *sql.DB) http.HandlerFunc {
 		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
 			"success": true,
 			"message": "Soal berhasil diperbarui",
+			"versi":   soal.Versi,
 		})
 	}
 }
@@ -376,19 +453,27 @@ func DeleteSoalHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		err = services.DeleteSoal(db, idSoal)
+		// Soal tidak dihapus permanen karena jawaban peserta merujuk versinya
+		dilepas, err := services.ArsipkanSoal(db, idSoal)
 		if err != nil {
-			utils.Error(w, http.StatusInternalServerError, "Gagal hapus soal: "+err.Error())
+			if err == services.ErrSoalDiarsipkan {
+				utils.Error(w, http.StatusConflict, "Soal sudah diarsipkan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengarsipkan soal: "+err.Error())
 			return
 		}
 
 		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-			"success": true,
-			"message": "Soal berhasil dihapus",
+			"success":          true,
+			"message":          "Soal berhasil diarsipkan",
+			"dilepas_dari_tes": dilepas,
 		})
 	}
 }
 
+// GetHasilTesUserHandler menampilkan hasil tes peserta (?id_test=) sesuai kebijakan rilis tes,
+// beserta jawaban, kunci, dan pembahasan per soal bila tes mengizinkan
 func GetHasilTesUserHandler(db *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -402,13 +487,12 @@ func GetHasilTesUserHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		testConfig, err := services.GetTestConfig(db)
-		if err != nil {
-			utils.Error(w, http.StatusNotFound, "Konfigurasi tes tidak ditemukan")
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
 			return
 		}
 
-		hasil, err := services.GetHasilByUserID(db, claims.IDUser, testConfig.IDTest)
+		hasil, err := services.GetHasilByUserID(db, claims.IDUser, idTest)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				utils.Error(w, http.StatusNotFound, "Anda belum mengikuti tes")
@@ -417,6 +501,33 @@ func GetHasilTesUserHandler(db *sql.DB) http.HandlerFunc {
 			utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil")
 			return
 		}
+		if hasil.Status != services.StatusHasilSelesai {
+			utils.Error(w, http.StatusForbidden, "Tes Anda masih berlangsung")
+			return
+		}
+
+		testConfig, err := services.GetTestByID(db, idTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat tes")
+			return
+		}
+
+		now := time.Now()
+		selesai := hasil.WaktuMulai
+		if hasil.WaktuSelesai != nil {
+			selesai = *hasil.WaktuSelesai
+		}
+		response := dto.HasilSayaResponse{
+			IDTest:      testConfig.IDTest,
+			Judul:       testConfig.Judul,
+			Dirilis:     services.HasilDirilis(testConfig, hasil, now),
+			DirilisPada: services.WaktuRilisHasil(testConfig, selesai),
+		}
+		if !response.Dirilis {
+			response.Pesan = "Hasil tes belum dirilis"
+			utils.JSONResponse(w, http.StatusOK, response)
+			return
+		}
 
 		var waktuSelesai *string
 		if hasil.WaktuSelesai != nil {
@@ -424,16 +535,78 @@ func GetHasilTesUserHandler(db *sql.DB) http.HandlerFunc {
 			waktuSelesai = &t
 		}
 
-		response := dto.HasilResponse{
-			IDHasil:      hasil.IDHasil,
-			UserID:       hasil.UserID,
-			PendaftarID:  hasil.PendaftarID,
-			SkorBenar:    hasil.SkorBenar,
-			SkorSalah:    hasil.SkorSalah,
-			Nilai:        hasil.Nilai,
-			WaktuMulai:   hasil.WaktuMulai.Format("2006-01-02 15:04:05"),
-			WaktuSelesai: waktuSelesai,
-			DurasiMenit:  hasil.DurasiMenit,
+		response.Hasil = &dto.HasilResponse{
+			IDHasil:           hasil.IDHasil,
+			UserID:            hasil.UserID,
+			PendaftarID:       hasil.PendaftarID,
+			SkorBenar:         hasil.SkorBenar,
+			SkorSalah:         hasil.SkorSalah,
+			Poin:              hasil.Poin,
+			TotalPoin:         hasil.TotalPoin,
+			Nilai:             hasil.Nilai,
+			MenungguPenilaian: hasil.MenungguPenilaian,
+			WaktuMulai:        hasil.WaktuMulai.Format("2006-01-02 15:04:05"),
+			WaktuSelesai:      waktuSelesai,
+			DurasiMenit:       hasil.DurasiMenit,
+		}
+
+		response.TampilkanPembahasan = services.PembahasanTerbuka(testConfig, hasil, now)
+		if !response.TampilkanPembahasan {
+			utils.JSONResponse(w, http.StatusOK, response)
+			return
+		}
+
+		// Pembahasan memakai versi soal yang dijawab peserta, bukan hasil edit sesudahnya
+		soals, err := services.GetSoalSesiDijawab(db, hasil.IDHasil, idTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil soal: "+err.Error())
+			return
+		}
+		jawabans, err := services.GetJawabanByHasilID(db, hasil.IDHasil)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil jawaban: "+err.Error())
+			return
+		}
+		jawabanMap := make(map[int]models.JawabanUser)
+		for _, j := range jawabans {
+			jawabanMap[j.IDSoal] = j
+		}
+
+		// Jawaban & kunci ditampilkan dalam huruf posisi yang dilihat peserta saat tes
+		urlGambar := urlGambarSesi(hasil)
+		response.Soal = []dto.SoalPembahasanResponse{}
+		for i := range soals {
+			s := &soals[i]
+			item := dto.SoalPembahasanResponse{
+				SoalResponse: soalResponseSesi(s, i+1, hasil),
+				Pembahasan:   s.Pembahasan,
+			}
+			if s.Pembahasan != nil {
+				item.PembahasanHTML = utils.RenderMarkdownSoal(*s.Pembahasan, urlGambar)
+			}
+
+			var kunci []string
+			for _, p := range s.Pilihan {
+				if p.IsBenar {
+					kunci = append(kunci, p.Label)
+				}
+			}
+			if len(kunci) > 0 {
+				item.JawabanBenar = services.JawabanTampil(s, strings.Join(kunci, ","))
+			}
+			if s.Tipe == services.TipeSoalIsian && s.ModeIsian == services.ModeIsianPersis {
+				item.KunciIsian = s.KunciIsian
+			}
+
+			if j, ok := jawabanMap[s.IDSoal]; ok {
+				jawab := services.JawabanTampil(s, j.JawabanUser)
+				item.JawabanAnda = &jawab
+				item.IsBenar = j.IsBenar
+				item.PoinDiperoleh = j.Poin
+				item.StatusPenilaian = j.StatusPenilaian
+				item.CatatanPenilai = j.CatatanPenilai
+			}
+			response.Soal = append(response.Soal, item)
 		}
 
 		utils.JSONResponse(w, http.StatusOK, response)
@@ -441,34 +614,47 @@ func GetHasilTesUserHandler(db *sql.DB) http.HandlerFunc {
 }
 
 func GetAllHasilTesHandler(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
-            return
-        }
-
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok || claims.Role != "admin" {
-            utils.Error(w, http.StatusForbidden, "Akses ditolak")
-            return
-        }
-
-        testConfig, err := services.GetTestConfig(db)
-        if err != nil {
-            utils.Error(w, http.StatusNotFound, "Konfigurasi tes tidak ditemukan")
-            return
-        }
-
-        // 🔁 Query: JOIN dengan pendaftar saja
-        query := `
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+		testConfig, err := services.GetTestByID(db, idTest)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal memuat tes: "+err.Error())
+			return
+		}
+
+		// 🔁 Query: JOIN dengan pendaftar saja
+		query := `
             SELECT 
                 ht.id_hasil,
                 ht.user_id,
                 ht.pendaftar_id,
                 p.nama_lengkap AS pendaftar_name,
+                ht.percobaan,
                 ht.skor_benar,
                 ht.skor_salah,
+                ht.poin,
+                ht.penyesuaian_poin,
+                ht.total_poin,
                 ht.nilai,
+                ht.status,
                 ht.waktu_mulai,
                 ht.waktu_selesai,
                 ht.durasi_menit
@@ -478,76 +664,117 @@ func GetAllHasilTesHandler(db *sql.DB) http.HandlerFunc {
             ORDER BY ht.nilai DESC, ht.waktu_mulai ASC
         `
 
-        rows, err := db.Query(query, testConfig.IDTest)
-        if err != nil {
-            utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil tes: "+err.Error())
-            return
-        }
-        defer rows.Close()
-
-        var hasilList []map[string]interface{}
-        for rows.Next() {
-            var (
-                idHasil       int
-                userID        int
-                pendaftarID   sql.NullInt64
-                pendaftarName sql.NullString
-                skorBenar     int
-                skorSalah     int
-                nilai         float64
-                waktuMulai    time.Time
-                waktuSelesai  sql.NullTime
-                durasiMenit   sql.NullInt64
-            )
-
-            err := rows.Scan(
-                &idHasil,
-                &userID,
-                &pendaftarID,
-                &pendaftarName,
-                &skorBenar,
-                &skorSalah,
-                &nilai,
-                &waktuMulai,
-                &waktuSelesai,
-                &durasiMenit,
-            )
-            if err != nil {
-                utils.Error(w, http.StatusInternalServerError, "Gagal scan hasil: "+err.Error())
-                return
-            }
-
-            item := map[string]interface{}{
-                "id_hasil":       idHasil,
-                "user_id":        userID,
-                "pendaftar_id":   nil,
-                "pendaftar_name": "Belum daftar",
-                "skor_benar":     skorBenar,
-                "skor_salah":     skorSalah,
-                "nilai":          nilai,
-                "waktu_mulai":    waktuMulai.Format("2006-01-02 15:04:05"),
-                "waktu_selesai":  nil,
-                "durasi_menit":   nil,
-            }
-
-            if pendaftarID.Valid {
-                item["pendaftar_id"] = int(pendaftarID.Int64)
-            }
-            if pendaftarName.Valid && pendaftarName.String != "" {
-                item["pendaftar_name"] = pendaftarName.String
-            }
-            if waktuSelesai.Valid {
-                item["waktu_selesai"] = waktuSelesai.Time.Format("2006-01-02 15:04:05")
-            }
-            if durasiMenit.Valid {
-                item["durasi_menit"] = int(durasiMenit.Int64)
-            }
-
-            hasilList = append(hasilList, item)
-        }
-
-        utils.JSONResponse(w, http.StatusOK, hasilList)
-    }
+		integritas, err := services.GetRingkasanIntegritasTest(db, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil ringkasan integritas: "+err.Error())
+			return
+		}
+		profil, err := services.GetProfilPsikotesTest(db, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil profil psikotes: "+err.Error())
+			return
+		}
+		daftarAkomodasi, err := services.GetAkomodasiTest(db, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil akomodasi tes: "+err.Error())
+			return
+		}
+		akomodasi := make(map[int]*models.AkomodasiTest, len(daftarAkomodasi))
+		for i := range daftarAkomodasi {
+			akomodasi[daftarAkomodasi[i].PendaftarID] = &daftarAkomodasi[i]
+		}
+
+		rows, err := db.Query(query, testConfig.IDTest)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil hasil tes: "+err.Error())
+			return
+		}
+		defer rows.Close()
+
+		var hasilList []map[string]interface{}
+		for rows.Next() {
+			var (
+				idHasil       int
+				userID        int
+				pendaftarID   sql.NullInt64
+				pendaftarName sql.NullString
+				percobaan     int
+				skorBenar     int
+				skorSalah     int
+				poin          float64
+				penyesuaian   float64
+				totalPoin     float64
+				nilai         float64
+				status        string
+				waktuMulai    time.Time
+				waktuSelesai  sql.NullTime
+				durasiMenit   sql.NullInt64
+			)
+
+			err := rows.Scan(
+				&idHasil,
+				&userID,
+				&pendaftarID,
+				&pendaftarName,
+				&percobaan,
+				&skorBenar,
+				&skorSalah,
+				&poin,
+				&penyesuaian,
+				&totalPoin,
+				&nilai,
+				&status,
+				&waktuMulai,
+				&waktuSelesai,
+				&durasiMenit,
+			)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal scan hasil: "+err.Error())
+				return
+			}
+
+			item := map[string]interface{}{
+				"id_hasil":         idHasil,
+				"user_id":          userID,
+				"pendaftar_id":     nil,
+				"pendaftar_name":   "Belum daftar",
+				"percobaan":        percobaan,
+				"skor_benar":       skorBenar,
+				"skor_salah":       skorSalah,
+				"poin":             poin,
+				"penyesuaian_poin": penyesuaian,
+				"total_poin":       totalPoin,
+				"nilai":            nilai,
+				"status":           status,
+				"waktu_mulai":      waktuMulai.Format("2006-01-02 15:04:05"),
+				"waktu_selesai":    nil,
+				"durasi_menit":     nil,
+				"integritas":       integritas[idHasil],
+				"profil_psikotes":  profil[idHasil],
+				"akomodasi":        nil,
+			}
+
+			if pendaftarID.Valid {
+				item["pendaftar_id"] = int(pendaftarID.Int64)
+				if a, ok := akomodasi[int(pendaftarID.Int64)]; ok {
+					item["akomodasi"] = a
+				}
+			}
+			if pendaftarName.Valid && pendaftarName.String != "" {
+				item["pendaftar_name"] = pendaftarName.String
+			}
+			if waktuSelesai.Valid {
+				item["waktu_selesai"] = waktuSelesai.Time.Format("2006-01-02 15:04:05")
+			}
+			if durasiMenit.Valid {
+				item["durasi_menit"] = int(durasiMenit.Int64)
+			}
+
+			hasilList = append(hasilList, item)
+		}
+
+		utils.JSONResponse(w, http.StatusOK, hasilList)
+	}
 }
 
 func GetAllSoalAdminHandler(db *sql.DB) http.HandlerFunc {
@@ -563,147 +790,13 @@ func GetAllSoalAdminHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		soals, err := services.GetAllSoal(db)
+		// ?arsip=true: sertakan soal yang sudah diarsipkan
+		soals, err := services.GetAllSoal(db, r.URL.Query().Get("arsip") == "true")
 		if err != nil {
 			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal: "+err.Error())
 			return
 		}
 
-		var response []dto.SoalResponse
-		for _, s := range soals {
-			response = append(response, dto.SoalResponse{
-				IDSoal:     s.IDSoal,
-				Nomor:      s.Nomor,
-				Pertanyaan: s.Pertanyaan,
-				PilihanA:   s.PilihanA,
-				PilihanB:   s.PilihanB,
-				PilihanC:   s.PilihanC,
-				PilihanD:   s.PilihanD,
-			})
-		}
-
-		utils.JSONResponse(w, http.StatusOK, response)
+		utils.JSONResponse(w, http.StatusOK, soals)
 	}
 }
-
-func UpdateTestConfigHandler(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok || claims.Role != "admin" {
-            utils.Error(w, http.StatusForbidden, "Akses ditolak")
-            return
-        }
-
-        switch r.Method {
-        case http.MethodGet:
-            // 🔹 Ambil konfigurasi saat ini
-            testConfig, err := services.GetTestConfig(db)
-            if err != nil {
-                if err == sql.ErrNoRows {
-                    utils.Error(w, http.StatusNotFound, "Konfigurasi tes belum dibuat")
-                    return
-                }
-                utils.Error(w, http.StatusInternalServerError, "Gagal ambil konfigurasi: "+err.Error())
-                return
-            }
-
-            utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-                "id_test":       testConfig.IDTest,
-                "judul":         testConfig.Judul,
-                "deskripsi":     testConfig.Deskripsi,
-                "durasi_menit":  testConfig.DurasiMenit,
-                "waktu_mulai":   testConfig.WaktuMulai,
-                "waktu_selesai": testConfig.WaktuSelesai,
-                "aktif":         testConfig.Aktif,
-            })
-
-        case http.MethodPut:
-            // 🔹 Update konfigurasi
-            var req dto.TestConfigUpdateRequest
-            if err := utils.ParseAndValidate(r, &req); err != nil {
-                utils.Error(w, http.StatusBadRequest, err.Error())
-                return
-            }
-
-            existing, _ := services.GetTestConfig(db)
-
-            config := models.Test{
-                Judul:       "Tes Seleksi",
-                DurasiMenit: 30,
-                Aktif:       true,
-            }
-
-            if existing != nil {
-                config.IDTest = existing.IDTest
-            }
-
-            if req.Judul != nil {
-                config.Judul = *req.Judul
-            }
-            if req.Deskripsi != nil {
-                config.Deskripsi = req.Deskripsi
-            }
-            if req.DurasiMenit != nil {
-                config.DurasiMenit = *req.DurasiMenit
-            }
-            if req.WaktuMulai != nil {
-                config.WaktuMulai = req.WaktuMulai
-            }
-            if req.WaktuSelesai != nil {
-                config.WaktuSelesai = req.WaktuSelesai
-            }
-            if req.Aktif != nil {
-                config.Aktif = *req.Aktif
-            }
-
-            err := services.UpsertTestConfig(db, config)
-            if err != nil {
-                utils.Error(w, http.StatusInternalServerError, "Gagal simpan konfigurasi: "+err.Error())
-                return
-            }
-
-            utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-                "success": true,
-                "message": "Konfigurasi tes berhasil disimpan",
-            })
-
-        default:
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan PUT yang diizinkan")
-        }
-    }
-}
-
-func GetTestConfigHandler(db *sql.DB) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-            utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
-            return
-        }
-
-        claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
-        if !ok || claims.Role != "admin" {
-            utils.Error(w, http.StatusForbidden, "Akses ditolak")
-            return
-        }
-
-        testConfig, err := services.GetTestConfig(db)
-        if err != nil {
-            if err == sql.ErrNoRows {
-                utils.Error(w, http.StatusNotFound, "Konfigurasi tes belum dibuat")
-                return
-            }
-            utils.Error(w, http.StatusInternalServerError, "Gagal ambil konfigurasi: "+err.Error())
-            return
-        }
-
-        utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
-            "id_test":       testConfig.IDTest,
-            "judul":         testConfig.Judul,
-            "deskripsi":     testConfig.Deskripsi,
-            "durasi_menit":  testConfig.DurasiMenit,
-            "waktu_mulai":   testConfig.WaktuMulai,
-            "waktu_selesai": testConfig.WaktuSelesai,
-            "aktif":         testConfig.Aktif,
-        })
-    }
-}
\ No newline at end of file
@@ -0,0 +1,570 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func parseIDTestQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.URL.Query().Get("id_test")
+	if idStr == "" {
+		utils.Error(w, http.StatusBadRequest, "Parameter id_test wajib diisi")
+		return 0, false
+	}
+	idTest, err := strconv.Atoi(idStr)
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, "ID tes tidak valid")
+		return 0, false
+	}
+	return idTest, true
+}
+
+// pesanJendelaTest mengembalikan pesan penolakan bila tes belum dibuka atau sudah ditutup
+func pesanJendelaTest(t *models.Test, now time.Time) string {
+	if t.WaktuMulai != nil && now.Before(*t.WaktuMulai) {
+		return "Tes belum dimulai"
+	}
+	if t.WaktuSelesai != nil && now.After(*t.WaktuSelesai) {
+		return "Tes telah ditutup"
+	}
+	return ""
+}
+
+func validasiJendelaTest(w http.ResponseWriter, t *models.Test) bool {
+	if t.WaktuMulai != nil && t.WaktuSelesai != nil && !t.WaktuSelesai.After(*t.WaktuMulai) {
+		utils.Error(w, http.StatusBadRequest, "Waktu selesai tes harus setelah waktu mulai")
+		return false
+	}
+	if t.RilisHasil == services.RilisHasilSetelahJendela && t.WaktuSelesai == nil {
+		utils.Error(w, http.StatusBadRequest, "Rilis hasil setelah jendela tes membutuhkan waktu_selesai")
+		return false
+	}
+	return true
+}
+
+// GetTestSayaHandler menampilkan tes yang sedang ditugaskan ke pendaftar beserta statusnya
+func GetTestSayaHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		pendaftar, err := services.GetLatestPendaftarByUserID(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran: "+err.Error())
+			return
+		}
+		if pendaftar == nil {
+			utils.Error(w, http.StatusForbidden, "Anda belum melakukan pendaftaran")
+			return
+		}
+
+		tests, err := services.GetTestDitugaskan(db, pendaftar.IDPendaftar)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil daftar tes: "+err.Error())
+			return
+		}
+
+		now := time.Now()
+		result := []dto.TestSayaResponse{}
+		for _, t := range tests {
+			item := dto.TestSayaResponse{
+				IDTest:       t.IDTest,
+				Judul:        t.Judul,
+				Deskripsi:    t.Deskripsi,
+				DurasiMenit:  t.DurasiMenit,
+				WaktuMulai:   t.WaktuMulai,
+				WaktuSelesai: t.WaktuSelesai,
+				JumlahSoal:   t.JumlahSoal,
+				Status:       "tersedia",
+			}
+			if t.WaktuMulai != nil && now.Before(*t.WaktuMulai) {
+				item.Status = "belum_dibuka"
+			}
+
+			sesi, err := services.GetHasilByUserID(db, claims.IDUser, t.IDTest)
+			if err != nil && err != sql.ErrNoRows {
+				utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa riwayat tes")
+				return
+			}
+			if sesi != nil {
+				switch {
+				case sesi.Status == services.StatusHasilSelesai:
+					item.Status = "selesai"
+					bolehUlang, err := services.AdaIzinUlang(db, claims.IDUser, t.IDTest)
+					if err != nil {
+						utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa izin ulang tes")
+						return
+					}
+					if bolehUlang {
+						item.Status = "boleh_ulang"
+					}
+				case services.SesiKedaluwarsa(sesi, now):
+					item.Status = "waktu_habis"
+				default:
+					sisa := services.SisaDetikSesi(sesi, now)
+					item.Status = "berlangsung"
+					item.BatasWaktu = sesi.BatasWaktu
+					item.SisaDetik = &sisa
+				}
+			}
+
+			result = append(result, item)
+		}
+
+		utils.JSONResponse(w, http.StatusOK, result)
+	}
+}
+
+// GetAllTestHandler menampilkan semua tes untuk admin
+func GetAllTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		if idStr := r.URL.Query().Get("id_test"); idStr != "" {
+			idTest, err := strconv.Atoi(idStr)
+			if err != nil {
+				utils.Error(w, http.StatusBadRequest, "ID tes tidak valid")
+				return
+			}
+			t, err := services.GetTestByID(db, idTest)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, t)
+			return
+		}
+
+		tests, err := services.GetAllTest(db)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil daftar tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, tests)
+	}
+}
+
+func CreateTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.TestCreateRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		t := models.Test{
+			Judul:         req.Judul,
+			Deskripsi:     req.Deskripsi,
+			DurasiMenit:   req.DurasiMenit,
+			WaktuMulai:    req.WaktuMulai,
+			WaktuSelesai:  req.WaktuSelesai,
+			Aktif:         true,
+			TargetPeserta: services.TargetPesertaSemua,
+			RilisHasil:    services.RilisHasilLangsung,
+		}
+		if req.Aktif != nil {
+			t.Aktif = *req.Aktif
+		}
+		if req.TargetPeserta != nil {
+			t.TargetPeserta = *req.TargetPeserta
+		}
+		if req.RilisHasil != nil {
+			t.RilisHasil = *req.RilisHasil
+		}
+		if req.TampilkanPembahasan != nil {
+			t.TampilkanPembahasan = *req.TampilkanPembahasan
+		}
+		if !validasiJendelaTest(w, &t) {
+			return
+		}
+
+		idTest, err := services.CreateTest(db, t)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal membuat tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+			"success": true,
+			"message": "Tes berhasil dibuat",
+			"id_test": idTest,
+		})
+	}
+}
+
+func UpdateTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya PUT yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.TestUpdateRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		t, err := services.GetTestByID(db, idTest)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		if req.Judul != nil {
+			t.Judul = *req.Judul
+		}
+		if req.Deskripsi != nil {
+			t.Deskripsi = req.Deskripsi
+		}
+		if req.DurasiMenit != nil {
+			t.DurasiMenit = *req.DurasiMenit
+		}
+		if req.WaktuMulai != nil {
+			t.WaktuMulai = req.WaktuMulai
+		}
+		if req.WaktuSelesai != nil {
+			t.WaktuSelesai = req.WaktuSelesai
+		}
+		if req.Aktif != nil {
+			t.Aktif = *req.Aktif
+		}
+		if req.RilisHasil != nil {
+			t.RilisHasil = *req.RilisHasil
+		}
+		if req.TampilkanPembahasan != nil {
+			t.TampilkanPembahasan = *req.TampilkanPembahasan
+		}
+		for _, field := range req.Kosongkan {
+			switch field {
+			case "waktu_mulai":
+				t.WaktuMulai = nil
+			case "waktu_selesai":
+				t.WaktuSelesai = nil
+			}
+		}
+		if !validasiJendelaTest(w, t) {
+			return
+		}
+
+		if err := services.UpdateTest(db, t); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memperbarui tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Tes berhasil diperbarui",
+		})
+	}
+}
+
+func DeleteTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya DELETE yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal cek tes")
+			return
+		}
+
+		if err := services.DeleteTest(db, idTest); err != nil {
+			if err == services.ErrTestSudahDikerjakan || err == services.ErrTestDipakaiPengumuman {
+				utils.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal hapus tes: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Tes berhasil dihapus",
+		})
+	}
+}
+
+// SoalTestHandler: GET daftar soal sebuah tes, PUT mengganti daftar dan urutan soal dari bank
+func SoalTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			soals, err := services.GetSoalByTest(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal tes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, soals)
+
+		case http.MethodPut:
+			var req dto.SoalTestSetRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			// Soal arsip hanya boleh tetap di tes yang sudah memakainya, tidak ditambahkan baru
+			soalLama, err := services.GetSoalByTest(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal tes: "+err.Error())
+				return
+			}
+			sudahDipakai := make(map[int]bool, len(soalLama))
+			for _, s := range soalLama {
+				sudahDipakai[s.IDSoal] = true
+			}
+			for _, idSoal := range req.IDSoal {
+				soal, err := services.GetSoalByID(db, idSoal)
+				if err != nil {
+					if err == sql.ErrNoRows {
+						utils.Error(w, http.StatusBadRequest, "Soal dengan ID "+strconv.Itoa(idSoal)+" tidak ditemukan")
+						return
+					}
+					utils.Error(w, http.StatusInternalServerError, "Gagal cek soal")
+					return
+				}
+				if soal.DiarsipkanAt != nil && !sudahDipakai[idSoal] {
+					utils.Error(w, http.StatusBadRequest, "Soal dengan ID "+strconv.Itoa(idSoal)+" sudah diarsipkan")
+					return
+				}
+			}
+
+			if err := services.SetSoalTest(db, idTest, req.IDSoal); err != nil {
+				if err == services.ErrSoalTestTerkunci {
+					utils.Error(w, http.StatusConflict, err.Error())
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan soal tes: "+err.Error())
+				return
+			}
+
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Soal tes berhasil disimpan",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan PUT yang diizinkan")
+		}
+	}
+}
+
+// PesertaTestHandler: GET/PUT target peserta tes (semua pendaftar atau pendaftar terpilih)
+func PesertaTestHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		t, err := services.GetTestByID(db, idTest)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			ids, err := services.GetPesertaTest(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil peserta tes: "+err.Error())
+				return
+			}
+			akomodasi, err := services.GetAkomodasiTest(db, idTest)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil akomodasi tes: "+err.Error())
+				return
+			}
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"id_test":        idTest,
+				"target_peserta": t.TargetPeserta,
+				"pendaftar_id":   ids,
+				"akomodasi":      akomodasi,
+			})
+
+		case http.MethodPut:
+			var req dto.PesertaTestSetRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if req.TargetPeserta == services.TargetPesertaPilih && len(req.PendaftarID) == 0 {
+				utils.Error(w, http.StatusBadRequest, "Pilih minimal satu pendaftar untuk target 'pilih'")
+				return
+			}
+
+			if err := services.SetPesertaTest(db, idTest, req.TargetPeserta, req.PendaftarID); err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan peserta tes: "+err.Error())
+				return
+			}
+
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Peserta tes berhasil disimpan",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan PUT yang diizinkan")
+		}
+	}
+}
+
+// RilisHasilHandler merilis atau menarik hasil tes secara manual (?id_test=, body { rilis })
+func RilisHasilHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idTest, ok := parseIDTestQuery(w, r)
+		if !ok {
+			return
+		}
+
+		var req dto.RilisHasilRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if _, err := services.GetTestByID(db, idTest); err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Tes tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil tes: "+err.Error())
+			return
+		}
+
+		baru, err := services.SetRilisHasilTest(db, idTest, *req.Rilis)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengubah rilis hasil: "+err.Error())
+			return
+		}
+		if baru {
+			if err := services.NotifikasiHasilDirilis(db, idTest); err != nil {
+				log.Printf("Gagal kirim notifikasi rilis hasil tes %d: %v", idTest, err)
+			}
+		}
+
+		pesan := "Hasil tes dirilis ke peserta"
+		if !*req.Rilis {
+			pesan = "Rilis manual hasil tes dibatalkan"
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": pesan,
+		})
+	}
+}
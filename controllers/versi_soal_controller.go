@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"cocopen-backend/middleware"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"strconv"
+)
+
+// parseIDSoalQuery membaca parameter ?id= soal
+func parseIDSoalQuery(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idSoal, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		utils.Error(w, http.StatusBadRequest, "Parameter id soal tidak valid")
+		return 0, false
+	}
+	return idSoal, true
+}
+
+// VersiSoalHandler menampilkan riwayat versi soal (?id=), terbaru lebih dulu
+func VersiSoalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idSoal, ok := parseIDSoalQuery(w, r)
+		if !ok {
+			return
+		}
+		soal, err := services.GetSoalByID(db, idSoal)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Soal tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal: "+err.Error())
+			return
+		}
+
+		versi, err := services.GetVersiSoal(db, idSoal)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil versi soal: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"id_soal":       idSoal,
+			"versi_terkini": soal.Versi,
+			"diarsipkan_at": soal.DiarsipkanAt,
+			"versi":         versi,
+		})
+	}
+}
+
+// DiffVersiSoalHandler membandingkan dua versi soal (?id=&dari=&ke=). Tanpa ke dipakai versi
+// terkini; tanpa dari dipakai versi sebelum ke.
+func DiffVersiSoalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idSoal, ok := parseIDSoalQuery(w, r)
+		if !ok {
+			return
+		}
+		soal, err := services.GetSoalByID(db, idSoal)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Soal tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal: "+err.Error())
+			return
+		}
+
+		ke := soal.Versi
+		if s := r.URL.Query().Get("ke"); s != "" {
+			if ke, err = strconv.Atoi(s); err != nil {
+				utils.Error(w, http.StatusBadRequest, "Parameter ke tidak valid")
+				return
+			}
+		}
+		dari := ke - 1
+		if s := r.URL.Query().Get("dari"); s != "" {
+			if dari, err = strconv.Atoi(s); err != nil {
+				utils.Error(w, http.StatusBadRequest, "Parameter dari tidak valid")
+				return
+			}
+		}
+
+		lama, err := services.GetSatuVersiSoal(db, idSoal, dari)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Versi "+strconv.Itoa(dari)+" tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil versi soal: "+err.Error())
+			return
+		}
+		baru, err := services.GetSatuVersiSoal(db, idSoal, ke)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Versi "+strconv.Itoa(ke)+" tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil versi soal: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"id_soal":   idSoal,
+			"dari":      lama,
+			"ke":        baru,
+			"perbedaan": services.BandingkanVersiSoal(lama, baru),
+		})
+	}
+}
+
+// PulihkanSoalHandler mengembalikan soal yang diarsipkan (?id=) ke bank soal
+func PulihkanSoalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idSoal, ok := parseIDSoalQuery(w, r)
+		if !ok {
+			return
+		}
+		soal, err := services.GetSoalByID(db, idSoal)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Soal tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil soal: "+err.Error())
+			return
+		}
+		if soal.DiarsipkanAt == nil {
+			utils.Error(w, http.StatusConflict, "Soal tidak sedang diarsipkan")
+			return
+		}
+
+		if err := services.PulihkanSoal(db, idSoal); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memulihkan soal: "+err.Error())
+			return
+		}
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Soal berhasil dipulihkan",
+		})
+	}
+}
@@ -0,0 +1,479 @@
+package controllers
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/middleware"
+	"cocopen-backend/models"
+	"cocopen-backend/services"
+	"cocopen-backend/utils"
+	"database/sql"
+	"net/http"
+	"strconv"
+)
+
+func kriteriaFromRequest(req []dto.KriteriaRequest) []models.KriteriaWawancara {
+	var kriteria []models.KriteriaWawancara
+	for _, k := range req {
+		kriteria = append(kriteria, models.KriteriaWawancara{
+			Nama:      k.Nama,
+			Deskripsi: k.Deskripsi,
+			Bobot:     k.Bobot,
+			SkorMaks:  k.SkorMaks,
+		})
+	}
+	return kriteria
+}
+
+// GetAllRubrikHandler menampilkan rubrik wawancara: admin melihat semua, pewawancara hanya rubrik aktif
+func GetAllRubrikHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		admin := claims.Role == "admin"
+		if !admin {
+			pewawancara, err := services.IsPewawancara(db, claims.IDUser)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa penugasan")
+				return
+			}
+			if !pewawancara {
+				utils.Error(w, http.StatusForbidden, "Akses ditolak")
+				return
+			}
+		}
+
+		rubriks, err := services.GetAllRubrik(db, !admin)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil rubrik: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, rubriks)
+	}
+}
+
+func CreateRubrikHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		var req dto.RubrikCreateRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		rubrik := models.RubrikWawancara{
+			Nama:      req.Nama,
+			Deskripsi: req.Deskripsi,
+			Aktif:     true,
+			Kriteria:  kriteriaFromRequest(req.Kriteria),
+		}
+		if req.Aktif != nil {
+			rubrik.Aktif = *req.Aktif
+		}
+
+		id, err := services.CreateRubrik(db, rubrik)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal membuat rubrik: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusCreated, map[string]interface{}{
+			"success":   true,
+			"message":   "Rubrik wawancara berhasil dibuat",
+			"id_rubrik": id,
+		})
+	}
+}
+
+func UpdateRubrikHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya PUT yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		id, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil || id <= 0 {
+			utils.Error(w, http.StatusBadRequest, "ID tidak valid")
+			return
+		}
+
+		var req dto.RubrikUpdateRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		rubrik, err := services.GetRubrikByID(db, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Rubrik tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil rubrik")
+			return
+		}
+
+		if req.Nama != nil {
+			rubrik.Nama = *req.Nama
+		}
+		if req.Deskripsi != nil {
+			rubrik.Deskripsi = req.Deskripsi
+		}
+		if req.Aktif != nil {
+			rubrik.Aktif = *req.Aktif
+		}
+		gantiKriteria := req.Kriteria != nil
+		if gantiKriteria {
+			rubrik.Kriteria = kriteriaFromRequest(req.Kriteria)
+		}
+
+		if err := services.UpdateRubrik(db, rubrik, gantiKriteria); err != nil {
+			if err == services.ErrRubrikDipakai {
+				utils.Error(w, http.StatusConflict, "Kriteria tidak bisa diubah karena rubrik sudah dipakai menilai. Buat rubrik baru.")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal update rubrik: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Rubrik wawancara berhasil diperbarui",
+		})
+	}
+}
+
+func DeleteRubrikHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya DELETE yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		id, err := strconv.Atoi(r.URL.Query().Get("id"))
+		if err != nil || id <= 0 {
+			utils.Error(w, http.StatusBadRequest, "ID tidak valid")
+			return
+		}
+
+		if err := services.DeleteRubrik(db, id); err != nil {
+			switch err {
+			case sql.ErrNoRows:
+				utils.Error(w, http.StatusNotFound, "Rubrik tidak ditemukan")
+			case services.ErrRubrikDipakai:
+				utils.Error(w, http.StatusConflict, "Rubrik sudah dipakai menilai dan tidak bisa dihapus")
+			default:
+				utils.Error(w, http.StatusInternalServerError, "Gagal hapus rubrik: "+err.Error())
+			}
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Rubrik wawancara berhasil dihapus",
+		})
+	}
+}
+
+// PewawancaraJadwalHandler: GET melihat, PUT mengganti pewawancara sebuah jadwal
+func PewawancaraJadwalHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			idJadwal, err := strconv.Atoi(r.URL.Query().Get("id_jadwal"))
+			if err != nil || idJadwal <= 0 {
+				utils.Error(w, http.StatusBadRequest, "Parameter id_jadwal tidak valid")
+				return
+			}
+
+			users, err := services.GetPewawancaraJadwal(db, idJadwal)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal ambil pewawancara: "+err.Error())
+				return
+			}
+
+			var result []map[string]interface{}
+			for _, u := range users {
+				result = append(result, map[string]interface{}{
+					"id":    u.IDUser,
+					"nama":  u.FullName,
+					"email": u.Email,
+				})
+			}
+			utils.JSONResponse(w, http.StatusOK, result)
+
+		case http.MethodPut:
+			var req dto.AssignPewawancaraRequest
+			if err := utils.ParseAndValidate(r, &req); err != nil {
+				utils.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if _, err := services.GetJadwalByID(db, req.IDJadwal); err != nil {
+				if err == sql.ErrNoRows {
+					utils.Error(w, http.StatusNotFound, "Jadwal tidak ditemukan")
+					return
+				}
+				utils.Error(w, http.StatusInternalServerError, "Gagal mengambil data jadwal: "+err.Error())
+				return
+			}
+
+			for _, userID := range req.UserIDs {
+				exists, err := services.UserExists(db, userID)
+				if err != nil || !exists {
+					utils.Error(w, http.StatusBadRequest, "User pewawancara "+strconv.Itoa(userID)+" tidak ditemukan")
+					return
+				}
+			}
+
+			if err := services.SetPewawancaraJadwal(db, req.IDJadwal, req.UserIDs); err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan pewawancara: "+err.Error())
+				return
+			}
+
+			utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+				"success": true,
+				"message": "Pewawancara berhasil ditetapkan",
+			})
+
+		default:
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET dan PUT yang diizinkan")
+		}
+	}
+}
+
+// GetJadwalWawancaraSayaHandler menampilkan jadwal yang harus diwawancarai oleh user login
+func GetJadwalWawancaraSayaHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya metode GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		jadwals, err := services.GetJadwalByPewawancara(db, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil jadwal wawancara: "+err.Error())
+			return
+		}
+
+		var result []dto.JadwalAdminResponse
+		for _, j := range jadwals {
+			result = append(result, dto.JadwalAdminResponse{
+				IDJadwal:            j.IDJadwal,
+				UserID:              j.UserID,
+				UserNama:            j.UserNama,
+				PendaftarID:         j.PendaftarID,
+				WaktuJadwalResponse: waktuJadwalResponse(&j),
+				Tempat:              j.Tempat,
+				KonfirmasiJadwal:    j.KonfirmasiJadwal,
+				Catatan:             j.Catatan,
+				JenisJadwal:         j.JenisJadwal,
+				CreatedAt:           j.CreatedAt,
+				UpdatedAt:           j.UpdatedAt,
+			})
+		}
+
+		utils.JSONResponse(w, http.StatusOK, result)
+	}
+}
+
+// SubmitPenilaianWawancaraHandler dipakai pewawancara yang ditugaskan untuk mengirim skor dan catatan
+func SubmitPenilaianWawancaraHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya POST yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok {
+			utils.Error(w, http.StatusUnauthorized, "Akses ditolak")
+			return
+		}
+
+		var req dto.PenilaianWawancaraRequest
+		if err := utils.ParseAndValidate(r, &req); err != nil {
+			utils.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		jadwal, err := services.GetJadwalByID(db, req.IDJadwal)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Jadwal tidak ditemukan")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal mengambil data jadwal: "+err.Error())
+			return
+		}
+
+		assigned, err := services.IsPewawancaraJadwal(db, jadwal.IDJadwal, claims.IDUser)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa penugasan")
+			return
+		}
+		if !assigned {
+			utils.Error(w, http.StatusForbidden, "Anda tidak ditugaskan mewawancarai jadwal ini")
+			return
+		}
+
+		var rubrik *models.RubrikWawancara
+		if req.IDRubrik != nil {
+			rubrik, err = services.GetRubrikByID(db, *req.IDRubrik)
+		} else {
+			rubrik, err = services.GetRubrikAktif(db)
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.Error(w, http.StatusNotFound, "Rubrik wawancara belum tersedia")
+				return
+			}
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil rubrik")
+			return
+		}
+		if !rubrik.Aktif {
+			utils.Error(w, http.StatusBadRequest, "Rubrik tidak aktif, gunakan rubrik yang sedang berlaku")
+			return
+		}
+
+		kriteriaMap := make(map[int]models.KriteriaWawancara)
+		for _, k := range rubrik.Kriteria {
+			kriteriaMap[k.IDKriteria] = k
+		}
+
+		skor := make(map[int]int)
+		var detail []models.PenilaianWawancaraDetail
+		for _, s := range req.Skor {
+			k, exists := kriteriaMap[s.IDKriteria]
+			if !exists {
+				utils.Error(w, http.StatusBadRequest, "Kriteria "+strconv.Itoa(s.IDKriteria)+" bukan bagian dari rubrik")
+				return
+			}
+			if _, dinilai := skor[s.IDKriteria]; dinilai {
+				utils.Error(w, http.StatusBadRequest, "Kriteria '"+k.Nama+"' dinilai lebih dari sekali")
+				return
+			}
+			if s.Skor > k.SkorMaks {
+				utils.Error(w, http.StatusBadRequest, "Skor untuk kriteria '"+k.Nama+"' melebihi skor maksimal "+strconv.Itoa(k.SkorMaks))
+				return
+			}
+			skor[s.IDKriteria] = s.Skor
+			detail = append(detail, models.PenilaianWawancaraDetail{
+				IDKriteria: s.IDKriteria,
+				Skor:       s.Skor,
+				Catatan:    s.Catatan,
+			})
+		}
+		if len(skor) != len(kriteriaMap) {
+			utils.Error(w, http.StatusBadRequest, "Semua kriteria rubrik wajib dinilai")
+			return
+		}
+
+		pendaftarID := jadwal.PendaftarID
+		if pendaftarID == nil {
+			pendaftar, err := services.GetLatestPendaftarByUserID(db, jadwal.UserID)
+			if err != nil {
+				utils.Error(w, http.StatusInternalServerError, "Gagal memeriksa pendaftaran")
+				return
+			}
+			if pendaftar != nil {
+				pendaftarID = &pendaftar.IDPendaftar
+			}
+		}
+
+		penilaian := models.PenilaianWawancara{
+			IDJadwal:      jadwal.IDJadwal,
+			PewawancaraID: claims.IDUser,
+			PendaftarID:   pendaftarID,
+			IDRubrik:      rubrik.IDRubrik,
+			Nilai:         services.HitungNilaiWawancara(rubrik.Kriteria, skor),
+			Catatan:       req.Catatan,
+			Detail:        detail,
+		}
+
+		if err := services.SimpanPenilaianWawancara(db, penilaian); err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal menyimpan penilaian: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Penilaian wawancara berhasil disimpan",
+			"nilai":   penilaian.Nilai,
+		})
+	}
+}
+
+// GetPenilaianWawancaraHandler menampilkan semua penilaian untuk satu jadwal (admin)
+func GetPenilaianWawancaraHandler(db *sql.DB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			utils.Error(w, http.StatusMethodNotAllowed, "Hanya GET yang diizinkan")
+			return
+		}
+
+		claims, ok := r.Context().Value(middleware.UserContextKey).(*utils.Claims)
+		if !ok || claims.Role != "admin" {
+			utils.Error(w, http.StatusForbidden, "Akses ditolak")
+			return
+		}
+
+		idJadwal, err := strconv.Atoi(r.URL.Query().Get("id_jadwal"))
+		if err != nil || idJadwal <= 0 {
+			utils.Error(w, http.StatusBadRequest, "Parameter id_jadwal tidak valid")
+			return
+		}
+
+		list, err := services.GetPenilaianByJadwal(db, idJadwal)
+		if err != nil {
+			utils.Error(w, http.StatusInternalServerError, "Gagal ambil penilaian: "+err.Error())
+			return
+		}
+
+		utils.JSONResponse(w, http.StatusOK, list)
+	}
+}
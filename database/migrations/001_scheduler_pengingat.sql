@@ -0,0 +1,23 @@
+-- 001: penjadwal job persisten dan log pengingat (idempoten)
+
+CREATE TABLE IF NOT EXISTS job_terjadwal (
+    nama VARCHAR(100) PRIMARY KEY,
+    interval_detik INT NOT NULL,
+    terakhir_jalan DATETIME NULL,
+    berikutnya DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    terkunci_sampai DATETIME NULL,
+    error_terakhir TEXT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS pengingat_terkirim (
+    id_pengingat INT AUTO_INCREMENT PRIMARY KEY,
+    jenis ENUM('jadwal', 'test') NOT NULL,
+    ref_id INT NOT NULL,
+    user_id INT NOT NULL,
+    offset_menit INT NOT NULL,
+    kanal ENUM('email', 'notifikasi') NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_pengingat (jenis, ref_id, user_id, offset_menit, kanal),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,59 @@
+-- 002: pewawancara per jadwal, rubrik wawancara, dan penilaian terstruktur
+
+CREATE TABLE IF NOT EXISTS pewawancara_jadwal (
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_jadwal, user_id),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS rubrik_wawancara (
+    id_rubrik INT AUTO_INCREMENT PRIMARY KEY,
+    nama VARCHAR(255) NOT NULL,
+    deskripsi TEXT NULL,
+    aktif BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS kriteria_wawancara (
+    id_kriteria INT AUTO_INCREMENT PRIMARY KEY,
+    id_rubrik INT NOT NULL,
+    nama VARCHAR(255) NOT NULL,
+    deskripsi TEXT NULL,
+    bobot DECIMAL(5,2) NOT NULL DEFAULT 1.00,
+    skor_maks INT NOT NULL DEFAULT 5,
+    urutan INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (id_rubrik) REFERENCES rubrik_wawancara(id_rubrik) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS penilaian_wawancara (
+    id_penilaian INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    pewawancara_id INT NOT NULL,
+    pendaftar_id INT NULL,
+    id_rubrik INT NOT NULL,
+    nilai DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    catatan TEXT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_penilai_per_jadwal (id_jadwal, pewawancara_id),
+    INDEX idx_pendaftar_id (pendaftar_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (pewawancara_id) REFERENCES users(id_user),
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE SET NULL,
+    FOREIGN KEY (id_rubrik) REFERENCES rubrik_wawancara(id_rubrik)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS penilaian_wawancara_detail (
+    id_penilaian INT NOT NULL,
+    id_kriteria INT NOT NULL,
+    skor INT NOT NULL,
+    catatan TEXT NULL,
+    PRIMARY KEY (id_penilaian, id_kriteria),
+    FOREIGN KEY (id_penilaian) REFERENCES penilaian_wawancara(id_penilaian) ON DELETE CASCADE,
+    FOREIGN KEY (id_kriteria) REFERENCES kriteria_wawancara(id_kriteria)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
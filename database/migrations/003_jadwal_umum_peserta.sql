@@ -0,0 +1,18 @@
+-- 003: jadwal umum sebagai acara dengan kapasitas, batas pendaftaran, dan waitlist
+
+ALTER TABLE jadwal
+    ADD COLUMN kapasitas INT NULL AFTER jenis_jadwal,
+    ADD COLUMN batas_pendaftaran DATETIME NULL AFTER kapasitas;
+
+CREATE TABLE IF NOT EXISTS peserta_jadwal (
+    id_peserta INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    status ENUM('terdaftar', 'waitlist') NOT NULL DEFAULT 'terdaftar',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_peserta_jadwal (id_jadwal, user_id),
+    INDEX idx_jadwal_status (id_jadwal, status, created_at),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+-- 004: kehadiran (check-in QR) untuk jadwal pribadi dan acara umum
+
+CREATE TABLE IF NOT EXISTS kehadiran (
+    id_kehadiran INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    waktu_checkin DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dicatat_oleh INT NULL,
+    metode ENUM('qr', 'manual') NOT NULL DEFAULT 'qr',
+    di_luar_jendela BOOLEAN NOT NULL DEFAULT FALSE, -- check-in manual di luar jendela check-in
+    UNIQUE KEY unique_kehadiran (id_jadwal, user_id),
+    INDEX idx_kehadiran_user (user_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE,
+    FOREIGN KEY (dicatat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
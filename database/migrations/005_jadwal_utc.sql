@@ -0,0 +1,100 @@
+-- 005: jadwal disimpan sebagai instan UTC + zona waktu IANA per jadwal
+--
+-- Sebelumnya aplikasi memakai loc=Local dan NOW() mengikuti zona server, sehingga semua kolom
+-- DATETIME lama berisi jam dinding zona server (WITA, +08:00). Sejak migrasi ini DSN memaksa
+-- time_zone='+00:00', jadi seluruh kolom DATETIME yang sudah ada ikut dikonversi ke UTC.
+-- Sesuaikan @offset_lama bila server lama tidak berjalan di WITA. Asia/Makassar tidak memakai
+-- DST, jadi offset tetap aman dipakai tanpa tabel zona waktu MySQL.
+--
+-- Kolom TIMESTAMP (test, hasil_test, soal_test, password_resets.created_at) disimpan MySQL
+-- sebagai UTC dan dibaca sesuai zona sesi, sehingga sudah benar tanpa konversi.
+--
+-- Setiap UPDATE ikut menetapkan updated_at secara eksplisit agar ON UPDATE CURRENT_TIMESTAMP
+-- tidak menimpanya. Jalankan SEBELUM aplikasi versi baru.
+
+SET @offset_lama = '+08:00';
+
+ALTER TABLE jadwal
+    ADD COLUMN waktu_mulai DATETIME NULL AFTER pendaftar_id,
+    ADD COLUMN waktu_selesai DATETIME NULL AFTER waktu_mulai,
+    ADD COLUMN zona_waktu VARCHAR(64) NOT NULL DEFAULT 'Asia/Makassar' AFTER waktu_selesai,
+    ADD COLUMN waktu_mulai_diajukan DATETIME NULL AFTER alasan_perubahan,
+    ADD COLUMN waktu_selesai_diajukan DATETIME NULL AFTER waktu_mulai_diajukan;
+
+UPDATE jadwal SET
+    waktu_mulai = CONVERT_TZ(TIMESTAMP(tanggal, jam_mulai), @offset_lama, '+00:00'),
+    waktu_selesai = CONVERT_TZ(TIMESTAMP(tanggal, jam_selesai), @offset_lama, '+00:00'),
+    batas_pendaftaran = CONVERT_TZ(batas_pendaftaran, @offset_lama, '+00:00'),
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE jadwal SET
+    waktu_mulai_diajukan = CONVERT_TZ(
+        TIMESTAMP(COALESCE(tanggal_diajukan, tanggal), COALESCE(jam_mulai_diajukan, jam_mulai)),
+        @offset_lama, '+00:00'),
+    waktu_selesai_diajukan = CONVERT_TZ(
+        TIMESTAMP(COALESCE(tanggal_diajukan, tanggal), COALESCE(jam_selesai_diajukan, jam_selesai)),
+        @offset_lama, '+00:00'),
+    updated_at = updated_at
+WHERE tanggal_diajukan IS NOT NULL
+   OR jam_mulai_diajukan IS NOT NULL
+   OR jam_selesai_diajukan IS NOT NULL;
+
+ALTER TABLE jadwal
+    MODIFY waktu_mulai DATETIME NOT NULL,
+    MODIFY waktu_selesai DATETIME NOT NULL,
+    DROP INDEX idx_tanggal,
+    DROP COLUMN tanggal,
+    DROP COLUMN jam_mulai,
+    DROP COLUMN jam_selesai,
+    DROP COLUMN tanggal_diajukan,
+    DROP COLUMN jam_mulai_diajukan,
+    DROP COLUMN jam_selesai_diajukan,
+    ADD INDEX idx_waktu_mulai (waktu_mulai);
+
+-- Kolom DATETIME lain yang sudah ada sebelum migrasi ini
+UPDATE users SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE password_resets SET
+    expires_at = CONVERT_TZ(expires_at, @offset_lama, '+00:00');
+
+UPDATE email_verification_tokens SET
+    expires_at = CONVERT_TZ(expires_at, @offset_lama, '+00:00'),
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00');
+
+UPDATE pendaftar SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE pengumuman SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE job_terjadwal SET
+    terakhir_jalan = CONVERT_TZ(terakhir_jalan, @offset_lama, '+00:00'),
+    berikutnya = CONVERT_TZ(berikutnya, @offset_lama, '+00:00'),
+    terkunci_sampai = CONVERT_TZ(terkunci_sampai, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE pengingat_terkirim SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00');
+
+UPDATE pewawancara_jadwal SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00');
+
+UPDATE rubrik_wawancara SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE penilaian_wawancara SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE peserta_jadwal SET
+    created_at = CONVERT_TZ(created_at, @offset_lama, '+00:00'),
+    updated_at = CONVERT_TZ(updated_at, @offset_lama, '+00:00');
+
+UPDATE kehadiran SET
+    waktu_checkin = CONVERT_TZ(waktu_checkin, @offset_lama, '+00:00');
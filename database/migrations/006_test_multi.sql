@@ -0,0 +1,33 @@
+-- 006: banyak tes, masing-masing dengan urutan soal sendiri dari bank soal bersama
+
+ALTER TABLE soal_test DROP INDEX unique_nomor;
+
+ALTER TABLE test
+    ADD COLUMN target_peserta ENUM('semua', 'pilih') NOT NULL DEFAULT 'semua' AFTER aktif;
+
+CREATE TABLE IF NOT EXISTS test_soal (
+    id_test INT NOT NULL,
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL,
+    PRIMARY KEY (id_test, id_soal),
+    UNIQUE KEY unique_urutan_test (id_test, urutan),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Penugasan tes ke pendaftar tertentu (dipakai bila target_peserta = 'pilih')
+CREATE TABLE IF NOT EXISTS test_peserta (
+    id_test INT NOT NULL,
+    pendaftar_id INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_test, pendaftar_id),
+    INDEX idx_test_peserta_pendaftar (pendaftar_id),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Sebelumnya semua tes memakai seluruh bank soal; pertahankan perilaku itu untuk tes lama
+INSERT IGNORE INTO test_soal (id_test, id_soal, urutan)
+SELECT t.id_test, s.id_soal, s.nomor
+FROM test t
+CROSS JOIN soal_test s;
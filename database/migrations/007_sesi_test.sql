@@ -0,0 +1,12 @@
+-- 007: sesi tes berbatas waktu; hasil_test dibuat saat tes dimulai dan dinilai saat selesai
+
+ALTER TABLE hasil_test
+    ADD COLUMN status ENUM('berlangsung', 'selesai') NOT NULL DEFAULT 'selesai' AFTER nilai,
+    ADD COLUMN batas_waktu TIMESTAMP NULL DEFAULT NULL AFTER waktu_mulai,
+    MODIFY waktu_selesai TIMESTAMP NULL DEFAULT NULL,
+    ADD COLUMN created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    ADD INDEX idx_hasil_status_batas (status, batas_waktu);
+
+-- Hasil lama dikirim sekaligus tanpa sesi; batas waktunya disamakan dengan waktu selesai
+UPDATE hasil_test SET batas_waktu = waktu_selesai WHERE batas_waktu IS NULL;
@@ -0,0 +1,5 @@
+-- 008: jawaban disimpan per soal selama sesi berlangsung (autosave), dicatat waktunya
+
+ALTER TABLE jawaban_user
+    ADD COLUMN created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP;
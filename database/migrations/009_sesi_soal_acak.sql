@@ -0,0 +1,13 @@
+-- 009: urutan soal dan pilihan diacak per sesi; pemetaan disimpan agar penilaian tetap memakai jawaban kanonik
+
+CREATE TABLE IF NOT EXISTS sesi_soal (
+    id_hasil INT NOT NULL,
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL,
+    -- urutan_pilihan[i] = pilihan kanonik yang tampil di posisi A..D, mis. 'CADB'
+    urutan_pilihan CHAR(4) NOT NULL DEFAULT 'ABCD',
+    PRIMARY KEY (id_hasil, id_soal),
+    UNIQUE KEY unique_urutan_sesi (id_hasil, urutan),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,76 @@
+-- 010: tipe soal beragam (pilihan ganda, multi jawaban, benar/salah, isian, esai) dan poin berbobot.
+-- Pilihan jawaban pindah ke tabel pilihan_soal; huruf A, B, C, ... mengikuti kolom urutan.
+
+ALTER TABLE soal_test
+    ADD COLUMN tipe ENUM('pilihan_ganda', 'pilihan_ganda_multi', 'benar_salah', 'isian', 'esai')
+        NOT NULL DEFAULT 'pilihan_ganda' AFTER pertanyaan,
+    ADD COLUMN poin DECIMAL(6,2) NOT NULL DEFAULT 1.00 AFTER tipe,
+    ADD COLUMN kunci_isian TEXT NULL AFTER poin, -- isian: satu jawaban per baris, atau satu pola regex
+    ADD COLUMN mode_isian ENUM('persis', 'regex') NOT NULL DEFAULT 'persis' AFTER kunci_isian;
+
+CREATE TABLE IF NOT EXISTS pilihan_soal (
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL, -- 1 = A, 2 = B, ...
+    teks TEXT NOT NULL,
+    is_benar BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id_soal, urutan),
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO pilihan_soal (id_soal, urutan, teks, is_benar)
+SELECT id_soal, 1, pilihan_a, jawaban_benar = 'A' FROM soal_test
+UNION ALL SELECT id_soal, 2, pilihan_b, jawaban_benar = 'B' FROM soal_test
+UNION ALL SELECT id_soal, 3, pilihan_c, jawaban_benar = 'C' FROM soal_test
+UNION ALL SELECT id_soal, 4, pilihan_d, jawaban_benar = 'D' FROM soal_test;
+
+ALTER TABLE soal_test
+    DROP COLUMN pilihan_a,
+    DROP COLUMN pilihan_b,
+    DROP COLUMN pilihan_c,
+    DROP COLUMN pilihan_d,
+    DROP COLUMN jawaban_benar;
+
+-- Pemetaan acak kini sepanjang jumlah pilihan; '' berarti urutan kanonik
+ALTER TABLE sesi_soal MODIFY urutan_pilihan VARCHAR(26) NOT NULL DEFAULT '';
+
+-- Jawaban: huruf kanonik dipisah koma (mis. 'A,C') atau teks bebas.
+-- is_benar/poin NULL selama esai menunggu penilaian manual.
+-- MySQL 8 menyimpan CHECK lama (jawaban A-D) sebagai constraint terpisah yang tetap berlaku
+-- setelah MODIFY, jadi dihapus dulu bila ada. Namanya dicari karena bisa berbeda antar server.
+SET @cek_jawaban := (
+    SELECT cc.CONSTRAINT_NAME
+    FROM information_schema.CHECK_CONSTRAINTS cc
+    INNER JOIN information_schema.TABLE_CONSTRAINTS tc
+        ON tc.CONSTRAINT_SCHEMA = cc.CONSTRAINT_SCHEMA
+       AND tc.CONSTRAINT_NAME = cc.CONSTRAINT_NAME
+    WHERE cc.CONSTRAINT_SCHEMA = DATABASE()
+      AND tc.TABLE_NAME = 'jawaban_user'
+      AND tc.CONSTRAINT_TYPE = 'CHECK'
+      AND cc.CHECK_CLAUSE LIKE '%jawaban_user%'
+    LIMIT 1
+);
+SET @sql_cek_jawaban := IF(@cek_jawaban IS NULL, 'DO 0',
+    CONCAT('ALTER TABLE jawaban_user DROP CHECK `', @cek_jawaban, '`'));
+PREPARE hapus_cek_jawaban FROM @sql_cek_jawaban;
+EXECUTE hapus_cek_jawaban;
+DEALLOCATE PREPARE hapus_cek_jawaban;
+
+ALTER TABLE jawaban_user
+    MODIFY jawaban_user TEXT NOT NULL,
+    MODIFY is_benar BOOLEAN NULL,
+    ADD COLUMN poin DECIMAL(6,2) NULL AFTER is_benar,
+    ADD COLUMN status_penilaian ENUM('otomatis', 'menunggu', 'dinilai') NOT NULL DEFAULT 'otomatis' AFTER poin,
+    ADD COLUMN catatan_penilai TEXT NULL AFTER status_penilaian,
+    ADD COLUMN dinilai_oleh INT NULL AFTER catatan_penilai,
+    ADD COLUMN dinilai_at DATETIME NULL AFTER dinilai_oleh,
+    ADD INDEX idx_jawaban_status_penilaian (status_penilaian),
+    ADD FOREIGN KEY (dinilai_oleh) REFERENCES users(id_user) ON DELETE SET NULL;
+
+UPDATE jawaban_user SET poin = IF(is_benar, 1, 0);
+
+-- Nilai kini dihitung dari poin: nilai = poin / total_poin * 100
+ALTER TABLE hasil_test
+    ADD COLUMN poin DECIMAL(8,2) NOT NULL DEFAULT 0.00 AFTER skor_salah,
+    ADD COLUMN total_poin DECIMAL(8,2) NOT NULL DEFAULT 0.00 AFTER poin;
+
+UPDATE hasil_test SET poin = skor_benar, total_poin = skor_benar + skor_salah;
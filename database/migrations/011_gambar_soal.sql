@@ -0,0 +1,12 @@
+-- 011: gambar untuk soal & pilihan. Teks soal berformat Markdown dan merujuk gambar lewat ![](gambar:ID).
+-- Berkas disimpan di luar folder uploads/ (tidak publik) dan hanya dilayani dengan token sesi tes.
+
+CREATE TABLE IF NOT EXISTS gambar_soal (
+    id_gambar INT AUTO_INCREMENT PRIMARY KEY,
+    nama_file VARCHAR(255) NOT NULL,
+    nama_asli VARCHAR(255) NOT NULL,
+    ukuran INT NOT NULL,
+    diunggah_oleh INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (diunggah_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
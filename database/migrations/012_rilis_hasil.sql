@@ -0,0 +1,11 @@
+-- 012: kebijakan rilis hasil tes dan pembahasan per soal.
+-- rilis_hasil: langsung (setelah submit), setelah_jendela (setelah waktu_selesai tes), manual (admin).
+-- hasil_dirilis_at diisi saat admin merilis manual; bila terisi, hasil dianggap rilis apa pun kebijakannya.
+
+ALTER TABLE test
+    ADD COLUMN rilis_hasil ENUM('langsung', 'setelah_jendela', 'manual') NOT NULL DEFAULT 'langsung' AFTER target_peserta,
+    ADD COLUMN tampilkan_pembahasan BOOLEAN NOT NULL DEFAULT FALSE AFTER rilis_hasil,
+    ADD COLUMN hasil_dirilis_at DATETIME NULL AFTER tampilkan_pembahasan;
+
+ALTER TABLE soal_test
+    ADD COLUMN pembahasan TEXT NULL AFTER mode_isian;
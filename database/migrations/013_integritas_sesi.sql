@@ -0,0 +1,20 @@
+-- 013: sinyal integritas tes: event dari klien (fokus hilang, salin/tempel, keluar layar penuh, ...)
+-- serta IP & user agent saat mulai dan submit.
+
+ALTER TABLE hasil_test
+    ADD COLUMN ip_mulai VARCHAR(45) NULL AFTER waktu_selesai,
+    ADD COLUMN ua_mulai VARCHAR(255) NULL AFTER ip_mulai,
+    ADD COLUMN ip_submit VARCHAR(45) NULL AFTER ua_mulai,
+    ADD COLUMN ua_submit VARCHAR(255) NULL AFTER ip_submit;
+
+CREATE TABLE IF NOT EXISTS sesi_event (
+    id_event BIGINT AUTO_INCREMENT PRIMARY KEY,
+    id_hasil INT NOT NULL,
+    jenis ENUM('fokus_hilang', 'tab_tersembunyi', 'salin', 'tempel', 'potong',
+               'keluar_layar_penuh', 'klik_kanan', 'ganti_perangkat') NOT NULL,
+    detail VARCHAR(255) NULL,
+    waktu_klien DATETIME(3) NOT NULL, -- waktu kejadian menurut klien (UTC)
+    diterima_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_event_hasil (id_hasil, jenis),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
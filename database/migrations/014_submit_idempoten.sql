@@ -0,0 +1,5 @@
+-- 014: submit tes idempoten; kunci dari header Idempotency-Key disimpan bersama hasil
+-- agar permintaan ulang (retry) mengembalikan hasil yang sama tanpa menilai ulang.
+
+ALTER TABLE hasil_test
+    ADD COLUMN kunci_idempotensi VARCHAR(100) NULL AFTER ua_submit;
@@ -0,0 +1,42 @@
+-- 015: koreksi hasil tes oleh admin: penilaian ulang, penyesuaian poin manual, izin mengulang tes,
+-- serta jejak audit setiap perubahan. hasil_test kini boleh berisi beberapa percobaan per user per tes.
+
+ALTER TABLE hasil_test
+    ADD COLUMN percobaan INT NOT NULL DEFAULT 1 AFTER id_test,
+    ADD COLUMN penyesuaian_poin DECIMAL(8,2) NOT NULL DEFAULT 0.00 AFTER poin,
+    ADD UNIQUE KEY unique_user_percobaan (user_id, id_test, percobaan),
+    DROP INDEX unique_user_per_test;
+
+-- Izin satu percobaan tambahan untuk seorang peserta; terpakai saat percobaan baru dimulai
+CREATE TABLE IF NOT EXISTS izin_ulang_test (
+    id_izin INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    user_id INT NOT NULL,
+    alasan VARCHAR(255) NOT NULL,
+    diberikan_oleh INT NULL,
+    id_hasil_dipakai INT NULL,
+    dipakai_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_izin_user_test (user_id, id_test),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE,
+    FOREIGN KEY (diberikan_oleh) REFERENCES users(id_user) ON DELETE SET NULL,
+    FOREIGN KEY (id_hasil_dipakai) REFERENCES hasil_test(id_hasil) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- id_hasil sengaja tanpa foreign key agar jejak reset sesi tetap ada setelah hasilnya dihapus
+CREATE TABLE IF NOT EXISTS audit_hasil_test (
+    id_audit INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    id_hasil INT NULL,
+    user_id INT NULL,
+    aksi ENUM('penilaian_ulang', 'penyesuaian', 'izin_ulang', 'reset_sesi') NOT NULL,
+    nilai_lama DECIMAL(5,2) NULL,
+    nilai_baru DECIMAL(5,2) NULL,
+    alasan VARCHAR(255) NOT NULL,
+    dilakukan_oleh INT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_test (id_test, created_at),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (dilakukan_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
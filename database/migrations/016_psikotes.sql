@@ -0,0 +1,45 @@
+-- 016: psikotes (mis. MBTI). Soal bertipe psikotes tidak punya jawaban benar; setiap pilihan
+-- memberi bobot ke dimensi sifat tes (E/I, S/N, T/F, J/P, ...). Model penilaian disimpan per tes
+-- sehingga dapat diubah admin, dan hasilnya disimpan sebagai profil, bukan persentase.
+
+ALTER TABLE soal_test
+    MODIFY tipe ENUM('pilihan_ganda', 'pilihan_ganda_multi', 'benar_salah', 'isian', 'esai', 'psikotes')
+        NOT NULL DEFAULT 'pilihan_ganda';
+
+ALTER TABLE hasil_test
+    ADD COLUMN tipe_kepribadian VARCHAR(50) NULL AFTER nilai;
+
+-- Dimensi sifat sebuah tes: dua kutub, mis. kode_a 'E' (Ekstrovert) vs kode_b 'I' (Introvert)
+CREATE TABLE IF NOT EXISTS dimensi_psikotes (
+    id_dimensi INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    urutan INT NOT NULL,
+    kode_a VARCHAR(10) NOT NULL,
+    nama_a VARCHAR(50) NOT NULL,
+    kode_b VARCHAR(10) NOT NULL,
+    nama_b VARCHAR(50) NOT NULL,
+    UNIQUE KEY unique_urutan_dimensi (id_test, urutan),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Bobot pilihan (huruf kanonik) soal psikotes ke sebuah dimensi: positif ke kutub A, negatif ke kutub B
+CREATE TABLE IF NOT EXISTS bobot_psikotes (
+    id_dimensi INT NOT NULL,
+    id_soal INT NOT NULL,
+    label CHAR(1) NOT NULL,
+    bobot DECIMAL(6,2) NOT NULL,
+    PRIMARY KEY (id_dimensi, id_soal, label),
+    FOREIGN KEY (id_dimensi) REFERENCES dimensi_psikotes(id_dimensi) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Profil hasil psikotes per sesi dan dimensi
+CREATE TABLE IF NOT EXISTS profil_psikotes (
+    id_hasil INT NOT NULL,
+    id_dimensi INT NOT NULL,
+    skor_a DECIMAL(8,2) NOT NULL DEFAULT 0.00,
+    skor_b DECIMAL(8,2) NOT NULL DEFAULT 0.00,
+    PRIMARY KEY (id_hasil, id_dimensi),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE,
+    FOREIGN KEY (id_dimensi) REFERENCES dimensi_psikotes(id_dimensi) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+-- 017: akomodasi tes per pendaftar (bentrok jadwal ujian, kebutuhan aksesibilitas): jendela
+-- mulai/selesai sendiri dan tambahan menit pengerjaan. Kolom NULL berarti mengikuti tes.
+
+CREATE TABLE IF NOT EXISTS akomodasi_test (
+    id_test INT NOT NULL,
+    pendaftar_id INT NOT NULL,
+    waktu_mulai TIMESTAMP NULL DEFAULT NULL,
+    waktu_selesai TIMESTAMP NULL DEFAULT NULL,
+    tambahan_menit INT NOT NULL DEFAULT 0,
+    catatan VARCHAR(255) NULL,
+    dibuat_oleh INT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_test, pendaftar_id),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE CASCADE,
+    FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,66 @@
+-- 018: riwayat versi soal. Isi soal (pertanyaan, tipe, poin, kunci, pembahasan, pilihan) disalin ke
+-- versi_soal setiap kali berubah dan salinan itu tidak pernah diubah lagi. jawaban_user merujuk versi
+-- yang dijawab peserta, sehingga mengedit soal setelah tes tidak mengubah makna jawaban tersimpan.
+-- Soal tidak lagi dihapus, hanya diarsipkan.
+
+ALTER TABLE soal_test
+    ADD COLUMN versi INT NOT NULL DEFAULT 1 AFTER pembahasan,
+    ADD COLUMN diarsipkan_at DATETIME NULL AFTER versi;
+
+CREATE TABLE IF NOT EXISTS versi_soal (
+    id_versi INT PRIMARY KEY AUTO_INCREMENT,
+    id_soal INT NOT NULL,
+    versi INT NOT NULL,
+    pertanyaan TEXT NOT NULL,
+    tipe ENUM('pilihan_ganda', 'pilihan_ganda_multi', 'benar_salah', 'isian', 'esai', 'psikotes') NOT NULL,
+    poin DECIMAL(6,2) NOT NULL,
+    kunci_isian TEXT NULL,
+    mode_isian ENUM('persis', 'regex') NOT NULL DEFAULT 'persis',
+    pembahasan TEXT NULL,
+    dibuat_oleh INT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_versi_soal (id_soal, versi),
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal),
+    FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS pilihan_versi_soal (
+    id_versi INT NOT NULL,
+    urutan INT NOT NULL,
+    teks TEXT NOT NULL,
+    is_benar BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id_versi, urutan),
+    FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Isi soal saat ini menjadi versi 1
+INSERT INTO versi_soal (id_soal, versi, pertanyaan, tipe, poin, kunci_isian, mode_isian, pembahasan, created_at)
+SELECT id_soal, 1, pertanyaan, tipe, poin, kunci_isian, mode_isian, pembahasan, updated_at
+FROM soal_test;
+
+INSERT INTO pilihan_versi_soal (id_versi, urutan, teks, is_benar)
+SELECT v.id_versi, p.urutan, p.teks, p.is_benar
+FROM pilihan_soal p
+INNER JOIN versi_soal v ON v.id_soal = p.id_soal AND v.versi = 1;
+
+ALTER TABLE jawaban_user
+    ADD COLUMN id_versi INT NULL AFTER id_soal,
+    ADD CONSTRAINT fk_jawaban_versi_soal FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi);
+
+UPDATE jawaban_user ju
+INNER JOIN versi_soal v ON v.id_soal = ju.id_soal AND v.versi = 1
+SET ju.id_versi = v.id_versi;
+
+-- Bobot psikotes berlaku per versi soal: mengedit soal tidak mengubah bobot jawaban lama
+ALTER TABLE bobot_psikotes
+    ADD COLUMN id_versi INT NULL AFTER id_soal;
+
+UPDATE bobot_psikotes b
+INNER JOIN versi_soal v ON v.id_soal = b.id_soal AND v.versi = 1
+SET b.id_versi = v.id_versi;
+
+ALTER TABLE bobot_psikotes
+    MODIFY id_versi INT NOT NULL,
+    DROP PRIMARY KEY,
+    ADD PRIMARY KEY (id_dimensi, id_versi, label),
+    ADD CONSTRAINT fk_bobot_psikotes_versi FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi) ON DELETE CASCADE;
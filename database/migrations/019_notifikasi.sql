@@ -0,0 +1,27 @@
+-- 019: kotak masuk notifikasi. Notifikasi disimpan agar user yang sedang offline tetap
+-- menerimanya; sasaran satu user, satu role, atau semua user. Status dibaca dicatat per user
+-- di notifikasi_dibaca supaya notifikasi role/semua bisa ditandai dibaca oleh masing-masing user.
+
+CREATE TABLE IF NOT EXISTS notifikasi (
+    id_notifikasi BIGINT AUTO_INCREMENT PRIMARY KEY,
+    target ENUM('user', 'role', 'semua') NOT NULL,
+    user_id INT NULL,
+    role ENUM('user', 'admin') NULL,
+    kategori VARCHAR(50) NOT NULL,
+    judul VARCHAR(255) NOT NULL,
+    isi TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_notifikasi_user (user_id, id_notifikasi),
+    INDEX idx_notifikasi_target (target, role, id_notifikasi),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS notifikasi_dibaca (
+    id_notifikasi BIGINT NOT NULL,
+    user_id INT NOT NULL,
+    dibaca_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_notifikasi, user_id),
+    INDEX idx_notifikasi_dibaca_user (user_id),
+    FOREIGN KEY (id_notifikasi) REFERENCES notifikasi(id_notifikasi) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+-- 020: langganan Web Push (PushSubscription browser/PWA). Satu endpoint hanya milik satu user;
+-- bila browser yang sama dipakai user lain, langganan berpindah ke user terakhir.
+
+CREATE TABLE IF NOT EXISTS langganan_push (
+    id_langganan INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    endpoint VARCHAR(500) NOT NULL,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(255) NULL,
+    terakhir_terkirim DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_endpoint (endpoint),
+    INDEX idx_langganan_push_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,42 @@
+-- 021: kanal WhatsApp lewat gateway. pesan_wa mencatat setiap pesan beserta status pengirimannya
+-- (diperbarui webhook /wa/status), users.wa_opt_out_at menandai user yang berhenti menerima
+-- pesan WhatsApp, dan pengingat_terkirim mendapat kanal 'whatsapp'. status_wa_tertunda menampung
+-- callback yang datang sebelum id_provider pesannya tersimpan.
+
+ALTER TABLE users
+    ADD COLUMN wa_opt_out_at DATETIME NULL AFTER is_verified;
+
+ALTER TABLE pengingat_terkirim
+    MODIFY kanal ENUM('email', 'notifikasi', 'whatsapp') NOT NULL;
+
+CREATE TABLE IF NOT EXISTS pesan_wa (
+    id_pesan BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL,
+    kanal VARCHAR(30) NOT NULL,
+    tujuan VARCHAR(16) NOT NULL, -- E.164, mis. +6281234567890
+    template VARCHAR(50) NOT NULL,
+    isi TEXT NOT NULL,
+    id_provider VARCHAR(100) NULL,
+    status ENUM('antre', 'terkirim', 'diterima', 'dibaca', 'gagal') NOT NULL DEFAULT 'antre',
+    error_terakhir VARCHAR(255) NULL,
+    percobaan INT NOT NULL DEFAULT 0,
+    kunci VARCHAR(100) NULL, -- pengenal pesan yang sama saat dicoba ulang, mis. pengingat:jadwal:12:5:60
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_id_provider (kanal, id_provider),
+    UNIQUE KEY unique_kunci_pesan_wa (kunci),
+    INDEX idx_pesan_wa_user (user_id, id_pesan),
+    INDEX idx_pesan_wa_status (status),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Callback status yang tiba sebelum id_provider pesannya tersimpan
+CREATE TABLE IF NOT EXISTS status_wa_tertunda (
+    kanal VARCHAR(30) NOT NULL,
+    id_provider VARCHAR(100) NOT NULL,
+    status ENUM('antre', 'terkirim', 'diterima', 'dibaca', 'gagal') NOT NULL,
+    error_terakhir VARCHAR(255) NULL,
+    diterima_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kanal, id_provider, status),
+    INDEX idx_status_wa_tertunda_waktu (diterima_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
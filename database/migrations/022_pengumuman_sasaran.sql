@@ -0,0 +1,23 @@
+-- 022: pengumuman bersasaran dan terjadwal. Sasaran: semua user, pendaftar (opsional per status),
+-- peserta sebuah tes (tahap seleksi), atau peserta sebuah jadwal. Pengumuman bisa disimpan
+-- sebagai draf, dijadwalkan lewat publish_at, dan berakhir pada expire_at. notifikasi_terkirim_at
+-- mencatat kapan notifikasi ke sasaran dikirim agar tidak terkirim dua kali. Tes dan jadwal yang
+-- masih menjadi sasaran pengumuman tidak bisa dihapus (RESTRICT) agar sasarannya tidak hilang.
+
+ALTER TABLE pengumuman
+    ADD COLUMN sasaran ENUM('semua', 'pendaftar', 'peserta_test', 'peserta_jadwal') NOT NULL DEFAULT 'semua' AFTER isi,
+    ADD COLUMN status_pendaftar ENUM('pending', 'diterima', 'ditolak') NULL AFTER sasaran,
+    ADD COLUMN id_test INT NULL AFTER status_pendaftar,
+    ADD COLUMN id_jadwal INT NULL AFTER id_test,
+    ADD COLUMN status ENUM('draf', 'terbit') NOT NULL DEFAULT 'terbit' AFTER id_jadwal,
+    ADD COLUMN publish_at DATETIME NULL AFTER status,
+    ADD COLUMN expire_at DATETIME NULL AFTER publish_at,
+    ADD COLUMN notifikasi_terkirim_at DATETIME NULL AFTER expire_at,
+    ADD COLUMN dibuat_oleh INT NULL AFTER notifikasi_terkirim_at,
+    ADD INDEX idx_pengumuman_tayang (status, publish_at),
+    ADD CONSTRAINT fk_pengumuman_test FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_pengumuman_jadwal FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_pengumuman_dibuat_oleh FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL;
+
+-- Pengumuman lama sudah disiarkan saat dibuat
+UPDATE pengumuman SET notifikasi_terkirim_at = created_at WHERE notifikasi_terkirim_at IS NULL;
@@ -3,73 +3,106 @@ package dto
 
 import "time"
 
+// WaktuJadwalResponse memuat instan jadwal dalam UTC (ISO-8601) sekaligus tampilan lokal sesuai zona jadwal
+type WaktuJadwalResponse struct {
+	ZonaWaktu             string     `json:"zona_waktu"`
+	WaktuMulai            time.Time  `json:"waktu_mulai"`
+	WaktuSelesai          time.Time  `json:"waktu_selesai"`
+	WaktuMulaiLokal       string     `json:"waktu_mulai_lokal"`
+	WaktuSelesaiLokal     string     `json:"waktu_selesai_lokal"`
+	Tanggal               string     `json:"tanggal"`
+	JamMulai              string     `json:"jam_mulai"`
+	JamSelesai            string     `json:"jam_selesai"`
+	WaktuMulaiDiajukan    *time.Time `json:"waktu_mulai_diajukan,omitempty"`
+	WaktuSelesaiDiajukan  *time.Time `json:"waktu_selesai_diajukan,omitempty"`
+	TanggalDiajukan       *string    `json:"tanggal_diajukan,omitempty"`
+	JamMulaiDiajukan      *string    `json:"jam_mulai_diajukan,omitempty"`
+	JamSelesaiDiajukan    *string    `json:"jam_selesai_diajukan,omitempty"`
+	BatasPendaftaran      *time.Time `json:"batas_pendaftaran,omitempty"`
+	BatasPendaftaranLokal *string    `json:"batas_pendaftaran_lokal,omitempty"`
+}
+
 type JadwalUserResponse struct {
-    IDJadwal                 int        `json:"id_jadwal"`
-    UserID                   int        `json:"user_id"` 
-    UserNama                 string     `json:"user_nama,omitempty"`
-    Tanggal                  time.Time  `json:"tanggal"`
-    JamMulai                 string     `json:"jam_mulai"`
-    JamSelesai               string     `json:"jam_selesai"`
-    Tempat                   string     `json:"tempat"`
-    KonfirmasiJadwal         string     `json:"konfirmasi_jadwal"`
-    Catatan                  *string    `json:"catatan,omitempty"`
-    PengajuanPerubahan       bool       `json:"pengajuan_perubahan"`
-    AlasanPerubahan          *string    `json:"alasan_perubahan,omitempty"`
-    TanggalDiajukan          *time.Time `json:"tanggal_diajukan,omitempty"`
-    JamMulaiDiajukan         *string    `json:"jam_mulai_diajukan,omitempty"`
-    JamSelesaiDiajukan       *string    `json:"jam_selesai_diajukan,omitempty"`
-    JenisJadwal              string     `json:"jenis_jadwal"`
+	IDJadwal int    `json:"id_jadwal"`
+	UserID   int    `json:"user_id"`
+	UserNama string `json:"user_nama,omitempty"`
+	WaktuJadwalResponse
+	Tempat             string  `json:"tempat"`
+	KonfirmasiJadwal   string  `json:"konfirmasi_jadwal"`
+	Catatan            *string `json:"catatan,omitempty"`
+	PengajuanPerubahan bool    `json:"pengajuan_perubahan"`
+	AlasanPerubahan    *string `json:"alasan_perubahan,omitempty"`
+	JenisJadwal        string  `json:"jenis_jadwal"`
+	Kapasitas          *int    `json:"kapasitas,omitempty"`
+	JumlahTerdaftar    int     `json:"jumlah_terdaftar"`
+	StatusPendaftaran  string  `json:"status_pendaftaran,omitempty"`
 }
 
 type JadwalAdminResponse struct {
-    IDJadwal           int        `json:"id_jadwal"`
-    UserID             int        `json:"user_id"`
-    PendaftarID        *int       `json:"pendaftar_id,omitempty"`
-    Tanggal            time.Time  `json:"tanggal"`
-    JamMulai           string     `json:"jam_mulai"`
-    JamSelesai         string     `json:"jam_selesai"`
-    Tempat             string     `json:"tempat"`
-    KonfirmasiJadwal   string     `json:"konfirmasi_jadwal"` 
-    Catatan            *string    `json:"catatan,omitempty"`
-    PengajuanPerubahan bool       `json:"pengajuan_perubahan"`
-    AlasanPerubahan    *string    `json:"alasan_perubahan,omitempty"`
-    TanggalDiajukan    *time.Time `json:"tanggal_diajukan,omitempty"`
-    JamMulaiDiajukan   *string    `json:"jam_mulai_diajukan,omitempty"`
-    JamSelesaiDiajukan *string    `json:"jam_selesai_diajukan,omitempty"`
-    CreatedAt          time.Time  `json:"created_at"`
-    UpdatedAt          time.Time  `json:"updated_at"`
-    JenisJadwal        string     `json:"jenis_jadwal"`
-    UserNama           string     `json:"user_nama,omitempty"`
-    UserEmail          string     `json:"user_email,omitempty"`
-    PendaftarNama      string     `json:"pendaftar_nama,omitempty"`
+	IDJadwal    int  `json:"id_jadwal"`
+	UserID      int  `json:"user_id"`
+	PendaftarID *int `json:"pendaftar_id,omitempty"`
+	WaktuJadwalResponse
+	Tempat             string    `json:"tempat"`
+	KonfirmasiJadwal   string    `json:"konfirmasi_jadwal"`
+	Catatan            *string   `json:"catatan,omitempty"`
+	PengajuanPerubahan bool      `json:"pengajuan_perubahan"`
+	AlasanPerubahan    *string   `json:"alasan_perubahan,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	JenisJadwal        string    `json:"jenis_jadwal"`
+	UserNama           string    `json:"user_nama,omitempty"`
+	UserEmail          string    `json:"user_email,omitempty"`
+	PendaftarNama      string    `json:"pendaftar_nama,omitempty"`
+	Kapasitas          *int      `json:"kapasitas,omitempty"`
+	JumlahTerdaftar    int       `json:"jumlah_terdaftar"`
+	JumlahWaitlist     int       `json:"jumlah_waitlist"`
 }
 
 type JadwalCreateRequest struct {
-    UserID      *int    `json:"user_id,omitempty"`
-    PendaftarID *int    `json:"pendaftar_id,omitempty"`
-    Tanggal     string  `json:"tanggal" validate:"required,datetime=2006-01-02"`
-    JamMulai    string  `json:"jam_mulai" validate:"required,datetime=15:04:05"`
-    JamSelesai  string  `json:"jam_selesai" validate:"required,datetime=15:04:05"`
-    Tempat      string  `json:"tempat" validate:"required,min=3,max=255"`
-    Catatan     *string `json:"catatan,omitempty"`
-    JenisJadwal *string  `json:"jenis_jadwal,omitempty" validate:"omitempty,oneof=pribadi umum"`
+	UserID           *int    `json:"user_id,omitempty"`
+	PendaftarID      *int    `json:"pendaftar_id,omitempty"`
+	Tanggal          string  `json:"tanggal" validate:"required,datetime=2006-01-02"`
+	JamMulai         string  `json:"jam_mulai" validate:"required,datetime=15:04:05"`
+	JamSelesai       string  `json:"jam_selesai" validate:"required,datetime=15:04:05"`
+	Tempat           string  `json:"tempat" validate:"required,min=3,max=255"`
+	Catatan          *string `json:"catatan,omitempty"`
+	JenisJadwal      *string `json:"jenis_jadwal,omitempty" validate:"omitempty,oneof=pribadi umum"`
+	ZonaWaktu        *string `json:"zona_waktu,omitempty" validate:"omitempty,max=64"`
+	Kapasitas        *int    `json:"kapasitas,omitempty" validate:"omitempty,min=1"`
+	BatasPendaftaran *string `json:"batas_pendaftaran,omitempty" validate:"omitempty,datetime=2006-01-02 15:04:05"`
 }
 
 type JadwalUpdateRequest struct {
-    PendaftarID      *int    `json:"pendaftar_id,omitempty"`
-    Tanggal          *string `json:"tanggal,omitempty" validate:"omitempty,datetime=2006-01-02"`
-    JamMulai         *string `json:"jam_mulai,omitempty" validate:"omitempty,datetime=15:04:05"`
-    JamSelesai       *string `json:"jam_selesai,omitempty" validate:"omitempty,datetime=15:04:05"`
-    Tempat           *string `json:"tempat,omitempty" validate:"omitempty,min=3,max=255"`
-    KonfirmasiJadwal *string `json:"konfirmasi_jadwal,omitempty" validate:"omitempty,oneof=belum dikonfirmasi ditolak"`
-    Catatan          *string `json:"catatan,omitempty"`
-    JenisJadwal      *string `json:"jenis_jadwal,omitempty" validate:"omitempty,oneof=pribadi umum"`
+	PendaftarID      *int    `json:"pendaftar_id,omitempty"`
+	Tanggal          *string `json:"tanggal,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	JamMulai         *string `json:"jam_mulai,omitempty" validate:"omitempty,datetime=15:04:05"`
+	JamSelesai       *string `json:"jam_selesai,omitempty" validate:"omitempty,datetime=15:04:05"`
+	Tempat           *string `json:"tempat,omitempty" validate:"omitempty,min=3,max=255"`
+	KonfirmasiJadwal *string `json:"konfirmasi_jadwal,omitempty" validate:"omitempty,oneof=belum dikonfirmasi ditolak"`
+	Catatan          *string `json:"catatan,omitempty"`
+	JenisJadwal      *string `json:"jenis_jadwal,omitempty" validate:"omitempty,oneof=pribadi umum"`
+	ZonaWaktu        *string `json:"zona_waktu,omitempty" validate:"omitempty,max=64"`
+	Kapasitas        *int    `json:"kapasitas,omitempty" validate:"omitempty,min=1"`
+	BatasPendaftaran *string `json:"batas_pendaftaran,omitempty" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+	// Kosongkan mengembalikan field opsional ke NULL (tanpa batas), mis. ["kapasitas"]
+	Kosongkan []string `json:"kosongkan,omitempty" validate:"omitempty,dive,oneof=kapasitas batas_pendaftaran"`
 }
 
 type JadwalAjukanPerubahanRequest struct {
-    IDJadwal             int        `json:"id_jadwal" validate:"required"`
-    TanggalDiajukan      *string    `json:"tanggal_diajukan,omitempty" validate:"omitempty,datetime=2006-01-02"`
-    JamMulaiDiajukan     *string    `json:"jam_mulai_diajukan,omitempty" validate:"omitempty,datetime=15:04:05"`
-    JamSelesaiDiajukan   *string    `json:"jam_selesai_diajukan,omitempty" validate:"omitempty,datetime=15:04:05"`
-    AlasanPerubahan      string     `json:"alasan_perubahan" validate:"required,min=10,max=500"`
-}
\ No newline at end of file
+	IDJadwal           int     `json:"id_jadwal" validate:"required"`
+	TanggalDiajukan    *string `json:"tanggal_diajukan,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	JamMulaiDiajukan   *string `json:"jam_mulai_diajukan,omitempty" validate:"omitempty,datetime=15:04:05"`
+	JamSelesaiDiajukan *string `json:"jam_selesai_diajukan,omitempty" validate:"omitempty,datetime=15:04:05"`
+	AlasanPerubahan    string  `json:"alasan_perubahan" validate:"required,min=10,max=500"`
+}
+
+type DaftarJadwalUmumRequest struct {
+	IDJadwal int `json:"id_jadwal" validate:"required"`
+}
+
+type CheckinRequest struct {
+	Token    *string `json:"token,omitempty"`
+	IDJadwal *int    `json:"id_jadwal,omitempty"`
+	UserID   *int    `json:"user_id,omitempty"`
+}
@@ -1,7 +1,49 @@
 // dto/notifikasi.go
 package dto
 
+import "time"
+
+// NotifikasiEvent adalah satu event stream notifikasi; ID naik terus dan dipakai sebagai
+// id SSE (Last-Event-ID), Jenis menjadi nama event SSE. Event yang berasal dari kotak masuk
+// membawa IDNotifikasi agar klien bisa menandainya dibaca.
 type NotifikasiEvent struct {
-    Judul string `json:"judul"`
-    Isi   string `json:"isi"`
-}
\ No newline at end of file
+	ID           int64     `json:"id"`
+	Jenis        string    `json:"jenis"`
+	IDNotifikasi int64     `json:"id_notifikasi,omitempty"`
+	Kategori     string    `json:"kategori,omitempty"`
+	Judul        string    `json:"judul"`
+	Isi          string    `json:"isi"`
+	UserID       int       `json:"user_id,omitempty"`
+	Role         string    `json:"role,omitempty"`
+	Waktu        time.Time `json:"waktu"`
+}
+
+type BacaNotifikasiRequest struct {
+	IDNotifikasi int64 `json:"id_notifikasi" validate:"required,min=1"`
+}
+
+// LanggananPushRequest mengikuti bentuk PushSubscription.toJSON() dari browser
+type LanggananPushRequest struct {
+	Endpoint string             `json:"endpoint" validate:"required,url,max=500"`
+	Keys     KunciLanggananPush `json:"keys" validate:"required"`
+}
+
+type KunciLanggananPush struct {
+	P256dh string `json:"p256dh" validate:"required,max=255"`
+	Auth   string `json:"auth" validate:"required,max=64"`
+}
+
+type HapusLanggananPushRequest struct {
+	Endpoint string `json:"endpoint" validate:"required,url,max=500"`
+}
+
+type PreferensiWARequest struct {
+	Aktif *bool `json:"aktif" validate:"required"`
+}
+
+// StatusPesanWARequest adalah callback status dari gateway WhatsApp
+type StatusPesanWARequest struct {
+	ID     string `json:"id" validate:"required,max=100"`
+	Status string `json:"status" validate:"required"`
+	Error  string `json:"error,omitempty"`
+}
@@ -1,19 +1,29 @@
 package dto
 
+import "cocopen-backend/models"
+
 type CreatePendaftarRequest struct {
-    NamaLengkap         string `json:"nama_lengkap"`
-    AsalKampus          string `json:"asal_kampus"`
-    Prodi               string `json:"prodi"`
-    Semester            string `json:"semester"`
-    NoWA                string `json:"no_wa"`
-    Domisili            string `json:"domisili"`
-    AlamatSekarang      string `json:"alamat_sekarang"`
-    TinggalDengan       string `json:"tinggal_dengan"`
-    AlasanMasuk         string `json:"alasan_masuk"`
-    PengetahuanCoconut  string `json:"pengetahuan_coconut"`
+	NamaLengkap        string `json:"nama_lengkap"`
+	AsalKampus         string `json:"asal_kampus"`
+	Prodi              string `json:"prodi"`
+	Semester           string `json:"semester"`
+	NoWA               string `json:"no_wa"`
+	Domisili           string `json:"domisili"`
+	AlamatSekarang     string `json:"alamat_sekarang"`
+	TinggalDengan      string `json:"tinggal_dengan"`
+	AlasanMasuk        string `json:"alasan_masuk"`
+	PengetahuanCoconut string `json:"pengetahuan_coconut"`
 }
 
 type UpdatePendaftarStatusRequest struct {
-    IDPendaftar int    `json:"id_pendaftar" validate:"required"`
-    Status      string `json:"status" validate:"required,oneof=pending diterima ditolak"`
+	IDPendaftar int    `json:"id_pendaftar" validate:"required"`
+	Status      string `json:"status" validate:"required,oneof=pending diterima ditolak"`
+}
+
+type PendaftarDetailResponse struct {
+	models.Pendaftar
+	FotoURL   string                     `json:"foto_url"`
+	HasilTest []RingkasanHasilTest       `json:"hasil_test"`
+	Wawancara RingkasanWawancara         `json:"wawancara"`
+	Kehadiran *models.RingkasanKehadiran `json:"kehadiran,omitempty"`
 }
@@ -1,15 +1,34 @@
 package dto
 
+import "time"
+
 type PengumumanResponse struct {
-    IDPengumuman int    `json:"id_pengumuman"`
-    Judul        string `json:"judul"`
-    Isi          string `json:"isi"`
-    CreatedAt    string `json:"created_at"`
-    WaktuLalu    string `json:"waktu_lalu"`
+	IDPengumuman    int        `json:"id_pengumuman"`
+	Judul           string     `json:"judul"`
+	Isi             string     `json:"isi"`
+	Sasaran         string     `json:"sasaran"`
+	StatusPendaftar *string    `json:"status_pendaftar,omitempty"`
+	IDTest          *int       `json:"id_test,omitempty"`
+	IDJadwal        *int       `json:"id_jadwal,omitempty"`
+	Status          string     `json:"status"`
+	PublishAt       *time.Time `json:"publish_at,omitempty"`
+	ExpireAt        *time.Time `json:"expire_at,omitempty"`
+	CreatedAt       string     `json:"created_at"`
+	WaktuLalu       string     `json:"waktu_lalu"`
 }
 
+// PengumumanCreateRequest juga dipakai untuk update: field opsional yang kosong tidak diubah.
+// publish_at kosong berarti tayang begitu diterbitkan.
 type PengumumanCreateRequest struct {
-    Judul   string `json:"judul" validate:"required,min=3"`
-    Isi     string `json:"isi" validate:"required,min=10"`
-    IdJadwal *int  `json:"id_jadwal,omitempty"` 
-}
\ No newline at end of file
+	Judul           string     `json:"judul" validate:"required,min=3"`
+	Isi             string     `json:"isi" validate:"required,min=10"`
+	IdJadwal        *int       `json:"id_jadwal,omitempty" validate:"omitempty,min=1"`
+	Sasaran         *string    `json:"sasaran,omitempty" validate:"omitempty,oneof=semua pendaftar peserta_test peserta_jadwal"`
+	StatusPendaftar *string    `json:"status_pendaftar,omitempty" validate:"omitempty,oneof=pending diterima ditolak"`
+	IDTest          *int       `json:"id_test,omitempty" validate:"omitempty,min=1"`
+	Status          *string    `json:"status,omitempty" validate:"omitempty,oneof=draf terbit"`
+	PublishAt       *time.Time `json:"publish_at,omitempty"`
+	ExpireAt        *time.Time `json:"expire_at,omitempty"`
+	// Kosongkan mengembalikan field opsional ke NULL, mis. ["expire_at"] agar pengumuman tidak berakhir
+	Kosongkan []string `json:"kosongkan,omitempty" validate:"omitempty,dive,oneof=publish_at expire_at id_jadwal status_pendaftar id_test"`
+}
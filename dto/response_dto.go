@@ -2,14 +2,14 @@
 package dto
 
 type SuccessResponse struct {
-    Success bool        `json:"success"`
-    Status  int         `json:"status"`
-    Message string      `json:"message"`
-    Data    interface{} `json:"data,omitempty"`
+	Success bool        `json:"success"`
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
 }
 
 type ErrorResponse struct {
-    Success bool   `json:"success"`
-    Status  int    `json:"status"`
-    Message string `json:"message"`
-}
\ No newline at end of file
+	Success bool   `json:"success"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
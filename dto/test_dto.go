@@ -1,58 +1,256 @@
 package dto
 
-import "time"
+import (
+	"cocopen-backend/models"
+	"time"
+)
+
+type PilihanSoalRequest struct {
+	Teks    string `json:"teks" validate:"required,min=1"`
+	IsBenar bool   `json:"is_benar"`
+}
 
 type SoalCreateRequest struct {
-    Nomor        int    `json:"nomor" validate:"required,min=1"`
-    Pertanyaan   string `json:"pertanyaan" validate:"required,min=10"`
-    PilihanA     string `json:"pilihan_a" validate:"required,min=1"`
-    PilihanB     string `json:"pilihan_b" validate:"required,min=1"`
-    PilihanC     string `json:"pilihan_c" validate:"required,min=1"`
-    PilihanD     string `json:"pilihan_d" validate:"required,min=1"`
-    JawabanBenar string `json:"jawaban_benar" validate:"required,oneof=A B C D"`
+	Nomor      int                  `json:"nomor" validate:"required,min=1"`
+	Pertanyaan string               `json:"pertanyaan" validate:"required,min=10"`
+	Tipe       string               `json:"tipe" validate:"omitempty,oneof=pilihan_ganda pilihan_ganda_multi benar_salah isian esai psikotes"`
+	Poin       *float64             `json:"poin,omitempty" validate:"omitempty,gte=0"`
+	Pilihan    []PilihanSoalRequest `json:"pilihan,omitempty" validate:"omitempty,max=10,dive"`
+	KunciIsian *string              `json:"kunci_isian,omitempty"`
+	ModeIsian  *string              `json:"mode_isian,omitempty" validate:"omitempty,oneof=persis regex"`
+	Pembahasan *string              `json:"pembahasan,omitempty"`
 }
 
 type SoalUpdateRequest struct {
-    Nomor        *int    `json:"nomor,omitempty" validate:"omitempty,min=1"`
-    Pertanyaan   *string `json:"pertanyaan,omitempty" validate:"omitempty,min=10"`
-    PilihanA     *string `json:"pilihan_a,omitempty" validate:"omitempty,min=1"`
-    PilihanB     *string `json:"pilihan_b,omitempty" validate:"omitempty,min=1"`
-    PilihanC     *string `json:"pilihan_c,omitempty" validate:"omitempty,min=1"`
-    PilihanD     *string `json:"pilihan_d,omitempty" validate:"omitempty,min=1"`
-    JawabanBenar *string `json:"jawaban_benar,omitempty" validate:"omitempty,oneof=A B C D"`
+	Nomor      *int     `json:"nomor,omitempty" validate:"omitempty,min=1"`
+	Pertanyaan *string  `json:"pertanyaan,omitempty" validate:"omitempty,min=10"`
+	Tipe       *string  `json:"tipe,omitempty" validate:"omitempty,oneof=pilihan_ganda pilihan_ganda_multi benar_salah isian esai psikotes"`
+	Poin       *float64 `json:"poin,omitempty" validate:"omitempty,gte=0"`
+	// nil: pilihan tidak diubah; [] menghapus semua pilihan (untuk isian/esai)
+	Pilihan    []PilihanSoalRequest `json:"pilihan,omitempty" validate:"omitempty,max=10,dive"`
+	KunciIsian *string              `json:"kunci_isian,omitempty"`
+	ModeIsian  *string              `json:"mode_isian,omitempty" validate:"omitempty,oneof=persis regex"`
+	// "" menghapus pembahasan
+	Pembahasan *string `json:"pembahasan,omitempty"`
+}
+
+type MulaiTestRequest struct {
+	IDTest int `json:"id_test" validate:"required"`
 }
 
 type SubmitJawabanRequest struct {
-    Jawaban map[int]string `json:"jawaban" validate:"required,dive,keys,required,endkeys,oneof=A B C D"`
+	IDTest int `json:"id_test" validate:"required"`
+	// Opsional: jawaban yang sudah di-autosave ikut dinilai
+	Jawaban map[int]string `json:"jawaban" validate:"omitempty,dive,keys,required,endkeys,max=5000"`
+}
+
+type SimpanJawabanRequest struct {
+	IDTest int `json:"id_test" validate:"required"`
+	IDSoal int `json:"id_soal" validate:"required"`
+	// Huruf pilihan ("A", atau "A,C" untuk multi jawaban) atau teks untuk isian/esai
+	Jawaban string `json:"jawaban" validate:"required,max=5000"`
 }
 
 type HasilResponse struct {
-    IDHasil      int     `json:"id_hasil"`
-    UserID       int     `json:"user_id"`
-    PendaftarID  int     `json:"pendaftar_id"`
-    SkorBenar    int     `json:"skor_benar"`
-    SkorSalah    int     `json:"skor_salah"`
-    Nilai        float64 `json:"nilai"`
-    WaktuMulai   string  `json:"waktu_mulai"`
-    WaktuSelesai *string `json:"waktu_selesai,omitempty"`
-    DurasiMenit  *int    `json:"durasi_menit,omitempty"`
+	IDHasil           int     `json:"id_hasil"`
+	UserID            int     `json:"user_id"`
+	PendaftarID       int     `json:"pendaftar_id"`
+	SkorBenar         int     `json:"skor_benar"`
+	SkorSalah         int     `json:"skor_salah"`
+	Poin              float64 `json:"poin"`
+	TotalPoin         float64 `json:"total_poin"`
+	Nilai             float64 `json:"nilai"`
+	MenungguPenilaian int     `json:"menunggu_penilaian"`
+	WaktuMulai        string  `json:"waktu_mulai"`
+	WaktuSelesai      *string `json:"waktu_selesai,omitempty"`
+	DurasiMenit       *int    `json:"durasi_menit,omitempty"`
+}
+
+type PilihanResponse struct {
+	Label    string `json:"label"`
+	Teks     string `json:"teks"`      // Markdown mentah
+	TeksHTML string `json:"teks_html"` // HTML tersanitasi, siap ditampilkan
 }
 
 type SoalResponse struct {
-    IDSoal     int    `json:"id_soal"`
-    Nomor      int    `json:"nomor"`
-    Pertanyaan string `json:"pertanyaan"`
-    PilihanA   string `json:"pilihan_a"`
-    PilihanB   string `json:"pilihan_b"`
-    PilihanC   string `json:"pilihan_c"`
-    PilihanD   string `json:"pilihan_d"`
-}
-
-type TestConfigUpdateRequest struct {
-    Judul           *string    `json:"judul,omitempty" validate:"omitempty,min=1"`
-    Deskripsi       *string    `json:"deskripsi,omitempty"`
-    DurasiMenit     *int       `json:"durasi_menit,omitempty" validate:"omitempty,min=1"`
-    WaktuMulai      *time.Time `json:"waktu_mulai,omitempty"`
-    WaktuSelesai    *time.Time `json:"waktu_selesai,omitempty"`
-    Aktif           *bool      `json:"aktif,omitempty"`
-}
\ No newline at end of file
+	IDSoal         int               `json:"id_soal"`
+	Nomor          int               `json:"nomor"`
+	Pertanyaan     string            `json:"pertanyaan"`      // Markdown mentah
+	PertanyaanHTML string            `json:"pertanyaan_html"` // HTML tersanitasi, siap ditampilkan
+	Tipe           string            `json:"tipe"`
+	Poin           float64           `json:"poin"`
+	Pilihan        []PilihanResponse `json:"pilihan,omitempty"`
+}
+
+type TestCreateRequest struct {
+	Judul               string     `json:"judul" validate:"required,min=1,max=255"`
+	Deskripsi           *string    `json:"deskripsi,omitempty"`
+	DurasiMenit         int        `json:"durasi_menit" validate:"required,min=1"`
+	WaktuMulai          *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai        *time.Time `json:"waktu_selesai,omitempty"`
+	Aktif               *bool      `json:"aktif,omitempty"`
+	TargetPeserta       *string    `json:"target_peserta,omitempty" validate:"omitempty,oneof=semua pilih"`
+	RilisHasil          *string    `json:"rilis_hasil,omitempty" validate:"omitempty,oneof=langsung setelah_jendela manual"`
+	TampilkanPembahasan *bool      `json:"tampilkan_pembahasan,omitempty"`
+}
+
+type TestUpdateRequest struct {
+	Judul               *string    `json:"judul,omitempty" validate:"omitempty,min=1,max=255"`
+	Deskripsi           *string    `json:"deskripsi,omitempty"`
+	DurasiMenit         *int       `json:"durasi_menit,omitempty" validate:"omitempty,min=1"`
+	WaktuMulai          *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai        *time.Time `json:"waktu_selesai,omitempty"`
+	Aktif               *bool      `json:"aktif,omitempty"`
+	RilisHasil          *string    `json:"rilis_hasil,omitempty" validate:"omitempty,oneof=langsung setelah_jendela manual"`
+	TampilkanPembahasan *bool      `json:"tampilkan_pembahasan,omitempty"`
+	// Kosongkan mengembalikan jendela tes ke NULL (tanpa batas), mis. ["waktu_selesai"]
+	Kosongkan []string `json:"kosongkan,omitempty" validate:"omitempty,dive,oneof=waktu_mulai waktu_selesai"`
+}
+
+type RilisHasilRequest struct {
+	Rilis *bool `json:"rilis" validate:"required"`
+}
+
+type SoalTestSetRequest struct {
+	IDSoal []int `json:"id_soal" validate:"required,min=1,unique,dive,min=1"`
+}
+
+type PesertaTestSetRequest struct {
+	TargetPeserta string `json:"target_peserta" validate:"required,oneof=semua pilih"`
+	PendaftarID   []int  `json:"pendaftar_id" validate:"omitempty,unique,dive,min=1"`
+}
+
+type TestSayaResponse struct {
+	IDTest       int        `json:"id_test"`
+	Judul        string     `json:"judul"`
+	Deskripsi    *string    `json:"deskripsi,omitempty"`
+	DurasiMenit  int        `json:"durasi_menit"`
+	WaktuMulai   *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai *time.Time `json:"waktu_selesai,omitempty"`
+	JumlahSoal   int        `json:"jumlah_soal"`
+	// tersedia, belum_dibuka, berlangsung, waktu_habis, selesai, atau boleh_ulang (ada izin ulang)
+	Status     string     `json:"status"`
+	BatasWaktu *time.Time `json:"batas_waktu,omitempty"`
+	SisaDetik  *int       `json:"sisa_detik,omitempty"`
+}
+
+// SoalSesiAdminResponse menampilkan soal seperti yang dilihat peserta beserta pemetaan ke kunci kanonik
+type SoalSesiAdminResponse struct {
+	Nomor              int                  `json:"nomor"`
+	IDSoal             int                  `json:"id_soal"`
+	Pertanyaan         string               `json:"pertanyaan"`
+	Tipe               string               `json:"tipe"`
+	Poin               float64              `json:"poin"`
+	Pilihan            []models.PilihanSoal `json:"pilihan,omitempty"`
+	UrutanPilihan      string               `json:"urutan_pilihan,omitempty"`
+	KunciIsian         *string              `json:"kunci_isian,omitempty"`
+	JawabanBenar       string               `json:"jawaban_benar,omitempty"`
+	JawabanBenarTampil string               `json:"jawaban_benar_tampil,omitempty"`
+	JawabanUser        *string              `json:"jawaban_user,omitempty"`
+	JawabanUserTampil  *string              `json:"jawaban_user_tampil,omitempty"`
+	IsBenar            *bool                `json:"is_benar,omitempty"`
+	PoinDiperoleh      *float64             `json:"poin_diperoleh,omitempty"`
+	StatusPenilaian    string               `json:"status_penilaian,omitempty"`
+}
+
+type NilaiEsaiRequest struct {
+	IDJawaban int      `json:"id_jawaban" validate:"required"`
+	Poin      *float64 `json:"poin" validate:"required,gte=0"`
+	Catatan   *string  `json:"catatan,omitempty"`
+}
+
+// HasilSayaResponse adalah hasil tes untuk peserta; Hasil dan Soal hanya terisi bila sudah dirilis
+type HasilSayaResponse struct {
+	IDTest              int                      `json:"id_test"`
+	Judul               string                   `json:"judul"`
+	Dirilis             bool                     `json:"dirilis"`
+	DirilisPada         *time.Time               `json:"dirilis_pada,omitempty"`
+	Pesan               string                   `json:"pesan,omitempty"`
+	Hasil               *HasilResponse           `json:"hasil,omitempty"`
+	TampilkanPembahasan bool                     `json:"tampilkan_pembahasan"`
+	Soal                []SoalPembahasanResponse `json:"soal,omitempty"`
+}
+
+// SoalPembahasanResponse menampilkan soal dengan jawaban peserta dan kunci dalam urutan yang dilihat peserta
+type SoalPembahasanResponse struct {
+	SoalResponse
+	JawabanAnda     *string  `json:"jawaban_anda"`
+	JawabanBenar    string   `json:"jawaban_benar,omitempty"`
+	KunciIsian      *string  `json:"kunci_isian,omitempty"`
+	IsBenar         *bool    `json:"is_benar"`
+	PoinDiperoleh   *float64 `json:"poin_diperoleh"`
+	StatusPenilaian string   `json:"status_penilaian,omitempty"`
+	CatatanPenilai  *string  `json:"catatan_penilai,omitempty"`
+	Pembahasan      *string  `json:"pembahasan,omitempty"`
+	PembahasanHTML  string   `json:"pembahasan_html,omitempty"`
+}
+
+type EventSesiItem struct {
+	Jenis string `json:"jenis" validate:"required,oneof=fokus_hilang tab_tersembunyi salin tempel potong keluar_layar_penuh klik_kanan"`
+	// Waktu kejadian di klien; kosong berarti waktu diterima server
+	Waktu  *time.Time `json:"waktu,omitempty"`
+	Detail *string    `json:"detail,omitempty" validate:"omitempty,max=255"`
+}
+
+// EventSesiRequest mengirim event integritas secara berkelompok selama sesi berlangsung
+type EventSesiRequest struct {
+	IDTest int             `json:"id_test" validate:"required"`
+	Events []EventSesiItem `json:"events" validate:"required,min=1,max=50,dive"`
+}
+
+// ResetSesiRequest menghapus percobaan tes yang rusak; alasan dicatat di audit hasil tes
+type ResetSesiRequest struct {
+	IDHasil int    `json:"id_hasil" validate:"required"`
+	Alasan  string `json:"alasan" validate:"required,min=5,max=255"`
+}
+
+// PenilaianUlangRequest menilai ulang semua hasil sebuah tes (?id_test=) dengan kunci soal terkini
+type PenilaianUlangRequest struct {
+	Alasan string `json:"alasan" validate:"required,min=5,max=255"`
+}
+
+// PenyesuaianNilaiRequest menetapkan penyesuaian poin (boleh negatif; 0 menghapus penyesuaian)
+type PenyesuaianNilaiRequest struct {
+	IDHasil     int      `json:"id_hasil" validate:"required"`
+	Penyesuaian *float64 `json:"penyesuaian_poin" validate:"required"`
+	Alasan      string   `json:"alasan" validate:"required,min=5,max=255"`
+}
+
+// IzinUlangRequest memberi seorang peserta satu percobaan tambahan pada sebuah tes
+type IzinUlangRequest struct {
+	IDTest int    `json:"id_test" validate:"required"`
+	UserID int    `json:"user_id" validate:"required"`
+	Alasan string `json:"alasan" validate:"required,min=5,max=255"`
+}
+
+// ModelPsikotesRequest mengganti seluruh model penilaian psikotes sebuah tes (?id_test=)
+type ModelPsikotesRequest struct {
+	Dimensi []DimensiPsikotesRequest `json:"dimensi" validate:"max=10,dive"`
+}
+
+type DimensiPsikotesRequest struct {
+	KodeA string                 `json:"kode_a" validate:"required,max=10"`
+	NamaA string                 `json:"nama_a" validate:"required,max=50"`
+	KodeB string                 `json:"kode_b" validate:"required,max=10"`
+	NamaB string                 `json:"nama_b" validate:"required,max=50"`
+	Bobot []BobotPsikotesRequest `json:"bobot" validate:"dive"`
+}
+
+// BobotPsikotesRequest: bobot positif menambah kutub A, negatif menambah kutub B.
+// Versi kosong berarti versi soal saat ini.
+type BobotPsikotesRequest struct {
+	IDSoal int     `json:"id_soal" validate:"required"`
+	Versi  int     `json:"versi,omitempty" validate:"omitempty,min=1"`
+	Label  string  `json:"label" validate:"required,len=1"`
+	Bobot  float64 `json:"bobot" validate:"required"`
+}
+
+// AkomodasiRequest mengatur jendela dan tambahan waktu khusus seorang pendaftar pada tes (?id_test=);
+// waktu yang dikosongkan mengikuti jendela tes
+type AkomodasiRequest struct {
+	PendaftarID   int        `json:"pendaftar_id" validate:"required"`
+	WaktuMulai    *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai  *time.Time `json:"waktu_selesai,omitempty"`
+	TambahanMenit int        `json:"tambahan_menit" validate:"min=0,max=600"`
+	Catatan       *string    `json:"catatan,omitempty" validate:"omitempty,max=255"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+type KriteriaRequest struct {
+	Nama      string  `json:"nama" validate:"required,min=2,max=255"`
+	Deskripsi *string `json:"deskripsi,omitempty"`
+	Bobot     float64 `json:"bobot" validate:"required,gt=0"`
+	SkorMaks  int     `json:"skor_maks" validate:"required,min=1,max=100"`
+}
+
+type RubrikCreateRequest struct {
+	Nama      string            `json:"nama" validate:"required,min=3,max=255"`
+	Deskripsi *string           `json:"deskripsi,omitempty"`
+	Aktif     *bool             `json:"aktif,omitempty"`
+	Kriteria  []KriteriaRequest `json:"kriteria" validate:"required,min=1,dive"`
+}
+
+type RubrikUpdateRequest struct {
+	Nama      *string           `json:"nama,omitempty" validate:"omitempty,min=3,max=255"`
+	Deskripsi *string           `json:"deskripsi,omitempty"`
+	Aktif     *bool             `json:"aktif,omitempty"`
+	Kriteria  []KriteriaRequest `json:"kriteria,omitempty" validate:"omitempty,min=1,dive"`
+}
+
+type AssignPewawancaraRequest struct {
+	IDJadwal int   `json:"id_jadwal" validate:"required"`
+	UserIDs  []int `json:"user_ids" validate:"required,dive,required"`
+}
+
+type SkorKriteriaRequest struct {
+	IDKriteria int     `json:"id_kriteria" validate:"required"`
+	Skor       int     `json:"skor" validate:"min=0"`
+	Catatan    *string `json:"catatan,omitempty"`
+}
+
+type PenilaianWawancaraRequest struct {
+	IDJadwal int                   `json:"id_jadwal" validate:"required"`
+	IDRubrik *int                  `json:"id_rubrik,omitempty"`
+	Skor     []SkorKriteriaRequest `json:"skor" validate:"required,min=1,dive"`
+	Catatan  *string               `json:"catatan,omitempty"`
+}
+
+type RingkasanWawancara struct {
+	JumlahPenilaian int      `json:"jumlah_penilaian"`
+	RataRata        *float64 `json:"rata_rata"`
+}
+
+type RingkasanHasilTest struct {
+	IDHasil      int     `json:"id_hasil"`
+	IDTest       int     `json:"id_test"`
+	JudulTest    string  `json:"judul_test"`
+	Nilai        float64 `json:"nilai"`
+	WaktuSelesai *string `json:"waktu_selesai,omitempty"`
+
+	// Diisi untuk psikotes: profil (mis. "INTJ") menggantikan nilai
+	TipeKepribadian *string `json:"tipe_kepribadian,omitempty"`
+}
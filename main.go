@@ -1,41 +1,41 @@
 package main
 
 import (
-    "fmt"
-    "net/http"
-    "os"
-
-    "cocopen-backend/config"
-    "cocopen-backend/routes"
-    "cocopen-backend/middleware"
-    "cocopen-backend/utils"
+	"fmt"
+	"net/http"
+	"os"
+
+	"cocopen-backend/config"
+	"cocopen-backend/middleware"
+	"cocopen-backend/routes"
+	"cocopen-backend/utils"
 )
 
 func main() {
 
-    config.LoadEnv()
+	config.LoadEnv()
 
-    utils.InitJWTSecret()
+	utils.InitJWTSecret()
 
-    db := config.ConnectDB()
-    defer db.Close()
+	db := config.ConnectDB()
+	defer db.Close()
 
-    config.StartCleanupJob(db)
+	config.StartScheduler(db, config.DefaultJobs())
 
-    mux := routes.Setup(db)
+	mux := routes.Setup(db)
 
-    handler := middleware.Recovery(middleware.Cors(mux))
+	handler := middleware.Recovery(middleware.Cors(mux))
 
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = os.Getenv("SERVER_PORT")
-    }
-    if port == "" {
-        port = "5012"
-    }
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = os.Getenv("SERVER_PORT")
+	}
+	if port == "" {
+		port = "5012"
+	}
 
-    fmt.Printf("Server berjalan di port %s\n", port)
-    if err := http.ListenAndServe(":"+port, handler); err != nil {
-        panic(fmt.Sprintf("Error menjalankan server: %v", err))
-    }
+	fmt.Printf("Server berjalan di port %s\n", port)
+	if err := http.ListenAndServe(":"+port, handler); err != nil {
+		panic(fmt.Sprintf("Error menjalankan server: %v", err))
+	}
 }
@@ -1,24 +1,25 @@
 package middleware
 
 import (
-    "net/http"
-    "os"
+	"net/http"
+	"os"
 )
 
 func Cors(next http.Handler) http.Handler {
-    allowedOrigin := os.Getenv("FRONTEND_URL")
+	allowedOrigin := os.Getenv("FRONTEND_URL")
 
-    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-        w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-        w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-        w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-        w.Header().Set("Access-Control-Allow-Credentials", "true")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, Last-Event-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "Idempotent-Replayed")
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
-        if r.Method == "OPTIONS" {
-            w.WriteHeader(http.StatusOK)
-            return
-        }
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
 
-        next.ServeHTTP(w, r)
-    })
+		next.ServeHTTP(w, r)
+	})
 }
@@ -2,25 +2,25 @@
 package middleware
 
 import (
-    "net/http"
-    "cocopen-backend/utils"
+	"cocopen-backend/utils"
+	"net/http"
 )
 
 func Role(requiredRole string) func(http.HandlerFunc) http.HandlerFunc {
-    return func(next http.HandlerFunc) http.HandlerFunc {
-        return func(w http.ResponseWriter, r *http.Request) {
-            claims, ok := r.Context().Value(UserContextKey).(*utils.Claims)
-            if !ok {
-                utils.Error(w, http.StatusUnauthorized, "Akses ditolak: pengguna tidak terautentikasi")
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(UserContextKey).(*utils.Claims)
+			if !ok {
+				utils.Error(w, http.StatusUnauthorized, "Akses ditolak: pengguna tidak terautentikasi")
 				return
-            }
+			}
 
-            if claims.Role != requiredRole {
-                utils.Error(w, http.StatusForbidden, "Akses ditolak: peran tidak sesuai")
+			if claims.Role != requiredRole {
+				utils.Error(w, http.StatusForbidden, "Akses ditolak: peran tidak sesuai")
 				return
-            }
+			}
 
-            next(w, r)
-        }
-    }
-}
\ No newline at end of file
+			next(w, r)
+		}
+	}
+}
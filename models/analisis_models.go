@@ -0,0 +1,63 @@
+package models
+
+// AnalisisTest merangkum statistik nilai dan analisis butir soal sebuah tes.
+// Hanya sesi berstatus selesai yang dihitung.
+type AnalisisTest struct {
+	IDTest        int            `json:"id_test"`
+	JumlahPeserta int            `json:"jumlah_peserta"`
+	Rata          float64        `json:"rata_rata"`
+	Median        float64        `json:"median"`
+	SimpanganBaku float64        `json:"simpangan_baku"`
+	Minimum       float64        `json:"minimum"`
+	Maksimum      float64        `json:"maksimum"`
+	Histogram     []BinHistogram `json:"histogram"`
+	Soal          []AnalisisSoal `json:"soal"`
+}
+
+// BinHistogram adalah jumlah peserta dengan nilai di rentang [Bawah, Atas); bin terakhir inklusif
+type BinHistogram struct {
+	Bawah  float64 `json:"bawah"`
+	Atas   float64 `json:"atas"`
+	Jumlah int     `json:"jumlah"`
+}
+
+// AnalisisSoal adalah statistik satu butir soal
+type AnalisisSoal struct {
+	IDSoal     int     `json:"id_soal"`
+	Urutan     int     `json:"urutan"`
+	Pertanyaan string  `json:"pertanyaan"`
+	Tipe       string  `json:"tipe"`
+	Poin       float64 `json:"poin"`
+
+	JumlahMenjawab int `json:"jumlah_menjawab"`
+	JumlahKosong   int `json:"jumlah_kosong"`
+	// Esai yang belum dinilai tidak ikut dihitung
+	JumlahMenunggu int `json:"jumlah_menunggu"`
+
+	// Tingkat kesukaran: rata-rata proporsi poin yang diperoleh (0..1), kosong dihitung 0
+	TingkatKesukaran *float64 `json:"tingkat_kesukaran"`
+	// Daya beda: korelasi point-biserial skor butir dengan skor total tanpa butir ini
+	DayaBeda *float64 `json:"daya_beda"`
+
+	// Sebaran pilihan per versi soal yang dijawab; label dan teks pilihan mengikuti versi itu
+	Versi []AnalisisVersiSoal `json:"versi,omitempty"`
+	Tanda []string            `json:"tanda"`
+}
+
+// AnalisisVersiSoal adalah sebaran pilihan peserta yang menjawab satu versi soal
+type AnalisisVersiSoal struct {
+	Versi          int               `json:"versi"`
+	Tipe           string            `json:"tipe"`
+	JumlahPeserta  int               `json:"jumlah_peserta"`
+	JumlahMenjawab int               `json:"jumlah_menjawab"`
+	Pilihan        []AnalisisPilihan `json:"pilihan"`
+}
+
+// AnalisisPilihan menunjukkan seberapa sering sebuah pilihan (termasuk pengecoh) dipilih
+type AnalisisPilihan struct {
+	Label      string  `json:"label"`
+	Teks       string  `json:"teks"`
+	IsBenar    bool    `json:"is_benar"`
+	Jumlah     int     `json:"jumlah"`
+	Persentase float64 `json:"persentase"`
+}
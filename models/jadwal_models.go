@@ -4,29 +4,43 @@ package models
 import "time"
 
 type Jadwal struct {
-    IDJadwal           int        `json:"id_jadwal"`
-    UserID             int        `json:"user_id"`
-    PendaftarID        *int       `json:"pendaftar_id"`        
-    Tanggal            time.Time  `json:"tanggal"`
-    JamMulai           string     `json:"jam_mulai"`            
-    JamSelesai         string     `json:"jam_selesai"`         
-    Tempat             string     `json:"tempat"`
-    KonfirmasiJadwal   string     `json:"konfirmasi_jadwal"`    
-    Catatan            *string    `json:"catatan"`              
-    PengajuanPerubahan bool       `json:"pengajuan_perubahan"`  
-    AlasanPerubahan    *string    `json:"alasan_perubahan"`     
-
-    TanggalDiajukan    *time.Time `json:"tanggal_diajukan,omitempty"`
-    JamMulaiDiajukan   *string    `json:"jam_mulai_diajukan,omitempty"`
-    JamSelesaiDiajukan *string    `json:"jam_selesai_diajukan,omitempty"`
-
-    CreatedAt          time.Time  `json:"created_at"`
-    UpdatedAt          time.Time  `json:"updated_at"`
-
-    JenisJadwal        string     `json:"jenis_jadwal"`
-
-    // Relasi (opsional)
-    User      *User      `json:"user,omitempty"`
-    Pendaftar *Pendaftar `json:"pendaftar,omitempty"`
-    UserNama  string     `json:"user_nama,omitempty"`
-}
\ No newline at end of file
+	IDJadwal           int       `json:"id_jadwal"`
+	UserID             int       `json:"user_id"`
+	PendaftarID        *int      `json:"pendaftar_id"`
+	WaktuMulai         time.Time `json:"waktu_mulai"`   // UTC
+	WaktuSelesai       time.Time `json:"waktu_selesai"` // UTC
+	ZonaWaktu          string    `json:"zona_waktu"`    // IANA, mis. Asia/Makassar
+	Tempat             string    `json:"tempat"`
+	KonfirmasiJadwal   string    `json:"konfirmasi_jadwal"`
+	Catatan            *string   `json:"catatan"`
+	PengajuanPerubahan bool      `json:"pengajuan_perubahan"`
+	AlasanPerubahan    *string   `json:"alasan_perubahan"`
+
+	WaktuMulaiDiajukan   *time.Time `json:"waktu_mulai_diajukan,omitempty"`
+	WaktuSelesaiDiajukan *time.Time `json:"waktu_selesai_diajukan,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	JenisJadwal      string     `json:"jenis_jadwal"`
+	Kapasitas        *int       `json:"kapasitas,omitempty"`
+	BatasPendaftaran *time.Time `json:"batas_pendaftaran,omitempty"`
+
+	// Relasi (opsional)
+	User      *User      `json:"user,omitempty"`
+	Pendaftar *Pendaftar `json:"pendaftar,omitempty"`
+	UserNama  string     `json:"user_nama,omitempty"`
+}
+
+// PesertaJadwal adalah pendaftaran user pada jadwal umum
+type PesertaJadwal struct {
+	IDPeserta int       `json:"id_peserta"`
+	IDJadwal  int       `json:"id_jadwal"`
+	UserID    int       `json:"user_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Nama  string `json:"nama,omitempty"`
+	Email string `json:"email,omitempty"`
+	NoWA  string `json:"no_wa,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Kehadiran adalah status check-in peserta yang diharapkan hadir pada jadwal
+type Kehadiran struct {
+	IDJadwal     int        `json:"id_jadwal"`
+	UserID       int        `json:"user_id"`
+	Nama         string     `json:"nama"`
+	Email        string     `json:"email"`
+	Hadir        bool       `json:"hadir"`
+	WaktuCheckin *time.Time `json:"waktu_checkin,omitempty"`
+	Metode       string     `json:"metode,omitempty"`
+	// DiLuarJendela menandai check-in manual oleh admin di luar jendela check-in
+	DiLuarJendela bool `json:"di_luar_jendela"`
+}
+
+// RingkasanKehadiran merangkum kehadiran dan no-show seorang user
+type RingkasanKehadiran struct {
+	UserID       int    `json:"user_id"`
+	Nama         string `json:"nama,omitempty"`
+	Email        string `json:"email,omitempty"`
+	JumlahJadwal int    `json:"jumlah_jadwal"`
+	Hadir        int    `json:"hadir"`
+	TidakHadir   int    `json:"tidak_hadir"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LanggananPush adalah PushSubscription satu browser/perangkat milik user
+type LanggananPush struct {
+	IDLangganan      int        `json:"id_langganan"`
+	UserID           int        `json:"user_id"`
+	Endpoint         string     `json:"endpoint"`
+	P256dh           string     `json:"-"`
+	Auth             string     `json:"-"`
+	UserAgent        *string    `json:"user_agent,omitempty"`
+	TerakhirTerkirim *time.Time `json:"terakhir_terkirim,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
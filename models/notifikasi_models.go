@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Notifikasi adalah satu item kotak masuk. Sasarannya satu user (UserID), satu role (Role),
+// atau semua user; Dibaca dihitung untuk user yang sedang membaca.
+type Notifikasi struct {
+	IDNotifikasi int64      `json:"id_notifikasi"`
+	Target       string     `json:"target"`
+	UserID       *int       `json:"user_id,omitempty"`
+	Role         *string    `json:"role,omitempty"`
+	Kategori     string     `json:"kategori"`
+	Judul        string     `json:"judul"`
+	Isi          string     `json:"isi"`
+	Dibaca       bool       `json:"dibaca"`
+	DibacaAt     *time.Time `json:"dibaca_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TargetPengingat adalah satu penerima pengingat untuk jadwal atau tes
+type TargetPengingat struct {
+	Jenis     string    `json:"jenis"`
+	RefID     int       `json:"ref_id"`
+	UserID    int       `json:"user_id"`
+	Email     string    `json:"email"`
+	Nama      string    `json:"nama"`
+	Judul     string    `json:"judul"`
+	Tempat    string    `json:"tempat"`
+	Mulai     time.Time `json:"mulai"`
+	ZonaWaktu string    `json:"zona_waktu"`
+
+	// Jadwal pribadi (wawancara) juga diingatkan lewat WhatsApp
+	Wawancara bool `json:"wawancara"`
+}
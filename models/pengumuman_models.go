@@ -3,9 +3,18 @@ package models
 import "time"
 
 type Pengumuman struct {
-    IDPengumuman int       `json:"id_pengumuman"`
-    Judul        string    `json:"judul"`
-    Isi          string    `json:"isi"`
-    CreatedAt    time.Time `json:"created_at"`
-    UpdatedAt    time.Time `json:"updated_at"`
-}
\ No newline at end of file
+	IDPengumuman         int        `json:"id_pengumuman"`
+	Judul                string     `json:"judul"`
+	Isi                  string     `json:"isi"`
+	Sasaran              string     `json:"sasaran"`
+	StatusPendaftar      *string    `json:"status_pendaftar,omitempty"`
+	IDTest               *int       `json:"id_test,omitempty"`
+	IDJadwal             *int       `json:"id_jadwal,omitempty"`
+	Status               string     `json:"status"`
+	PublishAt            *time.Time `json:"publish_at,omitempty"`
+	ExpireAt             *time.Time `json:"expire_at,omitempty"`
+	NotifikasiTerkirimAt *time.Time `json:"notifikasi_terkirim_at,omitempty"`
+	DibuatOleh           *int       `json:"dibuat_oleh,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
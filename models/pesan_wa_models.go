@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PesanWA adalah satu pesan WhatsApp yang dikirim lewat gateway beserta status pengirimannya
+type PesanWA struct {
+	IDPesan       int64     `json:"id_pesan"`
+	UserID        *int      `json:"user_id,omitempty"`
+	NamaUser      *string   `json:"nama_user,omitempty"`
+	Kanal         string    `json:"kanal"`
+	Tujuan        string    `json:"tujuan"`
+	Template      string    `json:"template"`
+	Isi           string    `json:"isi"`
+	IDProvider    *string   `json:"id_provider,omitempty"`
+	Status        string    `json:"status"`
+	ErrorTerakhir *string   `json:"error_terakhir,omitempty"`
+	Percobaan     int       `json:"percobaan"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// PreferensiWA adalah pengaturan WhatsApp seorang user
+type PreferensiWA struct {
+	Aktif      bool       `json:"aktif"`
+	Nomor      *string    `json:"nomor,omitempty"`
+	NomorValid bool       `json:"nomor_valid"`
+	OptOutAt   *time.Time `json:"opt_out_at,omitempty"`
+}
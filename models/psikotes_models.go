@@ -0,0 +1,43 @@
+package models
+
+// DimensiPsikotes adalah satu dimensi sifat sebuah tes dengan dua kutub, mis. E (Ekstrovert) vs I (Introvert)
+type DimensiPsikotes struct {
+	IDDimensi int             `json:"id_dimensi"`
+	IDTest    int             `json:"id_test"`
+	Urutan    int             `json:"urutan"`
+	KodeA     string          `json:"kode_a"`
+	NamaA     string          `json:"nama_a"`
+	KodeB     string          `json:"kode_b"`
+	NamaB     string          `json:"nama_b"`
+	Bobot     []BobotPsikotes `json:"bobot"`
+}
+
+// BobotPsikotes memetakan satu pilihan soal psikotes ke dimensi: positif ke kutub A, negatif ke kutub B.
+// Bobot melekat pada versi soal, sehingga jawaban lama tetap dinilai dengan bobot versi yang dijawab.
+type BobotPsikotes struct {
+	IDSoal int     `json:"id_soal"`
+	Versi  int     `json:"versi"`
+	Label  string  `json:"label"`
+	Bobot  float64 `json:"bobot"`
+}
+
+// SkorDimensiPsikotes adalah skor satu sesi pada satu dimensi
+type SkorDimensiPsikotes struct {
+	IDDimensi int     `json:"id_dimensi"`
+	KodeA     string  `json:"kode_a"`
+	NamaA     string  `json:"nama_a"`
+	KodeB     string  `json:"kode_b"`
+	NamaB     string  `json:"nama_b"`
+	SkorA     float64 `json:"skor_a"`
+	SkorB     float64 `json:"skor_b"`
+	// Porsi kutub A dari total skor dimensi (0..100); 50 bila belum ada jawaban berbobot
+	PersenA float64 `json:"persen_a"`
+	Dominan string  `json:"dominan"`
+}
+
+// ProfilPsikotes adalah hasil psikotes satu sesi, mis. tipe "INTJ" beserta skor per dimensi
+type ProfilPsikotes struct {
+	IDHasil         int                   `json:"id_hasil"`
+	TipeKepribadian *string               `json:"tipe_kepribadian"`
+	Dimensi         []SkorDimensiPsikotes `json:"dimensi"`
+}
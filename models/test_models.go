@@ -5,28 +5,106 @@ import (
 )
 
 type SoalTest struct {
-	IDSoal       int       `json:"id_soal"`
-	Nomor        int       `json:"nomor"`
-	Pertanyaan   string    `json:"pertanyaan"`
-	PilihanA     string    `json:"pilihan_a"`
-	PilihanB     string    `json:"pilihan_b"`
-	PilihanC     string    `json:"pilihan_c"`
-	PilihanD     string    `json:"pilihan_d"`
-	JawabanBenar string    `json:"jawaban_benar"`
+	IDSoal     int           `json:"id_soal"`
+	Nomor      int           `json:"nomor"`
+	Pertanyaan string        `json:"pertanyaan"`
+	Tipe       string        `json:"tipe"`
+	Poin       float64       `json:"poin"`
+	Pilihan    []PilihanSoal `json:"pilihan,omitempty"`
+	KunciIsian *string       `json:"kunci_isian,omitempty"`
+	ModeIsian  string        `json:"mode_isian,omitempty"`
+	Pembahasan *string       `json:"pembahasan,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+
+	// Versi isi soal (lihat VersiSoal); soal yang diarsipkan tidak bisa diubah atau ditambahkan ke tes
+	Versi        int        `json:"versi"`
+	DiarsipkanAt *time.Time `json:"diarsipkan_at,omitempty"`
+
+	// Urutan soal di dalam sebuah tes (diisi saat soal diambil per tes)
+	Urutan int `json:"urutan,omitempty"`
+	// Pemetaan pilihan teracak sesi: karakter ke-i adalah huruf kanonik yang tampil di posisi ke-i
+	UrutanPilihan string `json:"urutan_pilihan,omitempty"`
+}
+
+// PilihanSoal adalah satu pilihan jawaban; Label (A, B, ...) mengikuti Urutan
+type PilihanSoal struct {
+	Urutan  int    `json:"urutan"`
+	Label   string `json:"label"`
+	Teks    string `json:"teks"`
+	IsBenar bool   `json:"is_benar"`
+}
+
+// GambarSoal adalah gambar yang dirujuk teks soal/pilihan lewat ![](gambar:ID)
+type GambarSoal struct {
+	IDGambar     int       `json:"id_gambar"`
+	NamaFile     string    `json:"-"`
+	NamaAsli     string    `json:"nama_asli"`
+	Ukuran       int64     `json:"ukuran"`
+	DiunggahOleh *int      `json:"diunggah_oleh,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// SoalImpor adalah satu soal hasil membaca berkas impor; Baris menunjuk posisinya di berkas
+type SoalImpor struct {
+	Baris  int
+	Soal   SoalTest
+	Gambar []BerkasGambarSoal
+}
+
+// BerkasGambarSoal adalah isi berkas gambar yang dibawa ekspor/impor JSON. IDGambar adalah id di
+// bank asal, sama dengan rujukan gambar:ID di teks soal.
+type BerkasGambarSoal struct {
+	IDGambar int
+	NamaAsli string
+	Data     []byte
+}
+
+// KesalahanImporSoal adalah galat validasi satu baris/soal pada berkas impor
+type KesalahanImporSoal struct {
+	Baris int    `json:"baris"`
+	Pesan string `json:"pesan"`
 }
 
 type Test struct {
-	IDTest       int        `json:"id_test"`
-	Judul        string     `json:"judul"`
-	Deskripsi    *string    `json:"deskripsi,omitempty"`
-	DurasiMenit  int        `json:"durasi_menit"`
-	WaktuMulai   *time.Time `json:"waktu_mulai,omitempty"`
-	WaktuSelesai *time.Time `json:"waktu_selesai,omitempty"`
-	Aktif        bool       `json:"aktif"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	IDTest        int        `json:"id_test"`
+	Judul         string     `json:"judul"`
+	Deskripsi     *string    `json:"deskripsi,omitempty"`
+	DurasiMenit   int        `json:"durasi_menit"`
+	WaktuMulai    *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai  *time.Time `json:"waktu_selesai,omitempty"`
+	Aktif         bool       `json:"aktif"`
+	TargetPeserta string     `json:"target_peserta"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// Kebijakan rilis hasil ke peserta
+	RilisHasil          string     `json:"rilis_hasil"`
+	TampilkanPembahasan bool       `json:"tampilkan_pembahasan"`
+	HasilDirilisAt      *time.Time `json:"hasil_dirilis_at,omitempty"`
+
+	JumlahSoal int `json:"jumlah_soal"`
+
+	// Akomodasi: WaktuSelesaiTerakhir adalah akhir jendela paling akhir termasuk akomodasi
+	// (nil bila tes tanpa batas), Akomodasi hanya terisi pada tes yang dimuat untuk pendaftar
+	JumlahAkomodasi      int            `json:"jumlah_akomodasi"`
+	WaktuSelesaiTerakhir *time.Time     `json:"waktu_selesai_terakhir,omitempty"`
+	Akomodasi            *AkomodasiTest `json:"akomodasi,omitempty"`
+}
+
+// AkomodasiTest adalah jendela dan tambahan waktu khusus seorang pendaftar pada sebuah tes;
+// waktu yang nil mengikuti jendela tes
+type AkomodasiTest struct {
+	IDTest        int        `json:"id_test"`
+	PendaftarID   int        `json:"pendaftar_id"`
+	NamaPendaftar string     `json:"nama_pendaftar,omitempty"`
+	WaktuMulai    *time.Time `json:"waktu_mulai,omitempty"`
+	WaktuSelesai  *time.Time `json:"waktu_selesai,omitempty"`
+	TambahanMenit int        `json:"tambahan_menit"`
+	Catatan       *string    `json:"catatan,omitempty"`
+	DibuatOleh    *int       `json:"dibuat_oleh,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
 }
 
 type HasilTest struct {
@@ -34,21 +112,108 @@ type HasilTest struct {
 	UserID       int        `json:"user_id"`
 	PendaftarID  int        `json:"pendaftar_id"`
 	IDTest       int        `json:"id_test"`
+	Percobaan    int        `json:"percobaan"`
 	SkorBenar    int        `json:"skor_benar"`
 	SkorSalah    int        `json:"skor_salah"`
+	Poin         float64    `json:"poin"`
+	TotalPoin    float64    `json:"total_poin"`
 	Nilai        float64    `json:"nilai"`
+	Status       string     `json:"status"`
 	WaktuMulai   time.Time  `json:"waktu_mulai"`
+	BatasWaktu   *time.Time `json:"batas_waktu,omitempty"`
 	WaktuSelesai *time.Time `json:"waktu_selesai,omitempty"`
 	DurasiMenit  *int       `json:"durasi_menit,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Jumlah jawaban esai yang belum dinilai
+	MenungguPenilaian int `json:"menunggu_penilaian"`
+
+	// Penyesuaian poin manual oleh admin, sudah termasuk dalam Poin dan Nilai
+	PenyesuaianPoin float64 `json:"penyesuaian_poin"`
+
+	// Profil psikotes (mis. "INTJ"); kosong bila tes tidak memiliki dimensi psikotes
+	TipeKepribadian *string `json:"tipe_kepribadian,omitempty"`
+
+	// Relasi (opsional)
+	JudulTest string `json:"judul_test,omitempty"`
 }
 
 type JawabanUser struct {
+	IDJawaban       int        `json:"id_jawaban"`
+	IDHasil         int        `json:"id_hasil"`
+	IDSoal          int        `json:"id_soal"`
+	Versi           int        `json:"versi,omitempty"` // versi soal yang dijawab
+	JawabanUser     string     `json:"jawaban_user"`
+	IsBenar         *bool      `json:"is_benar"`
+	Poin            *float64   `json:"poin"`
+	StatusPenilaian string     `json:"status_penilaian"`
+	CatatanPenilai  *string    `json:"catatan_penilai,omitempty"`
+	DinilaiOleh     *int       `json:"dinilai_oleh,omitempty"`
+	DinilaiAt       *time.Time `json:"dinilai_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// JawabanMenungguPenilaian adalah satu jawaban esai dalam antrean penilaian manual
+type JawabanMenungguPenilaian struct {
 	IDJawaban   int       `json:"id_jawaban"`
 	IDHasil     int       `json:"id_hasil"`
+	IDTest      int       `json:"id_test"`
+	JudulTest   string    `json:"judul_test"`
+	UserID      int       `json:"user_id"`
+	NamaPeserta string    `json:"nama_peserta"`
 	IDSoal      int       `json:"id_soal"`
-	JawabanUser string    `json:"jawaban_user"`
-	IsBenar     bool      `json:"is_benar"`
-	CreatedAt   time.Time `json:"created_at"`
+	Pertanyaan  string    `json:"pertanyaan"`
+	PoinMaks    float64   `json:"poin_maks"`
+	Jawaban     string    `json:"jawaban"`
+	DikirimAt   time.Time `json:"dikirim_at"`
+}
+
+// EventIntegritas adalah satu sinyal integritas pada sesi tes
+type EventIntegritas struct {
+	IDEvent    int64     `json:"id_event"`
+	IDHasil    int       `json:"id_hasil"`
+	Jenis      string    `json:"jenis"`
+	Detail     *string   `json:"detail,omitempty"`
+	WaktuKlien time.Time `json:"waktu_klien"`
+	DiterimaAt time.Time `json:"diterima_at"`
+}
+
+// RingkasanIntegritas merangkum sinyal integritas satu sesi untuk peninjau
+type RingkasanIntegritas struct {
+	IPMulai     *string        `json:"ip_mulai,omitempty"`
+	UAMulai     *string        `json:"ua_mulai,omitempty"`
+	IPSubmit    *string        `json:"ip_submit,omitempty"`
+	UASubmit    *string        `json:"ua_submit,omitempty"`
+	JumlahEvent map[string]int `json:"jumlah_event"`
+	TotalEvent  int            `json:"total_event"`
+	Tanda       []string       `json:"tanda"`
+}
+
+// IzinUlangTest memberi seorang peserta satu percobaan tambahan pada sebuah tes
+type IzinUlangTest struct {
+	IDIzin         int        `json:"id_izin"`
+	IDTest         int        `json:"id_test"`
+	UserID         int        `json:"user_id"`
+	Alasan         string     `json:"alasan"`
+	DiberikanOleh  *int       `json:"diberikan_oleh,omitempty"`
+	IDHasilDipakai *int       `json:"id_hasil_dipakai,omitempty"`
+	DipakaiAt      *time.Time `json:"dipakai_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AuditHasilTest adalah satu jejak koreksi hasil tes oleh admin
+type AuditHasilTest struct {
+	IDAudit       int       `json:"id_audit"`
+	IDTest        int       `json:"id_test"`
+	IDHasil       *int      `json:"id_hasil,omitempty"`
+	UserID        *int      `json:"user_id,omitempty"`
+	Aksi          string    `json:"aksi"`
+	NilaiLama     *float64  `json:"nilai_lama,omitempty"`
+	NilaiBaru     *float64  `json:"nilai_baru,omitempty"`
+	Alasan        string    `json:"alasan"`
+	DilakukanOleh *int      `json:"dilakukan_oleh,omitempty"`
+	NamaAdmin     *string   `json:"nama_admin,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
@@ -4,14 +4,14 @@ package models
 import "time"
 
 type User struct {
-    IDUser          int       `json:"id_user"`
-    Username        string    `json:"username"`
-    FullName        string    `json:"full_name"`
-    Email           string    `json:"email"`
-    Password        string    `json:"-"`
-    ProfilePicture  string    `json:"profile_picture"`
-    Role            string    `json:"role"`
-    IsVerified      bool      `json:"is_verified"`
-    CreatedAt       time.Time `json:"created_at"`
-    UpdatedAt       time.Time `json:"updated_at"`
+	IDUser         int       `json:"id_user"`
+	Username       string    `json:"username"`
+	FullName       string    `json:"full_name"`
+	Email          string    `json:"email"`
+	Password       string    `json:"-"`
+	ProfilePicture string    `json:"profile_picture"`
+	Role           string    `json:"role"`
+	IsVerified     bool      `json:"is_verified"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
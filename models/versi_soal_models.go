@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// VersiSoal adalah salinan isi soal pada satu versi; tidak pernah diubah setelah dibuat
+type VersiSoal struct {
+	IDVersi     int           `json:"id_versi"`
+	IDSoal      int           `json:"id_soal"`
+	Versi       int           `json:"versi"`
+	Pertanyaan  string        `json:"pertanyaan"`
+	Tipe        string        `json:"tipe"`
+	Poin        float64       `json:"poin"`
+	Pilihan     []PilihanSoal `json:"pilihan,omitempty"`
+	KunciIsian  *string       `json:"kunci_isian,omitempty"`
+	ModeIsian   string        `json:"mode_isian"`
+	Pembahasan  *string       `json:"pembahasan,omitempty"`
+	DibuatOleh  *int          `json:"dibuat_oleh,omitempty"`
+	NamaPembuat *string       `json:"nama_pembuat,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+
+	// Jumlah jawaban peserta yang merujuk versi ini
+	JumlahJawaban int `json:"jumlah_jawaban"`
+}
+
+// PerbedaanSoal adalah satu bagian soal yang berbeda antara dua versi. Teks panjang
+// (pertanyaan, pembahasan, pilihan) disertai perbandingan per baris.
+type PerbedaanSoal struct {
+	Bagian string      `json:"bagian"`
+	Lama   *string     `json:"lama"`
+	Baru   *string     `json:"baru"`
+	Baris  []BarisDiff `json:"baris,omitempty"`
+}
+
+// BarisDiff adalah satu baris perbandingan teks: jenis "sama", "hapus", atau "tambah"
+type BarisDiff struct {
+	Jenis string `json:"jenis"`
+	Teks  string `json:"teks"`
+}
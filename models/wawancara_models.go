@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type RubrikWawancara struct {
+	IDRubrik  int                 `json:"id_rubrik"`
+	Nama      string              `json:"nama"`
+	Deskripsi *string             `json:"deskripsi,omitempty"`
+	Aktif     bool                `json:"aktif"`
+	Kriteria  []KriteriaWawancara `json:"kriteria"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+type KriteriaWawancara struct {
+	IDKriteria int     `json:"id_kriteria"`
+	IDRubrik   int     `json:"id_rubrik"`
+	Nama       string  `json:"nama"`
+	Deskripsi  *string `json:"deskripsi,omitempty"`
+	Bobot      float64 `json:"bobot"`
+	SkorMaks   int     `json:"skor_maks"`
+	Urutan     int     `json:"urutan"`
+}
+
+type PenilaianWawancara struct {
+	IDPenilaian   int                        `json:"id_penilaian"`
+	IDJadwal      int                        `json:"id_jadwal"`
+	PewawancaraID int                        `json:"pewawancara_id"`
+	PendaftarID   *int                       `json:"pendaftar_id,omitempty"`
+	IDRubrik      int                        `json:"id_rubrik"`
+	Nilai         float64                    `json:"nilai"`
+	Catatan       *string                    `json:"catatan,omitempty"`
+	Detail        []PenilaianWawancaraDetail `json:"detail"`
+	CreatedAt     time.Time                  `json:"created_at"`
+	UpdatedAt     time.Time                  `json:"updated_at"`
+
+	PewawancaraNama string `json:"pewawancara_nama,omitempty"`
+}
+
+type PenilaianWawancaraDetail struct {
+	IDKriteria int     `json:"id_kriteria"`
+	Skor       int     `json:"skor"`
+	Catatan    *string `json:"catatan,omitempty"`
+}
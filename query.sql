@@ -7,6 +7,7 @@ CREATE TABLE users (
     profile_picture VARCHAR(255) DEFAULT 'default.jpg',
     role ENUM('user', 'admin') DEFAULT 'user',
     is_verified BOOLEAN DEFAULT FALSE,
+    wa_opt_out_at DATETIME NULL, -- berhenti menerima pesan WhatsApp
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
@@ -57,42 +58,93 @@ CREATE TABLE jadwal (
     FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE,
     pendaftar_id INT,
     FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE SET NULL,
-    tanggal DATE NOT NULL,
-    jam_mulai TIME NOT NULL,
-    jam_selesai TIME NOT NULL,
+    waktu_mulai DATETIME NOT NULL, -- UTC
+    waktu_selesai DATETIME NOT NULL, -- UTC
+    zona_waktu VARCHAR(64) NOT NULL DEFAULT 'Asia/Makassar', -- IANA
     tempat VARCHAR(255) NOT NULL,
     jenis_jadwal ENUM('pribadi', 'umum') DEFAULT 'pribadi',
+    kapasitas INT NULL, -- khusus jadwal umum, NULL = tanpa batas
+    batas_pendaftaran DATETIME NULL, -- UTC
     konfirmasi_jadwal ENUM('belum', 'dikonfirmasi', 'ditolak') DEFAULT 'belum',
     catatan TEXT DEFAULT NULL,
     pengajuan_perubahan BOOLEAN DEFAULT FALSE,
     alasan_perubahan TEXT DEFAULT NULL,
-    tanggal_diajukan DATE NULL,
-    jam_mulai_diajukan TIME NULL,
-    jam_selesai_diajukan TIME NULL,
+    waktu_mulai_diajukan DATETIME NULL, -- UTC
+    waktu_selesai_diajukan DATETIME NULL, -- UTC
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     INDEX idx_user_id (user_id),
     INDEX idx_pendaftar_id (pendaftar_id),
-    INDEX idx_tanggal (tanggal),
+    INDEX idx_waktu_mulai (waktu_mulai),
     INDEX idx_konfirmasi (konfirmasi_jadwal)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 
--- 1. Tabel soal_test: bank soal pilihan ganda
+-- 1. Tabel soal_test: bank soal (pilihan ganda, multi jawaban, benar/salah, isian, esai, psikotes)
 CREATE TABLE soal_test (
     id_soal INT PRIMARY KEY AUTO_INCREMENT,
     nomor INT NOT NULL,
-    pertanyaan TEXT NOT NULL,
-    pilihan_a TEXT NOT NULL,
-    pilihan_b TEXT NOT NULL,
-    pilihan_c TEXT NOT NULL,
-    pilihan_d TEXT NOT NULL,
-    jawaban_benar CHAR(1) NOT NULL CHECK (jawaban_benar IN ('A', 'B', 'C', 'D')),
+    pertanyaan TEXT NOT NULL, -- Markdown; gambar dirujuk lewat ![](gambar:ID)
+    tipe ENUM('pilihan_ganda', 'pilihan_ganda_multi', 'benar_salah', 'isian', 'esai', 'psikotes') NOT NULL DEFAULT 'pilihan_ganda',
+    poin DECIMAL(6,2) NOT NULL DEFAULT 1.00, -- bobot soal
+    kunci_isian TEXT NULL, -- isian: satu jawaban per baris, atau satu pola regex
+    mode_isian ENUM('persis', 'regex') NOT NULL DEFAULT 'persis',
+    pembahasan TEXT NULL, -- Markdown; ditampilkan ke peserta bila tes mengizinkan
+    versi INT NOT NULL DEFAULT 1, -- versi isi saat ini (lihat versi_soal)
+    diarsipkan_at DATETIME NULL, -- soal tidak dihapus, hanya diarsipkan
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
-    UNIQUE KEY unique_nomor (nomor)
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
 
+-- 1a. Tabel pilihan_soal: pilihan jawaban; huruf A, B, C, ... mengikuti urutan
+CREATE TABLE pilihan_soal (
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL,
+    teks TEXT NOT NULL,
+    is_benar BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id_soal, urutan),
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 1c. Tabel versi_soal: salinan isi soal per versi yang tidak diubah lagi; dirujuk jawaban_user
+CREATE TABLE versi_soal (
+    id_versi INT PRIMARY KEY AUTO_INCREMENT,
+    id_soal INT NOT NULL,
+    versi INT NOT NULL,
+    pertanyaan TEXT NOT NULL,
+    tipe ENUM('pilihan_ganda', 'pilihan_ganda_multi', 'benar_salah', 'isian', 'esai', 'psikotes') NOT NULL,
+    poin DECIMAL(6,2) NOT NULL,
+    kunci_isian TEXT NULL,
+    mode_isian ENUM('persis', 'regex') NOT NULL DEFAULT 'persis',
+    pembahasan TEXT NULL,
+    dibuat_oleh INT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_versi_soal (id_soal, versi),
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal),
+    FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 1d. Tabel pilihan_versi_soal: pilihan jawaban milik satu versi soal
+CREATE TABLE pilihan_versi_soal (
+    id_versi INT NOT NULL,
+    urutan INT NOT NULL,
+    teks TEXT NOT NULL,
+    is_benar BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (id_versi, urutan),
+    FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 1b. Tabel gambar_soal: gambar soal/pilihan, disimpan di luar uploads/ dan dilayani dengan token sesi
+CREATE TABLE gambar_soal (
+    id_gambar INT AUTO_INCREMENT PRIMARY KEY,
+    nama_file VARCHAR(255) NOT NULL,
+    nama_asli VARCHAR(255) NOT NULL,
+    ukuran INT NOT NULL,
+    diunggah_oleh INT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (diunggah_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- 2. Tabel test: konfigurasi tes (admin atur durasi, aktif/tidak)
 CREATE TABLE test (
     id_test INT PRIMARY KEY AUTO_INCREMENT,
@@ -102,46 +154,412 @@ CREATE TABLE test (
     waktu_mulai TIMESTAMP NULL, -- opsional: kapan tes dibuka
     waktu_selesai TIMESTAMP NULL, -- opsional: kapan tes ditutup
     aktif BOOLEAN DEFAULT TRUE,
+    target_peserta ENUM('semua', 'pilih') NOT NULL DEFAULT 'semua', -- pilih: hanya pendaftar di test_peserta
+    rilis_hasil ENUM('langsung', 'setelah_jendela', 'manual') NOT NULL DEFAULT 'langsung', -- kapan peserta boleh melihat hasil
+    tampilkan_pembahasan BOOLEAN NOT NULL DEFAULT FALSE, -- peserta melihat kunci & pembahasan per soal setelah rilis
+    hasil_dirilis_at DATETIME NULL, -- diisi saat admin merilis hasil secara manual
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
 );
 
+-- 2a. Tabel test_soal: soal dari bank yang dipakai sebuah tes, beserta urutannya
+CREATE TABLE test_soal (
+    id_test INT NOT NULL,
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL,
+    PRIMARY KEY (id_test, id_soal),
+    UNIQUE KEY unique_urutan_test (id_test, urutan),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+);
+
+-- 2b. Tabel test_peserta: penugasan tes ke pendaftar tertentu
+CREATE TABLE test_peserta (
+    id_test INT NOT NULL,
+    pendaftar_id INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_test, pendaftar_id),
+    INDEX idx_test_peserta_pendaftar (pendaftar_id),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE CASCADE
+);
+
+-- 2c. Tabel akomodasi_test: jendela dan tambahan waktu khusus per pendaftar (NULL = ikut tes)
+CREATE TABLE akomodasi_test (
+    id_test INT NOT NULL,
+    pendaftar_id INT NOT NULL,
+    waktu_mulai TIMESTAMP NULL DEFAULT NULL,
+    waktu_selesai TIMESTAMP NULL DEFAULT NULL,
+    tambahan_menit INT NOT NULL DEFAULT 0,
+    catatan VARCHAR(255) NULL,
+    dibuat_oleh INT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_test, pendaftar_id),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE CASCADE,
+    FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+);
+
 -- 3. Tabel hasil_test: hasil ujian per user
 CREATE TABLE hasil_test (
     id_hasil INT PRIMARY KEY AUTO_INCREMENT,
     user_id INT NOT NULL,
     pendaftar_id INT NOT NULL,
     id_test INT NOT NULL,
+    percobaan INT NOT NULL DEFAULT 1, -- percobaan ke-; lebih dari 1 hanya dengan izin_ulang_test
     skor_benar INT NOT NULL DEFAULT 0,
     skor_salah INT NOT NULL DEFAULT 0,
-    nilai DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    poin DECIMAL(8,2) NOT NULL DEFAULT 0.00, -- poin diperoleh (termasuk penyesuaian)
+    penyesuaian_poin DECIMAL(8,2) NOT NULL DEFAULT 0.00, -- penyesuaian manual admin
+    total_poin DECIMAL(8,2) NOT NULL DEFAULT 0.00, -- jumlah bobot soal tes
+    nilai DECIMAL(5,2) NOT NULL DEFAULT 0.00, -- poin / total_poin * 100
+    tipe_kepribadian VARCHAR(50) NULL, -- psikotes: gabungan kutub dominan tiap dimensi, mis. 'INTJ'
+    status ENUM('berlangsung', 'selesai') NOT NULL DEFAULT 'selesai', -- berlangsung sejak /test/mulai
     waktu_mulai TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    waktu_selesai TIMESTAMP, -- waktu saat user submit
+    batas_waktu TIMESTAMP NULL DEFAULT NULL, -- tenggat dari server (durasi tes, dipotong jendela tes)
+    waktu_selesai TIMESTAMP NULL DEFAULT NULL, -- waktu saat user submit / difinalisasi otomatis
+    ip_mulai VARCHAR(45) NULL, -- IP & user agent saat /test/mulai pertama
+    ua_mulai VARCHAR(255) NULL,
+    ip_submit VARCHAR(45) NULL, -- IP & user agent saat submit (kosong bila difinalisasi otomatis)
+    ua_submit VARCHAR(255) NULL,
+    kunci_idempotensi VARCHAR(100) NULL, -- header Idempotency-Key saat submit
     durasi_menit INT AS (TIMESTAMPDIFF(MINUTE, waktu_mulai, waktu_selesai)) STORED, -- durasi pakai
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id_user),
     FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar),
     FOREIGN KEY (id_test) REFERENCES test(id_test),
-    UNIQUE KEY unique_user_per_test (user_id, id_test)
+    UNIQUE KEY unique_user_percobaan (user_id, id_test, percobaan),
+    INDEX idx_hasil_status_batas (status, batas_waktu)
 );
 
+-- 3a. Tabel sesi_soal: urutan soal & pilihan yang diacak untuk tiap sesi (hasil_test)
+CREATE TABLE sesi_soal (
+    id_hasil INT NOT NULL,
+    id_soal INT NOT NULL,
+    urutan INT NOT NULL,
+    urutan_pilihan VARCHAR(26) NOT NULL DEFAULT '', -- pilihan kanonik yang tampil di posisi A, B, ...; '' = tidak diacak
+    PRIMARY KEY (id_hasil, id_soal),
+    UNIQUE KEY unique_urutan_sesi (id_hasil, urutan),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Event integritas sesi tes yang dilaporkan klien (plus ganti_perangkat dari server)
+CREATE TABLE sesi_event (
+    id_event BIGINT AUTO_INCREMENT PRIMARY KEY,
+    id_hasil INT NOT NULL,
+    jenis ENUM('fokus_hilang', 'tab_tersembunyi', 'salin', 'tempel', 'potong',
+               'keluar_layar_penuh', 'klik_kanan', 'ganti_perangkat') NOT NULL,
+    detail VARCHAR(255) NULL,
+    waktu_klien DATETIME(3) NOT NULL,
+    diterima_at DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+    INDEX idx_event_hasil (id_hasil, jenis),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Dimensi sifat psikotes sebuah tes: dua kutub, mis. kode_a 'E' (Ekstrovert) vs kode_b 'I' (Introvert)
+CREATE TABLE dimensi_psikotes (
+    id_dimensi INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    urutan INT NOT NULL,
+    kode_a VARCHAR(10) NOT NULL,
+    nama_a VARCHAR(50) NOT NULL,
+    kode_b VARCHAR(10) NOT NULL,
+    nama_b VARCHAR(50) NOT NULL,
+    UNIQUE KEY unique_urutan_dimensi (id_test, urutan),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Bobot pilihan (huruf kanonik) satu versi soal psikotes ke sebuah dimensi: positif ke kutub A,
+-- negatif ke kutub B. Jawaban dinilai dengan bobot versi yang dijawab.
+CREATE TABLE bobot_psikotes (
+    id_dimensi INT NOT NULL,
+    id_soal INT NOT NULL,
+    id_versi INT NOT NULL,
+    label CHAR(1) NOT NULL,
+    bobot DECIMAL(6,2) NOT NULL,
+    PRIMARY KEY (id_dimensi, id_versi, label),
+    FOREIGN KEY (id_dimensi) REFERENCES dimensi_psikotes(id_dimensi) ON DELETE CASCADE,
+    FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal) ON DELETE CASCADE,
+    FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Profil hasil psikotes per sesi dan dimensi
+CREATE TABLE profil_psikotes (
+    id_hasil INT NOT NULL,
+    id_dimensi INT NOT NULL,
+    skor_a DECIMAL(8,2) NOT NULL DEFAULT 0.00,
+    skor_b DECIMAL(8,2) NOT NULL DEFAULT 0.00,
+    PRIMARY KEY (id_hasil, id_dimensi),
+    FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE,
+    FOREIGN KEY (id_dimensi) REFERENCES dimensi_psikotes(id_dimensi) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Izin satu percobaan tambahan untuk seorang peserta; terpakai saat percobaan baru dimulai
+CREATE TABLE izin_ulang_test (
+    id_izin INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    user_id INT NOT NULL,
+    alasan VARCHAR(255) NOT NULL,
+    diberikan_oleh INT NULL,
+    id_hasil_dipakai INT NULL, -- NULL: belum dipakai (atau percobaannya direset)
+    dipakai_at DATETIME NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_izin_user_test (user_id, id_test),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE,
+    FOREIGN KEY (diberikan_oleh) REFERENCES users(id_user) ON DELETE SET NULL,
+    FOREIGN KEY (id_hasil_dipakai) REFERENCES hasil_test(id_hasil) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Jejak audit koreksi hasil tes oleh admin; id_hasil tanpa FK agar jejak reset sesi tetap ada
+CREATE TABLE audit_hasil_test (
+    id_audit INT PRIMARY KEY AUTO_INCREMENT,
+    id_test INT NOT NULL,
+    id_hasil INT NULL,
+    user_id INT NULL, -- peserta
+    aksi ENUM('penilaian_ulang', 'penyesuaian', 'izin_ulang', 'reset_sesi') NOT NULL,
+    nilai_lama DECIMAL(5,2) NULL,
+    nilai_baru DECIMAL(5,2) NULL,
+    alasan VARCHAR(255) NOT NULL,
+    dilakukan_oleh INT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_test (id_test, created_at),
+    FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE CASCADE,
+    FOREIGN KEY (dilakukan_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- 4. Tabel jawaban_user: jawaban per soal
 CREATE TABLE jawaban_user (
     id_jawaban INT PRIMARY KEY AUTO_INCREMENT,
     id_hasil INT NOT NULL,
     id_soal INT NOT NULL,
-    jawaban_user CHAR(1) NOT NULL CHECK (jawaban_user IN ('A', 'B', 'C', 'D')),
-    is_benar BOOLEAN NOT NULL,
+    id_versi INT NULL, -- versi soal yang dijawab
+    jawaban_user TEXT NOT NULL, -- huruf kanonik dipisah koma (mis. 'A,C') atau teks bebas
+    is_benar BOOLEAN NULL, -- NULL: esai menunggu penilaian
+    poin DECIMAL(6,2) NULL,
+    status_penilaian ENUM('otomatis', 'menunggu', 'dinilai') NOT NULL DEFAULT 'otomatis',
+    catatan_penilai TEXT NULL,
+    dinilai_oleh INT NULL,
+    dinilai_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP, -- autosave terakhir
     FOREIGN KEY (id_hasil) REFERENCES hasil_test(id_hasil) ON DELETE CASCADE,
     FOREIGN KEY (id_soal) REFERENCES soal_test(id_soal),
-    UNIQUE KEY unique_jawaban_soal (id_hasil, id_soal)
+    FOREIGN KEY (id_versi) REFERENCES versi_soal(id_versi),
+    FOREIGN KEY (dinilai_oleh) REFERENCES users(id_user) ON DELETE SET NULL,
+    UNIQUE KEY unique_jawaban_soal (id_hasil, id_soal),
+    INDEX idx_jawaban_status_penilaian (status_penilaian)
 );
 
 CREATE TABLE pengumuman (
     id_pengumuman INT AUTO_INCREMENT PRIMARY KEY,
     judul VARCHAR(255) NOT NULL,
     isi TEXT NOT NULL,
+    sasaran ENUM('semua', 'pendaftar', 'peserta_test', 'peserta_jadwal') NOT NULL DEFAULT 'semua',
+    status_pendaftar ENUM('pending', 'diterima', 'ditolak') NULL, -- sasaran pendaftar: NULL = semua status
+    id_test INT NULL, -- sasaran peserta_test (tahap seleksi)
+    id_jadwal INT NULL, -- jadwal terkait; wajib untuk sasaran peserta_jadwal
+    status ENUM('draf', 'terbit') NOT NULL DEFAULT 'terbit',
+    publish_at DATETIME NULL, -- UTC; NULL = tayang begitu diterbitkan
+    expire_at DATETIME NULL, -- UTC; NULL = tidak berakhir
+    notifikasi_terkirim_at DATETIME NULL,
+    dibuat_oleh INT NULL,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     
-    INDEX idx_created_at (created_at)
-);
\ No newline at end of file
+    INDEX idx_created_at (created_at),
+    INDEX idx_pengumuman_tayang (status, publish_at),
+    CONSTRAINT fk_pengumuman_test FOREIGN KEY (id_test) REFERENCES test(id_test) ON DELETE RESTRICT,
+    CONSTRAINT fk_pengumuman_jadwal FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE RESTRICT,
+    CONSTRAINT fk_pengumuman_dibuat_oleh FOREIGN KEY (dibuat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+);
+
+-- Kotak masuk notifikasi: sasaran user/role/semua, status dibaca per user
+CREATE TABLE notifikasi (
+    id_notifikasi BIGINT AUTO_INCREMENT PRIMARY KEY,
+    target ENUM('user', 'role', 'semua') NOT NULL,
+    user_id INT NULL,
+    role ENUM('user', 'admin') NULL,
+    kategori VARCHAR(50) NOT NULL,
+    judul VARCHAR(255) NOT NULL,
+    isi TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_notifikasi_user (user_id, id_notifikasi),
+    INDEX idx_notifikasi_target (target, role, id_notifikasi),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE notifikasi_dibaca (
+    id_notifikasi BIGINT NOT NULL,
+    user_id INT NOT NULL,
+    dibaca_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_notifikasi, user_id),
+    INDEX idx_notifikasi_dibaca_user (user_id),
+    FOREIGN KEY (id_notifikasi) REFERENCES notifikasi(id_notifikasi) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Langganan Web Push per browser/perangkat user
+CREATE TABLE langganan_push (
+    id_langganan INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL,
+    endpoint VARCHAR(500) NOT NULL,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(255) NULL,
+    terakhir_terkirim DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_endpoint (endpoint),
+    INDEX idx_langganan_push_user (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Pesan WhatsApp: log pengiriman dan status dari webhook gateway
+CREATE TABLE pesan_wa (
+    id_pesan BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NULL,
+    kanal VARCHAR(30) NOT NULL,
+    tujuan VARCHAR(16) NOT NULL, -- E.164, mis. +6281234567890
+    template VARCHAR(50) NOT NULL,
+    isi TEXT NOT NULL,
+    id_provider VARCHAR(100) NULL,
+    status ENUM('antre', 'terkirim', 'diterima', 'dibaca', 'gagal') NOT NULL DEFAULT 'antre',
+    error_terakhir VARCHAR(255) NULL,
+    percobaan INT NOT NULL DEFAULT 0,
+    kunci VARCHAR(100) NULL, -- pengenal pesan yang sama saat dicoba ulang, mis. pengingat:jadwal:12:5:60
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_id_provider (kanal, id_provider),
+    UNIQUE KEY unique_kunci_pesan_wa (kunci),
+    INDEX idx_pesan_wa_user (user_id, id_pesan),
+    INDEX idx_pesan_wa_status (status),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Callback status yang tiba sebelum id_provider pesannya tersimpan
+CREATE TABLE status_wa_tertunda (
+    kanal VARCHAR(30) NOT NULL,
+    id_provider VARCHAR(100) NOT NULL,
+    status ENUM('antre', 'terkirim', 'diterima', 'dibaca', 'gagal') NOT NULL,
+    error_terakhir VARCHAR(255) NULL,
+    diterima_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kanal, id_provider, status),
+    INDEX idx_status_wa_tertunda_waktu (diterima_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Scheduler: jadwal job disimpan agar tetap berlaku setelah restart
+CREATE TABLE job_terjadwal (
+    nama VARCHAR(100) PRIMARY KEY,
+    interval_detik INT NOT NULL,
+    terakhir_jalan DATETIME NULL,
+    berikutnya DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    terkunci_sampai DATETIME NULL,
+    error_terakhir TEXT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Log pengingat: UNIQUE mencegah pengingat terkirim dua kali walau server restart
+CREATE TABLE pengingat_terkirim (
+    id_pengingat INT AUTO_INCREMENT PRIMARY KEY,
+    jenis ENUM('jadwal', 'test') NOT NULL,
+    ref_id INT NOT NULL,
+    user_id INT NOT NULL,
+    offset_menit INT NOT NULL,
+    kanal ENUM('email', 'notifikasi', 'whatsapp') NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_pengingat (jenis, ref_id, user_id, offset_menit, kanal),
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Wawancara: pewawancara, rubrik, dan penilaian
+CREATE TABLE pewawancara_jadwal (
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id_jadwal, user_id),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE rubrik_wawancara (
+    id_rubrik INT AUTO_INCREMENT PRIMARY KEY,
+    nama VARCHAR(255) NOT NULL,
+    deskripsi TEXT NULL,
+    aktif BOOLEAN DEFAULT TRUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE kriteria_wawancara (
+    id_kriteria INT AUTO_INCREMENT PRIMARY KEY,
+    id_rubrik INT NOT NULL,
+    nama VARCHAR(255) NOT NULL,
+    deskripsi TEXT NULL,
+    bobot DECIMAL(5,2) NOT NULL DEFAULT 1.00,
+    skor_maks INT NOT NULL DEFAULT 5,
+    urutan INT NOT NULL DEFAULT 0,
+    FOREIGN KEY (id_rubrik) REFERENCES rubrik_wawancara(id_rubrik) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE penilaian_wawancara (
+    id_penilaian INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    pewawancara_id INT NOT NULL,
+    pendaftar_id INT NULL,
+    id_rubrik INT NOT NULL,
+    nilai DECIMAL(5,2) NOT NULL DEFAULT 0.00,
+    catatan TEXT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_penilai_per_jadwal (id_jadwal, pewawancara_id),
+    INDEX idx_pendaftar_id (pendaftar_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (pewawancara_id) REFERENCES users(id_user),
+    FOREIGN KEY (pendaftar_id) REFERENCES pendaftar(id_pendaftar) ON DELETE SET NULL,
+    FOREIGN KEY (id_rubrik) REFERENCES rubrik_wawancara(id_rubrik)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE penilaian_wawancara_detail (
+    id_penilaian INT NOT NULL,
+    id_kriteria INT NOT NULL,
+    skor INT NOT NULL,
+    catatan TEXT NULL,
+    PRIMARY KEY (id_penilaian, id_kriteria),
+    FOREIGN KEY (id_penilaian) REFERENCES penilaian_wawancara(id_penilaian) ON DELETE CASCADE,
+    FOREIGN KEY (id_kriteria) REFERENCES kriteria_wawancara(id_kriteria)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Peserta jadwal umum (acara): terdaftar atau waitlist
+CREATE TABLE peserta_jadwal (
+    id_peserta INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    status ENUM('terdaftar', 'waitlist') NOT NULL DEFAULT 'terdaftar',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY unique_peserta_jadwal (id_jadwal, user_id),
+    INDEX idx_jadwal_status (id_jadwal, status, created_at),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Kehadiran (check-in QR) pada jadwal
+CREATE TABLE kehadiran (
+    id_kehadiran INT AUTO_INCREMENT PRIMARY KEY,
+    id_jadwal INT NOT NULL,
+    user_id INT NOT NULL,
+    waktu_checkin DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dicatat_oleh INT NULL,
+    metode ENUM('qr', 'manual') NOT NULL DEFAULT 'qr',
+    di_luar_jendela BOOLEAN NOT NULL DEFAULT FALSE, -- check-in manual di luar jendela check-in
+    UNIQUE KEY unique_kehadiran (id_jadwal, user_id),
+    INDEX idx_kehadiran_user (user_id),
+    FOREIGN KEY (id_jadwal) REFERENCES jadwal(id_jadwal) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id_user) ON DELETE CASCADE,
+    FOREIGN KEY (dicatat_oleh) REFERENCES users(id_user) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -44,7 +44,6 @@ func Setup(db *sql.DB) http.Handler {
 
 	mux.HandleFunc("/pendaftar/create", controllers.CreatePendaftar(db))
 
-
 	// 🔹 User: Lihat SEMUA jadwal
 	mux.Handle("/jadwal/user", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
 		controllers.GetUserJadwalHandler(db)(w, r)
@@ -60,6 +59,19 @@ func Setup(db *sql.DB) http.Handler {
 		controllers.CancelPengajuanPerubahanHandler(db)(w, r)
 	})))
 
+	// 🔹 User: Daftar / batal daftar jadwal umum
+	mux.Handle("/jadwal/umum/daftar", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.DaftarJadwalUmumHandler(db)(w, r)
+	})))
+	mux.Handle("/jadwal/umum/batal", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.BatalDaftarJadwalUmumHandler(db)(w, r)
+	})))
+
+	// 🔹 User: QR check-in (PNG) untuk jadwal yang sudah dikonfirmasi
+	mux.Handle("/jadwal/qr", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetQRCheckinHandler(db)(w, r)
+	})))
+
 	// 🔹 Test - User
 	mux.Handle("/test/soal", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
 		controllers.GetUserSoalHandler(db)(w, r)
@@ -67,16 +79,35 @@ func Setup(db *sql.DB) http.Handler {
 	mux.Handle("/test/submit", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
 		controllers.SubmitJawabanHandler(db)(w, r)
 	})))
+	mux.Handle("/test/mulai", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.MulaiTestHandler(db)(w, r)
+	})))
+	// Autosave jawaban per soal selama sesi berlangsung
+	mux.Handle("/test/jawaban", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.SimpanJawabanHandler(db)(w, r)
+	})))
+	// Gambar soal untuk peserta: tanpa header Auth (dipakai <img>), dijaga token sesi bertanda tangan
+	mux.HandleFunc("/test/gambar", controllers.GambarSesiHandler(db))
+	// Event integritas sesi (fokus hilang, salin/tempel, keluar layar penuh, ...)
+	mux.Handle("/test/event", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.EventSesiHandler(db)(w, r)
+	})))
+	mux.Handle("/test/saya", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetTestSayaHandler(db)(w, r)
+	})))
+	mux.Handle("/test/hasil/saya", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetHasilTesUserHandler(db)(w, r)
+	})))
 
 	// 🔹 Pengumuman - User: Lihat semua
 	mux.Handle("/pengumuman", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
-    controllers.GetPengumumanHandler(db)(w, r)
+		controllers.GetPengumumanHandler(db)(w, r)
 	}))
 
 	// === Protected Routes - Admin Role ===
 	// Pendaftar
 	mux.Handle("/pendaftar/my", middleware.Auth(middleware.Role("user")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.GetMyPendaftar(db)(w, r)
+		controllers.GetMyPendaftar(db)(w, r)
 	})))
 
 	mux.HandleFunc("/pendaftar/all", controllers.GetAllPendaftar(db))
@@ -111,7 +142,7 @@ func Setup(db *sql.DB) http.Handler {
 	})))
 
 	mux.Handle("/users", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.GetUsersForJadwalHandler(db)(w, r)
+		controllers.GetUsersForJadwalHandler(db)(w, r)
 	})))
 
 	// GET /jadwal?id=123
@@ -129,35 +160,225 @@ func Setup(db *sql.DB) http.Handler {
 	mux.Handle("/test/soal/delete", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
 		controllers.DeleteSoalHandler(db)(w, r)
 	})))
+	// Soal tidak dihapus permanen: delete mengarsipkan, POST ?id= memulihkan
+	mux.Handle("/test/soal/pulihkan", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PulihkanSoalHandler(db)(w, r)
+	})))
+	// GET ?id=: riwayat versi soal; diff ?id=&dari=&ke= membandingkan dua versi
+	mux.Handle("/test/soal/versi", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.VersiSoalHandler(db)(w, r)
+	})))
+	mux.Handle("/test/soal/versi/diff", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.DiffVersiSoalHandler(db)(w, r)
+	})))
+	// Bank soal: POST impor (multipart "file", format=csv|json|gift, dry_run=true), GET ekspor ?format=
+	mux.Handle("/test/soal/impor", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.ImporSoalHandler(db)(w, r)
+	})))
+	mux.Handle("/test/soal/ekspor", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.EksporSoalHandler(db)(w, r)
+	})))
 	mux.Handle("/test/hasil", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
 		controllers.GetAllHasilTesHandler(db)(w, r)
 	})))
 
 	mux.Handle("/test/admin/soal", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.GetAllSoalAdminHandler(db)(w, r)
+		controllers.GetAllSoalAdminHandler(db)(w, r)
 	})))
 
-	mux.Handle("/test/config", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.UpdateTestConfigHandler(db)(w, r)
+	// Kelola tes: GET /test/admin/list[?id_test=], create, update/delete ?id_test=
+	mux.Handle("/test/admin/list", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetAllTestHandler(db)(w, r)
+	})))
+	mux.Handle("/test/admin/create", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.CreateTestHandler(db)(w, r)
+	})))
+	mux.Handle("/test/admin/update", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.UpdateTestHandler(db)(w, r)
+	})))
+	mux.Handle("/test/admin/delete", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.DeleteTestHandler(db)(w, r)
+	})))
+	// GET/PUT ?id_test=: soal tes (urutan dari bank) & target peserta
+	mux.Handle("/test/admin/soal-test", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.SoalTestHandler(db)(w, r)
+	})))
+	// GET ?id_hasil=: tampilan soal teracak yang dilihat seorang peserta
+	mux.Handle("/test/admin/sesi-soal", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetSoalSesiAdminHandler(db)(w, r)
+	})))
+	// Gambar soal: GET daftar / berkas ?id_gambar=, POST unggah (multipart "gambar"), DELETE ?id_gambar=
+	mux.Handle("/test/admin/gambar", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GambarSoalAdminHandler(db)(w, r)
+	})))
+	// POST ?id_test= { rilis }: rilis hasil tes secara manual
+	mux.Handle("/test/admin/rilis-hasil", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.RilisHasilHandler(db)(w, r)
+	})))
+	// POST { id_hasil, alasan }: hapus percobaan tes yang rusak agar peserta dapat mengulang
+	mux.Handle("/test/admin/reset-sesi", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.ResetSesiAdminHandler(db)(w, r)
+	})))
+	// POST ?id_test= { alasan }: nilai ulang semua hasil dengan kunci soal terkini
+	mux.Handle("/test/admin/nilai-ulang", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PenilaianUlangHandler(db)(w, r)
+	})))
+	// POST { id_hasil, penyesuaian_poin, alasan }: penyesuaian poin manual
+	mux.Handle("/test/admin/penyesuaian", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PenyesuaianNilaiHandler(db)(w, r)
+	})))
+	// POST { id_test, user_id, alasan }: izinkan satu percobaan tambahan
+	mux.Handle("/test/admin/izin-ulang", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.IzinUlangHandler(db)(w, r)
+	})))
+	// GET ?id_test=: jejak audit koreksi hasil tes
+	mux.Handle("/test/admin/audit", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.AuditHasilHandler(db)(w, r)
+	})))
+	// GET/PUT ?id_test=: dimensi sifat & bobot pilihan psikotes
+	mux.Handle("/test/admin/psikotes", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.ModelPsikotesHandler(db)(w, r)
+	})))
+	// GET/PUT/DELETE ?id_test=: akomodasi jendela & tambahan waktu per pendaftar
+	mux.Handle("/test/admin/akomodasi", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.AkomodasiTestHandler(db)(w, r)
+	})))
+	// GET ?id_hasil=: ringkasan & linimasa event integritas sesi
+	mux.Handle("/test/admin/integritas", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.IntegritasSesiAdminHandler(db)(w, r)
+	})))
+	// GET ?id_test=: statistik nilai & analisis butir soal
+	mux.Handle("/test/admin/analisis", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.AnalisisTestHandler(db)(w, r)
+	})))
+	// GET antrean esai (?id_test= opsional), POST { id_jawaban, poin, catatan }
+	mux.Handle("/test/admin/penilaian", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PenilaianEsaiHandler(db)(w, r)
+	})))
+	mux.Handle("/test/admin/peserta", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PesertaTestHandler(db)(w, r)
 	})))
 
-	// 🔹 Pengumuman 
+	// 🔹 Pengumuman
 	mux.Handle("/pengumuman/create", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.CreatePengumumanHandler(db)(w, r)
+		controllers.CreatePengumumanHandler(db)(w, r)
 	})))
 
 	mux.Handle("/pengumuman/update", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.UpdatePengumumanHandler(db)(w, r)
+		controllers.UpdatePengumumanHandler(db)(w, r)
 	})))
 
 	mux.Handle("/pengumuman/delete", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.DeletePengumumanHandler(db)(w, r)
+		controllers.DeletePengumumanHandler(db)(w, r)
 	})))
 
+	// 🔹 Wawancara
+	// GET /wawancara/rubrik: admin dan pewawancara yang ditugaskan (dicek di controller)
+	mux.Handle("/wawancara/rubrik", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetAllRubrikHandler(db)(w, r)
+	}))
+
+	mux.Handle("/wawancara/rubrik/create", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.CreateRubrikHandler(db)(w, r)
+	})))
+
+	mux.Handle("/wawancara/rubrik/update", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.UpdateRubrikHandler(db)(w, r)
+	})))
+
+	mux.Handle("/wawancara/rubrik/delete", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.DeleteRubrikHandler(db)(w, r)
+	})))
+
+	// GET /jadwal/peserta?id_jadwal=123 (JSON) & /jadwal/peserta/export?id_jadwal=123 (CSV)
+	mux.Handle("/jadwal/peserta", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetPesertaJadwalHandler(db)(w, r)
+	})))
+	mux.Handle("/jadwal/peserta/export", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.ExportPesertaJadwalHandler(db)(w, r)
+	})))
+
+	// Kehadiran: check-in (token QR / manual), daftar hadir per jadwal, rekap no-show
+	mux.Handle("/jadwal/checkin", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.CheckinHandler(db)(w, r)
+	})))
+	mux.Handle("/jadwal/kehadiran", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetKehadiranJadwalHandler(db)(w, r)
+	})))
+	mux.Handle("/kehadiran/laporan", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetLaporanKehadiranHandler(db)(w, r)
+	})))
+
+	// GET/PUT /jadwal/pewawancara?id_jadwal=123
+	mux.Handle("/jadwal/pewawancara", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PewawancaraJadwalHandler(db)(w, r)
+	})))
+
+	mux.Handle("/wawancara/penilaian", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetPenilaianWawancaraHandler(db)(w, r)
+	})))
+
+	// Pewawancara (user mana pun yang ditugaskan pada jadwal)
+	mux.Handle("/wawancara/saya", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.GetJadwalWawancaraSayaHandler(db)(w, r)
+	}))
+
+	mux.Handle("/wawancara/nilai", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.SubmitPenilaianWawancaraHandler(db)(w, r)
+	}))
+
 	// 🔹 Notifikasi
 	mux.Handle("/notifikasi-stream", middleware.Cors(middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
-    	controllers.NotifikasiStreamHandler(w, r)
+		controllers.NotifikasiStreamHandler(w, r)
+	})))
+
+	// Kotak masuk notifikasi (GET ?belum_dibaca=true&limit=&sebelum=)
+	mux.Handle("/notifikasi", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.NotifikasiHandler(db)(w, r)
+	}))
+
+	// Jumlah notifikasi belum dibaca
+	mux.Handle("/notifikasi/belum-dibaca", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.JumlahNotifikasiBelumDibacaHandler(db)(w, r)
+	}))
+
+	// Tandai satu notifikasi dibaca
+	mux.Handle("/notifikasi/baca", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.BacaNotifikasiHandler(db)(w, r)
+	}))
+
+	// Tandai semua notifikasi dibaca
+	mux.Handle("/notifikasi/baca-semua", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.BacaSemuaNotifikasiHandler(db)(w, r)
+	}))
+
+	// Web Push: kunci publik VAPID untuk pushManager.subscribe
+	mux.Handle("/push/kunci-publik", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.KunciPublikPushHandler(w, r)
+	}))
+
+	// Web Push: daftarkan (POST) / lepas (DELETE) langganan browser
+	mux.Handle("/push/langganan", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.LanggananPushHandler(db)(w, r)
+	}))
+
+	// WhatsApp: preferensi (GET) / berhenti atau aktifkan kembali (PUT) pesan WhatsApp user
+	mux.Handle("/profile/whatsapp", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PreferensiWAHandler(db)(w, r)
+	}))
+
+	// WhatsApp: log pesan dan status pengiriman (?user_id=&status=&limit=)
+	mux.Handle("/wa/pesan", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.PesanWAHandler(db)(w, r)
 	})))
-	
+
+	// WhatsApp: kirim ulang pesan yang gagal (?id_pesan=)
+	mux.Handle("/wa/pesan/kirim-ulang", middleware.Auth(middleware.Role("admin")(func(w http.ResponseWriter, r *http.Request) {
+		controllers.KirimUlangPesanWAHandler(db)(w, r)
+	})))
+
+	// WhatsApp: callback status pengiriman dari gateway (header X-Webhook-Token)
+	mux.HandleFunc("/wa/status", controllers.StatusPesanWAHandler(db))
+
 	return mux
-}
\ No newline at end of file
+}
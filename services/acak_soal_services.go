@@ -0,0 +1,221 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"math/rand/v2"
+	"sort"
+	"strings"
+)
+
+type soalAcak struct {
+	idSoal        int
+	tipe          string
+	jumlahPilihan int
+}
+
+// acakUrutanSesi menghasilkan urutan soal dan pilihan yang deterministik untuk satu sesi:
+// sesi yang sama selalu menghasilkan urutan yang sama. Pilihan benar/salah tidak diacak.
+func acakUrutanSesi(idHasil, idTest int, soals []soalAcak) ([]soalAcak, []string) {
+	rng := rand.New(rand.NewPCG(uint64(idHasil), uint64(idTest)))
+
+	urutan := append([]soalAcak(nil), soals...)
+	rng.Shuffle(len(urutan), func(i, j int) { urutan[i], urutan[j] = urutan[j], urutan[i] })
+
+	pilihan := make([]string, len(urutan))
+	for i, s := range urutan {
+		if s.tipe == TipeSoalBenarSalah || !TipeBerpilihan(s.tipe) {
+			continue
+		}
+		p := make([]byte, s.jumlahPilihan)
+		for k := range p {
+			p[k] = byte('A' + k)
+		}
+		rng.Shuffle(len(p), func(a, b int) { p[a], p[b] = p[b], p[a] })
+		pilihan[i] = string(p)
+	}
+	return urutan, pilihan
+}
+
+// SiapkanUrutanSesi menyimpan urutan soal dan pilihan teracak untuk sesi; aman dipanggil berulang
+func SiapkanUrutanSesi(db *sql.DB, idHasil, idTest int) error {
+	var sudahAda bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM sesi_soal WHERE id_hasil = ?)`, idHasil).Scan(&sudahAda)
+	if err != nil || sudahAda {
+		return err
+	}
+
+	rows, err := db.Query(`
+		SELECT ts.id_soal, s.tipe, COUNT(ps.urutan)
+		FROM test_soal ts
+		INNER JOIN soal_test s ON ts.id_soal = s.id_soal
+		LEFT JOIN pilihan_soal ps ON ps.id_soal = ts.id_soal
+		WHERE ts.id_test = ?
+		GROUP BY ts.id_soal, s.tipe, ts.urutan
+		ORDER BY ts.urutan ASC
+	`, idTest)
+	if err != nil {
+		return err
+	}
+	var soals []soalAcak
+	for rows.Next() {
+		var s soalAcak
+		if err := rows.Scan(&s.idSoal, &s.tipe, &s.jumlahPilihan); err != nil {
+			rows.Close()
+			return err
+		}
+		soals = append(soals, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	urutan, pilihan := acakUrutanSesi(idHasil, idTest, soals)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, s := range urutan {
+		_, err := tx.Exec(
+			`INSERT IGNORE INTO sesi_soal (id_hasil, id_soal, urutan, urutan_pilihan) VALUES (?, ?, ?, ?)`,
+			idHasil, s.idSoal, i+1, pilihan[i],
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSoalSesi mengambil soal tes (versi terkini) sesuai urutan sesi. Sesi tanpa pemetaan (dibuat
+// sebelum pengacakan ada) memakai urutan tes dan pilihan kanonik.
+func GetSoalSesi(db *sql.DB, idHasil, idTest int) ([]models.SoalTest, error) {
+	return getSoalSesi(db, idHasil, idTest, false)
+}
+
+// GetSoalSesiDijawab sama dengan GetSoalSesi, tetapi soal yang sudah dijawab berisi versi yang
+// dijawab peserta; dipakai untuk pembahasan dan tinjauan admin
+func GetSoalSesiDijawab(db *sql.DB, idHasil, idTest int) ([]models.SoalTest, error) {
+	return getSoalSesi(db, idHasil, idTest, true)
+}
+
+func getSoalSesi(db *sql.DB, idHasil, idTest int, versiJawaban bool) ([]models.SoalTest, error) {
+	// Soal tanpa pemetaan sesi (ditambahkan setelah sesi dimulai) ditaruh setelah posisi
+	// terakhir sesi agar urutannya tidak bertabrakan dengan soal yang sudah dipetakan
+	rows, err := db.Query(selectSoal+`,
+			COALESCE(ss.urutan, mx.maks + ts.urutan) AS urutan_sesi, COALESCE(ss.urutan_pilihan, '')
+		FROM test_soal ts
+		INNER JOIN soal_test s ON ts.id_soal = s.id_soal
+		CROSS JOIN (SELECT COALESCE(MAX(urutan), 0) AS maks FROM sesi_soal WHERE id_hasil = ?) mx
+		LEFT JOIN sesi_soal ss ON ss.id_hasil = ? AND ss.id_soal = ts.id_soal
+		WHERE ts.id_test = ?
+		ORDER BY urutan_sesi ASC
+	`, idHasil, idHasil, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var soals []models.SoalTest
+	for rows.Next() {
+		var urutan int
+		var urutanPilihan string
+		s, err := scanSoal(rows, &urutan, &urutanPilihan)
+		if err != nil {
+			return nil, err
+		}
+		s.Urutan = urutan
+		s.UrutanPilihan = urutanPilihan
+		soals = append(soals, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := lampirkanPilihan(db, soals); err != nil {
+		return nil, err
+	}
+	if versiJawaban {
+		if err := terapkanVersiJawaban(db, idHasil, soals); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pemetaan yang tidak cocok lagi dengan jumlah pilihan (soal diubah) kembali ke urutan kanonik
+	for i := range soals {
+		if len(soals[i].UrutanPilihan) != len(soals[i].Pilihan) {
+			soals[i].UrutanPilihan = ""
+		}
+	}
+	return soals, nil
+}
+
+// labelKanonik mengembalikan huruf kanonik yang tampil di posisi ke-i
+func labelKanonik(s *models.SoalTest, i int) string {
+	if s.UrutanPilihan == "" {
+		return LabelPilihan(i + 1)
+	}
+	return string(s.UrutanPilihan[i])
+}
+
+// PilihanTampil mengembalikan pilihan sesuai urutan yang dilihat peserta, dengan label posisi tampil
+func PilihanTampil(s *models.SoalTest) []models.PilihanSoal {
+	kanonik := make(map[string]models.PilihanSoal, len(s.Pilihan))
+	for _, p := range s.Pilihan {
+		kanonik[p.Label] = p
+	}
+
+	tampil := make([]models.PilihanSoal, 0, len(s.Pilihan))
+	for i := range s.Pilihan {
+		p := kanonik[labelKanonik(s, i)]
+		p.Label = LabelPilihan(i + 1)
+		tampil = append(tampil, p)
+	}
+	return tampil
+}
+
+// petaLabel menerjemahkan daftar huruf berpisah koma memakai fungsi pemetaan, hasilnya terurut
+func petaLabel(jawaban string, peta func(string) string) string {
+	var hasil []string
+	for _, part := range strings.Split(jawaban, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		hasil = append(hasil, peta(part))
+	}
+	sort.Strings(hasil)
+	return strings.Join(hasil, ",")
+}
+
+// JawabanKanonik mengubah huruf yang dipilih peserta (posisi tampil) ke huruf pilihan kanonik.
+// Jawaban soal tanpa pilihan dikembalikan apa adanya.
+func JawabanKanonik(s *models.SoalTest, tampil string) string {
+	if !TipeBerpilihan(s.Tipe) || s.UrutanPilihan == "" {
+		return tampil
+	}
+	return petaLabel(tampil, func(label string) string {
+		i := int(label[0] - 'A')
+		if len(label) != 1 || i < 0 || i >= len(s.UrutanPilihan) {
+			return label
+		}
+		return string(s.UrutanPilihan[i])
+	})
+}
+
+// JawabanTampil mengubah huruf pilihan kanonik ke posisi yang dilihat peserta
+func JawabanTampil(s *models.SoalTest, kanonik string) string {
+	if !TipeBerpilihan(s.Tipe) || s.UrutanPilihan == "" {
+		return kanonik
+	}
+	return petaLabel(kanonik, func(label string) string {
+		i := strings.Index(s.UrutanPilihan, label)
+		if len(label) != 1 || i < 0 {
+			return label
+		}
+		return LabelPilihan(i + 1)
+	})
+}
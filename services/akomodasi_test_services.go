@@ -0,0 +1,212 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Batas tambahan waktu pengerjaan yang boleh diberikan lewat akomodasi
+const MaxTambahanMenit = 600
+
+var ErrAkomodasiTidakValid = errors.New("akomodasi tes tidak valid")
+
+const selectAkomodasi = `
+	SELECT a.id_test, a.pendaftar_id, p.nama_lengkap, a.waktu_mulai, a.waktu_selesai,
+		a.tambahan_menit, a.catatan, a.dibuat_oleh, a.created_at, a.updated_at
+	FROM akomodasi_test a
+	INNER JOIN pendaftar p ON p.id_pendaftar = a.pendaftar_id
+`
+
+func scanAkomodasi(row interface{ Scan(...any) error }) (*models.AkomodasiTest, error) {
+	var a models.AkomodasiTest
+	var waktuMulai, waktuSelesai sql.NullTime
+	var catatan sql.NullString
+	var dibuatOleh sql.NullInt64
+
+	err := row.Scan(&a.IDTest, &a.PendaftarID, &a.NamaPendaftar, &waktuMulai, &waktuSelesai,
+		&a.TambahanMenit, &catatan, &dibuatOleh, &a.CreatedAt, &a.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if waktuMulai.Valid {
+		a.WaktuMulai = &waktuMulai.Time
+	}
+	if waktuSelesai.Valid {
+		a.WaktuSelesai = &waktuSelesai.Time
+	}
+	a.Catatan = nullStringPtr(catatan)
+	a.DibuatOleh = nullIntPtr(dibuatOleh)
+	return &a, nil
+}
+
+func queryAkomodasi(db *sql.DB, query string, args ...any) ([]models.AkomodasiTest, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daftar := []models.AkomodasiTest{}
+	for rows.Next() {
+		a, err := scanAkomodasi(rows)
+		if err != nil {
+			return nil, err
+		}
+		daftar = append(daftar, *a)
+	}
+	return daftar, rows.Err()
+}
+
+// GetAkomodasi mengambil akomodasi seorang pendaftar pada sebuah tes (sql.ErrNoRows bila tidak ada)
+func GetAkomodasi(db *sql.DB, idTest, pendaftarID int) (*models.AkomodasiTest, error) {
+	return scanAkomodasi(db.QueryRow(selectAkomodasi+`
+		WHERE a.id_test = ? AND a.pendaftar_id = ?`, idTest, pendaftarID))
+}
+
+// GetAkomodasiTest mengambil seluruh akomodasi sebuah tes, urut nama pendaftar
+func GetAkomodasiTest(db *sql.DB, idTest int) ([]models.AkomodasiTest, error) {
+	return queryAkomodasi(db, selectAkomodasi+`
+		WHERE a.id_test = ?
+		ORDER BY p.nama_lengkap`, idTest)
+}
+
+// GetAkomodasiPendaftar mengambil akomodasi seorang pendaftar di semua tes, per id_test
+func GetAkomodasiPendaftar(db *sql.DB, pendaftarID int) (map[int]*models.AkomodasiTest, error) {
+	daftar, err := queryAkomodasi(db, selectAkomodasi+` WHERE a.pendaftar_id = ?`, pendaftarID)
+	if err != nil {
+		return nil, err
+	}
+	hasil := make(map[int]*models.AkomodasiTest, len(daftar))
+	for i := range daftar {
+		hasil[daftar[i].IDTest] = &daftar[i]
+	}
+	return hasil, nil
+}
+
+// TerapkanAkomodasi menimpa jendela tes dengan jendela akomodasi yang diisi dan menambahkan
+// tambahan menit ke durasi. WaktuSelesaiTerakhir tidak berubah karena berlaku untuk seluruh peserta.
+func TerapkanAkomodasi(t *models.Test, a *models.AkomodasiTest) {
+	if a == nil {
+		return
+	}
+	t.Akomodasi = a
+	if a.WaktuMulai != nil {
+		t.WaktuMulai = a.WaktuMulai
+	}
+	if a.WaktuSelesai != nil {
+		t.WaktuSelesai = a.WaktuSelesai
+	}
+	t.DurasiMenit += a.TambahanMenit
+}
+
+// batasSesi menghitung batas waktu sesi: durasi tes sejak mulai, dipotong akhir jendela tes
+func batasSesi(mulai time.Time, t *models.Test) time.Time {
+	batas := mulai.Add(time.Duration(t.DurasiMenit) * time.Minute)
+	if t.WaktuSelesai != nil && t.WaktuSelesai.Before(batas) {
+		batas = *t.WaktuSelesai
+	}
+	return batas
+}
+
+// SimpanAkomodasi membuat atau mengganti akomodasi seorang pendaftar, lalu menyesuaikan batas
+// waktu sesinya yang sedang berlangsung
+func SimpanAkomodasi(db *sql.DB, a models.AkomodasiTest) error {
+	if a.WaktuMulai == nil && a.WaktuSelesai == nil && a.TambahanMenit == 0 {
+		return fmt.Errorf("%w: isi jendela waktu atau tambahan menit", ErrAkomodasiTidakValid)
+	}
+	if a.TambahanMenit < 0 || a.TambahanMenit > MaxTambahanMenit {
+		return fmt.Errorf("%w: tambahan menit harus 0..%d", ErrAkomodasiTidakValid, MaxTambahanMenit)
+	}
+
+	t, err := GetTestByID(db, a.IDTest)
+	if err != nil {
+		return err
+	}
+	var ditugaskan bool
+	err = db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM test t INNER JOIN pendaftar p ON p.id_pendaftar = ?
+			WHERE t.id_test = ? AND `+kondisiTestDitugaskan+`)
+	`, a.PendaftarID, a.IDTest, a.PendaftarID).Scan(&ditugaskan)
+	if err != nil {
+		return err
+	}
+	if !ditugaskan {
+		return fmt.Errorf("%w: pendaftar tidak ditugaskan pada tes ini", ErrAkomodasiTidakValid)
+	}
+	TerapkanAkomodasi(t, &a)
+	if t.WaktuMulai != nil && t.WaktuSelesai != nil && !t.WaktuSelesai.After(*t.WaktuMulai) {
+		return fmt.Errorf("%w: waktu selesai harus setelah waktu mulai", ErrAkomodasiTidakValid)
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO akomodasi_test (id_test, pendaftar_id, waktu_mulai, waktu_selesai, tambahan_menit, catatan, dibuat_oleh)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			waktu_mulai = VALUES(waktu_mulai),
+			waktu_selesai = VALUES(waktu_selesai),
+			tambahan_menit = VALUES(tambahan_menit),
+			catatan = VALUES(catatan),
+			dibuat_oleh = VALUES(dibuat_oleh)
+	`, a.IDTest, a.PendaftarID, a.WaktuMulai, a.WaktuSelesai, a.TambahanMenit, a.Catatan, a.DibuatOleh)
+	if err != nil {
+		return err
+	}
+	return sesuaikanBatasSesi(db, t, a.PendaftarID)
+}
+
+// HapusAkomodasi menghapus akomodasi seorang pendaftar; sesi berlangsungnya kembali ke jendela tes
+func HapusAkomodasi(db *sql.DB, idTest, pendaftarID int) error {
+	res, err := db.Exec(`DELETE FROM akomodasi_test WHERE id_test = ? AND pendaftar_id = ?`, idTest, pendaftarID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	t, err := GetTestByID(db, idTest)
+	if err != nil {
+		return err
+	}
+	return sesuaikanBatasSesi(db, t, pendaftarID)
+}
+
+// sesuaikanBatasSesi menghitung ulang batas waktu sesi berlangsung pendaftar dengan tes t
+// (yang sudah disesuaikan akomodasinya) agar perubahan akomodasi berlaku saat itu juga
+func sesuaikanBatasSesi(db *sql.DB, t *models.Test, pendaftarID int) error {
+	rows, err := db.Query(`
+		SELECT id_hasil, waktu_mulai FROM hasil_test
+		WHERE id_test = ? AND pendaftar_id = ? AND status = ?
+	`, t.IDTest, pendaftarID, StatusHasilBerlangsung)
+	if err != nil {
+		return err
+	}
+	type sesi struct {
+		idHasil int
+		mulai   time.Time
+	}
+	var daftar []sesi
+	for rows.Next() {
+		var s sesi
+		if err := rows.Scan(&s.idHasil, &s.mulai); err != nil {
+			rows.Close()
+			return err
+		}
+		daftar = append(daftar, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range daftar {
+		_, err := db.Exec(`UPDATE hasil_test SET batas_waktu = ? WHERE id_hasil = ? AND status = ?`,
+			batasSesi(s.mulai, t), s.idHasil, StatusHasilBerlangsung)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Ambang penandaan butir soal pada analisis tes
+const (
+	BatasSoalTerlaluMudah = 0.90
+	BatasSoalTerlaluSulit = 0.20
+	BatasDayaBedaRendah   = 0.20
+
+	LebarBinHistogram = 10.0
+)
+
+// Tanda butir soal pada analisis tes
+const (
+	TandaTerlaluMudah       = "terlalu_mudah"
+	TandaTerlaluSulit       = "terlalu_sulit"
+	TandaDayaBedaRendah     = "daya_beda_rendah"
+	TandaDayaBedaNegatif    = "daya_beda_negatif"
+	TandaPengecohTakDipilih = "pengecoh_tidak_dipilih"
+	TandaPengecohDominan    = "pengecoh_lebih_dipilih_dari_kunci"
+)
+
+type jawabanAnalisis struct {
+	idVersi  int
+	jawaban  string
+	poin     float64
+	menunggu bool
+}
+
+// percobaanTerakhirSelesai membatasi analisis pada percobaan selesai terakhir setiap peserta
+const percobaanTerakhirSelesai = `
+	ht.percobaan = (
+		SELECT MAX(h2.percobaan) FROM hasil_test h2
+		WHERE h2.user_id = ht.user_id AND h2.id_test = ht.id_test AND h2.status = 'selesai'
+	)`
+
+// GetAnalisisTest menghitung statistik nilai dan analisis butir dari jawaban sesi yang sudah selesai
+func GetAnalisisTest(db *sql.DB, idTest int) (*models.AnalisisTest, error) {
+	soals, err := GetSoalByTest(db, idTest)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT ht.id_hasil, ht.nilai FROM hasil_test ht
+		WHERE ht.id_test = ? AND ht.status = 'selesai' AND`+percobaanTerakhirSelesai+`
+		ORDER BY ht.id_hasil
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	var idHasils []int
+	nilai := map[int]float64{}
+	for rows.Next() {
+		var id int
+		var n float64
+		if err := rows.Scan(&id, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		idHasils = append(idHasils, id)
+		nilai[id] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	daftarVersi, err := queryVersiSoal(db, `
+		WHERE v.id_versi IN (
+			SELECT ju.id_versi FROM jawaban_user ju
+			INNER JOIN hasil_test ht ON ht.id_hasil = ju.id_hasil
+			WHERE ht.id_test = ? AND ht.status = 'selesai' AND`+percobaanTerakhirSelesai+`
+		)`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	versi := make(map[int]*models.VersiSoal, len(daftarVersi))
+	for i := range daftarVersi {
+		versi[daftarVersi[i].IDVersi] = &daftarVersi[i]
+	}
+
+	rows, err = db.Query(`
+		SELECT ju.id_hasil, ju.id_soal, COALESCE(ju.id_versi, 0), ju.jawaban_user, COALESCE(ju.poin, 0), ju.status_penilaian
+		FROM jawaban_user ju
+		INNER JOIN hasil_test ht ON ht.id_hasil = ju.id_hasil
+		WHERE ht.id_test = ? AND ht.status = 'selesai' AND`+percobaanTerakhirSelesai+`
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jawaban := map[[2]int]jawabanAnalisis{}
+	for rows.Next() {
+		var idHasil, idSoal int
+		var j jawabanAnalisis
+		var status string
+		if err := rows.Scan(&idHasil, &idSoal, &j.idVersi, &j.jawaban, &j.poin, &status); err != nil {
+			return nil, err
+		}
+		j.menunggu = status == StatusPenilaianMenunggu
+		jawaban[[2]int{idHasil, idSoal}] = j
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return hitungAnalisis(idTest, soals, idHasils, nilai, jawaban, versi), nil
+}
+
+func hitungAnalisis(idTest int, soals []models.SoalTest, idHasils []int, nilai map[int]float64,
+	jawaban map[[2]int]jawabanAnalisis, versi map[int]*models.VersiSoal) *models.AnalisisTest {
+	a := &models.AnalisisTest{IDTest: idTest, JumlahPeserta: len(idHasils), Soal: []models.AnalisisSoal{}}
+
+	// Statistik nilai (0..100)
+	daftarNilai := make([]float64, 0, len(idHasils))
+	for _, id := range idHasils {
+		daftarNilai = append(daftarNilai, nilai[id])
+	}
+	a.Rata, a.SimpanganBaku = rataDanSimpangan(daftarNilai)
+	if len(daftarNilai) > 0 {
+		urut := append([]float64(nil), daftarNilai...)
+		sort.Float64s(urut)
+		a.Minimum, a.Maksimum = urut[0], urut[len(urut)-1]
+		tengah := len(urut) / 2
+		if len(urut)%2 == 0 {
+			a.Median = (urut[tengah-1] + urut[tengah]) / 2
+		} else {
+			a.Median = urut[tengah]
+		}
+	}
+	for bawah := 0.0; bawah < 100; bawah += LebarBinHistogram {
+		a.Histogram = append(a.Histogram, models.BinHistogram{Bawah: bawah, Atas: bawah + LebarBinHistogram})
+	}
+	for _, n := range daftarNilai {
+		i := int(n / LebarBinHistogram)
+		i = max(0, min(i, len(a.Histogram)-1))
+		a.Histogram[i].Jumlah++
+	}
+
+	// Skor total per peserta dalam poin; esai yang menunggu dihitung 0
+	total := map[int]float64{}
+	for _, id := range idHasils {
+		for _, s := range soals {
+			total[id] += jawaban[[2]int{id, s.IDSoal}].poin
+		}
+	}
+
+	for i := range soals {
+		a.Soal = append(a.Soal, analisisSoal(&soals[i], idHasils, total, jawaban, versi))
+	}
+	return a
+}
+
+// sebaranVersi menampung pilihan yang dipilih peserta pada satu versi soal
+type sebaranVersi struct {
+	versi    int
+	tipe     string
+	pilihan  []models.PilihanSoal
+	peserta  int
+	menjawab int
+	dipilih  map[string]int
+}
+
+func analisisSoal(s *models.SoalTest, idHasils []int, total map[int]float64, jawaban map[[2]int]jawabanAnalisis,
+	versi map[int]*models.VersiSoal) models.AnalisisSoal {
+	as := models.AnalisisSoal{
+		IDSoal:     s.IDSoal,
+		Urutan:     s.Urutan,
+		Pertanyaan: s.Pertanyaan,
+		Tipe:       s.Tipe,
+		Poin:       s.Poin,
+		Tanda:      []string{},
+	}
+
+	// Jawaban tanpa versi (atau yang belum dijawab) dipetakan ke isi soal saat ini
+	perVersi := map[int]*sebaranVersi{}
+	ambilSebaran := func(idVersi int) *sebaranVersi {
+		if _, ok := versi[idVersi]; !ok {
+			idVersi = 0
+		}
+		if sv, ok := perVersi[idVersi]; ok {
+			return sv
+		}
+		sv := &sebaranVersi{versi: s.Versi, tipe: s.Tipe, pilihan: s.Pilihan, dipilih: map[string]int{}}
+		if v, ok := versi[idVersi]; ok {
+			sv.versi, sv.tipe, sv.pilihan = v.Versi, v.Tipe, v.Pilihan
+		}
+		perVersi[idVersi] = sv
+		return sv
+	}
+
+	var skorButir, skorSisa []float64
+	for _, id := range idHasils {
+		j, ada := jawaban[[2]int{id, s.IDSoal}]
+		sv := ambilSebaran(j.idVersi)
+		sv.peserta++
+		switch {
+		case !ada || strings.TrimSpace(j.jawaban) == "":
+			as.JumlahKosong++
+		case j.menunggu:
+			as.JumlahMenunggu++
+			continue
+		default:
+			as.JumlahMenjawab++
+			sv.menjawab++
+			if TipeBerpilihan(sv.tipe) {
+				for _, label := range strings.Split(j.jawaban, ",") {
+					sv.dipilih[strings.TrimSpace(label)]++
+				}
+			}
+		}
+		skorButir = append(skorButir, j.poin)
+		skorSisa = append(skorSisa, total[id]-j.poin)
+	}
+
+	if s.Poin > 0 && len(skorButir) > 0 {
+		rata, _ := rataDanSimpangan(skorButir)
+		p := rata / s.Poin
+		as.TingkatKesukaran = &p
+		if p > BatasSoalTerlaluMudah {
+			as.Tanda = append(as.Tanda, TandaTerlaluMudah)
+		} else if p < BatasSoalTerlaluSulit {
+			as.Tanda = append(as.Tanda, TandaTerlaluSulit)
+		}
+	}
+	if r, ok := korelasi(skorButir, skorSisa); ok {
+		as.DayaBeda = &r
+		if r < 0 {
+			as.Tanda = append(as.Tanda, TandaDayaBedaNegatif)
+		} else if r < BatasDayaBedaRendah {
+			as.Tanda = append(as.Tanda, TandaDayaBedaRendah)
+		}
+	}
+
+	urutVersi := make([]*sebaranVersi, 0, len(perVersi))
+	for _, sv := range perVersi {
+		urutVersi = append(urutVersi, sv)
+	}
+	sort.Slice(urutVersi, func(i, j int) bool { return urutVersi[i].versi < urutVersi[j].versi })
+
+	tanda := map[string]bool{}
+	for _, sv := range urutVersi {
+		if !TipeBerpilihan(sv.tipe) {
+			continue
+		}
+		avs := models.AnalisisVersiSoal{
+			Versi: sv.versi, Tipe: sv.tipe, JumlahPeserta: sv.peserta, JumlahMenjawab: sv.menjawab,
+			Pilihan: []models.AnalisisPilihan{},
+		}
+		maksKunci, maksPengecoh := 0, 0
+		pengecohTakDipilih := false
+		for _, p := range sv.pilihan {
+			ap := models.AnalisisPilihan{Label: p.Label, Teks: p.Teks, IsBenar: p.IsBenar, Jumlah: sv.dipilih[p.Label]}
+			if sv.peserta > 0 {
+				ap.Persentase = float64(ap.Jumlah) / float64(sv.peserta) * 100
+			}
+			if p.IsBenar {
+				maksKunci = max(maksKunci, ap.Jumlah)
+			} else {
+				maksPengecoh = max(maksPengecoh, ap.Jumlah)
+				pengecohTakDipilih = pengecohTakDipilih || ap.Jumlah == 0
+			}
+			avs.Pilihan = append(avs.Pilihan, ap)
+		}
+		as.Versi = append(as.Versi, avs)
+
+		if sv.menjawab > 0 && sv.tipe != TipeSoalPsikotes {
+			if pengecohTakDipilih && sv.tipe != TipeSoalBenarSalah && !tanda[TandaPengecohTakDipilih] {
+				tanda[TandaPengecohTakDipilih] = true
+				as.Tanda = append(as.Tanda, TandaPengecohTakDipilih)
+			}
+			if maksPengecoh > maksKunci && !tanda[TandaPengecohDominan] {
+				tanda[TandaPengecohDominan] = true
+				as.Tanda = append(as.Tanda, TandaPengecohDominan)
+			}
+		}
+	}
+	return as
+}
+
+// rataDanSimpangan menghitung rata-rata dan simpangan baku populasi
+func rataDanSimpangan(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	var jumlah float64
+	for _, x := range xs {
+		jumlah += x
+	}
+	rata := jumlah / float64(len(xs))
+	var ragam float64
+	for _, x := range xs {
+		ragam += (x - rata) * (x - rata)
+	}
+	return rata, math.Sqrt(ragam / float64(len(xs)))
+}
+
+// korelasi menghitung korelasi Pearson; untuk skor butir benar/salah hasilnya sama dengan point-biserial
+func korelasi(xs, ys []float64) (float64, bool) {
+	if len(xs) < 2 || len(xs) != len(ys) {
+		return 0, false
+	}
+	rx, sx := rataDanSimpangan(xs)
+	ry, sy := rataDanSimpangan(ys)
+	if sx == 0 || sy == 0 {
+		return 0, false
+	}
+	var kov float64
+	for i := range xs {
+		kov += (xs[i] - rx) * (ys[i] - ry)
+	}
+	return kov / float64(len(xs)) / (sx * sy), true
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+const toleransiAnalisis = 1e-9
+
+func hampirSama(a, b float64) bool {
+	return math.Abs(a-b) < toleransiAnalisis
+}
+
+func TestRataDanSimpangan(t *testing.T) {
+	tests := []struct {
+		nama      string
+		xs        []float64
+		rata, std float64
+	}{
+		{"kosong", nil, 0, 0},
+		{"satu data", []float64{7}, 7, 0},
+		{"konstan", []float64{3, 3, 3}, 3, 0},
+		{"populasi klasik", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 5, 2},
+		{"dua data", []float64{0, 100}, 50, 50},
+	}
+	for _, tc := range tests {
+		rata, std := rataDanSimpangan(tc.xs)
+		if !hampirSama(rata, tc.rata) || !hampirSama(std, tc.std) {
+			t.Errorf("%s: rataDanSimpangan = %v, %v; ingin %v, %v", tc.nama, rata, std, tc.rata, tc.std)
+		}
+	}
+}
+
+// pointBiserial menghitung r_pb = (M1 - M0) / s * sqrt(p*q) dengan simpangan baku populasi
+func pointBiserial(butir, total []float64) float64 {
+	var m1, m0, n1, n0 float64
+	for i, x := range butir {
+		if x == 1 {
+			m1 += total[i]
+			n1++
+		} else {
+			m0 += total[i]
+			n0++
+		}
+	}
+	m1, m0 = m1/n1, m0/n0
+	_, s := rataDanSimpangan(total)
+	n := n1 + n0
+	return (m1 - m0) / s * math.Sqrt(n1/n*n0/n)
+}
+
+func TestKorelasi(t *testing.T) {
+	butir := []float64{1, 1, 0, 0, 1}
+	total := []float64{8, 9, 3, 4, 6}
+	r, ok := korelasi(butir, total)
+	if !ok {
+		t.Fatal("korelasi butir dikotomis tidak terhitung")
+	}
+	if want := pointBiserial(butir, total); !hampirSama(r, want) {
+		t.Errorf("korelasi = %v, ingin point-biserial %v", r, want)
+	}
+	// cov = 1, sx = sqrt(0.24), sy = sqrt(5.2)
+	if want := 1 / math.Sqrt(0.24*5.2); !hampirSama(r, want) {
+		t.Errorf("korelasi = %v, ingin %v", r, want)
+	}
+
+	tests := []struct {
+		nama   string
+		xs, ys []float64
+		r      float64
+		ok     bool
+	}{
+		{"positif sempurna", []float64{1, 2, 3}, []float64{2, 4, 6}, 1, true},
+		{"negatif sempurna", []float64{1, 0, 1, 0}, []float64{0, 5, 0, 5}, -1, true},
+		{"butir tanpa ragam", []float64{1, 1, 1, 1}, []float64{2, 5, 7, 9}, 0, false},
+		{"total tanpa ragam", []float64{1, 0, 1, 0}, []float64{4, 4, 4, 4}, 0, false},
+		{"semua salah", []float64{0, 0, 0}, []float64{0, 0, 0}, 0, false},
+		{"satu peserta", []float64{1}, []float64{3}, 0, false},
+		{"panjang beda", []float64{1, 0}, []float64{1, 2, 3}, 0, false},
+	}
+	for _, tc := range tests {
+		r, ok := korelasi(tc.xs, tc.ys)
+		if ok != tc.ok || !hampirSama(r, tc.r) || math.IsNaN(r) || math.IsInf(r, 0) {
+			t.Errorf("%s: korelasi = %v, %v; ingin %v, %v", tc.nama, r, ok, tc.r, tc.ok)
+		}
+	}
+}
+
+func TestHitungAnalisis(t *testing.T) {
+	pg := func(teks ...string) []models.PilihanSoal {
+		var p []models.PilihanSoal
+		for i, s := range teks {
+			p = append(p, models.PilihanSoal{Urutan: i + 1, Label: LabelPilihan(i + 1), Teks: s, IsBenar: i == 0})
+		}
+		return p
+	}
+	soals := []models.SoalTest{
+		{IDSoal: 1, Tipe: TipeSoalPilihanGanda, Poin: 1, Versi: 2, Pilihan: pg("a", "b", "c")},
+		{IDSoal: 2, Tipe: TipeSoalPilihanGanda, Poin: 1, Versi: 1, Pilihan: pg("p", "q")},
+		{IDSoal: 3, Tipe: TipeSoalPilihanGanda, Poin: 1, Versi: 1, Pilihan: pg("x", "y")},
+	}
+	// Peserta 13 mengerjakan soal 1 versi lama (id_versi 5)
+	versi := map[int]*models.VersiSoal{
+		5: {IDVersi: 5, IDSoal: 1, Versi: 1, Tipe: TipeSoalPilihanGanda, Pilihan: pg("lama-benar", "lama-salah")},
+	}
+	idHasils := []int{10, 11, 12, 13}
+	nilai := map[int]float64{10: 100, 11: 80, 12: 45, 13: 0}
+	jawaban := map[[2]int]jawabanAnalisis{
+		{10, 1}: {jawaban: "A", poin: 1},
+		{11, 1}: {jawaban: "A", poin: 1},
+		{12, 1}: {jawaban: "B", poin: 0},
+		{13, 1}: {idVersi: 5, jawaban: "B", poin: 0},
+
+		{10, 2}: {jawaban: "A", poin: 1},
+		{11, 2}: {jawaban: "A", poin: 1},
+		{12, 2}: {jawaban: "B", poin: 0},
+		{13, 2}: {jawaban: "", poin: 0},
+
+		{10, 3}: {jawaban: "A", poin: 1},
+		{11, 3}: {jawaban: "A", poin: 1},
+		{12, 3}: {jawaban: "A", poin: 1},
+		{13, 3}: {jawaban: "A", poin: 1},
+	}
+
+	a := hitungAnalisis(7, soals, idHasils, nilai, jawaban, versi)
+
+	if a.JumlahPeserta != 4 || !hampirSama(a.Rata, 56.25) || !hampirSama(a.Median, 62.5) ||
+		!hampirSama(a.SimpanganBaku, math.Sqrt(1442.1875)) || a.Minimum != 0 || a.Maksimum != 100 {
+		t.Errorf("statistik nilai = peserta %d rata %v median %v std %v min %v maks %v",
+			a.JumlahPeserta, a.Rata, a.Median, a.SimpanganBaku, a.Minimum, a.Maksimum)
+	}
+	var histogram []int
+	for _, b := range a.Histogram {
+		histogram = append(histogram, b.Jumlah)
+	}
+	// Nilai 100 masuk bin terakhir [90, 100]
+	if want := []int{1, 0, 0, 0, 1, 0, 0, 0, 1, 1}; !reflect.DeepEqual(histogram, want) {
+		t.Errorf("histogram = %v, ingin %v", histogram, want)
+	}
+
+	s1, s2, s3 := a.Soal[0], a.Soal[1], a.Soal[2]
+
+	// Soal 1: skor sisa [2 2 1 1] searah dengan skor butir [1 1 0 0]
+	if s1.TingkatKesukaran == nil || !hampirSama(*s1.TingkatKesukaran, 0.5) ||
+		s1.DayaBeda == nil || !hampirSama(*s1.DayaBeda, 1) {
+		t.Errorf("soal 1 kesukaran %v daya beda %v", s1.TingkatKesukaran, s1.DayaBeda)
+	}
+	if want := []string{TandaPengecohDominan, TandaPengecohTakDipilih}; !reflect.DeepEqual(s1.Tanda, want) {
+		t.Errorf("soal 1 tanda = %v, ingin %v", s1.Tanda, want)
+	}
+	if len(s1.Versi) != 2 || s1.Versi[0].Versi != 1 || s1.Versi[1].Versi != 2 {
+		t.Fatalf("soal 1 versi = %+v", s1.Versi)
+	}
+	jumlah := func(v models.AnalisisVersiSoal) []int {
+		var j []int
+		for _, p := range v.Pilihan {
+			j = append(j, p.Jumlah)
+		}
+		return j
+	}
+	if v := s1.Versi[0]; v.JumlahPeserta != 1 || v.Pilihan[1].Teks != "lama-salah" || !reflect.DeepEqual(jumlah(v), []int{0, 1}) {
+		t.Errorf("soal 1 versi lama = %+v", v)
+	}
+	if v := s1.Versi[1]; v.JumlahPeserta != 3 || !reflect.DeepEqual(jumlah(v), []int{2, 1, 0}) ||
+		!hampirSama(v.Pilihan[0].Persentase, 200.0/3) {
+		t.Errorf("soal 1 versi kini = %+v", v)
+	}
+
+	if s2.JumlahMenjawab != 3 || s2.JumlahKosong != 1 || len(s2.Tanda) != 0 {
+		t.Errorf("soal 2 = menjawab %d kosong %d tanda %v", s2.JumlahMenjawab, s2.JumlahKosong, s2.Tanda)
+	}
+
+	// Soal 3 dijawab benar semua: tanpa ragam, daya beda tidak dihitung (bukan NaN)
+	if s3.DayaBeda != nil {
+		t.Errorf("soal 3 daya beda = %v, ingin nil", *s3.DayaBeda)
+	}
+	if want := []string{TandaTerlaluMudah, TandaPengecohTakDipilih}; !reflect.DeepEqual(s3.Tanda, want) {
+		t.Errorf("soal 3 tanda = %v, ingin %v", s3.Tanda, want)
+	}
+	if _, err := json.Marshal(a); err != nil {
+		t.Errorf("analisis tidak bisa di-encode JSON: %v", err)
+	}
+}
+
+func TestHitungAnalisisTanpaPeserta(t *testing.T) {
+	soals := []models.SoalTest{{IDSoal: 1, Tipe: TipeSoalEsai, Poin: 5}}
+	a := hitungAnalisis(1, soals, nil, map[int]float64{}, map[[2]int]jawabanAnalisis{}, nil)
+	if a.JumlahPeserta != 0 || a.Rata != 0 || a.Median != 0 || a.SimpanganBaku != 0 {
+		t.Errorf("statistik tanpa peserta = %+v", a)
+	}
+	if s := a.Soal[0]; s.TingkatKesukaran != nil || s.DayaBeda != nil || len(s.Versi) != 0 {
+		t.Errorf("soal tanpa peserta = %+v", s)
+	}
+	if _, err := json.Marshal(a); err != nil {
+		t.Errorf("analisis tidak bisa di-encode JSON: %v", err)
+	}
+}
@@ -0,0 +1,716 @@
+package services
+
+import (
+	"bufio"
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format berkas bank soal untuk impor dan ekspor
+const (
+	FormatSoalCSV  = "csv"
+	FormatSoalJSON = "json"
+	FormatSoalGIFT = "gift"
+)
+
+var ErrFormatSoalTidakDikenal = errors.New("format berkas tidak dikenal (csv, json, gift)")
+
+// BacaSoal membaca berkas bank soal. Galat per soal dikumpulkan di slice kesalahan;
+// error hanya dikembalikan bila berkas tidak bisa dibaca sama sekali.
+func BacaSoal(format string, r io.Reader) ([]models.SoalImpor, []models.KesalahanImporSoal, error) {
+	switch format {
+	case FormatSoalCSV:
+		return BacaSoalCSV(r)
+	case FormatSoalJSON:
+		return BacaSoalJSON(r)
+	case FormatSoalGIFT:
+		return BacaSoalGIFT(r)
+	}
+	return nil, nil, ErrFormatSoalTidakDikenal
+}
+
+// TulisSoal menulis bank soal ke w dalam format yang diminta. Berkas gambar hanya ikut
+// di format JSON; lihat SiapkanEksporSoal.
+func TulisSoal(format string, w io.Writer, soals []models.SoalTest, gambar map[int]models.BerkasGambarSoal) error {
+	switch format {
+	case FormatSoalCSV:
+		return TulisSoalCSV(w, soals)
+	case FormatSoalJSON:
+		return TulisSoalJSON(w, soals, gambar)
+	case FormatSoalGIFT:
+		return TulisSoalGIFT(w, soals)
+	}
+	return ErrFormatSoalTidakDikenal
+}
+
+// lengkapiSoalImpor mengisi tipe dan mode isian yang tidak disebutkan di berkas
+func lengkapiSoalImpor(s *models.SoalTest) {
+	if s.ModeIsian == "" {
+		s.ModeIsian = ModeIsianPersis
+	}
+	if s.Tipe != "" {
+		return
+	}
+	jumlahBenar := 0
+	for _, p := range s.Pilihan {
+		if p.IsBenar {
+			jumlahBenar++
+		}
+	}
+	switch {
+	case jumlahBenar > 1:
+		s.Tipe = TipeSoalPilihanGandaMulti
+	case len(s.Pilihan) > 0:
+		s.Tipe = TipeSoalPilihanGanda
+	case s.KunciIsian != nil && strings.TrimSpace(*s.KunciIsian) != "":
+		s.Tipe = TipeSoalIsian
+	default:
+		s.Tipe = TipeSoalEsai
+	}
+}
+
+// ---------- CSV ----------
+// Kolom: nomor, tipe, poin, pertanyaan, pilihan_a .. pilihan_j, benar ("A" atau "A,C"),
+// kunci_isian, mode_isian, pembahasan. Hanya pertanyaan yang wajib; urutan kolom bebas.
+
+func kolomPilihanCSV(i int) string {
+	return "pilihan_" + strings.ToLower(LabelPilihan(i+1))
+}
+
+func BacaSoalCSV(r io.Reader) ([]models.SoalImpor, []models.KesalahanImporSoal, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca header CSV: %v", err)
+	}
+	kolom := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		kolom[h] = i
+	}
+	if _, ok := kolom["pertanyaan"]; !ok {
+		return nil, nil, errors.New("header CSV wajib memiliki kolom pertanyaan")
+	}
+
+	var items []models.SoalImpor
+	var kesalahan []models.KesalahanImporSoal
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSV tidak valid: %v", err)
+		}
+		baris, _ := cr.FieldPos(0)
+
+		ambil := func(nama string) string {
+			if i, ok := kolom[nama]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		s, err := soalDariBarisCSV(ambil)
+		if err != nil {
+			kesalahan = append(kesalahan, models.KesalahanImporSoal{Baris: baris, Pesan: err.Error()})
+			continue
+		}
+		items = append(items, models.SoalImpor{Baris: baris, Soal: s})
+	}
+	return items, kesalahan, nil
+}
+
+func soalDariBarisCSV(ambil func(string) string) (models.SoalTest, error) {
+	s := models.SoalTest{
+		Pertanyaan: ambil("pertanyaan"),
+		Tipe:       strings.ToLower(ambil("tipe")),
+		Poin:       1,
+		ModeIsian:  strings.ToLower(ambil("mode_isian")),
+	}
+
+	if v := ambil("nomor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return s, fmt.Errorf("nomor %q tidak valid", v)
+		}
+		s.Nomor = n
+	}
+	if v := ambil("poin"); v != "" {
+		poin, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+		if err != nil {
+			return s, fmt.Errorf("poin %q tidak valid", v)
+		}
+		s.Poin = poin
+	}
+	if v := ambil("kunci_isian"); v != "" {
+		s.KunciIsian = &v
+	}
+	if v := ambil("pembahasan"); v != "" {
+		s.Pembahasan = &v
+	}
+
+	kosongSebelumnya := false
+	for i := 0; i < MaxPilihanSoal; i++ {
+		teks := ambil(kolomPilihanCSV(i))
+		if teks == "" {
+			kosongSebelumnya = true
+			continue
+		}
+		if kosongSebelumnya {
+			return s, fmt.Errorf("kolom %s terisi tetapi pilihan sebelumnya kosong", kolomPilihanCSV(i))
+		}
+		s.Pilihan = append(s.Pilihan, models.PilihanSoal{Urutan: i + 1, Label: LabelPilihan(i + 1), Teks: teks})
+	}
+	if benar := ambil("benar"); benar != "" {
+		labels, err := parseLabelPilihan(benar, len(s.Pilihan))
+		if err != nil {
+			return s, fmt.Errorf("kolom benar %q tidak cocok dengan pilihan yang terisi", benar)
+		}
+		for _, l := range labels {
+			s.Pilihan[l[0]-'A'].IsBenar = true
+		}
+	}
+
+	lengkapiSoalImpor(&s)
+	return s, nil
+}
+
+func TulisSoalCSV(w io.Writer, soals []models.SoalTest) error {
+	jumlahPilihan := 4
+	for _, s := range soals {
+		if len(s.Pilihan) > jumlahPilihan {
+			jumlahPilihan = len(s.Pilihan)
+		}
+	}
+
+	header := []string{"nomor", "tipe", "poin", "pertanyaan"}
+	for i := 0; i < jumlahPilihan; i++ {
+		header = append(header, kolomPilihanCSV(i))
+	}
+	header = append(header, "benar", "kunci_isian", "mode_isian", "pembahasan")
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, s := range soals {
+		record := []string{
+			strconv.Itoa(s.Nomor),
+			s.Tipe,
+			strconv.FormatFloat(s.Poin, 'f', -1, 64),
+			s.Pertanyaan,
+		}
+		var benar []string
+		for i := 0; i < jumlahPilihan; i++ {
+			if i < len(s.Pilihan) {
+				record = append(record, s.Pilihan[i].Teks)
+				if s.Pilihan[i].IsBenar {
+					benar = append(benar, s.Pilihan[i].Label)
+				}
+			} else {
+				record = append(record, "")
+			}
+		}
+		kunci := ""
+		if s.KunciIsian != nil {
+			kunci = *s.KunciIsian
+		}
+		pembahasan := ""
+		if s.Pembahasan != nil {
+			pembahasan = *s.Pembahasan
+		}
+		record = append(record, strings.Join(benar, ","), kunci, s.ModeIsian, pembahasan)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ---------- JSON ----------
+// Array objek dengan skema yang sama seperti body POST /test/soal/create, ditambah "gambar":
+// berkas gambar (base64) yang dirujuk soal lewat gambar:ID. Tiap gambar dibawa sekali, di soal
+// pertama yang merujuknya; saat impor gambar disimpan ulang dan rujukannya diganti id baru.
+
+type gambarSoalJSON struct {
+	ID       int    `json:"id"`
+	NamaAsli string `json:"nama_asli"`
+	Data     []byte `json:"data"`
+}
+
+type pilihanSoalJSON struct {
+	Teks    string `json:"teks"`
+	IsBenar bool   `json:"is_benar"`
+}
+
+type soalJSON struct {
+	Nomor      int               `json:"nomor,omitempty"`
+	Pertanyaan string            `json:"pertanyaan"`
+	Tipe       string            `json:"tipe,omitempty"`
+	Poin       *float64          `json:"poin,omitempty"`
+	Pilihan    []pilihanSoalJSON `json:"pilihan,omitempty"`
+	KunciIsian *string           `json:"kunci_isian,omitempty"`
+	ModeIsian  string            `json:"mode_isian,omitempty"`
+	Pembahasan *string           `json:"pembahasan,omitempty"`
+	Gambar     []gambarSoalJSON  `json:"gambar,omitempty"`
+}
+
+func BacaSoalJSON(r io.Reader) ([]models.SoalImpor, []models.KesalahanImporSoal, error) {
+	var list []soalJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&list); err != nil {
+		return nil, nil, fmt.Errorf("JSON tidak valid (harus berupa array soal): %v", err)
+	}
+
+	var items []models.SoalImpor
+	for i, sj := range list {
+		s := models.SoalTest{
+			Nomor:      sj.Nomor,
+			Pertanyaan: strings.TrimSpace(sj.Pertanyaan),
+			Tipe:       sj.Tipe,
+			Poin:       1,
+			KunciIsian: sj.KunciIsian,
+			ModeIsian:  sj.ModeIsian,
+			Pembahasan: sj.Pembahasan,
+		}
+		if sj.Poin != nil {
+			s.Poin = *sj.Poin
+		}
+		for k, p := range sj.Pilihan {
+			s.Pilihan = append(s.Pilihan, models.PilihanSoal{
+				Urutan: k + 1, Label: LabelPilihan(k + 1), Teks: p.Teks, IsBenar: p.IsBenar,
+			})
+		}
+		lengkapiSoalImpor(&s)
+		it := models.SoalImpor{Baris: i + 1, Soal: s}
+		for _, g := range sj.Gambar {
+			it.Gambar = append(it.Gambar, models.BerkasGambarSoal{IDGambar: g.ID, NamaAsli: g.NamaAsli, Data: g.Data})
+		}
+		items = append(items, it)
+	}
+	return items, nil, nil
+}
+
+// IDGambarSoal mengembalikan id gambar yang dirujuk pertanyaan, pilihan, dan pembahasan soal
+func IDGambarSoal(s *models.SoalTest) []int {
+	ids := utils.IDGambarMarkdown(s.Pertanyaan)
+	for _, p := range s.Pilihan {
+		ids = append(ids, utils.IDGambarMarkdown(p.Teks)...)
+	}
+	if s.Pembahasan != nil {
+		ids = append(ids, utils.IDGambarMarkdown(*s.Pembahasan)...)
+	}
+	return ids
+}
+
+func TulisSoalJSON(w io.Writer, soals []models.SoalTest, gambar map[int]models.BerkasGambarSoal) error {
+	sudahDibawa := map[int]bool{}
+	list := make([]soalJSON, 0, len(soals))
+	for _, s := range soals {
+		poin := s.Poin
+		sj := soalJSON{
+			Nomor:      s.Nomor,
+			Pertanyaan: s.Pertanyaan,
+			Tipe:       s.Tipe,
+			Poin:       &poin,
+			KunciIsian: s.KunciIsian,
+			ModeIsian:  s.ModeIsian,
+			Pembahasan: s.Pembahasan,
+		}
+		for _, p := range s.Pilihan {
+			sj.Pilihan = append(sj.Pilihan, pilihanSoalJSON{Teks: p.Teks, IsBenar: p.IsBenar})
+		}
+		for _, id := range IDGambarSoal(&s) {
+			g, ok := gambar[id]
+			if !ok || sudahDibawa[id] {
+				continue
+			}
+			sudahDibawa[id] = true
+			sj.Gambar = append(sj.Gambar, gambarSoalJSON{ID: id, NamaAsli: g.NamaAsli, Data: g.Data})
+		}
+		list = append(list, sj)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// ---------- GIFT (Moodle) ----------
+// Didukung: pilihan ganda {=benar ~salah}, multi jawaban dengan bobot {~%50%a ~%50%b ~%-100%c},
+// benar/salah {T}/{F}, isian {=jawaban =alternatif}, esai {}. Judul ::N:: dipakai sebagai nomor,
+// umpan balik umum {... ####teks} menjadi pembahasan.
+// Poin, mode isian regex, dan soal psikotes (tidak ada di GIFT) dibawa lewat komentar "// poin: 2",
+// "// mode_isian: regex", dan "// tipe: psikotes" di dalam blok soal. Soal benar/salah dengan teks
+// pilihan selain "Benar"/"Salah" ditulis sebagai {=teks ~teks} dengan "// tipe: benar_salah" agar
+// teksnya tidak hilang. Soal numerik dan menjodohkan tidak didukung.
+
+var polaMetaGIFT = regexp.MustCompile(`^//\s*(poin|mode_isian|tipe)\s*:\s*(\S+)\s*$`)
+
+func BacaSoalGIFT(r io.Reader) ([]models.SoalImpor, []models.KesalahanImporSoal, error) {
+	var items []models.SoalImpor
+	var kesalahan []models.KesalahanImporSoal
+
+	var blok []string
+	meta := map[string]string{}
+	mulai := 0
+	selesaiBlok := func() {
+		if len(blok) > 0 {
+			s, err := soalDariGIFT(strings.Join(blok, "\n"), meta)
+			if err != nil {
+				kesalahan = append(kesalahan, models.KesalahanImporSoal{Baris: mulai, Pesan: err.Error()})
+			} else {
+				items = append(items, models.SoalImpor{Baris: mulai, Soal: s})
+			}
+		}
+		blok = nil
+		meta = map[string]string{}
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 4<<20)
+	nomorBaris := 0
+	for sc.Scan() {
+		nomorBaris++
+		baris := strings.TrimRight(sc.Text(), "\r")
+		if nomorBaris == 1 {
+			baris = strings.TrimPrefix(baris, "\ufeff")
+		}
+		trim := strings.TrimSpace(baris)
+
+		switch {
+		case trim == "":
+			selesaiBlok()
+		case strings.HasPrefix(trim, "//"):
+			if m := polaMetaGIFT.FindStringSubmatch(trim); m != nil {
+				meta[m[1]] = m[2]
+			}
+		case strings.HasPrefix(trim, "$CATEGORY:"):
+			// kategori Moodle tidak dipakai
+		default:
+			if len(blok) == 0 {
+				mulai = nomorBaris
+			}
+			blok = append(blok, baris)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca berkas GIFT: %v", err)
+	}
+	selesaiBlok()
+	return items, kesalahan, nil
+}
+
+// indexTakLolos mencari sub string pertama yang tidak didahului backslash
+func indexTakLolos(s, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeGIFT membuang escape GIFT; \n menjadi baris baru
+func unescapeGIFT(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func escapeGIFT(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		switch c {
+		case '~', '=', '#', '{', '}', ':', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func soalDariGIFT(blok string, meta map[string]string) (models.SoalTest, error) {
+	s := models.SoalTest{Poin: 1, ModeIsian: ModeIsianPersis}
+	teks := strings.TrimSpace(blok)
+
+	if strings.HasPrefix(teks, "::") {
+		akhir := indexTakLolos(teks[2:], "::")
+		if akhir < 0 {
+			return s, errors.New("judul ::...:: tidak ditutup")
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(unescapeGIFT(teks[2 : 2+akhir]))); err == nil && n > 0 {
+			s.Nomor = n
+		}
+		teks = strings.TrimSpace(teks[2+akhir+2:])
+	}
+	for _, f := range []string{"[markdown]", "[plain]", "[moodle]", "[html]"} {
+		teks = strings.TrimPrefix(teks, f)
+	}
+
+	buka := indexTakLolos(teks, "{")
+	if buka < 0 {
+		return s, errors.New("blok jawaban {...} tidak ditemukan")
+	}
+	tutup := indexTakLolos(teks[buka:], "}")
+	if tutup < 0 {
+		return s, errors.New("blok jawaban tidak ditutup dengan }")
+	}
+	tutup += buka
+
+	s.Pertanyaan = unescapeGIFT(teks[:buka])
+	if setelah := unescapeGIFT(teks[tutup+1:]); setelah != "" {
+		s.Pertanyaan += " _____ " + setelah
+	}
+
+	if v, ok := meta["poin"]; ok {
+		poin, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return s, fmt.Errorf("komentar poin %q tidak valid", v)
+		}
+		s.Poin = poin
+	}
+	if v, ok := meta["mode_isian"]; ok {
+		s.ModeIsian = strings.ToLower(v)
+	}
+
+	jawaban := strings.TrimSpace(teks[buka+1 : tutup])
+	if i := indexTakLolos(jawaban, "####"); i >= 0 {
+		if pembahasan := unescapeGIFT(jawaban[i+4:]); pembahasan != "" {
+			s.Pembahasan = &pembahasan
+		}
+		jawaban = strings.TrimSpace(jawaban[:i])
+	}
+	if i := indexTakLolos(jawaban, "#"); i == 0 {
+		return s, errors.New("soal numerik GIFT belum didukung")
+	}
+
+	// Benar/salah: {T}, {TRUE}, {F}, {FALSE}, boleh diikuti umpan balik #...
+	tf := jawaban
+	if i := indexTakLolos(tf, "#"); i >= 0 {
+		tf = tf[:i]
+	}
+	switch strings.ToUpper(strings.TrimSpace(tf)) {
+	case "T", "TRUE", "F", "FALSE":
+		benar := strings.HasPrefix(strings.ToUpper(strings.TrimSpace(tf)), "T")
+		s.Tipe = TipeSoalBenarSalah
+		s.Pilihan = []models.PilihanSoal{
+			{Urutan: 1, Label: "A", Teks: "Benar", IsBenar: benar},
+			{Urutan: 2, Label: "B", Teks: "Salah", IsBenar: !benar},
+		}
+		return s, nil
+	}
+
+	if jawaban == "" {
+		s.Tipe = TipeSoalEsai
+		return s, nil
+	}
+
+	type tokenGIFT struct {
+		tanda byte
+		teks  string
+		bobot *float64
+	}
+	// Pecah jawaban per tanda = / ~ yang tidak di-escape; escape dibiarkan utuh untuk unescapeGIFT
+	var tokens []tokenGIFT
+	for i := 0; i < len(jawaban); i++ {
+		c := jawaban[i]
+		if c == '\\' && i+1 < len(jawaban) {
+			if len(tokens) > 0 {
+				tokens[len(tokens)-1].teks += jawaban[i : i+2]
+			}
+			i++
+			continue
+		}
+		if c == '=' || c == '~' {
+			tokens = append(tokens, tokenGIFT{tanda: c})
+			continue
+		}
+		if len(tokens) == 0 {
+			if strings.TrimSpace(string(c)) == "" {
+				continue
+			}
+			return s, errors.New("setiap jawaban harus diawali = atau ~")
+		}
+		tokens[len(tokens)-1].teks += string(c)
+	}
+
+	semuaSama := true
+	adaBobot := false
+	for k := range tokens {
+		t := &tokens[k]
+		if indexTakLolos(t.teks, "->") >= 0 {
+			return s, errors.New("soal menjodohkan GIFT belum didukung")
+		}
+		if i := indexTakLolos(t.teks, "#"); i >= 0 {
+			t.teks = t.teks[:i]
+		}
+		t.teks = strings.TrimSpace(t.teks)
+		if strings.HasPrefix(t.teks, "%") {
+			akhir := strings.Index(t.teks[1:], "%")
+			if akhir < 0 {
+				return s, errors.New("bobot %...% tidak ditutup")
+			}
+			bobot, err := strconv.ParseFloat(t.teks[1:1+akhir], 64)
+			if err != nil {
+				return s, fmt.Errorf("bobot %q tidak valid", t.teks[1:1+akhir])
+			}
+			t.bobot = &bobot
+			adaBobot = true
+			t.teks = strings.TrimSpace(t.teks[akhir+2:])
+		}
+		t.teks = unescapeGIFT(t.teks)
+		if t.teks == "" {
+			return s, errors.New("teks jawaban tidak boleh kosong")
+		}
+		if t.tanda != '=' {
+			semuaSama = false
+		}
+	}
+
+	// Hanya jawaban "=" tanpa "~": soal isian dengan satu atau beberapa jawaban benar
+	if semuaSama && !adaBobot {
+		kunci := make([]string, 0, len(tokens))
+		for _, t := range tokens {
+			kunci = append(kunci, t.teks)
+		}
+		gabung := strings.Join(kunci, "\n")
+		s.Tipe = TipeSoalIsian
+		s.KunciIsian = &gabung
+		return s, nil
+	}
+
+	jumlahBenar := 0
+	for k, t := range tokens {
+		benar := t.tanda == '=' || (t.bobot != nil && *t.bobot > 0)
+		if benar {
+			jumlahBenar++
+		}
+		s.Pilihan = append(s.Pilihan, models.PilihanSoal{
+			Urutan: k + 1, Label: LabelPilihan(k + 1), Teks: t.teks, IsBenar: benar,
+		})
+	}
+	s.Tipe = TipeSoalPilihanGanda
+	if jumlahBenar > 1 || adaBobot {
+		s.Tipe = TipeSoalPilihanGandaMulti
+	}
+	switch strings.ToLower(meta["tipe"]) {
+	case TipeSoalPsikotes:
+		s.Tipe = TipeSoalPsikotes
+	case TipeSoalBenarSalah:
+		s.Tipe = TipeSoalBenarSalah
+	}
+	return s, nil
+}
+
+// pilihanBenarSalahBawaan bernilai true bila teks pilihan sama dengan hasil impor {T}/{F}
+func pilihanBenarSalahBawaan(pilihan []models.PilihanSoal) bool {
+	return len(pilihan) == 2 && pilihan[0].Teks == "Benar" && pilihan[1].Teks == "Salah"
+}
+
+func TulisSoalGIFT(w io.Writer, soals []models.SoalTest) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "// Ekspor bank soal. Poin, mode isian regex & tipe psikotes disimpan sebagai komentar.")
+	for _, s := range soals {
+		fmt.Fprintln(bw)
+		fmt.Fprintf(bw, "// poin: %s\n", strconv.FormatFloat(s.Poin, 'f', -1, 64))
+		if s.Tipe == TipeSoalIsian && s.ModeIsian == ModeIsianRegex {
+			fmt.Fprintf(bw, "// mode_isian: %s\n", ModeIsianRegex)
+		}
+		bsBawaan := s.Tipe == TipeSoalBenarSalah && pilihanBenarSalahBawaan(s.Pilihan)
+		if s.Tipe == TipeSoalPsikotes || (s.Tipe == TipeSoalBenarSalah && !bsBawaan) {
+			fmt.Fprintf(bw, "// tipe: %s\n", s.Tipe)
+		}
+		fmt.Fprintf(bw, "::%d::[markdown]%s {", s.Nomor, escapeGIFT(s.Pertanyaan))
+
+		switch {
+		case bsBawaan:
+			// Pilihan pertama adalah pernyataan "Benar"
+			if s.Pilihan[0].IsBenar {
+				fmt.Fprint(bw, "T")
+			} else {
+				fmt.Fprint(bw, "F")
+			}
+
+		case s.Tipe == TipeSoalPilihanGanda, s.Tipe == TipeSoalPsikotes, s.Tipe == TipeSoalBenarSalah:
+			for _, p := range s.Pilihan {
+				tanda := "~"
+				if p.IsBenar {
+					tanda = "="
+				}
+				fmt.Fprintf(bw, "\n\t%s%s", tanda, escapeGIFT(p.Teks))
+			}
+			fmt.Fprint(bw, "\n")
+
+		case s.Tipe == TipeSoalPilihanGandaMulti:
+			jumlahBenar := 0
+			for _, p := range s.Pilihan {
+				if p.IsBenar {
+					jumlahBenar++
+				}
+			}
+			for _, p := range s.Pilihan {
+				bobot := -100.0
+				if p.IsBenar {
+					bobot = 100 / float64(jumlahBenar)
+				}
+				fmt.Fprintf(bw, "\n\t~%%%s%%%s", strconv.FormatFloat(math.Round(bobot*1e5)/1e5, 'f', -1, 64), escapeGIFT(p.Teks))
+			}
+			fmt.Fprint(bw, "\n")
+
+		case s.Tipe == TipeSoalIsian:
+			if s.KunciIsian != nil {
+				kunci := []string{*s.KunciIsian}
+				if s.ModeIsian != ModeIsianRegex {
+					kunci = strings.Split(*s.KunciIsian, "\n")
+				}
+				for _, k := range kunci {
+					if k = strings.TrimSpace(k); k != "" {
+						fmt.Fprintf(bw, "=%s ", escapeGIFT(k))
+					}
+				}
+			}
+		}
+		if s.Pembahasan != nil && strings.TrimSpace(*s.Pembahasan) != "" {
+			fmt.Fprintf(bw, " ####%s", escapeGIFT(*s.Pembahasan))
+		}
+		fmt.Fprintln(bw, "}")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"bytes"
+	"cocopen-backend/models"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pilihanRingkas menuliskan pilihan sebagai "teks" atau "teks*" (benar) agar mudah dibandingkan
+func pilihanRingkas(pilihan []models.PilihanSoal) []string {
+	var hasil []string
+	for i, p := range pilihan {
+		if p.Urutan != i+1 || p.Label != LabelPilihan(i+1) {
+			hasil = append(hasil, "urutan/label salah: "+p.Label)
+		}
+		if p.IsBenar {
+			hasil = append(hasil, p.Teks+"*")
+		} else {
+			hasil = append(hasil, p.Teks)
+		}
+	}
+	return hasil
+}
+
+func teksPtr(p *string) string {
+	if p == nil {
+		return "<nil>"
+	}
+	return *p
+}
+
+func TestBacaSoalGIFT(t *testing.T) {
+	tests := []struct {
+		nama       string
+		gift       string
+		nomor      int
+		tipe       string
+		pertanyaan string
+		pilihan    []string
+		kunci      string
+		pembahasan string
+		poin       float64
+	}{
+		{
+			nama:       "pilihan ganda",
+			gift:       "::3::Ibu kota Indonesia? {=Jakarta ~Bandung ~Surabaya}",
+			nomor:      3,
+			tipe:       TipeSoalPilihanGanda,
+			pertanyaan: "Ibu kota Indonesia?",
+			pilihan:    []string{"Jakarta*", "Bandung", "Surabaya"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "escape",
+			gift:       `Apakah 1\=1 dan \{a\} \~ b\: c? {=ya\: tentu \#1 ~tidak\\lain}`,
+			tipe:       TipeSoalPilihanGanda,
+			pertanyaan: `Apakah 1=1 dan {a} ~ b: c?`,
+			pilihan:    []string{"ya: tentu #1*", `tidak\lain`},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "escape baris baru",
+			gift:       `Baris satu\nbaris dua {}`,
+			tipe:       TipeSoalEsai,
+			pertanyaan: "Baris satu\nbaris dua",
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "multi jawaban berbobot",
+			gift:       "Pilih bilangan prima {~%50%2 ~%50%3 ~%-100%4}",
+			tipe:       TipeSoalPilihanGandaMulti,
+			pertanyaan: "Pilih bilangan prima",
+			pilihan:    []string{"2*", "3*", "4"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "multi jawaban bobot pecahan",
+			gift:       "Pilih {~%33.33333%a ~%33.33333%b ~%33.33334%c ~%-100%d}",
+			tipe:       TipeSoalPilihanGandaMulti,
+			pertanyaan: "Pilih",
+			pilihan:    []string{"a*", "b*", "c*", "d"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "benar salah dengan umpan balik",
+			gift:       "Bumi itu bulat. {TRUE#Betul}",
+			tipe:       TipeSoalBenarSalah,
+			pertanyaan: "Bumi itu bulat.",
+			pilihan:    []string{"Benar*", "Salah"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "salah",
+			gift:       "Matahari mengelilingi bumi. {F}",
+			tipe:       TipeSoalBenarSalah,
+			pertanyaan: "Matahari mengelilingi bumi.",
+			pilihan:    []string{"Benar", "Salah*"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "isian dengan alternatif dan pembahasan",
+			gift:       "Ibu kota Jawa Barat {=Bandung =Kota Bandung ####Bandung sejak 1925}",
+			tipe:       TipeSoalIsian,
+			pertanyaan: "Ibu kota Jawa Barat",
+			kunci:      "Bandung\nKota Bandung",
+			pembahasan: "Bandung sejak 1925",
+			poin:       1,
+		},
+		{
+			nama:       "isian di tengah kalimat",
+			gift:       "Proklamasi dibacakan tahun {=1945} oleh Soekarno.",
+			tipe:       TipeSoalIsian,
+			pertanyaan: "Proklamasi dibacakan tahun _____ oleh Soekarno.",
+			kunci:      "1945",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+		{
+			nama:       "meta poin dan psikotes",
+			gift:       "// poin: 2.5\n// tipe: psikotes\nSaya suka keramaian {~Setuju ~Tidak setuju}",
+			tipe:       TipeSoalPsikotes,
+			pertanyaan: "Saya suka keramaian",
+			pilihan:    []string{"Setuju", "Tidak setuju"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       2.5,
+		},
+		{
+			nama:       "benar salah dengan teks sendiri",
+			gift:       "// tipe: benar_salah\nAir mendidih pada 100 C {=Ya ~Tidak}",
+			tipe:       TipeSoalBenarSalah,
+			pertanyaan: "Air mendidih pada 100 C",
+			pilihan:    []string{"Ya*", "Tidak"},
+			kunci:      "<nil>",
+			pembahasan: "<nil>",
+			poin:       1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.nama, func(t *testing.T) {
+			items, kesalahan, err := BacaSoalGIFT(strings.NewReader(tc.gift))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kesalahan) > 0 || len(items) != 1 {
+				t.Fatalf("items %d, kesalahan %+v", len(items), kesalahan)
+			}
+			s := items[0].Soal
+			if s.Nomor != tc.nomor || s.Tipe != tc.tipe || s.Pertanyaan != tc.pertanyaan || s.Poin != tc.poin {
+				t.Errorf("soal = nomor %d tipe %q pertanyaan %q poin %v", s.Nomor, s.Tipe, s.Pertanyaan, s.Poin)
+			}
+			if got := pilihanRingkas(s.Pilihan); !reflect.DeepEqual(got, tc.pilihan) {
+				t.Errorf("pilihan = %q, ingin %q", got, tc.pilihan)
+			}
+			if got := teksPtr(s.KunciIsian); got != tc.kunci {
+				t.Errorf("kunci isian = %q, ingin %q", got, tc.kunci)
+			}
+			if got := teksPtr(s.Pembahasan); got != tc.pembahasan {
+				t.Errorf("pembahasan = %q, ingin %q", got, tc.pembahasan)
+			}
+		})
+	}
+}
+
+func TestBacaSoalGIFTKesalahanPerBlok(t *testing.T) {
+	gift := "$CATEGORY: tahap1\n\n" +
+		"Soal valid {=a ~b}\n\n" +
+		"Numerik {#3:1}\n\n" +
+		"// komentar biasa\n" +
+		"Menjodohkan {=a -> 1 =b -> 2}\n\n" +
+		"Tanpa jawaban\n\n" +
+		"Bobot rusak {~%abc%a ~b}\n\n" +
+		"Tidak ditutup {=a ~b\n"
+
+	items, kesalahan, err := BacaSoalGIFT(strings.NewReader(gift))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Baris != 3 {
+		t.Fatalf("items = %+v, ingin satu soal di baris 3", items)
+	}
+	var baris []int
+	for _, k := range kesalahan {
+		baris = append(baris, k.Baris)
+	}
+	if want := []int{5, 8, 10, 12, 14}; !reflect.DeepEqual(baris, want) {
+		t.Errorf("baris kesalahan = %v, ingin %v (%+v)", baris, want, kesalahan)
+	}
+}
+
+func TestTulisBacaSoalGIFTBolakBalik(t *testing.T) {
+	pembahasan := "Lihat {rumus} a=b"
+	kunci := "Jakarta\nDKI Jakarta"
+	soals := []models.SoalTest{
+		{Nomor: 1, Tipe: TipeSoalPilihanGanda, Poin: 2, Pertanyaan: "Hasil 2~3: berapa?", Pembahasan: &pembahasan,
+			Pilihan: []models.PilihanSoal{
+				{Urutan: 1, Label: "A", Teks: "#1", IsBenar: true},
+				{Urutan: 2, Label: "B", Teks: `a\b`},
+			}},
+		{Nomor: 2, Tipe: TipeSoalPilihanGandaMulti, Poin: 1, Pertanyaan: "Pilih tiga",
+			Pilihan: []models.PilihanSoal{
+				{Urutan: 1, Label: "A", Teks: "a", IsBenar: true},
+				{Urutan: 2, Label: "B", Teks: "b", IsBenar: true},
+				{Urutan: 3, Label: "C", Teks: "c", IsBenar: true},
+				{Urutan: 4, Label: "D", Teks: "d"},
+			}},
+		{Nomor: 3, Tipe: TipeSoalBenarSalah, Poin: 1, Pertanyaan: "Benar?",
+			Pilihan: []models.PilihanSoal{
+				{Urutan: 1, Label: "A", Teks: "Benar"},
+				{Urutan: 2, Label: "B", Teks: "Salah", IsBenar: true},
+			}},
+		{Nomor: 4, Tipe: TipeSoalIsian, Poin: 1, Pertanyaan: "Ibu kota?", KunciIsian: &kunci, ModeIsian: ModeIsianPersis},
+		{Nomor: 5, Tipe: TipeSoalEsai, Poin: 5, Pertanyaan: "Jelaskan\nsecara singkat"},
+	}
+
+	var buf bytes.Buffer
+	if err := TulisSoalGIFT(&buf, soals); err != nil {
+		t.Fatal(err)
+	}
+	items, kesalahan, err := BacaSoalGIFT(&buf)
+	if err != nil || len(kesalahan) > 0 {
+		t.Fatalf("err %v, kesalahan %+v\n%s", err, kesalahan, buf.String())
+	}
+	if len(items) != len(soals) {
+		t.Fatalf("jumlah soal %d, ingin %d", len(items), len(soals))
+	}
+	for i, it := range items {
+		asli, s := soals[i], it.Soal
+		if s.Nomor != asli.Nomor || s.Tipe != asli.Tipe || s.Poin != asli.Poin || s.Pertanyaan != asli.Pertanyaan {
+			t.Errorf("soal %d = %+v, ingin %+v", i+1, s, asli)
+		}
+		if !reflect.DeepEqual(pilihanRingkas(s.Pilihan), pilihanRingkas(asli.Pilihan)) {
+			t.Errorf("soal %d pilihan = %q, ingin %q", i+1, pilihanRingkas(s.Pilihan), pilihanRingkas(asli.Pilihan))
+		}
+		if teksPtr(s.KunciIsian) != teksPtr(asli.KunciIsian) || teksPtr(s.Pembahasan) != teksPtr(asli.Pembahasan) {
+			t.Errorf("soal %d kunci/pembahasan = %q/%q", i+1, teksPtr(s.KunciIsian), teksPtr(s.Pembahasan))
+		}
+	}
+}
+
+func TestBacaSoalCSV(t *testing.T) {
+	csv := "\ufeffNomor,Tipe,Poin,Pertanyaan,Pilihan_A,Pilihan_B,Pilihan_C,Benar,Kunci_Isian,Pembahasan\n" +
+		`1,,"1,5","Jika x = 2, maka 2x = ?","4","2, atau 3",5,A,,"Kalikan: 2 × 2, hasilnya 4"` + "\n" +
+		`2,,,"Pilih ""genap""",2,3,4,"A, C",,` + "\n" +
+		`3,,,Ibu kota?,,,,,"Jakarta",` + "\n" +
+		`,,,,,,,,,` + "\n" +
+		`4,,,Esai singkat` + "\n" +
+		`5,,,Pilihan bolong,a,,c,A,,` + "\n" +
+		`6,,,Kunci salah,a,b,,C,,` + "\n" +
+		`x,,,Nomor salah,a,b,,A,,` + "\n" +
+		`7,,dua,Poin salah,a,b,,A,,` + "\n"
+
+	items, kesalahan, err := BacaSoalCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type hasil struct {
+		baris      int
+		nomor      int
+		tipe       string
+		poin       float64
+		pertanyaan string
+		pilihan    []string
+		kunci      string
+		pembahasan string
+	}
+	var got []hasil
+	for _, it := range items {
+		s := it.Soal
+		got = append(got, hasil{it.Baris, s.Nomor, s.Tipe, s.Poin, s.Pertanyaan, pilihanRingkas(s.Pilihan),
+			teksPtr(s.KunciIsian), teksPtr(s.Pembahasan)})
+	}
+	want := []hasil{
+		{2, 1, TipeSoalPilihanGanda, 1.5, "Jika x = 2, maka 2x = ?", []string{"4*", "2, atau 3", "5"}, "<nil>", "Kalikan: 2 × 2, hasilnya 4"},
+		{3, 2, TipeSoalPilihanGandaMulti, 1, `Pilih "genap"`, []string{"2*", "3", "4*"}, "<nil>", "<nil>"},
+		{4, 3, TipeSoalIsian, 1, "Ibu kota?", nil, "Jakarta", "<nil>"},
+		{6, 4, TipeSoalEsai, 1, "Esai singkat", nil, "<nil>", "<nil>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("soal =\n%+v\ningin\n%+v", got, want)
+	}
+
+	var baris []int
+	for _, k := range kesalahan {
+		baris = append(baris, k.Baris)
+	}
+	if w := []int{7, 8, 9, 10}; !reflect.DeepEqual(baris, w) {
+		t.Errorf("baris kesalahan = %v, ingin %v (%+v)", baris, w, kesalahan)
+	}
+}
+
+func TestBacaSoalCSVBerkasTidakValid(t *testing.T) {
+	for nama, csv := range map[string]string{
+		"kosong":           "",
+		"tanpa pertanyaan": "nomor,pilihan_a\n1,a\n",
+		"kutip tak tutup":  "pertanyaan\n\"tidak ditutup\n",
+	} {
+		if _, _, err := BacaSoalCSV(strings.NewReader(csv)); err == nil {
+			t.Errorf("%s: berkas diterima", nama)
+		}
+	}
+}
+
+func TestTulisBacaSoalCSVBolakBalik(t *testing.T) {
+	pembahasan := "Baris 1\nbaris 2, dengan koma"
+	soals := []models.SoalTest{
+		{Nomor: 1, Tipe: TipeSoalPilihanGandaMulti, Poin: 2.5, Pertanyaan: `Kutip "ganda", koma`, Pembahasan: &pembahasan,
+			Pilihan: []models.PilihanSoal{
+				{Urutan: 1, Label: "A", Teks: "a, b", IsBenar: true},
+				{Urutan: 2, Label: "B", Teks: "c"},
+				{Urutan: 3, Label: "C", Teks: `"d"`, IsBenar: true},
+			}},
+	}
+	var buf bytes.Buffer
+	if err := TulisSoalCSV(&buf, soals); err != nil {
+		t.Fatal(err)
+	}
+	items, kesalahan, err := BacaSoalCSV(&buf)
+	if err != nil || len(kesalahan) > 0 || len(items) != 1 {
+		t.Fatalf("err %v, kesalahan %+v, items %d", err, kesalahan, len(items))
+	}
+	s := items[0].Soal
+	if s.Pertanyaan != soals[0].Pertanyaan || s.Poin != 2.5 || s.Tipe != TipeSoalPilihanGandaMulti ||
+		teksPtr(s.Pembahasan) != pembahasan {
+		t.Errorf("soal = %+v", s)
+	}
+	if got, want := pilihanRingkas(s.Pilihan), pilihanRingkas(soals[0].Pilihan); !reflect.DeepEqual(got, want) {
+		t.Errorf("pilihan = %q, ingin %q", got, want)
+	}
+}
+
+func TestBacaSoalJSON(t *testing.T) {
+	items, _, err := BacaSoalJSON(strings.NewReader(`[
+		{"pertanyaan": "  Pilih  ", "pilihan": [{"teks": "a", "is_benar": true}, {"teks": "b", "is_benar": true}]},
+		{"pertanyaan": "Isi", "kunci_isian": "x", "poin": 3},
+		{"pertanyaan": "Gambar ![](gambar:7)", "gambar": [{"id": 7, "nama_asli": "a.png", "data": "iVBORw=="}]}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("jumlah soal %d", len(items))
+	}
+	if s := items[0].Soal; s.Pertanyaan != "Pilih" || s.Tipe != TipeSoalPilihanGandaMulti || s.Poin != 1 {
+		t.Errorf("soal 1 = %+v", s)
+	}
+	if s := items[1].Soal; s.Tipe != TipeSoalIsian || s.Poin != 3 || s.ModeIsian != ModeIsianPersis {
+		t.Errorf("soal 2 = %+v", s)
+	}
+	if g := items[2].Gambar; len(g) != 1 || g[0].IDGambar != 7 || g[0].NamaAsli != "a.png" || len(g[0].Data) != 4 {
+		t.Errorf("gambar soal 3 = %+v", g)
+	}
+
+	for _, tidakValid := range []string{`{"pertanyaan": "bukan array"}`, `[{"pertanyaan": "x", "kolom_asing": 1}]`} {
+		if _, _, err := BacaSoalJSON(strings.NewReader(tidakValid)); err == nil {
+			t.Errorf("JSON %s diterima", tidakValid)
+		}
+	}
+}
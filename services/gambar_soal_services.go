@@ -0,0 +1,138 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrGambarMasihDipakai = errors.New("gambar masih dirujuk oleh soal")
+
+func CreateGambarSoal(db *sql.DB, g *models.GambarSoal) error {
+	res, err := db.Exec(
+		`INSERT INTO gambar_soal (nama_file, nama_asli, ukuran, diunggah_oleh) VALUES (?, ?, ?, ?)`,
+		g.NamaFile, g.NamaAsli, g.Ukuran, g.DiunggahOleh,
+	)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	g.IDGambar = int(id)
+	return nil
+}
+
+func scanGambarSoal(row interface{ Scan(...any) error }) (models.GambarSoal, error) {
+	var g models.GambarSoal
+	var oleh sql.NullInt64
+	if err := row.Scan(&g.IDGambar, &g.NamaFile, &g.NamaAsli, &g.Ukuran, &oleh, &g.CreatedAt); err != nil {
+		return g, err
+	}
+	if oleh.Valid {
+		id := int(oleh.Int64)
+		g.DiunggahOleh = &id
+	}
+	return g, nil
+}
+
+const selectGambarSoal = `SELECT id_gambar, nama_file, nama_asli, ukuran, diunggah_oleh, created_at FROM gambar_soal`
+
+func GetGambarSoalByID(db *sql.DB, idGambar int) (*models.GambarSoal, error) {
+	g, err := scanGambarSoal(db.QueryRow(selectGambarSoal+` WHERE id_gambar = ?`, idGambar))
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func GetAllGambarSoal(db *sql.DB) ([]models.GambarSoal, error) {
+	rows, err := db.Query(selectGambarSoal + ` ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.GambarSoal{}
+	for rows.Next() {
+		g, err := scanGambarSoal(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, g)
+	}
+	return list, rows.Err()
+}
+
+// DeleteGambarSoal menghapus data gambar yang tidak lagi dirujuk soal maupun pilihan,
+// termasuk versi lama soal yang masih ditampilkan di pembahasan hasil tes
+func DeleteGambarSoal(db *sql.DB, g *models.GambarSoal) error {
+	var dipakai bool
+	// Sama dengan pola utils.IDGambarMarkdown: "(gambar:ID" tidak diikuti digit lain
+	pola := "[(][[:space:]]*" + utils.SkemaGambarSoal + strconv.Itoa(g.IDGambar) + "([^0-9]|$)"
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM soal_test WHERE pertanyaan REGEXP ?)
+			OR EXISTS(SELECT 1 FROM pilihan_soal WHERE teks REGEXP ?)
+			OR EXISTS(SELECT 1 FROM versi_soal WHERE pertanyaan REGEXP ?)
+			OR EXISTS(SELECT 1 FROM pilihan_versi_soal WHERE teks REGEXP ?)
+	`, pola, pola, pola, pola).Scan(&dipakai)
+	if err != nil {
+		return err
+	}
+	if dipakai {
+		return ErrGambarMasihDipakai
+	}
+
+	if _, err := db.Exec(`DELETE FROM gambar_soal WHERE id_gambar = ?`, g.IDGambar); err != nil {
+		return err
+	}
+	return utils.HapusFoto(utils.GambarSoalPath, g.NamaFile)
+}
+
+// GambarSoalTidakDikenal mengembalikan id gambar yang dirujuk soal tetapi tidak ada di gambar_soal
+func GambarSoalTidakDikenal(db *sql.DB, s *models.SoalTest) ([]int, error) {
+	ids := utils.IDGambarMarkdown(s.Pertanyaan)
+	for _, p := range s.Pilihan {
+		ids = append(ids, utils.IDGambarMarkdown(p.Teks)...)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	rows, err := db.Query(`SELECT id_gambar FROM gambar_soal WHERE id_gambar IN (`+strings.Join(placeholders, ",")+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ada := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ada[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var hilang []int
+	for _, id := range ids {
+		if !ada[id] {
+			hilang = append(hilang, id)
+			ada[id] = true
+		}
+	}
+	return hilang, nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MinPanjangPertanyaan sama dengan validasi body pembuatan soal satu per satu
+const MinPanjangPertanyaan = 10
+
+// MaxUkuranGambarSoal sama dengan batas unggah gambar soal
+const MaxUkuranGambarSoal = 2 << 20
+
+var (
+	ErrEksporGambarTidakDidukung = errors.New("soal merujuk gambar; hanya format json yang membawa berkas gambar")
+	ErrEksporBobotPsikotes       = errors.New("soal psikotes memiliki bobot dimensi yang tidak ikut diekspor " +
+		"(bobot tersimpan per test di /test/admin/psikotes); ulangi dengan abaikan_bobot_psikotes=true " +
+		"lalu atur ulang bobot setelah impor")
+)
+
+var ekstensiGambarSoal = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true}
+
+// validasiBerkasGambarSoal menerapkan aturan yang sama dengan unggah gambar soal
+func validasiBerkasGambarSoal(g models.BerkasGambarSoal) error {
+	if !ekstensiGambarSoal[strings.ToLower(filepath.Ext(g.NamaAsli))] {
+		return fmt.Errorf("gambar %d: format tidak didukung (hanya .jpg, .jpeg, .png, .gif, .webp)", g.IDGambar)
+	}
+	if len(g.Data) == 0 || len(g.Data) > MaxUkuranGambarSoal {
+		return fmt.Errorf("gambar %d: ukuran harus 1 byte sampai 2 MB", g.IDGambar)
+	}
+	if !strings.HasPrefix(http.DetectContentType(g.Data), "image/") {
+		return fmt.Errorf("gambar %d: isi berkas bukan gambar", g.IDGambar)
+	}
+	return nil
+}
+
+// SiapkanEksporSoal memastikan bank soal bisa diekspor utuh ke format yang diminta dan memuat
+// berkas gambar yang dirujuk. CSV dan GIFT tidak bisa membawa gambar, dan bobot psikotes
+// (milik model psikotes per test) tidak ikut di format mana pun.
+func SiapkanEksporSoal(db *sql.DB, format string, soals []models.SoalTest, abaikanBobot bool) (map[int]models.BerkasGambarSoal, error) {
+	if format != FormatSoalCSV && format != FormatSoalJSON && format != FormatSoalGIFT {
+		return nil, ErrFormatSoalTidakDikenal
+	}
+	if !abaikanBobot {
+		var ids []int
+		for _, s := range soals {
+			if s.Tipe == TipeSoalPsikotes {
+				ids = append(ids, s.IDSoal)
+			}
+		}
+		if len(ids) > 0 {
+			var adaBobot bool
+			placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+			args := make([]any, len(ids))
+			for i, id := range ids {
+				args[i] = id
+			}
+			if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM bobot_psikotes WHERE id_soal IN (`+placeholders+`))`, args...).Scan(&adaBobot); err != nil {
+				return nil, err
+			}
+			if adaBobot {
+				return nil, ErrEksporBobotPsikotes
+			}
+		}
+	}
+
+	var ids []int
+	for i := range soals {
+		ids = append(ids, IDGambarSoal(&soals[i])...)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if format != FormatSoalJSON {
+		return nil, ErrEksporGambarTidakDidukung
+	}
+
+	gambar := map[int]models.BerkasGambarSoal{}
+	for _, id := range ids {
+		if _, ok := gambar[id]; ok {
+			continue
+		}
+		g, err := GetGambarSoalByID(db, id)
+		if err == sql.ErrNoRows {
+			// Rujukan rusak tetap diekspor apa adanya; impor akan menolaknya
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(filepath.Join(utils.GambarSoalPath, g.NamaFile))
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca berkas gambar %d: %v", id, err)
+		}
+		gambar[id] = models.BerkasGambarSoal{IDGambar: id, NamaAsli: g.NamaAsli, Data: data}
+	}
+	return gambar, nil
+}
+
+// ValidasiSoalImpor memeriksa setiap soal impor dan mengisi nomor yang kosong dengan nomor
+// berikutnya setelah nomor terbesar di bank soal maupun di berkas.
+func ValidasiSoalImpor(db *sql.DB, items []models.SoalImpor) ([]models.KesalahanImporSoal, error) {
+	var nomorTerakhir int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(nomor), 0) FROM soal_test`).Scan(&nomorTerakhir); err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		if it.Soal.Nomor > nomorTerakhir {
+			nomorTerakhir = it.Soal.Nomor
+		}
+	}
+
+	// Gambar yang dibawa berkas impor tidak perlu ada di bank tujuan
+	dibawa := map[int]bool{}
+	for _, it := range items {
+		for _, g := range it.Gambar {
+			dibawa[g.IDGambar] = true
+		}
+	}
+
+	var kesalahan []models.KesalahanImporSoal
+	for i := range items {
+		s := &items[i].Soal
+		if s.Nomor == 0 {
+			nomorTerakhir++
+			s.Nomor = nomorTerakhir
+		}
+
+		pesan := ""
+		if utf8.RuneCountInString(strings.TrimSpace(s.Pertanyaan)) < MinPanjangPertanyaan {
+			pesan = fmt.Sprintf("pertanyaan minimal %d karakter", MinPanjangPertanyaan)
+		} else if err := ValidasiSoal(s); err != nil {
+			pesan = err.Error()
+		} else if err := validasiGambarImpor(items[i].Gambar); err != nil {
+			pesan = err.Error()
+		} else if hilang, err := GambarSoalTidakDikenal(db, s); err != nil {
+			return nil, err
+		} else if hilang = idTanpaDibawa(hilang, dibawa); len(hilang) > 0 {
+			pesan = fmt.Sprintf("gambar %v tidak ditemukan", hilang)
+		}
+		if pesan != "" {
+			kesalahan = append(kesalahan, models.KesalahanImporSoal{Baris: items[i].Baris, Pesan: pesan})
+		}
+	}
+	return kesalahan, nil
+}
+
+func validasiGambarImpor(gambar []models.BerkasGambarSoal) error {
+	for _, g := range gambar {
+		if err := validasiBerkasGambarSoal(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func idTanpaDibawa(ids []int, dibawa map[int]bool) []int {
+	var sisa []int
+	for _, id := range ids {
+		if !dibawa[id] {
+			sisa = append(sisa, id)
+		}
+	}
+	return sisa
+}
+
+// ImporSoal menyimpan seluruh soal dalam satu transaksi: gagal satu, batal semua.
+// Gambar yang dibawa berkas disimpan sebagai gambar baru dan rujukan gambar:ID di soal
+// diganti ke id barunya.
+func ImporSoal(db *sql.DB, items []models.SoalImpor, adminID int) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var berkasBaru []string
+	defer func() {
+		if err != nil {
+			for _, nama := range berkasBaru {
+				utils.HapusFoto(utils.GambarSoalPath, nama)
+			}
+		}
+	}()
+
+	petaGambar := map[int]int{}
+	for _, it := range items {
+		for _, g := range it.Gambar {
+			if _, ok := petaGambar[g.IDGambar]; ok {
+				continue
+			}
+			namaFile, err := utils.SimpanBerkas(g.Data, g.NamaAsli, utils.GambarSoalPath)
+			if err != nil {
+				return fmt.Errorf("baris %d: gagal menyimpan gambar %d: %v", it.Baris, g.IDGambar, err)
+			}
+			berkasBaru = append(berkasBaru, namaFile)
+
+			res, err := tx.Exec(
+				`INSERT INTO gambar_soal (nama_file, nama_asli, ukuran, diunggah_oleh) VALUES (?, ?, ?, ?)`,
+				namaFile, g.NamaAsli, len(g.Data), adminID,
+			)
+			if err != nil {
+				return fmt.Errorf("baris %d: %v", it.Baris, err)
+			}
+			id, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			petaGambar[g.IDGambar] = int(id)
+		}
+	}
+
+	for _, it := range items {
+		if len(petaGambar) > 0 {
+			gantiGambarSoal(&it.Soal, petaGambar)
+		}
+		if err := insertSoal(tx, it.Soal, adminID); err != nil {
+			return fmt.Errorf("baris %d: %v", it.Baris, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// gantiGambarSoal mengganti rujukan gambar di salinan soal impor
+func gantiGambarSoal(s *models.SoalTest, peta map[int]int) {
+	s.Pertanyaan = utils.GantiIDGambarMarkdown(s.Pertanyaan, peta)
+	pilihan := make([]models.PilihanSoal, len(s.Pilihan))
+	for i, p := range s.Pilihan {
+		p.Teks = utils.GantiIDGambarMarkdown(p.Teks, peta)
+		pilihan[i] = p
+	}
+	s.Pilihan = pilihan
+	if s.Pembahasan != nil {
+		pembahasan := utils.GantiIDGambarMarkdown(*s.Pembahasan, peta)
+		s.Pembahasan = &pembahasan
+	}
+}
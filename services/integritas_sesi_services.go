@@ -0,0 +1,218 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"fmt"
+)
+
+// Jenis event integritas sesi tes
+const (
+	EventFokusHilang      = "fokus_hilang"
+	EventTabTersembunyi   = "tab_tersembunyi"
+	EventSalin            = "salin"
+	EventTempel           = "tempel"
+	EventPotong           = "potong"
+	EventKeluarLayarPenuh = "keluar_layar_penuh"
+	EventKlikKanan        = "klik_kanan"
+	// Dicatat server saat sesi dilanjutkan dari IP/perangkat berbeda
+	EventGantiPerangkat = "ganti_perangkat"
+
+	// Batas event per sesi agar klien tidak bisa membanjiri tabel
+	MaxEventPerSesi = 2000
+	// Fokus hilang/tab tersembunyi sebanyak ini atau lebih ditandai
+	BatasEventFokus = 3
+)
+
+// Tanda integritas sesi pada ringkasan admin
+const (
+	TandaIPBerubah         = "ip_berubah"
+	TandaPerangkatBerubah  = "perangkat_berubah"
+	TandaSeringKeluarFokus = "sering_keluar_fokus"
+	TandaSalinTempel       = "salin_tempel"
+	TandaKeluarLayarPenuh  = "keluar_layar_penuh"
+)
+
+var ErrEventSesiPenuh = fmt.Errorf("jumlah event sesi melebihi batas %d", MaxEventPerSesi)
+
+// CatatPerangkatMulai menyimpan IP & user agent saat sesi pertama dimulai. Bila sesi dilanjutkan
+// dari IP atau perangkat lain, event ganti_perangkat dicatat tanpa menimpa data awal.
+func CatatPerangkatMulai(db *sql.DB, idHasil int, ip, ua string) error {
+	var ipMulai, uaMulai sql.NullString
+	err := db.QueryRow(`SELECT ip_mulai, ua_mulai FROM hasil_test WHERE id_hasil = ?`, idHasil).Scan(&ipMulai, &uaMulai)
+	if err != nil {
+		return err
+	}
+	if !ipMulai.Valid {
+		_, err := db.Exec(`UPDATE hasil_test SET ip_mulai = ?, ua_mulai = ? WHERE id_hasil = ? AND ip_mulai IS NULL`, ip, ua, idHasil)
+		return err
+	}
+	if ipMulai.String == ip && uaMulai.String == ua {
+		return nil
+	}
+
+	detail := utils.PotongTeks(fmt.Sprintf("ip=%s ua=%s", ip, ua), 255)
+	_, err = db.Exec(`
+		INSERT INTO sesi_event (id_hasil, jenis, detail, waktu_klien) VALUES (?, ?, ?, UTC_TIMESTAMP(3))
+	`, idHasil, EventGantiPerangkat, detail)
+	return err
+}
+
+// CatatPerangkatSubmit menyimpan IP & user agent saat peserta submit
+func CatatPerangkatSubmit(db *sql.DB, idHasil int, ip, ua string) error {
+	_, err := db.Exec(`UPDATE hasil_test SET ip_submit = ?, ua_submit = ? WHERE id_hasil = ?`, ip, ua, idHasil)
+	return err
+}
+
+// SimpanEventSesi menyimpan sekumpulan event dari klien dalam satu transaksi
+func SimpanEventSesi(db *sql.DB, idHasil int, events []models.EventIntegritas) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := kunciSesiBerlangsung(tx, idHasil); err != nil {
+		return err
+	}
+
+	var jumlah int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM sesi_event WHERE id_hasil = ?`, idHasil).Scan(&jumlah); err != nil {
+		return err
+	}
+	if jumlah+len(events) > MaxEventPerSesi {
+		return ErrEventSesiPenuh
+	}
+
+	for _, e := range events {
+		_, err := tx.Exec(`
+			INSERT INTO sesi_event (id_hasil, jenis, detail, waktu_klien) VALUES (?, ?, ?, ?)
+		`, idHasil, e.Jenis, e.Detail, e.WaktuKlien.UTC())
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetEventSesi mengambil seluruh event integritas sebuah sesi sesuai urutan waktu
+func GetEventSesi(db *sql.DB, idHasil int) ([]models.EventIntegritas, error) {
+	rows, err := db.Query(`
+		SELECT id_event, id_hasil, jenis, detail, waktu_klien, diterima_at
+		FROM sesi_event
+		WHERE id_hasil = ?
+		ORDER BY waktu_klien, id_event
+	`, idHasil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.EventIntegritas{}
+	for rows.Next() {
+		var e models.EventIntegritas
+		var detail sql.NullString
+		if err := rows.Scan(&e.IDEvent, &e.IDHasil, &e.Jenis, &detail, &e.WaktuKlien, &e.DiterimaAt); err != nil {
+			return nil, err
+		}
+		if detail.Valid {
+			e.Detail = &detail.String
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// GetRingkasanIntegritasTest merangkum sinyal integritas setiap sesi sebuah tes, per id_hasil
+func GetRingkasanIntegritasTest(db *sql.DB, idTest int) (map[int]*models.RingkasanIntegritas, error) {
+	rows, err := db.Query(`
+		SELECT id_hasil, ip_mulai, ua_mulai, ip_submit, ua_submit
+		FROM hasil_test
+		WHERE id_test = ?
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	ringkasan := map[int]*models.RingkasanIntegritas{}
+	for rows.Next() {
+		var idHasil int
+		var ipMulai, uaMulai, ipSubmit, uaSubmit sql.NullString
+		if err := rows.Scan(&idHasil, &ipMulai, &uaMulai, &ipSubmit, &uaSubmit); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ringkasan[idHasil] = &models.RingkasanIntegritas{
+			IPMulai:     nullStringPtr(ipMulai),
+			UAMulai:     nullStringPtr(uaMulai),
+			IPSubmit:    nullStringPtr(ipSubmit),
+			UASubmit:    nullStringPtr(uaSubmit),
+			JumlahEvent: map[string]int{},
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT e.id_hasil, e.jenis, COUNT(*)
+		FROM sesi_event e
+		INNER JOIN hasil_test ht ON ht.id_hasil = e.id_hasil
+		WHERE ht.id_test = ?
+		GROUP BY e.id_hasil, e.jenis
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idHasil, jumlah int
+		var jenis string
+		if err := rows.Scan(&idHasil, &jenis, &jumlah); err != nil {
+			return nil, err
+		}
+		if r, ok := ringkasan[idHasil]; ok {
+			r.JumlahEvent[jenis] = jumlah
+			r.TotalEvent += jumlah
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range ringkasan {
+		r.Tanda = tandaIntegritas(r)
+	}
+	return ringkasan, nil
+}
+
+// tandaIntegritas menurunkan tanda dari ringkasan; keputusan tetap di tangan peninjau
+func tandaIntegritas(r *models.RingkasanIntegritas) []string {
+	tanda := []string{}
+	beda := func(a, b *string) bool { return a != nil && b != nil && *a != *b }
+
+	if beda(r.IPMulai, r.IPSubmit) {
+		tanda = append(tanda, TandaIPBerubah)
+	}
+	if beda(r.UAMulai, r.UASubmit) || r.JumlahEvent[EventGantiPerangkat] > 0 {
+		tanda = append(tanda, TandaPerangkatBerubah)
+	}
+	if r.JumlahEvent[EventFokusHilang]+r.JumlahEvent[EventTabTersembunyi] >= BatasEventFokus {
+		tanda = append(tanda, TandaSeringKeluarFokus)
+	}
+	if r.JumlahEvent[EventSalin]+r.JumlahEvent[EventTempel]+r.JumlahEvent[EventPotong] > 0 {
+		tanda = append(tanda, TandaSalinTempel)
+	}
+	if r.JumlahEvent[EventKeluarLayarPenuh] > 0 {
+		tanda = append(tanda, TandaKeluarLayarPenuh)
+	}
+	return tanda
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
@@ -3,300 +3,348 @@ package services
 
 import (
 	"cocopen-backend/models"
+	"cocopen-backend/utils"
 	"database/sql"
+	"errors"
+	"fmt"
 	"strconv"
 	"time"
 )
 
+var ErrJadwalDipakaiPengumuman = errors.New("jadwal masih terkait dengan pengumuman; ubah atau hapus pengumumannya terlebih dahulu")
+
 func scanRowToJadwal(row *sql.Row) (*models.Jadwal, error) {
-    var j models.Jadwal
-    var pendaftarID sql.NullInt64
-    var catatan, alasan, jenisJadwal sql.NullString
-    var tanggalD sql.NullTime
-    var jamMulaiD, jamSelesaiD sql.NullString
-
-    err := row.Scan(
-        &j.IDJadwal,
-        &j.UserID,
-        &pendaftarID,
-        &j.Tanggal,
-        &j.JamMulai,
-        &j.JamSelesai,
-        &j.Tempat,
-        &j.KonfirmasiJadwal,
-        &catatan,
-        &j.PengajuanPerubahan,
-        &alasan,
-        &tanggalD,
-        &jamMulaiD,
-        &jamSelesaiD,
-        &jenisJadwal,
-        &j.CreatedAt,
-        &j.UpdatedAt,
-    )
-    if err != nil {
-        return nil, err
-    }
-
-    if pendaftarID.Valid {
-        id := int(pendaftarID.Int64)
-        j.PendaftarID = &id
-    }
-    if catatan.Valid {
-        j.Catatan = &catatan.String
-    }
-    if alasan.Valid {
-        j.AlasanPerubahan = &alasan.String
-    }
-    if tanggalD.Valid {
-        j.TanggalDiajukan = &tanggalD.Time
-    }
-    if jamMulaiD.Valid {
-        j.JamMulaiDiajukan = &jamMulaiD.String
-    }
-    if jamSelesaiD.Valid {
-        j.JamSelesaiDiajukan = &jamSelesaiD.String
-    }
-    if jenisJadwal.Valid {
-        j.JenisJadwal = jenisJadwal.String
-    } else {
-        j.JenisJadwal = "pribadi"
-    }
-
-    return &j, nil
+	var j models.Jadwal
+	var pendaftarID sql.NullInt64
+	var catatan, alasan, jenisJadwal sql.NullString
+	var mulaiD, selesaiD sql.NullTime
+	var kapasitas sql.NullInt64
+	var batasPendaftaran sql.NullTime
+
+	err := row.Scan(
+		&j.IDJadwal,
+		&j.UserID,
+		&pendaftarID,
+		&j.WaktuMulai,
+		&j.WaktuSelesai,
+		&j.ZonaWaktu,
+		&j.Tempat,
+		&j.KonfirmasiJadwal,
+		&catatan,
+		&j.PengajuanPerubahan,
+		&alasan,
+		&mulaiD,
+		&selesaiD,
+		&jenisJadwal,
+		&kapasitas,
+		&batasPendaftaran,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if pendaftarID.Valid {
+		id := int(pendaftarID.Int64)
+		j.PendaftarID = &id
+	}
+	if catatan.Valid {
+		j.Catatan = &catatan.String
+	}
+	if alasan.Valid {
+		j.AlasanPerubahan = &alasan.String
+	}
+	if mulaiD.Valid {
+		j.WaktuMulaiDiajukan = &mulaiD.Time
+	}
+	if selesaiD.Valid {
+		j.WaktuSelesaiDiajukan = &selesaiD.Time
+	}
+	if jenisJadwal.Valid {
+		j.JenisJadwal = jenisJadwal.String
+	} else {
+		j.JenisJadwal = "pribadi"
+	}
+	if kapasitas.Valid {
+		k := int(kapasitas.Int64)
+		j.Kapasitas = &k
+	}
+	if batasPendaftaran.Valid {
+		j.BatasPendaftaran = &batasPendaftaran.Time
+	}
+
+	return &j, nil
 }
 
 func scanJadwalRows(rows *sql.Rows) ([]models.Jadwal, error) {
-    var jadwals []models.Jadwal
-
-    for rows.Next() {
-        var j models.Jadwal
-        var pendaftarID sql.NullInt64
-        var catatan, alasan, jenisJadwal, userNama sql.NullString
-        var tanggalD, createdAt, updatedAt sql.NullTime
-        var jamMulaiD, jamSelesaiD sql.NullString
-
-        err := rows.Scan(
-            &j.IDJadwal,
-            &j.UserID,
-            &pendaftarID,
-            &j.Tanggal,
-            &j.JamMulai,
-            &j.JamSelesai,
-            &j.Tempat,
-            &j.KonfirmasiJadwal,
-            &catatan,
-            &j.PengajuanPerubahan,
-            &alasan,
-            &tanggalD,
-            &jamMulaiD,
-            &jamSelesaiD,
-            &jenisJadwal,
-            &createdAt,
-            &updatedAt,
-            &userNama,
-        )
-        if err != nil {
-            return nil, err
-        }
-
-        if createdAt.Valid {
-            j.CreatedAt = createdAt.Time
-        }
-        if updatedAt.Valid {
-            j.UpdatedAt = updatedAt.Time
-        }
-
-        if pendaftarID.Valid {
-            id := int(pendaftarID.Int64)
-            j.PendaftarID = &id
-        }
-        if catatan.Valid {
-            j.Catatan = &catatan.String
-        }
-        if alasan.Valid {
-            j.AlasanPerubahan = &alasan.String
-        }
-        if tanggalD.Valid {
-            j.TanggalDiajukan = &tanggalD.Time
-        }
-        if jamMulaiD.Valid {
-            j.JamMulaiDiajukan = &jamMulaiD.String
-        }
-        if jamSelesaiD.Valid {
-            j.JamSelesaiDiajukan = &jamSelesaiD.String
-        }
-        if jenisJadwal.Valid {
-            j.JenisJadwal = jenisJadwal.String
-        } else {
-            j.JenisJadwal = "pribadi"
-        }
-
-        if userNama.Valid {
-            j.UserNama = userNama.String
-        } else {
-            j.UserNama = "User " + strconv.Itoa(j.UserID)
-        }
-
-        jadwals = append(jadwals, j)
-    }
-
-    return jadwals, rows.Err()
-}
+	var jadwals []models.Jadwal
+
+	for rows.Next() {
+		var j models.Jadwal
+		var pendaftarID sql.NullInt64
+		var catatan, alasan, jenisJadwal, userNama sql.NullString
+		var mulaiD, selesaiD, createdAt, updatedAt sql.NullTime
+		var kapasitas sql.NullInt64
+		var batasPendaftaran sql.NullTime
+
+		err := rows.Scan(
+			&j.IDJadwal,
+			&j.UserID,
+			&pendaftarID,
+			&j.WaktuMulai,
+			&j.WaktuSelesai,
+			&j.ZonaWaktu,
+			&j.Tempat,
+			&j.KonfirmasiJadwal,
+			&catatan,
+			&j.PengajuanPerubahan,
+			&alasan,
+			&mulaiD,
+			&selesaiD,
+			&jenisJadwal,
+			&kapasitas,
+			&batasPendaftaran,
+			&createdAt,
+			&updatedAt,
+			&userNama,
+		)
+		if err != nil {
+			return nil, err
+		}
 
+		if createdAt.Valid {
+			j.CreatedAt = createdAt.Time
+		}
+		if updatedAt.Valid {
+			j.UpdatedAt = updatedAt.Time
+		}
+
+		if pendaftarID.Valid {
+			id := int(pendaftarID.Int64)
+			j.PendaftarID = &id
+		}
+		if catatan.Valid {
+			j.Catatan = &catatan.String
+		}
+		if alasan.Valid {
+			j.AlasanPerubahan = &alasan.String
+		}
+		if mulaiD.Valid {
+			j.WaktuMulaiDiajukan = &mulaiD.Time
+		}
+		if selesaiD.Valid {
+			j.WaktuSelesaiDiajukan = &selesaiD.Time
+		}
+		if jenisJadwal.Valid {
+			j.JenisJadwal = jenisJadwal.String
+		} else {
+			j.JenisJadwal = "pribadi"
+		}
+		if kapasitas.Valid {
+			k := int(kapasitas.Int64)
+			j.Kapasitas = &k
+		}
+		if batasPendaftaran.Valid {
+			j.BatasPendaftaran = &batasPendaftaran.Time
+		}
+
+		if userNama.Valid {
+			j.UserNama = userNama.String
+		} else {
+			j.UserNama = "User " + strconv.Itoa(j.UserID)
+		}
+
+		jadwals = append(jadwals, j)
+	}
+
+	return jadwals, rows.Err()
+}
 
 func CreateJadwal(db *sql.DB, jadwal models.Jadwal) error {
 	if jadwal.JenisJadwal == "" {
 		jadwal.JenisJadwal = "pribadi"
 	}
+	if jadwal.ZonaWaktu == "" {
+		jadwal.ZonaWaktu = utils.DefaultZonaWaktu
+	}
 
 	query := `
 		INSERT INTO jadwal (
-			user_id, pendaftar_id, tanggal, jam_mulai, jam_selesai,
+			user_id, pendaftar_id, waktu_mulai, waktu_selesai, zona_waktu,
 			tempat, konfirmasi_jadwal, catatan, pengajuan_perubahan,
-			alasan_perubahan, tanggal_diajukan, jam_mulai_diajukan,
-			jam_selesai_diajukan, jenis_jadwal
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			alasan_perubahan, waktu_mulai_diajukan, waktu_selesai_diajukan,
+			jenis_jadwal, kapasitas, batas_pendaftaran
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := db.Exec(
 		query,
 		jadwal.UserID,
 		jadwal.PendaftarID,
-		jadwal.Tanggal,
-		jadwal.JamMulai,
-		jadwal.JamSelesai,
+		jadwal.WaktuMulai.UTC(),
+		jadwal.WaktuSelesai.UTC(),
+		jadwal.ZonaWaktu,
 		jadwal.Tempat,
 		jadwal.KonfirmasiJadwal,
 		jadwal.Catatan,
 		jadwal.PengajuanPerubahan,
 		jadwal.AlasanPerubahan,
-		jadwal.TanggalDiajukan,
-		jadwal.JamMulaiDiajukan,
-		jadwal.JamSelesaiDiajukan,
+		jadwal.WaktuMulaiDiajukan,
+		jadwal.WaktuSelesaiDiajukan,
 		jadwal.JenisJadwal,
+		jadwal.Kapasitas,
+		jadwal.BatasPendaftaran,
 	)
 	return err
 }
 
 func GetAllJadwal(db *sql.DB) ([]models.Jadwal, error) {
-    query := `
+	query := `
         SELECT 
             j.id_jadwal, j.user_id, j.pendaftar_id,
-            j.tanggal, j.jam_mulai, j.jam_selesai,
+            j.waktu_mulai, j.waktu_selesai, j.zona_waktu,
             j.tempat, j.konfirmasi_jadwal,
             j.catatan, j.pengajuan_perubahan, j.alasan_perubahan,
-            j.tanggal_diajukan, j.jam_mulai_diajukan, j.jam_selesai_diajukan,
-            j.jenis_jadwal,
+            j.waktu_mulai_diajukan, j.waktu_selesai_diajukan,
+            j.jenis_jadwal, j.kapasitas, j.batas_pendaftaran,
             j.created_at, j.updated_at,
             u.full_name AS user_nama
         FROM jadwal j
         LEFT JOIN users u ON j.user_id = u.id_user
-        ORDER BY j.tanggal DESC, j.jam_mulai ASC
+        ORDER BY j.waktu_mulai DESC
     `
-    rows, err := db.Query(query)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-    return scanJadwalRows(rows)
+	return scanJadwalRows(rows)
 }
 
 func GetJadwalByID(db *sql.DB, id int) (*models.Jadwal, error) {
-    query := `
+	query := `
         SELECT 
             id_jadwal, user_id, pendaftar_id,
-            tanggal, jam_mulai, jam_selesai,
+            waktu_mulai, waktu_selesai, zona_waktu,
             tempat, konfirmasi_jadwal,
             catatan, pengajuan_perubahan, alasan_perubahan,
-            tanggal_diajukan, jam_mulai_diajukan, jam_selesai_diajukan,
-            jenis_jadwal,
+            waktu_mulai_diajukan, waktu_selesai_diajukan,
+            jenis_jadwal, kapasitas, batas_pendaftaran,
             created_at, updated_at
         FROM jadwal
         WHERE id_jadwal = ?
     `
-    row := db.QueryRow(query, id) 
-    return scanRowToJadwal(row)  
+	row := db.QueryRow(query, id)
+	return scanRowToJadwal(row)
 }
 
 func GetJadwalByUserID(db *sql.DB, userID int) ([]models.Jadwal, error) {
-    query := `
+	query := `
         SELECT 
             j.id_jadwal, j.user_id, j.pendaftar_id,
-            j.tanggal, j.jam_mulai, j.jam_selesai,
+            j.waktu_mulai, j.waktu_selesai, j.zona_waktu,
             j.tempat, j.konfirmasi_jadwal,
             j.catatan, j.pengajuan_perubahan, j.alasan_perubahan,
-            j.tanggal_diajukan, j.jam_mulai_diajukan, j.jam_selesai_diajukan,
-            j.jenis_jadwal,
+            j.waktu_mulai_diajukan, j.waktu_selesai_diajukan,
+            j.jenis_jadwal, j.kapasitas, j.batas_pendaftaran,
             j.created_at, j.updated_at,
             u.full_name AS user_nama
         FROM jadwal j
         LEFT JOIN users u ON j.user_id = u.id_user
         WHERE j.user_id = ?
-        ORDER BY j.tanggal DESC, j.jam_mulai ASC
+        ORDER BY j.waktu_mulai DESC
     `
-    rows, err := db.Query(query, userID)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-    return scanJadwalRows(rows)
+	return scanJadwalRows(rows)
 }
 
 func GetJadwalUmum(db *sql.DB) ([]models.Jadwal, error) {
-    query := `
+	query := `
         SELECT 
             j.id_jadwal, j.user_id, j.pendaftar_id,
-            j.tanggal, j.jam_mulai, j.jam_selesai,
+            j.waktu_mulai, j.waktu_selesai, j.zona_waktu,
             j.tempat, j.konfirmasi_jadwal,
             j.catatan, j.pengajuan_perubahan, j.alasan_perubahan,
-            j.tanggal_diajukan, j.jam_mulai_diajukan, j.jam_selesai_diajukan,
-            j.jenis_jadwal,
+            j.waktu_mulai_diajukan, j.waktu_selesai_diajukan,
+            j.jenis_jadwal, j.kapasitas, j.batas_pendaftaran,
             j.created_at, j.updated_at,
             u.full_name AS user_nama
         FROM jadwal j
         LEFT JOIN users u ON j.user_id = u.id_user
         WHERE j.jenis_jadwal = 'umum'
-        ORDER BY j.tanggal DESC, j.jam_mulai ASC
+        ORDER BY j.waktu_mulai DESC
     `
-    rows, err := db.Query(query)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-    return scanJadwalRows(rows)
+	return scanJadwalRows(rows)
 }
 
+// UpdateJadwal menyimpan perubahan jadwal. Kapasitas tidak boleh diturunkan di bawah jumlah peserta
+// terdaftar (ErrKapasitasDiBawahPeserta); jadwal dikunci agar tidak balapan dengan pendaftaran.
 func UpdateJadwal(db *sql.DB, jadwal *models.Jadwal) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := kunciJadwal(tx, jadwal.IDJadwal); err != nil {
+		return err
+	}
+	if jadwal.Kapasitas != nil {
+		terdaftar, err := hitungPesertaTerdaftar(tx, jadwal.IDJadwal)
+		if err != nil {
+			return err
+		}
+		if terdaftar > *jadwal.Kapasitas {
+			return fmt.Errorf("%w: sudah ada %d peserta terdaftar", ErrKapasitasDiBawahPeserta, terdaftar)
+		}
+	}
+
 	query := `
 		UPDATE jadwal SET
 			pendaftar_id = ?,
-			tanggal = ?,
-			jam_mulai = ?,
-			jam_selesai = ?,
+			waktu_mulai = ?,
+			waktu_selesai = ?,
+			zona_waktu = ?,
 			tempat = ?,
 			konfirmasi_jadwal = ?,
 			catatan = ?,
 			jenis_jadwal = ?,
+			kapasitas = ?,
+			batas_pendaftaran = ?,
 			updated_at = NOW()
 		WHERE id_jadwal = ?
 	`
-	_, err := db.Exec(
+	_, err = tx.Exec(
 		query,
 		jadwal.PendaftarID,
-		jadwal.Tanggal,
-		jadwal.JamMulai,
-		jadwal.JamSelesai,
+		jadwal.WaktuMulai.UTC(),
+		jadwal.WaktuSelesai.UTC(),
+		jadwal.ZonaWaktu,
 		jadwal.Tempat,
 		jadwal.KonfirmasiJadwal,
 		jadwal.Catatan,
 		jadwal.JenisJadwal,
+		jadwal.Kapasitas,
+		jadwal.BatasPendaftaran,
 		jadwal.IDJadwal,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func UpdatePengajuanPerubahan(
@@ -304,17 +352,15 @@ func UpdatePengajuanPerubahan(
 	idJadwal int,
 	pengajuan bool,
 	alasan *string,
-	tanggalD *time.Time,
-	jamMulaiD *string,
-	jamSelesaiD *string,
+	waktuMulaiD *time.Time,
+	waktuSelesaiD *time.Time,
 ) error {
 	query := `
 		UPDATE jadwal SET
 			pengajuan_perubahan = ?,
 			alasan_perubahan = ?,
-			tanggal_diajukan = ?,
-			jam_mulai_diajukan = ?,
-			jam_selesai_diajukan = ?,
+			waktu_mulai_diajukan = ?,
+			waktu_selesai_diajukan = ?,
 			updated_at = NOW()
 		WHERE id_jadwal = ?
 	`
@@ -322,17 +368,25 @@ func UpdatePengajuanPerubahan(
 		query,
 		pengajuan,
 		alasan,
-		tanggalD,
-		jamMulaiD,
-		jamSelesaiD,
+		waktuMulaiD,
+		waktuSelesaiD,
 		idJadwal,
 	)
 	return err
 }
 
+// DeleteJadwal menghapus jadwal yang tidak terkait dengan pengumuman
 func DeleteJadwal(db *sql.DB, idJadwal int) error {
+	var diumumkan bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pengumuman WHERE id_jadwal = ?)`, idJadwal).Scan(&diumumkan)
+	if err != nil {
+		return err
+	}
+	if diumumkan {
+		return ErrJadwalDipakaiPengumuman
+	}
 	query := `DELETE FROM jadwal WHERE id_jadwal = ?`
-	_, err := db.Exec(query, idJadwal)
+	_, err = db.Exec(query, idJadwal)
 	return err
 }
 
@@ -347,20 +401,20 @@ func UserExists(db *sql.DB, userID int) (bool, error) {
 }
 
 func GetAllUsers(db *sql.DB) ([]models.User, error) {
-    query := `SELECT id_user, full_name FROM users ORDER BY full_name ASC`
-    rows, err := db.Query(query)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-
-    var users []models.User
-    for rows.Next() {
-        var u models.User
-        if err := rows.Scan(&u.IDUser, &u.FullName); err != nil {
-            return nil, err
-        }
-        users = append(users, u)
-    }
-    return users, rows.Err()
-}
\ No newline at end of file
+	query := `SELECT id_user, full_name FROM users ORDER BY full_name ASC`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.IDUser, &u.FullName); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
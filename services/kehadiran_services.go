@@ -0,0 +1,174 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	MetodeKehadiranQR     = "qr"
+	MetodeKehadiranManual = "manual"
+
+	// JendelaCheckinSebelum adalah batas paling awal check-in sebelum jadwal dimulai
+	JendelaCheckinSebelum = 30 * time.Minute
+)
+
+var (
+	ErrBukanPesertaJadwal   = errors.New("user tidak terdaftar pada jadwal ini")
+	ErrDiLuarJendelaCheckin = errors.New("check-in hanya dapat dilakukan 30 menit sebelum jadwal dimulai hingga jadwal selesai")
+	ErrSudahCheckin         = errors.New("user sudah tercatat hadir pada jadwal ini")
+)
+
+// pesertaDiharapkan adalah pasangan (jadwal, user) yang wajib hadir:
+// pemilik jadwal pribadi yang sudah dikonfirmasi dan peserta terdaftar acara umum
+const pesertaDiharapkan = `
+	SELECT j.id_jadwal, j.user_id
+	FROM jadwal j
+	WHERE j.jenis_jadwal = 'pribadi' AND j.konfirmasi_jadwal = 'dikonfirmasi'
+	UNION ALL
+	SELECT pj.id_jadwal, pj.user_id
+	FROM peserta_jadwal pj
+	INNER JOIN jadwal j ON pj.id_jadwal = j.id_jadwal
+	WHERE j.jenis_jadwal = 'umum' AND pj.status = 'terdaftar'
+`
+
+// IsPesertaDiharapkan memeriksa apakah user wajib hadir pada jadwal
+func IsPesertaDiharapkan(db *sql.DB, idJadwal, userID int) (bool, error) {
+	var exists bool
+	err := db.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM (`+pesertaDiharapkan+`) d
+			WHERE d.id_jadwal = ? AND d.user_id = ?
+		)
+	`, idJadwal, userID).Scan(&exists)
+	return exists, err
+}
+
+// CatatKehadiran mencatat check-in user. Check-in QR harus berada di jendela check-in; check-in
+// manual oleh admin di luar jendela tetap dicatat tetapi ditandai di_luar_jendela.
+func CatatKehadiran(db *sql.DB, idJadwal, userID, dicatatOleh int, metode string) error {
+	var dalamJendela bool
+	err := db.QueryRow(`
+		SELECT UTC_TIMESTAMP() >= waktu_mulai - INTERVAL ? SECOND
+		   AND UTC_TIMESTAMP() <= waktu_selesai
+		FROM jadwal
+		WHERE id_jadwal = ?
+	`, int(JendelaCheckinSebelum.Seconds()), idJadwal).Scan(&dalamJendela)
+	if err != nil {
+		return err
+	}
+
+	ok, err := IsPesertaDiharapkan(db, idJadwal, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBukanPesertaJadwal
+	}
+	if !dalamJendela && metode != MetodeKehadiranManual {
+		return ErrDiLuarJendelaCheckin
+	}
+
+	res, err := db.Exec(`
+		INSERT IGNORE INTO kehadiran (id_jadwal, user_id, dicatat_oleh, metode, di_luar_jendela)
+		VALUES (?, ?, ?, ?, ?)
+	`, idJadwal, userID, dicatatOleh, metode, !dalamJendela)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSudahCheckin
+	}
+	return nil
+}
+
+// GetKehadiranJadwal mengambil daftar peserta yang diharapkan hadir beserta status check-in
+func GetKehadiranJadwal(db *sql.DB, idJadwal int) ([]models.Kehadiran, error) {
+	query := `
+		SELECT d.id_jadwal, d.user_id, u.full_name, u.email, k.waktu_checkin, k.metode,
+			COALESCE(k.di_luar_jendela, FALSE)
+		FROM (` + pesertaDiharapkan + `) d
+		INNER JOIN users u ON d.user_id = u.id_user
+		LEFT JOIN kehadiran k ON k.id_jadwal = d.id_jadwal AND k.user_id = d.user_id
+		WHERE d.id_jadwal = ?
+		ORDER BY u.full_name ASC
+	`
+	rows, err := db.Query(query, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.Kehadiran
+	for rows.Next() {
+		var k models.Kehadiran
+		var waktu sql.NullTime
+		var metode sql.NullString
+		if err := rows.Scan(&k.IDJadwal, &k.UserID, &k.Nama, &k.Email, &waktu, &metode, &k.DiLuarJendela); err != nil {
+			return nil, err
+		}
+		if waktu.Valid {
+			k.Hadir = true
+			k.WaktuCheckin = &waktu.Time
+		}
+		if metode.Valid {
+			k.Metode = metode.String
+		}
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+const ringkasanKehadiranQuery = `
+	SELECT
+		d.user_id, u.full_name, u.email,
+		COUNT(*) AS jumlah_jadwal,
+		COALESCE(SUM(k.id_kehadiran IS NOT NULL), 0) AS hadir,
+		COALESCE(SUM(k.id_kehadiran IS NULL AND j.waktu_selesai < UTC_TIMESTAMP()), 0) AS tidak_hadir
+	FROM (` + pesertaDiharapkan + `) d
+	INNER JOIN jadwal j ON d.id_jadwal = j.id_jadwal
+	INNER JOIN users u ON d.user_id = u.id_user
+	LEFT JOIN kehadiran k ON k.id_jadwal = d.id_jadwal AND k.user_id = d.user_id
+`
+
+func scanRingkasanKehadiran(row interface{ Scan(...any) error }) (models.RingkasanKehadiran, error) {
+	var r models.RingkasanKehadiran
+	err := row.Scan(&r.UserID, &r.Nama, &r.Email, &r.JumlahJadwal, &r.Hadir, &r.TidakHadir)
+	return r, err
+}
+
+// GetRingkasanKehadiranUser menghitung jumlah jadwal, kehadiran, dan no-show seorang user
+func GetRingkasanKehadiranUser(db *sql.DB, userID int) (models.RingkasanKehadiran, error) {
+	r, err := scanRingkasanKehadiran(db.QueryRow(ringkasanKehadiranQuery+`
+		WHERE d.user_id = ?
+		GROUP BY d.user_id, u.full_name, u.email
+	`, userID))
+	if err == sql.ErrNoRows {
+		return models.RingkasanKehadiran{UserID: userID}, nil
+	}
+	return r, err
+}
+
+// GetLaporanKehadiran merangkum kehadiran seluruh user, urut dari no-show terbanyak
+func GetLaporanKehadiran(db *sql.DB) ([]models.RingkasanKehadiran, error) {
+	rows, err := db.Query(ringkasanKehadiranQuery + `
+		GROUP BY d.user_id, u.full_name, u.email
+		ORDER BY tidak_hadir DESC, u.full_name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.RingkasanKehadiran
+	for rows.Next() {
+		r, err := scanRingkasanKehadiran(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+)
+
+// Aksi pada jejak audit koreksi hasil tes
+const (
+	AuditPenilaianUlang = "penilaian_ulang"
+	AuditPenyesuaian    = "penyesuaian"
+	AuditIzinUlang      = "izin_ulang"
+	AuditResetSesi      = "reset_sesi"
+)
+
+var (
+	ErrIzinUlangSudahAda    = errors.New("peserta masih memiliki izin ulang yang belum dipakai")
+	ErrBelumPernahMengikuti = errors.New("peserta belum pernah menyelesaikan tes ini")
+)
+
+func catatAudit(tx *sql.Tx, a models.AuditHasilTest) error {
+	_, err := tx.Exec(`
+		INSERT INTO audit_hasil_test (id_test, id_hasil, user_id, aksi, nilai_lama, nilai_baru, alasan, dilakukan_oleh)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.IDTest, a.IDHasil, a.UserID, a.Aksi, a.NilaiLama, a.NilaiBaru, a.Alasan, a.DilakukanOleh)
+	return err
+}
+
+// PenilaianUlangTest menilai ulang seluruh jawaban otomatis sebuah tes terhadap versi soal terkini
+// (mis. setelah kunci jawaban diperbaiki) lalu menghitung ulang setiap hasil. Jawaban yang dinilai
+// ulang ikut merujuk versi terkini. Jawaban esai yang sudah
+// dinilai manual tidak diubah. Mengembalikan jumlah hasil selesai yang nilainya berubah.
+func PenilaianUlangTest(db *sql.DB, idTest int, alasan string, adminID int) (int, error) {
+	soals, err := GetSoalByTest(db, idTest)
+	if err != nil {
+		return 0, err
+	}
+	soalMap := make(map[int]*models.SoalTest, len(soals))
+	for i := range soals {
+		soalMap[soals[i].IDSoal] = &soals[i]
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id_hasil, user_id, nilai, status FROM hasil_test WHERE id_test = ? ORDER BY id_hasil FOR UPDATE
+	`, idTest)
+	if err != nil {
+		return 0, err
+	}
+	var daftar []models.HasilTest
+	for rows.Next() {
+		var h models.HasilTest
+		if err := rows.Scan(&h.IDHasil, &h.UserID, &h.Nilai, &h.Status); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		daftar = append(daftar, h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	berubah := 0
+	for _, lama := range daftar {
+		if err := nilaiUlangJawaban(tx, lama.IDHasil, soalMap); err != nil {
+			return 0, err
+		}
+		baru, err := hitungUlangHasil(tx, lama.IDHasil, idTest)
+		if err != nil {
+			return 0, err
+		}
+		if lama.Status != StatusHasilSelesai || baru.Nilai == lama.Nilai {
+			continue
+		}
+		berubah++
+		err = catatAudit(tx, models.AuditHasilTest{
+			IDTest:        idTest,
+			IDHasil:       &lama.IDHasil,
+			UserID:        &lama.UserID,
+			Aksi:          AuditPenilaianUlang,
+			NilaiLama:     &lama.Nilai,
+			NilaiBaru:     &baru.Nilai,
+			Alasan:        alasan,
+			DilakukanOleh: &adminID,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return berubah, tx.Commit()
+}
+
+// nilaiUlangJawaban menilai ulang jawaban tersimpan satu sesi. Jawaban yang tidak lagi valid
+// (mis. jumlah pilihan berkurang) dianggap salah; soal yang berubah menjadi esai masuk antrean penilaian.
+func nilaiUlangJawaban(tx *sql.Tx, idHasil int, soalMap map[int]*models.SoalTest) error {
+	rows, err := tx.Query(`
+		SELECT id_jawaban, id_soal, jawaban_user, status_penilaian FROM jawaban_user WHERE id_hasil = ?
+	`, idHasil)
+	if err != nil {
+		return err
+	}
+	var jawabans []models.JawabanUser
+	for rows.Next() {
+		var j models.JawabanUser
+		if err := rows.Scan(&j.IDJawaban, &j.IDSoal, &j.JawabanUser, &j.StatusPenilaian); err != nil {
+			rows.Close()
+			return err
+		}
+		jawabans = append(jawabans, j)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, lama := range jawabans {
+		s, ok := soalMap[lama.IDSoal]
+		if !ok || (s.Tipe == TipeSoalEsai && lama.StatusPenilaian != StatusPenilaianOtomatis) {
+			continue
+		}
+		baru, err := NilaiJawaban(s, lama.JawabanUser)
+		if err != nil {
+			salah, nol := false, 0.0
+			baru = models.JawabanUser{JawabanUser: lama.JawabanUser, IsBenar: &salah, Poin: &nol, StatusPenilaian: StatusPenilaianOtomatis}
+		}
+		_, err = tx.Exec(`
+			UPDATE jawaban_user SET
+				id_versi = (SELECT id_versi FROM versi_soal WHERE id_soal = ? AND versi = ?),
+				is_benar = ?, poin = ?, status_penilaian = ?
+			WHERE id_jawaban = ?
+		`, s.IDSoal, s.Versi, baru.IsBenar, baru.Poin, baru.StatusPenilaian, lama.IDJawaban)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SesuaikanPoinHasil menetapkan penyesuaian poin manual sebuah hasil selesai (menggantikan
+// penyesuaian sebelumnya; 0 berarti dihapus) lalu menghitung ulang nilainya
+func SesuaikanPoinHasil(db *sql.DB, idHasil int, penyesuaian float64, alasan string, adminID int) (*models.HasilTest, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var lama models.HasilTest
+	err = tx.QueryRow(`
+		SELECT id_hasil, id_test, user_id, nilai, status FROM hasil_test WHERE id_hasil = ? FOR UPDATE
+	`, idHasil).Scan(&lama.IDHasil, &lama.IDTest, &lama.UserID, &lama.Nilai, &lama.Status)
+	if err != nil {
+		return nil, err
+	}
+	if lama.Status != StatusHasilSelesai {
+		return nil, ErrSesiBelumSelesai
+	}
+
+	if _, err := tx.Exec(`UPDATE hasil_test SET penyesuaian_poin = ? WHERE id_hasil = ?`, penyesuaian, idHasil); err != nil {
+		return nil, err
+	}
+	baru, err := hitungUlangHasil(tx, idHasil, lama.IDTest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = catatAudit(tx, models.AuditHasilTest{
+		IDTest:        lama.IDTest,
+		IDHasil:       &lama.IDHasil,
+		UserID:        &lama.UserID,
+		Aksi:          AuditPenyesuaian,
+		NilaiLama:     &lama.Nilai,
+		NilaiBaru:     &baru.Nilai,
+		Alasan:        alasan,
+		DilakukanOleh: &adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return baru, tx.Commit()
+}
+
+// BeriIzinUlang memberi peserta satu percobaan tambahan setelah percobaan terakhirnya selesai
+func BeriIzinUlang(db *sql.DB, idTest, userID int, alasan string, adminID int) (*models.IzinUlangTest, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var idHasil int
+	var nilai float64
+	err = tx.QueryRow(`
+		SELECT id_hasil, nilai FROM hasil_test
+		WHERE user_id = ? AND id_test = ? AND status = ?
+		ORDER BY percobaan DESC
+		LIMIT 1
+		FOR UPDATE
+	`, userID, idTest, StatusHasilSelesai).Scan(&idHasil, &nilai)
+	if err == sql.ErrNoRows {
+		return nil, ErrBelumPernahMengikuti
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var adaIzin bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM izin_ulang_test WHERE user_id = ? AND id_test = ? AND id_hasil_dipakai IS NULL)
+	`, userID, idTest).Scan(&adaIzin)
+	if err != nil {
+		return nil, err
+	}
+	if adaIzin {
+		return nil, ErrIzinUlangSudahAda
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO izin_ulang_test (id_test, user_id, alasan, diberikan_oleh) VALUES (?, ?, ?, ?)
+	`, idTest, userID, alasan, adminID)
+	if err != nil {
+		return nil, err
+	}
+	idIzin, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	err = catatAudit(tx, models.AuditHasilTest{
+		IDTest:        idTest,
+		IDHasil:       &idHasil,
+		UserID:        &userID,
+		Aksi:          AuditIzinUlang,
+		NilaiLama:     &nilai,
+		Alasan:        alasan,
+		DilakukanOleh: &adminID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.IzinUlangTest{
+		IDIzin:        int(idIzin),
+		IDTest:        idTest,
+		UserID:        userID,
+		Alasan:        alasan,
+		DiberikanOleh: &adminID,
+	}, nil
+}
+
+// AdaIzinUlang memeriksa apakah peserta punya izin ulang yang belum dipakai pada sebuah tes
+func AdaIzinUlang(db *sql.DB, userID, idTest int) (bool, error) {
+	var ada bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM izin_ulang_test WHERE user_id = ? AND id_test = ? AND id_hasil_dipakai IS NULL)
+	`, userID, idTest).Scan(&ada)
+	return ada, err
+}
+
+// GetAuditHasilTest mengambil jejak audit koreksi hasil sebuah tes, terbaru lebih dulu
+func GetAuditHasilTest(db *sql.DB, idTest int) ([]models.AuditHasilTest, error) {
+	rows, err := db.Query(`
+		SELECT a.id_audit, a.id_test, a.id_hasil, a.user_id, a.aksi, a.nilai_lama, a.nilai_baru,
+			a.alasan, a.dilakukan_oleh, u.full_name, a.created_at
+		FROM audit_hasil_test a
+		LEFT JOIN users u ON u.id_user = a.dilakukan_oleh
+		WHERE a.id_test = ?
+		ORDER BY a.created_at DESC, a.id_audit DESC
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AuditHasilTest{}
+	for rows.Next() {
+		var a models.AuditHasilTest
+		var idHasil, userID, oleh sql.NullInt64
+		var nilaiLama, nilaiBaru sql.NullFloat64
+		var namaAdmin sql.NullString
+		err := rows.Scan(&a.IDAudit, &a.IDTest, &idHasil, &userID, &a.Aksi, &nilaiLama, &nilaiBaru,
+			&a.Alasan, &oleh, &namaAdmin, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		a.IDHasil = nullIntPtr(idHasil)
+		a.UserID = nullIntPtr(userID)
+		a.DilakukanOleh = nullIntPtr(oleh)
+		a.NamaAdmin = nullStringPtr(namaAdmin)
+		if nilaiLama.Valid {
+			a.NilaiLama = &nilaiLama.Float64
+		}
+		if nilaiBaru.Valid {
+			a.NilaiBaru = &nilaiBaru.Float64
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func nullIntPtr(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"cocopen-backend/dto"
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Sasaran notifikasi kotak masuk
+const (
+	TargetNotifikasiUser  = "user"
+	TargetNotifikasiRole  = "role"
+	TargetNotifikasiSemua = "semua"
+)
+
+// Kategori notifikasi kotak masuk
+const (
+	KategoriNotifikasiPengumuman        = "pengumuman"
+	KategoriNotifikasiStatusPendaftaran = "status_pendaftaran"
+	KategoriNotifikasiJadwal            = "jadwal"
+	KategoriNotifikasiHasilTest         = "hasil_test"
+	KategoriNotifikasiPengingat         = "pengingat"
+	KategoriNotifikasiPesanWA           = "pesan_wa"
+)
+
+const (
+	DefaultLimitNotifikasi = 20
+	MaxLimitNotifikasi     = 100
+
+	// Jumlah baris per INSERT saat notifikasi yang sama dikirim ke banyak user
+	batchNotifikasi = 500
+)
+
+var ErrNotifikasiTidakValid = errors.New("notifikasi tidak valid")
+
+// kondisiNotifikasiUntukUser menyaring notifikasi (alias n) yang ditujukan ke seorang user;
+// argumennya dari argsNotifikasiUntukUser. Notifikasi role/semua yang dibuat sebelum akun
+// terdaftar tidak ikut agar user baru tidak menerima tumpukan notifikasi lama.
+const kondisiNotifikasiUntukUser = `(
+	(n.target = 'user' AND n.user_id = ?)
+	OR (
+		(n.target = 'semua' OR (n.target = 'role' AND n.role = ?))
+		AND n.created_at >= (SELECT u.created_at FROM users u WHERE u.id_user = ?)
+	)
+)`
+
+func argsNotifikasiUntukUser(userID int, role string) []any {
+	return []any{userID, role, userID}
+}
+
+// KirimNotifikasi menyimpan notifikasi ke kotak masuk lalu menerbitkannya ke stream SSE
+// sehingga user yang sedang tersambung menerimanya saat itu juga. Web Push dikirim di latar
+// belakang untuk menjangkau user yang tidak sedang membuka situs.
+func KirimNotifikasi(db *sql.DB, n *models.Notifikasi) error {
+	switch n.Target {
+	case TargetNotifikasiUser:
+		if n.UserID == nil {
+			return fmt.Errorf("%w: notifikasi user wajib punya user_id", ErrNotifikasiTidakValid)
+		}
+		n.Role = nil
+	case TargetNotifikasiRole:
+		if n.Role == nil || (*n.Role != "user" && *n.Role != "admin") {
+			return fmt.Errorf("%w: role tidak dikenal", ErrNotifikasiTidakValid)
+		}
+		n.UserID = nil
+	case TargetNotifikasiSemua:
+		n.UserID, n.Role = nil, nil
+	default:
+		return fmt.Errorf("%w: target tidak dikenal", ErrNotifikasiTidakValid)
+	}
+
+	n.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	res, err := db.Exec(`
+		INSERT INTO notifikasi (target, user_id, role, kategori, judul, isi, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, n.Target, n.UserID, n.Role, n.Kategori, n.Judul, n.Isi, n.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if n.IDNotifikasi, err = res.LastInsertId(); err != nil {
+		return err
+	}
+
+	terbitkanNotifikasi(*n)
+	go kirimPushNotifikasi(db, *n)
+	return nil
+}
+
+// terbitkanNotifikasi menerbitkan notifikasi yang sudah tersimpan ke stream SSE
+func terbitkanNotifikasi(n models.Notifikasi) {
+	e := dto.NotifikasiEvent{
+		Jenis:        utils.EventNotifikasi,
+		IDNotifikasi: n.IDNotifikasi,
+		Kategori:     n.Kategori,
+		Judul:        n.Judul,
+		Isi:          n.Isi,
+		Waktu:        n.CreatedAt,
+	}
+	if n.Kategori == KategoriNotifikasiPengumuman {
+		e.Jenis = utils.EventPengumuman
+	}
+	if n.UserID != nil {
+		e.UserID = *n.UserID
+	}
+	if n.Role != nil {
+		e.Role = *n.Role
+	}
+	utils.Notifikasi.Terbitkan(e)
+}
+
+// KirimNotifikasiUser mengirim notifikasi kotak masuk untuk satu user
+func KirimNotifikasiUser(db *sql.DB, userID int, kategori, judul, isi string) error {
+	return KirimNotifikasi(db, &models.Notifikasi{
+		Target:   TargetNotifikasiUser,
+		UserID:   &userID,
+		Kategori: kategori,
+		Judul:    judul,
+		Isi:      isi,
+	})
+}
+
+// KirimNotifikasiUsers mengirim notifikasi yang sama ke banyak user. Baris disimpan per
+// batchNotifikasi user dengan satu INSERT, dan Web Push seluruh penerima dikirim dari satu
+// goroutine lewat antrean bersama. Bila sebuah batch gagal, batch sebelumnya tetap terkirim.
+func KirimNotifikasiUsers(db *sql.DB, userIDs []int, kategori, judul, isi string) error {
+	var tersimpan []models.Notifikasi
+	var errSimpan error
+	for len(userIDs) > 0 {
+		batch := userIDs[:min(len(userIDs), batchNotifikasi)]
+		userIDs = userIDs[len(batch):]
+
+		ns, err := simpanNotifikasiUsers(db, batch, kategori, judul, isi)
+		if err != nil {
+			errSimpan = err
+			break
+		}
+		for _, n := range ns {
+			terbitkanNotifikasi(n)
+		}
+		tersimpan = append(tersimpan, ns...)
+	}
+	if len(tersimpan) > 0 {
+		go kirimPushNotifikasiUsers(db, tersimpan)
+	}
+	return errSimpan
+}
+
+// simpanNotifikasiUsers menyimpan satu notifikasi per user dengan satu INSERT multi-baris.
+// ID tiap baris dibaca ulang karena auto-increment satu INSERT tidak dijamin berurutan.
+func simpanNotifikasiUsers(db *sql.DB, userIDs []int, kategori, judul, isi string) ([]models.Notifikasi, error) {
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	placeholders := strings.TrimSuffix(strings.Repeat("(?, ?, ?, ?, ?, ?),", len(userIDs)), ",")
+	args := make([]any, 0, len(userIDs)*6)
+	for _, userID := range userIDs {
+		args = append(args, TargetNotifikasiUser, userID, kategori, judul, isi, createdAt)
+	}
+	res, err := db.Exec(`
+		INSERT INTO notifikasi (target, user_id, kategori, judul, isi, created_at)
+		VALUES `+placeholders, args...)
+	if err != nil {
+		return nil, err
+	}
+	idAwal, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	placeholders = strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	args = []any{idAwal, TargetNotifikasiUser, kategori, createdAt}
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+	rows, err := db.Query(`
+		SELECT id_notifikasi, user_id FROM notifikasi
+		WHERE id_notifikasi >= ? AND target = ? AND kategori = ? AND created_at = ?
+		  AND user_id IN (`+placeholders+`)
+		ORDER BY id_notifikasi ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ns := make([]models.Notifikasi, 0, len(userIDs))
+	sudah := make(map[int]bool, len(userIDs))
+	for rows.Next() {
+		var idNotifikasi int64
+		var userID int
+		if err := rows.Scan(&idNotifikasi, &userID); err != nil {
+			return nil, err
+		}
+		if sudah[userID] {
+			continue
+		}
+		sudah[userID] = true
+		ns = append(ns, models.Notifikasi{
+			IDNotifikasi: idNotifikasi,
+			Target:       TargetNotifikasiUser,
+			UserID:       &userID,
+			Kategori:     kategori,
+			Judul:        judul,
+			Isi:          isi,
+			CreatedAt:    createdAt,
+		})
+	}
+	return ns, rows.Err()
+}
+
+// KirimNotifikasiSemua mengirim notifikasi kotak masuk untuk semua user
+func KirimNotifikasiSemua(db *sql.DB, kategori, judul, isi string) error {
+	return KirimNotifikasi(db, &models.Notifikasi{
+		Target:   TargetNotifikasiSemua,
+		Kategori: kategori,
+		Judul:    judul,
+		Isi:      isi,
+	})
+}
+
+// GetNotifikasiUser mengambil kotak masuk user dari yang terbaru. sebelumID > 0 melanjutkan
+// halaman sebelumnya (id_notifikasi lebih kecil dari itu).
+func GetNotifikasiUser(db *sql.DB, userID int, role string, hanyaBelumDibaca bool, sebelumID int64, limit int) ([]models.Notifikasi, error) {
+	query := `
+		SELECT n.id_notifikasi, n.target, n.user_id, n.role, n.kategori, n.judul, n.isi,
+			d.dibaca_at, n.created_at
+		FROM notifikasi n
+		LEFT JOIN notifikasi_dibaca d ON d.id_notifikasi = n.id_notifikasi AND d.user_id = ?
+		WHERE ` + kondisiNotifikasiUntukUser
+	args := append([]any{userID}, argsNotifikasiUntukUser(userID, role)...)
+	if hanyaBelumDibaca {
+		query += ` AND d.id_notifikasi IS NULL`
+	}
+	if sebelumID > 0 {
+		query += ` AND n.id_notifikasi < ?`
+		args = append(args, sebelumID)
+	}
+	query += ` ORDER BY n.id_notifikasi DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daftar := []models.Notifikasi{}
+	for rows.Next() {
+		var n models.Notifikasi
+		var userIDNotif sql.NullInt64
+		var roleNotif sql.NullString
+		var dibacaAt sql.NullTime
+		if err := rows.Scan(&n.IDNotifikasi, &n.Target, &userIDNotif, &roleNotif, &n.Kategori,
+			&n.Judul, &n.Isi, &dibacaAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		n.UserID = nullIntPtr(userIDNotif)
+		n.Role = nullStringPtr(roleNotif)
+		if dibacaAt.Valid {
+			n.Dibaca = true
+			n.DibacaAt = &dibacaAt.Time
+		}
+		daftar = append(daftar, n)
+	}
+	return daftar, rows.Err()
+}
+
+// JumlahNotifikasiBelumDibaca menghitung notifikasi user yang belum dibaca
+func JumlahNotifikasiBelumDibaca(db *sql.DB, userID int, role string) (int, error) {
+	var jumlah int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM notifikasi n
+		LEFT JOIN notifikasi_dibaca d ON d.id_notifikasi = n.id_notifikasi AND d.user_id = ?
+		WHERE d.id_notifikasi IS NULL AND `+kondisiNotifikasiUntukUser,
+		append([]any{userID}, argsNotifikasiUntukUser(userID, role)...)...,
+	).Scan(&jumlah)
+	return jumlah, err
+}
+
+// BacaNotifikasi menandai satu notifikasi dibaca oleh user (sql.ErrNoRows bila notifikasi
+// tidak ada atau bukan untuk user tersebut). Menandai ulang tidak mengubah waktu dibaca.
+func BacaNotifikasi(db *sql.DB, idNotifikasi int64, userID int, role string) error {
+	var ada bool
+	err := db.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM notifikasi n WHERE n.id_notifikasi = ? AND `+kondisiNotifikasiUntukUser+`)
+	`, append([]any{idNotifikasi}, argsNotifikasiUntukUser(userID, role)...)...).Scan(&ada)
+	if err != nil {
+		return err
+	}
+	if !ada {
+		return sql.ErrNoRows
+	}
+	_, err = db.Exec(`
+		INSERT IGNORE INTO notifikasi_dibaca (id_notifikasi, user_id, dibaca_at)
+		VALUES (?, ?, UTC_TIMESTAMP())
+	`, idNotifikasi, userID)
+	return err
+}
+
+// BacaSemuaNotifikasi menandai seluruh notifikasi user dibaca dan mengembalikan jumlah yang baru ditandai
+func BacaSemuaNotifikasi(db *sql.DB, userID int, role string) (int64, error) {
+	res, err := db.Exec(`
+		INSERT IGNORE INTO notifikasi_dibaca (id_notifikasi, user_id, dibaca_at)
+		SELECT n.id_notifikasi, ?, UTC_TIMESTAMP()
+		FROM notifikasi n
+		WHERE `+kondisiNotifikasiUntukUser,
+		append([]any{userID}, argsNotifikasiUntukUser(userID, role)...)...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// NotifikasiStatusPendaftar memberi tahu pemilik pendaftaran bahwa statusnya berubah. Hasil
+// akhir (diterima/ditolak) juga dikirim lewat WhatsApp.
+func NotifikasiStatusPendaftar(db *sql.DB, p models.Pendaftar, statusBaru string) error {
+	if p.UserID == nil || p.Status == statusBaru {
+		return nil
+	}
+	var judul, isi string
+	switch statusBaru {
+	case "diterima":
+		judul = "Pendaftaran Diterima"
+		isi = "Selamat, pendaftaran Anda telah diterima. Pantau jadwal dan tes berikutnya di dashboard."
+		kirimPesanWALatar(db, *p.UserID, TemplateWAPendaftaranDiterima, map[string]string{"Nama": p.NamaLengkap})
+	case "ditolak":
+		judul = "Pendaftaran Ditolak"
+		isi = "Mohon maaf, pendaftaran Anda belum dapat kami terima. Terima kasih atas minat Anda."
+		kirimPesanWALatar(db, *p.UserID, TemplateWAPendaftaranDitolak, map[string]string{"Nama": p.NamaLengkap})
+	default:
+		judul = "Status Pendaftaran Diperbarui"
+		isi = "Pendaftaran Anda dikembalikan ke status menunggu peninjauan."
+	}
+	return KirimNotifikasiUser(db, *p.UserID, KategoriNotifikasiStatusPendaftaran, judul, isi)
+}
+
+// NotifikasiJadwalPribadi memberi tahu user bahwa ia mendapat jadwal baru atau jadwalnya diubah
+func NotifikasiJadwalPribadi(db *sql.DB, j models.Jadwal, baru bool) error {
+	judul := "Jadwal Baru"
+	if !baru {
+		judul = "Jadwal Diperbarui"
+	}
+	isi := fmt.Sprintf("Jadwal Anda pada %s di %s. Mohon konfirmasi kehadiran Anda.",
+		utils.FormatWaktuLokal(j.WaktuMulai, j.ZonaWaktu), j.Tempat)
+	return KirimNotifikasiUser(db, j.UserID, KategoriNotifikasiJadwal, judul, isi)
+}
+
+// NotifikasiHasilDirilis memberi tahu setiap peserta yang sudah menyelesaikan tes bahwa hasilnya bisa dilihat
+func NotifikasiHasilDirilis(db *sql.DB, idTest int) error {
+	t, err := GetTestByID(db, idTest)
+	if err != nil {
+		return err
+	}
+	rows, err := db.Query(`
+		SELECT DISTINCT user_id FROM hasil_test WHERE id_test = ? AND status = ?
+	`, idTest, StatusHasilSelesai)
+	if err != nil {
+		return err
+	}
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	isi := fmt.Sprintf("Hasil tes \"%s\" sudah dapat dilihat.", t.Judul)
+	for _, userID := range userIDs {
+		if err := KirimNotifikasiUser(db, userID, KategoriNotifikasiHasilTest, "Hasil Tes Dirilis", isi); err != nil {
+			log.Printf("Gagal kirim notifikasi hasil tes #%d ke user %d: %v", idTest, userID, err)
+		}
+	}
+	return nil
+}
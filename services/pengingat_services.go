@@ -0,0 +1,271 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	KanalPengingatEmail      = "email"
+	KanalPengingatNotifikasi = "notifikasi"
+	KanalPengingatWhatsApp   = "whatsapp"
+)
+
+func scanTargetPengingat(rows *sql.Rows, jenis string) ([]models.TargetPengingat, error) {
+	var targets []models.TargetPengingat
+	for rows.Next() {
+		t := models.TargetPengingat{Jenis: jenis}
+		var nama sql.NullString
+		err := rows.Scan(&t.RefID, &t.UserID, &t.Email, &nama, &t.Judul, &t.Tempat, &t.Mulai, &t.ZonaWaktu, &t.Wawancara)
+		if err != nil {
+			return nil, err
+		}
+		if nama.Valid {
+			t.Nama = nama.String
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// GetJadwalPengingat mengambil jadwal pribadi dan peserta acara umum yang dimulai dalam rentang offset dari sekarang
+func GetJadwalPengingat(db *sql.DB, offset time.Duration) ([]models.TargetPengingat, error) {
+	query := `
+		SELECT
+			j.id_jadwal, j.user_id, u.email, u.full_name,
+			'Jadwal wawancara', j.tempat,
+			j.waktu_mulai, j.zona_waktu, TRUE
+		FROM jadwal j
+		INNER JOIN users u ON j.user_id = u.id_user
+		WHERE j.jenis_jadwal = 'pribadi'
+		  AND j.konfirmasi_jadwal <> 'ditolak'
+		  AND j.waktu_mulai > UTC_TIMESTAMP()
+		  AND j.waktu_mulai <= UTC_TIMESTAMP() + INTERVAL ? SECOND
+		UNION ALL
+		SELECT
+			j.id_jadwal, pj.user_id, u.email, u.full_name,
+			'Acara umum', j.tempat,
+			j.waktu_mulai, j.zona_waktu, FALSE
+		FROM jadwal j
+		INNER JOIN peserta_jadwal pj ON pj.id_jadwal = j.id_jadwal AND pj.status = 'terdaftar'
+		INNER JOIN users u ON pj.user_id = u.id_user
+		WHERE j.jenis_jadwal = 'umum'
+		  AND j.waktu_mulai > UTC_TIMESTAMP()
+		  AND j.waktu_mulai <= UTC_TIMESTAMP() + INTERVAL ? SECOND
+	`
+	detik := int(offset.Seconds())
+	rows, err := db.Query(query, detik, detik)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTargetPengingat(rows, "jadwal")
+}
+
+// GetTestPengingat mengambil pendaftar yang ditugaskan dan belum mengikuti tes aktif yang akan dibuka dalam rentang offset
+func GetTestPengingat(db *sql.DB, offset time.Duration) ([]models.TargetPengingat, error) {
+	query := `
+		SELECT DISTINCT
+			t.id_test, u.id_user, u.email, u.full_name,
+			t.judul, 'Online', t.waktu_mulai, ?, FALSE
+		FROM test t
+		INNER JOIN pendaftar p ON p.user_id IS NOT NULL AND p.status <> 'ditolak'
+		INNER JOIN users u ON p.user_id = u.id_user
+		WHERE t.aktif = TRUE
+		  AND t.waktu_mulai IS NOT NULL
+		  AND t.waktu_mulai > NOW()
+		  AND t.waktu_mulai <= NOW() + INTERVAL ? SECOND
+		  AND (t.target_peserta = 'semua' OR EXISTS (
+			SELECT 1 FROM test_peserta tp
+			WHERE tp.id_test = t.id_test AND tp.pendaftar_id = p.id_pendaftar
+		  ))
+		  AND NOT EXISTS (
+			SELECT 1 FROM hasil_test ht
+			WHERE ht.user_id = u.id_user AND ht.id_test = t.id_test
+		  )
+	`
+	rows, err := db.Query(query, utils.DefaultZonaWaktu, int(offset.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTargetPengingat(rows, "test")
+}
+
+// KlaimPengingat mencatat pengingat sebelum dikirim; false berarti sudah pernah dikirim
+func KlaimPengingat(db *sql.DB, t models.TargetPengingat, offsetMenit int, kanal string) (bool, error) {
+	query := `
+		INSERT IGNORE INTO pengingat_terkirim (jenis, ref_id, user_id, offset_menit, kanal)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	res, err := db.Exec(query, t.Jenis, t.RefID, t.UserID, offsetMenit, kanal)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// BatalkanKlaimPengingat menghapus catatan pengingat yang gagal dikirim agar dicoba lagi
+func BatalkanKlaimPengingat(db *sql.DB, t models.TargetPengingat, offsetMenit int, kanal string) error {
+	query := `
+		DELETE FROM pengingat_terkirim
+		WHERE jenis = ? AND ref_id = ? AND user_id = ? AND offset_menit = ? AND kanal = ?
+	`
+	_, err := db.Exec(query, t.Jenis, t.RefID, t.UserID, offsetMenit, kanal)
+	return err
+}
+
+func formatOffsetPengingat(offset time.Duration) string {
+	switch {
+	case offset%(24*time.Hour) == 0:
+		return fmt.Sprintf("H-%d", int(offset.Hours()/24))
+	case offset%time.Hour == 0:
+		return fmt.Sprintf("%d jam lagi", int(offset.Hours()))
+	default:
+		return fmt.Sprintf("%d menit lagi", int(offset.Minutes()))
+	}
+}
+
+// toleransiLabelPengingat adalah selisih maksimum antara offset dan sisa waktu acara agar
+// pengingat masih diberi label offset; job berjalan berkala sehingga sisa waktu sedikit di bawah offset
+const toleransiLabelPengingat = 15 * time.Minute
+
+// labelPengingat memberi label offset (mis. "H-1") bila sisa waktu acara masih sekitar offset itu.
+// Target yang baru muncul di tengah jendela offset (mis. jadwal dibuat 3 jam sebelum mulai)
+// diberi label sesuai sisa waktu sebenarnya.
+func labelPengingat(mulai time.Time, offset time.Duration, now time.Time) string {
+	sisa := mulai.Sub(now)
+	if offset-sisa <= toleransiLabelPengingat {
+		return formatOffsetPengingat(offset)
+	}
+	if sisa >= time.Hour {
+		return formatOffsetPengingat(sisa.Truncate(time.Hour))
+	}
+	if sisa < time.Minute {
+		sisa = time.Minute
+	}
+	return formatOffsetPengingat(sisa.Truncate(time.Minute))
+}
+
+func isiPengingat(t models.TargetPengingat, label string) (string, string) {
+	waktu := utils.FormatWaktuLokal(t.Mulai, t.ZonaWaktu)
+	if t.Jenis == "test" {
+		judul := fmt.Sprintf("Pengingat Tes (%s): %s", label, t.Judul)
+		isi := fmt.Sprintf("Tes \"%s\" akan dibuka pada %s. Pastikan koneksi dan perangkat Anda siap.", t.Judul, waktu)
+		return judul, isi
+	}
+	judul := fmt.Sprintf("Pengingat Jadwal (%s)", label)
+	isi := fmt.Sprintf("%s pada %s di %s. Mohon hadir tepat waktu.", t.Judul, waktu, t.Tempat)
+	return judul, isi
+}
+
+func kirimPengingatKeTarget(db *sql.DB, t models.TargetPengingat, offset time.Duration, now time.Time) {
+	offsetMenit := int(offset.Minutes())
+	label := labelPengingat(t.Mulai, offset, now)
+	judul, isi := isiPengingat(t, label)
+
+	ok, err := KlaimPengingat(db, t, offsetMenit, KanalPengingatNotifikasi)
+	if err != nil {
+		log.Printf("Gagal klaim pengingat notifikasi %s#%d user %d: %v", t.Jenis, t.RefID, t.UserID, err)
+	} else if ok {
+		if err := KirimNotifikasiUser(db, t.UserID, KategoriNotifikasiPengingat, judul, isi); err != nil {
+			log.Printf("Gagal kirim pengingat notifikasi ke user %d: %v", t.UserID, err)
+			if err := BatalkanKlaimPengingat(db, t, offsetMenit, KanalPengingatNotifikasi); err != nil {
+				log.Printf("Gagal membatalkan klaim pengingat: %v", err)
+			}
+		}
+	}
+
+	if t.Wawancara && utils.KanalWhatsApp() != nil {
+		kirimPengingatWhatsApp(db, t, offset, label)
+	}
+
+	if t.Email == "" {
+		return
+	}
+	ok, err = KlaimPengingat(db, t, offsetMenit, KanalPengingatEmail)
+	if err != nil {
+		log.Printf("Gagal klaim pengingat email %s#%d user %d: %v", t.Jenis, t.RefID, t.UserID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	if err := utils.SendPengingatEmail(t.Email, judul, isi); err != nil {
+		log.Printf("Gagal kirim email pengingat ke %s: %v", t.Email, err)
+		if err := BatalkanKlaimPengingat(db, t, offsetMenit, KanalPengingatEmail); err != nil {
+			log.Printf("Gagal membatalkan klaim pengingat: %v", err)
+		}
+	}
+}
+
+// kirimPengingatWhatsApp mengirim pengingat wawancara ke nomor WhatsApp pendaftar
+func kirimPengingatWhatsApp(db *sql.DB, t models.TargetPengingat, offset time.Duration, label string) {
+	offsetMenit := int(offset.Minutes())
+	ok, err := KlaimPengingat(db, t, offsetMenit, KanalPengingatWhatsApp)
+	if err != nil {
+		log.Printf("Gagal klaim pengingat WhatsApp %s#%d user %d: %v", t.Jenis, t.RefID, t.UserID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	// Kunci yang sama membuat percobaan ulang memakai baris pesan_wa yang sama
+	kunci := fmt.Sprintf("pengingat:%s:%d:%d:%d", t.Jenis, t.RefID, t.UserID, offsetMenit)
+	err = KirimPesanWA(db, t.UserID, TemplateWAPengingatWawancara, map[string]string{
+		"Nama":   t.Nama,
+		"Judul":  strings.ToLower(t.Judul),
+		"Offset": label,
+		"Waktu":  utils.FormatWaktuLokal(t.Mulai, t.ZonaWaktu),
+		"Tempat": t.Tempat,
+	}, kunci)
+	if err != nil {
+		log.Printf("Gagal kirim pengingat WhatsApp ke user %d: %v", t.UserID, err)
+		if err := BatalkanKlaimPengingat(db, t, offsetMenit, KanalPengingatWhatsApp); err != nil {
+			log.Printf("Gagal membatalkan klaim pengingat: %v", err)
+		}
+	}
+}
+
+// KirimPengingat mengirim pengingat jadwal dan tes untuk setiap offset yang dikonfigurasi.
+// Offset diproses dari yang terkecil agar target yang sudah dekat tidak menerima pengingat offset besar sekaligus.
+func KirimPengingat(db *sql.DB, offsets []time.Duration) error {
+	now := time.Now()
+	urut := append([]time.Duration(nil), offsets...)
+	sort.Slice(urut, func(i, j int) bool { return urut[i] < urut[j] })
+
+	sudah := make(map[string]bool)
+	for _, offset := range urut {
+		jadwals, err := GetJadwalPengingat(db, offset)
+		if err != nil {
+			return fmt.Errorf("gagal ambil jadwal untuk pengingat: %v", err)
+		}
+		tests, err := GetTestPengingat(db, offset)
+		if err != nil {
+			return fmt.Errorf("gagal ambil tes untuk pengingat: %v", err)
+		}
+
+		for _, t := range append(jadwals, tests...) {
+			kunci := fmt.Sprintf("%s:%d:%d", t.Jenis, t.RefID, t.UserID)
+			if sudah[kunci] {
+				continue
+			}
+			sudah[kunci] = true
+			kirimPengingatKeTarget(db, t, offset, now)
+		}
+	}
+	return nil
+}
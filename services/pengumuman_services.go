@@ -1,21 +1,100 @@
 package services
 
 import (
-	"database/sql"
 	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
 )
 
-// CreatePengumuman membuat pengumuman baru
-func CreatePengumuman(db *sql.DB, p models.Pengumuman) error {
-	query := `INSERT INTO pengumuman (judul, isi) VALUES (?, ?)`
-	_, err := db.Exec(query, p.Judul, p.Isi)
-	return err
+// Sasaran pengumuman
+const (
+	SasaranPengumumanSemua         = "semua"
+	SasaranPengumumanPendaftar     = "pendaftar"
+	SasaranPengumumanPesertaTest   = "peserta_test"
+	SasaranPengumumanPesertaJadwal = "peserta_jadwal"
+)
+
+const (
+	StatusPengumumanDraf   = "draf"
+	StatusPengumumanTerbit = "terbit"
+)
+
+var ErrPengumumanTidakValid = errors.New("pengumuman tidak valid")
+
+const selectPengumuman = `
+	SELECT p.id_pengumuman, p.judul, p.isi, p.sasaran, p.status_pendaftar, p.id_test, p.id_jadwal,
+		p.status, p.publish_at, p.expire_at, p.notifikasi_terkirim_at, p.dibuat_oleh,
+		p.created_at, p.updated_at
+	FROM pengumuman p
+`
+
+// kondisiPengumumanTayang: pengumuman (alias p) sudah terbit, sudah lewat publish_at, dan belum berakhir
+const kondisiPengumumanTayang = `
+	p.status = 'terbit'
+	AND (p.publish_at IS NULL OR p.publish_at <= UTC_TIMESTAMP())
+	AND (p.expire_at IS NULL OR p.expire_at > UTC_TIMESTAMP())
+`
+
+// kondisiSasaranPengumuman: user (alias u) termasuk sasaran pengumuman (alias p). Peserta tes
+// mengikuti penugasan tes seperti pengingat tes: pendaftar yang ditolak tidak ikut.
+const kondisiSasaranPengumuman = `(
+	p.sasaran = 'semua'
+	OR (p.sasaran = 'pendaftar' AND EXISTS (
+		SELECT 1 FROM pendaftar pd
+		WHERE pd.user_id = u.id_user
+		  AND (p.status_pendaftar IS NULL OR pd.status = p.status_pendaftar)
+	))
+	OR (p.sasaran = 'peserta_test' AND EXISTS (
+		SELECT 1 FROM pendaftar pd
+		INNER JOIN test t ON t.id_test = p.id_test
+		WHERE pd.user_id = u.id_user AND pd.status <> 'ditolak'
+		  AND (t.target_peserta = 'semua' OR EXISTS (
+			SELECT 1 FROM test_peserta tp WHERE tp.id_test = t.id_test AND tp.pendaftar_id = pd.id_pendaftar
+		  ))
+	))
+	OR (p.sasaran = 'peserta_jadwal' AND (
+		EXISTS (
+			SELECT 1 FROM jadwal j
+			WHERE j.id_jadwal = p.id_jadwal AND j.jenis_jadwal = 'pribadi' AND j.user_id = u.id_user
+		)
+		OR EXISTS (
+			SELECT 1 FROM peserta_jadwal pj
+			WHERE pj.id_jadwal = p.id_jadwal AND pj.user_id = u.id_user AND pj.status = 'terdaftar'
+		)
+	))
+)`
+
+func scanPengumuman(row interface{ Scan(...any) error }) (*models.Pengumuman, error) {
+	var p models.Pengumuman
+	var statusPendaftar sql.NullString
+	var idTest, idJadwal, dibuatOleh sql.NullInt64
+	var publishAt, expireAt, terkirimAt sql.NullTime
+
+	err := row.Scan(&p.IDPengumuman, &p.Judul, &p.Isi, &p.Sasaran, &statusPendaftar, &idTest, &idJadwal,
+		&p.Status, &publishAt, &expireAt, &terkirimAt, &dibuatOleh, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	p.StatusPendaftar = nullStringPtr(statusPendaftar)
+	p.IDTest = nullIntPtr(idTest)
+	p.IDJadwal = nullIntPtr(idJadwal)
+	p.DibuatOleh = nullIntPtr(dibuatOleh)
+	if publishAt.Valid {
+		p.PublishAt = &publishAt.Time
+	}
+	if expireAt.Valid {
+		p.ExpireAt = &expireAt.Time
+	}
+	if terkirimAt.Valid {
+		p.NotifikasiTerkirimAt = &terkirimAt.Time
+	}
+	return &p, nil
 }
 
-// GetAllPengumuman mengambil semua pengumuman (terbaru dulu)
-func GetAllPengumuman(db *sql.DB) ([]models.Pengumuman, error) {
-	query := `SELECT id_pengumuman, judul, isi, created_at, updated_at FROM pengumuman ORDER BY created_at DESC`
-	rows, err := db.Query(query)
+func queryPengumuman(db *sql.DB, query string, args ...any) ([]models.Pengumuman, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -23,33 +102,129 @@ func GetAllPengumuman(db *sql.DB) ([]models.Pengumuman, error) {
 
 	var pengumumans []models.Pengumuman
 	for rows.Next() {
-		var p models.Pengumuman
-		err := rows.Scan(&p.IDPengumuman, &p.Judul, &p.Isi, &p.CreatedAt, &p.UpdatedAt)
+		p, err := scanPengumuman(rows)
 		if err != nil {
 			return nil, err
 		}
-		pengumumans = append(pengumumans, p)
+		pengumumans = append(pengumumans, *p)
 	}
 	return pengumumans, rows.Err()
 }
 
-// GetPengumumanByID mengambil satu pengumuman
-func GetPengumumanByID(db *sql.DB, id int) (*models.Pengumuman, error) {
-	query := `SELECT id_pengumuman, judul, isi, created_at, updated_at FROM pengumuman WHERE id_pengumuman = ?`
-	row := db.QueryRow(query, id)
+// validasiPengumuman memeriksa sasaran dan rentang waktu, lalu mengosongkan field sasaran
+// yang tidak berlaku untuk sasaran terpilih
+func validasiPengumuman(db *sql.DB, p *models.Pengumuman) error {
+	if p.Sasaran == "" {
+		p.Sasaran = SasaranPengumumanSemua
+	}
+	if p.Status == "" {
+		p.Status = StatusPengumumanTerbit
+	}
 
-	var p models.Pengumuman
-	err := row.Scan(&p.IDPengumuman, &p.Judul, &p.Isi, &p.CreatedAt, &p.UpdatedAt)
+	switch p.Sasaran {
+	case SasaranPengumumanSemua:
+		p.StatusPendaftar, p.IDTest = nil, nil
+	case SasaranPengumumanPendaftar:
+		p.IDTest = nil
+	case SasaranPengumumanPesertaTest:
+		p.StatusPendaftar = nil
+		if p.IDTest == nil {
+			return fmt.Errorf("%w: sasaran peserta_test wajib memiliki id_test", ErrPengumumanTidakValid)
+		}
+		if _, err := GetTestByID(db, *p.IDTest); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: tes tidak ditemukan", ErrPengumumanTidakValid)
+			}
+			return err
+		}
+	case SasaranPengumumanPesertaJadwal:
+		p.StatusPendaftar, p.IDTest = nil, nil
+		if p.IDJadwal == nil {
+			return fmt.Errorf("%w: sasaran peserta_jadwal wajib memiliki id_jadwal", ErrPengumumanTidakValid)
+		}
+	default:
+		return fmt.Errorf("%w: sasaran tidak dikenal", ErrPengumumanTidakValid)
+	}
+
+	if p.IDJadwal != nil {
+		var ada bool
+		if err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM jadwal WHERE id_jadwal = ?)`, *p.IDJadwal).Scan(&ada); err != nil {
+			return err
+		}
+		if !ada {
+			return fmt.Errorf("%w: jadwal tidak ditemukan", ErrPengumumanTidakValid)
+		}
+	}
+
+	if p.PublishAt != nil {
+		t := p.PublishAt.UTC()
+		p.PublishAt = &t
+	}
+	if p.ExpireAt != nil {
+		t := p.ExpireAt.UTC()
+		p.ExpireAt = &t
+		if p.PublishAt != nil && !p.ExpireAt.After(*p.PublishAt) {
+			return fmt.Errorf("%w: expire_at harus setelah publish_at", ErrPengumumanTidakValid)
+		}
+	}
+	return nil
+}
+
+// CreatePengumuman membuat pengumuman baru dan mengisi ID-nya
+func CreatePengumuman(db *sql.DB, p *models.Pengumuman) error {
+	if err := validasiPengumuman(db, p); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO pengumuman (judul, isi, sasaran, status_pendaftar, id_test, id_jadwal, status,
+			publish_at, expire_at, dibuat_oleh)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := db.Exec(query, p.Judul, p.Isi, p.Sasaran, p.StatusPendaftar, p.IDTest, p.IDJadwal, p.Status,
+		p.PublishAt, p.ExpireAt, p.DibuatOleh)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &p, nil
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	p.IDPengumuman = int(id)
+	return nil
+}
+
+// GetAllPengumuman mengambil semua pengumuman termasuk draf dan yang terjadwal (terbaru dulu)
+func GetAllPengumuman(db *sql.DB) ([]models.Pengumuman, error) {
+	return queryPengumuman(db, selectPengumuman+` ORDER BY COALESCE(p.publish_at, p.created_at) DESC`)
+}
+
+// GetPengumumanUntukUser mengambil pengumuman yang sedang tayang dan menyasar user (terbaru dulu)
+func GetPengumumanUntukUser(db *sql.DB, userID int) ([]models.Pengumuman, error) {
+	return queryPengumuman(db, selectPengumuman+`
+		INNER JOIN users u ON u.id_user = ?
+		WHERE `+kondisiPengumumanTayang+` AND `+kondisiSasaranPengumuman+`
+		ORDER BY COALESCE(p.publish_at, p.created_at) DESC
+	`, userID)
+}
+
+// GetPengumumanByID mengambil satu pengumuman
+func GetPengumumanByID(db *sql.DB, id int) (*models.Pengumuman, error) {
+	return scanPengumuman(db.QueryRow(selectPengumuman+` WHERE p.id_pengumuman = ?`, id))
 }
 
 // UpdatePengumuman memperbarui pengumuman
 func UpdatePengumuman(db *sql.DB, p *models.Pengumuman) error {
-	query := `UPDATE pengumuman SET judul = ?, isi = ?, updated_at = NOW() WHERE id_pengumuman = ?`
-	_, err := db.Exec(query, p.Judul, p.Isi, p.IDPengumuman)
+	if err := validasiPengumuman(db, p); err != nil {
+		return err
+	}
+	query := `
+		UPDATE pengumuman SET
+			judul = ?, isi = ?, sasaran = ?, status_pendaftar = ?, id_test = ?, id_jadwal = ?,
+			status = ?, publish_at = ?, expire_at = ?, updated_at = NOW()
+		WHERE id_pengumuman = ?
+	`
+	_, err := db.Exec(query, p.Judul, p.Isi, p.Sasaran, p.StatusPendaftar, p.IDTest, p.IDJadwal,
+		p.Status, p.PublishAt, p.ExpireAt, p.IDPengumuman)
 	return err
 }
 
@@ -58,4 +233,83 @@ func DeletePengumuman(db *sql.DB, id int) error {
 	query := `DELETE FROM pengumuman WHERE id_pengumuman = ?`
 	_, err := db.Exec(query, id)
 	return err
-}
\ No newline at end of file
+}
+
+// KirimNotifikasiPengumuman mengirim notifikasi pengumuman ke sasarannya bila pengumuman sudah
+// tayang dan belum pernah dikirim. terkirim bernilai false untuk draf, pengumuman terjadwal yang
+// belum waktunya, atau yang sudah dikirim sebelumnya.
+func KirimNotifikasiPengumuman(db *sql.DB, id int) (terkirim bool, err error) {
+	res, err := db.Exec(`
+		UPDATE pengumuman p SET p.notifikasi_terkirim_at = UTC_TIMESTAMP()
+		WHERE p.id_pengumuman = ? AND p.notifikasi_terkirim_at IS NULL AND `+kondisiPengumumanTayang, id)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	p, err := GetPengumumanByID(db, id)
+	if err != nil {
+		return false, err
+	}
+	if p.Sasaran == SasaranPengumumanSemua {
+		return true, KirimNotifikasiSemua(db, KategoriNotifikasiPengumuman, p.Judul, p.Isi)
+	}
+
+	rows, err := db.Query(`
+		SELECT u.id_user FROM users u
+		INNER JOIN pengumuman p ON p.id_pengumuman = ?
+		WHERE `+kondisiSasaranPengumuman, id)
+	if err != nil {
+		return false, err
+	}
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return false, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if err := KirimNotifikasiUsers(db, userIDs, KategoriNotifikasiPengumuman, p.Judul, p.Isi); err != nil {
+		log.Printf("Gagal kirim notifikasi pengumuman %d ke %d user: %v", id, len(userIDs), err)
+	}
+	return true, nil
+}
+
+// TerbitkanPengumumanTerjadwal mengirim notifikasi pengumuman terjadwal yang publish_at-nya sudah lewat
+func TerbitkanPengumumanTerjadwal(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT p.id_pengumuman FROM pengumuman p
+		WHERE p.notifikasi_terkirim_at IS NULL AND ` + kondisiPengumumanTayang)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if _, err := KirimNotifikasiPengumuman(db, id); err != nil {
+			log.Printf("Gagal menerbitkan pengumuman terjadwal %d: %v", id, err)
+		}
+	}
+	return nil
+}
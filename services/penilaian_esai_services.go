@@ -0,0 +1,104 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+)
+
+var (
+	ErrBukanJawabanEsai  = errors.New("jawaban ini bukan jawaban soal esai")
+	ErrPoinMelebihiBobot = errors.New("poin melebihi bobot soal")
+	ErrSesiBelumSelesai  = errors.New("sesi tes belum selesai")
+)
+
+// GetAntrianPenilaian mengambil jawaban esai yang menunggu dinilai; idTest 0 berarti semua tes
+func GetAntrianPenilaian(db *sql.DB, idTest int) ([]models.JawabanMenungguPenilaian, error) {
+	query := `
+		SELECT
+			ju.id_jawaban, ju.id_hasil, ht.id_test, t.judul, ht.user_id, u.full_name,
+			s.id_soal, COALESCE(v.pertanyaan, s.pertanyaan), COALESCE(v.poin, s.poin), ju.jawaban_user, ju.updated_at
+		FROM jawaban_user ju
+		INNER JOIN hasil_test ht ON ju.id_hasil = ht.id_hasil
+		INNER JOIN test t ON ht.id_test = t.id_test
+		INNER JOIN users u ON ht.user_id = u.id_user
+		INNER JOIN soal_test s ON ju.id_soal = s.id_soal
+		LEFT JOIN versi_soal v ON v.id_versi = ju.id_versi
+		WHERE ju.status_penilaian = 'menunggu'
+		  AND ht.status = 'selesai'
+		  AND (? = 0 OR ht.id_test = ?)
+		ORDER BY ht.waktu_selesai ASC, ju.id_jawaban ASC
+	`
+	rows, err := db.Query(query, idTest, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.JawabanMenungguPenilaian{}
+	for rows.Next() {
+		var j models.JawabanMenungguPenilaian
+		err := rows.Scan(
+			&j.IDJawaban, &j.IDHasil, &j.IDTest, &j.JudulTest, &j.UserID, &j.NamaPeserta,
+			&j.IDSoal, &j.Pertanyaan, &j.PoinMaks, &j.Jawaban, &j.DikirimAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, j)
+	}
+	return list, rows.Err()
+}
+
+// NilaiJawabanEsai menyimpan poin esai dari penilai lalu menghitung ulang nilai sesi
+func NilaiJawabanEsai(db *sql.DB, idJawaban int, poin float64, catatan *string, penilaiID int) (*models.HasilTest, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var idHasil, idTest int
+	var tipe, statusHasil string
+	var poinMaks float64
+	err = tx.QueryRow(`
+		SELECT ju.id_hasil, ht.id_test, ht.status, COALESCE(v.tipe, s.tipe), COALESCE(v.poin, s.poin)
+		FROM jawaban_user ju
+		INNER JOIN hasil_test ht ON ju.id_hasil = ht.id_hasil
+		INNER JOIN soal_test s ON ju.id_soal = s.id_soal
+		LEFT JOIN versi_soal v ON v.id_versi = ju.id_versi
+		WHERE ju.id_jawaban = ?
+		FOR UPDATE
+	`, idJawaban).Scan(&idHasil, &idTest, &statusHasil, &tipe, &poinMaks)
+	if err != nil {
+		return nil, err
+	}
+	if tipe != TipeSoalEsai {
+		return nil, ErrBukanJawabanEsai
+	}
+	if statusHasil != StatusHasilSelesai {
+		return nil, ErrSesiBelumSelesai
+	}
+	if poin > poinMaks {
+		return nil, ErrPoinMelebihiBobot
+	}
+
+	_, err = tx.Exec(`
+		UPDATE jawaban_user SET
+			poin = ?,
+			status_penilaian = ?,
+			catatan_penilai = ?,
+			dinilai_oleh = ?,
+			dinilai_at = UTC_TIMESTAMP()
+		WHERE id_jawaban = ?
+	`, poin, StatusPenilaianDinilai, catatan, penilaiID, idJawaban)
+	if err != nil {
+		return nil, err
+	}
+
+	hasil, err := hitungUlangHasil(tx, idHasil, idTest)
+	if err != nil {
+		return nil, err
+	}
+	return hasil, tx.Commit()
+}
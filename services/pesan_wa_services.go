@@ -0,0 +1,545 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+)
+
+// Status pengiriman pesan WhatsApp, berurutan kecuali gagal
+const (
+	StatusPesanWAAntre    = "antre"
+	StatusPesanWATerkirim = "terkirim"
+	StatusPesanWADiterima = "diterima"
+	StatusPesanWADibaca   = "dibaca"
+	StatusPesanWAGagal    = "gagal"
+)
+
+// Template pesan WhatsApp
+const (
+	TemplateWAPengingatWawancara  = "pengingat_wawancara"
+	TemplateWAPendaftaranDiterima = "pendaftaran_diterima"
+	TemplateWAPendaftaranDitolak  = "pendaftaran_ditolak"
+)
+
+const (
+	MaxLimitPesanWA = 200
+
+	// MaxPercobaanPesanWA membatasi percobaan kirim ulang pesan yang gagal sementara
+	MaxPercobaanPesanWA = 5
+
+	// MasaTungguStatusWAHari adalah lama callback untuk ID provider yang belum dikenal disimpan
+	MasaTungguStatusWAHari = 7
+)
+
+var (
+	ErrStatusPesanWATidakDikenal    = errors.New("status pesan tidak dikenal")
+	ErrKanalWANonaktif              = errors.New("gateway WhatsApp belum dikonfigurasi")
+	ErrPesanWATidakBisaDikirimUlang = errors.New("hanya pesan berstatus gagal yang bisa dikirim ulang")
+	ErrPenerimaWATidakAktif         = errors.New("penerima berhenti berlangganan WhatsApp atau tidak punya nomor")
+)
+
+var templatePesanWA = map[string]*template.Template{
+	TemplateWAPengingatWawancara: template.Must(template.New(TemplateWAPengingatWawancara).Parse(
+		"Halo {{.Nama}}, ini pengingat {{.Judul}} ({{.Offset}}) pada {{.Waktu}} di {{.Tempat}}. " +
+			"Mohon hadir tepat waktu.\n\n- Coconut Computer Club")),
+	TemplateWAPendaftaranDiterima: template.Must(template.New(TemplateWAPendaftaranDiterima).Parse(
+		"Halo {{.Nama}}, selamat! Pendaftaran Anda di Coconut Computer Club telah DITERIMA. " +
+			"Informasi tahap berikutnya dapat dilihat di dashboard COCOPEN.\n\n- Coconut Computer Club")),
+	TemplateWAPendaftaranDitolak: template.Must(template.New(TemplateWAPendaftaranDitolak).Parse(
+		"Halo {{.Nama}}, terima kasih telah mendaftar di Coconut Computer Club. Mohon maaf, " +
+			"pendaftaran Anda belum dapat kami terima kali ini. Tetap semangat!\n\n- Coconut Computer Club")),
+}
+
+// statusProviderWA memetakan status dari gateway (istilah umum provider atau istilah kita)
+var statusProviderWA = map[string]string{
+	"queued":      StatusPesanWAAntre,
+	"antre":       StatusPesanWAAntre,
+	"sent":        StatusPesanWATerkirim,
+	"terkirim":    StatusPesanWATerkirim,
+	"delivered":   StatusPesanWADiterima,
+	"diterima":    StatusPesanWADiterima,
+	"read":        StatusPesanWADibaca,
+	"dibaca":      StatusPesanWADibaca,
+	"failed":      StatusPesanWAGagal,
+	"undelivered": StatusPesanWAGagal,
+	"gagal":       StatusPesanWAGagal,
+}
+
+func renderPesanWA(nama string, data map[string]string) (string, error) {
+	tmpl, ok := templatePesanWA[nama]
+	if !ok {
+		return "", fmt.Errorf("template pesan WhatsApp %q tidak dikenal", nama)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// tujuanWAUser mengambil nomor WhatsApp pendaftaran terbaru milik user; aktif bernilai false bila user
+// berhenti berlangganan atau tidak punya nomor
+func tujuanWAUser(db *sql.DB, userID int) (nomor string, aktif bool, err error) {
+	var optOut sql.NullTime
+	var noWA sql.NullString
+	err = db.QueryRow(`
+		SELECT u.wa_opt_out_at,
+			(SELECT p.no_wa FROM pendaftar p WHERE p.user_id = u.id_user ORDER BY p.created_at DESC LIMIT 1)
+		FROM users u
+		WHERE u.id_user = ?
+	`, userID).Scan(&optOut, &noWA)
+	if err != nil {
+		return "", false, err
+	}
+	if optOut.Valid || !noWA.Valid || strings.TrimSpace(noWA.String) == "" {
+		return "", false, nil
+	}
+	return noWA.String, true, nil
+}
+
+// catatPesanWA menyimpan pesan dan mengembalikan id serta statusnya. Dengan kunci, pesan yang sama
+// (mis. satu pengingat) memakai baris yang sama saat dicoba ulang: baris antre/gagal diperbarui
+// isinya, baris yang sudah terkirim dikembalikan apa adanya agar tidak dikirim dua kali.
+func catatPesanWA(db *sql.DB, p models.PesanWA, kunci string) (int64, string, error) {
+	res, err := db.Exec(`
+		INSERT INTO pesan_wa (user_id, kanal, tujuan, template, isi, status, error_terakhir, kunci)
+		VALUES (?, ?, ?, ?, ?, ?, ?, NULLIF(?, ''))
+		ON DUPLICATE KEY UPDATE id_pesan = LAST_INSERT_ID(id_pesan)
+	`, p.UserID, p.Kanal, p.Tujuan, p.Template, p.Isi, p.Status, p.ErrorTerakhir, kunci)
+	if err != nil {
+		return 0, "", err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, "", err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return id, p.Status, err
+	}
+
+	var status string
+	if err := db.QueryRow(`SELECT status FROM pesan_wa WHERE id_pesan = ?`, id).Scan(&status); err != nil {
+		return 0, "", err
+	}
+	if status != StatusPesanWAAntre && status != StatusPesanWAGagal {
+		return id, status, nil
+	}
+	_, err = db.Exec(`UPDATE pesan_wa SET tujuan = ?, isi = ?, status = ?, error_terakhir = ? WHERE id_pesan = ?`,
+		p.Tujuan, p.Isi, p.Status, p.ErrorTerakhir, id)
+	return id, p.Status, err
+}
+
+// KirimPesanWA mengirim pesan template ke nomor WhatsApp pendaftaran terbaru milik user. Pesan
+// dilewati tanpa error bila gateway belum dikonfigurasi, user berhenti berlangganan, atau tidak
+// punya nomor. kunci (boleh kosong) mengenali pesan yang sama saat pemanggil mencoba ulang.
+// Error dikembalikan hanya untuk kegagalan sementara yang layak dicoba ulang dengan kunci yang sama.
+func KirimPesanWA(db *sql.DB, userID int, namaTemplate string, data map[string]string, kunci string) error {
+	kanal := utils.KanalWhatsApp()
+	if kanal == nil {
+		return nil
+	}
+
+	noWA, aktif, err := tujuanWAUser(db, userID)
+	if err != nil || !aktif {
+		return err
+	}
+
+	isi, err := renderPesanWA(namaTemplate, data)
+	if err != nil {
+		return err
+	}
+	pesan := models.PesanWA{
+		UserID:   &userID,
+		Kanal:    kanal.Nama(),
+		Template: namaTemplate,
+		Isi:      isi,
+		Status:   StatusPesanWAAntre,
+	}
+
+	tujuan, err := utils.NormalisasiNomorIndonesia(noWA)
+	if err != nil {
+		// Dicatat gagal agar admin bisa melihat nomor yang perlu diperbaiki; tidak dicoba ulang
+		pesan.Tujuan = utils.PotongTeks(noWA, 16)
+		pesan.Status = StatusPesanWAGagal
+		alasan := err.Error()
+		pesan.ErrorTerakhir = &alasan
+		_, _, err := catatPesanWA(db, pesan, kunci)
+		return err
+	}
+	pesan.Tujuan = tujuan
+
+	idPesan, status, err := catatPesanWA(db, pesan, kunci)
+	if err != nil || status != StatusPesanWAAntre {
+		return err
+	}
+	return kirimPesanTercatat(db, kanal, idPesan, tujuan, isi, kunci != "")
+}
+
+// kirimPesanTercatat mengirim pesan yang sudah dicatat berstatus antre. Kegagalan sementara
+// dikembalikan (pesan tetap antre) selama bisaDiulang dan percobaan belum habis; penolakan
+// gateway atau percobaan terakhir menandai pesan gagal dan memberi tahu admin.
+func kirimPesanTercatat(db *sql.DB, kanal utils.KanalPesan, idPesan int64, tujuan, isi string, bisaDiulang bool) error {
+	if _, err := db.Exec(`UPDATE pesan_wa SET percobaan = percobaan + 1 WHERE id_pesan = ?`, idPesan); err != nil {
+		return err
+	}
+	var percobaan int
+	if err := db.QueryRow(`SELECT percobaan FROM pesan_wa WHERE id_pesan = ?`, idPesan).Scan(&percobaan); err != nil {
+		return err
+	}
+
+	idProvider, errKirim := kanal.Kirim(tujuan, isi)
+	if errKirim == nil {
+		return tandaiPesanWATerkirim(db, kanal.Nama(), idPesan, idProvider)
+	}
+
+	alasan := utils.PotongTeks(errKirim.Error(), 255)
+	ditolak := errors.Is(errKirim, utils.ErrPesanDitolakGateway)
+	if bisaDiulang && !ditolak && percobaan < MaxPercobaanPesanWA {
+		if _, err := db.Exec(`UPDATE pesan_wa SET error_terakhir = ? WHERE id_pesan = ?`, alasan, idPesan); err != nil {
+			log.Printf("Gagal mencatat kegagalan pesan WhatsApp %d: %v", idPesan, err)
+		}
+		return errKirim
+	}
+
+	_, err := db.Exec(`UPDATE pesan_wa SET status = ?, error_terakhir = ? WHERE id_pesan = ?`,
+		StatusPesanWAGagal, alasan, idPesan)
+	if err != nil {
+		log.Printf("Gagal mencatat kegagalan pesan WhatsApp %d: %v", idPesan, err)
+	}
+	peringatkanAdminPesanWA(db, idPesan, tujuan, alasan)
+	return nil
+}
+
+// tandaiPesanWATerkirim menyimpan ID provider lalu menerapkan callback status yang datang lebih
+// dulu daripada ID tersebut tersimpan
+func tandaiPesanWATerkirim(db *sql.DB, kanal string, idPesan int64, idProvider string) error {
+	_, err := db.Exec(`
+		UPDATE pesan_wa SET status = ?, id_provider = ?, error_terakhir = NULL
+		WHERE id_pesan = ? AND status = ?
+	`, StatusPesanWATerkirim, idProvider, idPesan, StatusPesanWAAntre)
+	if err != nil {
+		return err
+	}
+	return terapkanStatusWATertunda(db, kanal, idProvider)
+}
+
+// peringatkanAdminPesanWA memberi tahu admin lewat kotak masuk bahwa sebuah pesan gagal terkirim
+func peringatkanAdminPesanWA(db *sql.DB, idPesan int64, tujuan, alasan string) {
+	role := "admin"
+	err := KirimNotifikasi(db, &models.Notifikasi{
+		Target:   TargetNotifikasiRole,
+		Role:     &role,
+		Kategori: KategoriNotifikasiPesanWA,
+		Judul:    "Pesan WhatsApp gagal terkirim",
+		Isi: fmt.Sprintf("Pesan #%d ke %s gagal: %s. Periksa nomor penerima lalu kirim ulang dari log pesan WhatsApp.",
+			idPesan, tujuan, alasan),
+	})
+	if err != nil {
+		log.Printf("Gagal memberi tahu admin tentang pesan WhatsApp %d: %v", idPesan, err)
+	}
+}
+
+// KirimUlangPesanWA mengirim ulang pesan berstatus gagal (admin) ke nomor terbaru penerimanya.
+// sql.ErrNoRows bila pesan tidak ada.
+func KirimUlangPesanWA(db *sql.DB, idPesan int64) error {
+	kanal := utils.KanalWhatsApp()
+	if kanal == nil {
+		return ErrKanalWANonaktif
+	}
+
+	var userID sql.NullInt64
+	var tujuan, isi, status string
+	err := db.QueryRow(`SELECT user_id, tujuan, isi, status FROM pesan_wa WHERE id_pesan = ?`, idPesan).
+		Scan(&userID, &tujuan, &isi, &status)
+	if err != nil {
+		return err
+	}
+	if status != StatusPesanWAGagal {
+		return ErrPesanWATidakBisaDikirimUlang
+	}
+
+	if userID.Valid {
+		noWA, aktif, err := tujuanWAUser(db, int(userID.Int64))
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if !aktif {
+			return ErrPenerimaWATidakAktif
+		}
+		if tujuan, err = utils.NormalisasiNomorIndonesia(noWA); err != nil {
+			return err
+		}
+	}
+
+	res, err := db.Exec(`
+		UPDATE pesan_wa SET status = ?, tujuan = ?, error_terakhir = NULL
+		WHERE id_pesan = ? AND status = ?
+	`, StatusPesanWAAntre, tujuan, idPesan, StatusPesanWAGagal)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrPesanWATidakBisaDikirimUlang
+		}
+		return err
+	}
+	return kirimPesanTercatat(db, kanal, idPesan, tujuan, isi, false)
+}
+
+// kirimPesanWALatar menjalankan KirimPesanWA di goroutine agar request tidak menunggu gateway
+func kirimPesanWALatar(db *sql.DB, userID int, namaTemplate string, data map[string]string) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Pengiriman WhatsApp %s ke user %d panic: %v", namaTemplate, userID, r)
+			}
+		}()
+		if err := KirimPesanWA(db, userID, namaTemplate, data, ""); err != nil {
+			log.Printf("Gagal kirim WhatsApp %s ke user %d: %v", namaTemplate, userID, err)
+		}
+	}()
+}
+
+// terapkanStatusPesanWA menerapkan satu status ke pesan dengan ID provider tersebut. Status hanya
+// bergerak maju (antre, terkirim, diterima, dibaca); gagal hanya berlaku sebelum pesan diterima.
+func terapkanStatusPesanWA(db *sql.DB, kanal, idProvider, status string, errorTerakhir *string) (bool, error) {
+	query := `
+		UPDATE pesan_wa SET status = ?, error_terakhir = COALESCE(?, error_terakhir)
+		WHERE kanal = ? AND id_provider = ? AND status <> 'gagal'
+		  AND FIELD(status, 'antre', 'terkirim', 'diterima', 'dibaca') < FIELD(?, 'antre', 'terkirim', 'diterima', 'dibaca')
+	`
+	args := []any{status, errorTerakhir, kanal, idProvider, status}
+	if status == StatusPesanWAGagal {
+		query = `
+			UPDATE pesan_wa SET status = ?, error_terakhir = COALESCE(?, error_terakhir)
+			WHERE kanal = ? AND id_provider = ? AND status IN ('antre', 'terkirim')
+		`
+		args = args[:4]
+	}
+
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	if status == StatusPesanWAGagal {
+		var idPesan int64
+		var tujuan string
+		err := db.QueryRow(`SELECT id_pesan, tujuan FROM pesan_wa WHERE kanal = ? AND id_provider = ?`, kanal, idProvider).
+			Scan(&idPesan, &tujuan)
+		if err != nil {
+			return true, err
+		}
+		alasan := "gagal dikirim gateway"
+		if errorTerakhir != nil {
+			alasan = *errorTerakhir
+		}
+		peringatkanAdminPesanWA(db, idPesan, tujuan, alasan)
+	}
+	return true, nil
+}
+
+// terapkanStatusWATertunda menerapkan callback yang tersimpan untuk ID provider lalu menghapusnya
+func terapkanStatusWATertunda(db *sql.DB, kanal, idProvider string) error {
+	rows, err := db.Query(`
+		SELECT status, error_terakhir FROM status_wa_tertunda
+		WHERE kanal = ? AND id_provider = ?
+		ORDER BY FIELD(status, 'antre', 'terkirim', 'diterima', 'dibaca', 'gagal')
+	`, kanal, idProvider)
+	if err != nil {
+		return err
+	}
+	type tertunda struct {
+		status        string
+		errorTerakhir *string
+	}
+	var daftar []tertunda
+	for rows.Next() {
+		var t tertunda
+		var e sql.NullString
+		if err := rows.Scan(&t.status, &e); err != nil {
+			rows.Close()
+			return err
+		}
+		t.errorTerakhir = nullStringPtr(e)
+		daftar = append(daftar, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(daftar) == 0 {
+		return err
+	}
+
+	for _, t := range daftar {
+		if _, err := terapkanStatusPesanWA(db, kanal, idProvider, t.status, t.errorTerakhir); err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`DELETE FROM status_wa_tertunda WHERE kanal = ? AND id_provider = ?`, kanal, idProvider)
+	return err
+}
+
+// PerbaruiStatusPesanWA menerapkan callback status dari gateway. Callback berulang atau terlambat
+// diabaikan tanpa error. Callback yang tiba sebelum ID provider tersimpan (gateway lebih cepat
+// daripada respons kirim) disimpan di status_wa_tertunda dan diterapkan saat ID itu tersimpan.
+func PerbaruiStatusPesanWA(db *sql.DB, kanal, idProvider, statusProvider, pesanError string) error {
+	status, ok := statusProviderWA[strings.ToLower(strings.TrimSpace(statusProvider))]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrStatusPesanWATidakDikenal, statusProvider)
+	}
+
+	var errorTerakhir *string
+	if status == StatusPesanWAGagal && pesanError != "" {
+		e := utils.PotongTeks(pesanError, 255)
+		errorTerakhir = &e
+	}
+
+	diterapkan, err := terapkanStatusPesanWA(db, kanal, idProvider, status, errorTerakhir)
+	if err != nil || diterapkan {
+		return err
+	}
+
+	ada, err := pesanWAAda(db, kanal, idProvider)
+	if err != nil || ada {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO status_wa_tertunda (kanal, id_provider, status, error_terakhir)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE error_terakhir = COALESCE(VALUES(error_terakhir), error_terakhir)
+	`, kanal, idProvider, status, errorTerakhir)
+	if err != nil {
+		return err
+	}
+	// Callback untuk ID yang tidak pernah tersimpan dibuang setelah masa tunggu
+	if _, err := db.Exec(`DELETE FROM status_wa_tertunda WHERE diterima_at < UTC_TIMESTAMP() - INTERVAL ? DAY`,
+		MasaTungguStatusWAHari); err != nil {
+		log.Printf("Gagal membersihkan status WhatsApp tertunda: %v", err)
+	}
+
+	// ID bisa saja tersimpan di antara pemeriksaan di atas dan penyimpanan callback
+	if ada, err := pesanWAAda(db, kanal, idProvider); err != nil || !ada {
+		return err
+	}
+	return terapkanStatusWATertunda(db, kanal, idProvider)
+}
+
+func pesanWAAda(db *sql.DB, kanal, idProvider string) (bool, error) {
+	var ada bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pesan_wa WHERE kanal = ? AND id_provider = ?)`,
+		kanal, idProvider).Scan(&ada)
+	return ada, err
+}
+
+const selectPesanWA = `
+	SELECT pw.id_pesan, pw.user_id, u.full_name, pw.kanal, pw.tujuan, pw.template, pw.isi,
+		pw.id_provider, pw.status, pw.error_terakhir, pw.percobaan, pw.created_at, pw.updated_at
+	FROM pesan_wa pw
+	LEFT JOIN users u ON u.id_user = pw.user_id
+`
+
+func scanPesanWA(row interface{ Scan(...any) error }) (models.PesanWA, error) {
+	var p models.PesanWA
+	var userIDPesan sql.NullInt64
+	var nama, idProvider, errorTerakhir sql.NullString
+	if err := row.Scan(&p.IDPesan, &userIDPesan, &nama, &p.Kanal, &p.Tujuan, &p.Template, &p.Isi,
+		&idProvider, &p.Status, &errorTerakhir, &p.Percobaan, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return p, err
+	}
+	p.UserID = nullIntPtr(userIDPesan)
+	p.NamaUser = nullStringPtr(nama)
+	p.IDProvider = nullStringPtr(idProvider)
+	p.ErrorTerakhir = nullStringPtr(errorTerakhir)
+	return p, nil
+}
+
+// GetPesanWA mengambil log pesan WhatsApp terbaru, opsional per user dan per status
+func GetPesanWA(db *sql.DB, userID int, status string, limit int) ([]models.PesanWA, error) {
+	query := selectPesanWA + ` WHERE 1 = 1`
+	var args []any
+	if userID > 0 {
+		query += ` AND pw.user_id = ?`
+		args = append(args, userID)
+	}
+	if status != "" {
+		query += ` AND pw.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY pw.id_pesan DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	daftar := []models.PesanWA{}
+	for rows.Next() {
+		p, err := scanPesanWA(rows)
+		if err != nil {
+			return nil, err
+		}
+		daftar = append(daftar, p)
+	}
+	return daftar, rows.Err()
+}
+
+// GetPesanWAByID mengambil satu pesan WhatsApp (sql.ErrNoRows bila tidak ada)
+func GetPesanWAByID(db *sql.DB, idPesan int64) (*models.PesanWA, error) {
+	p, err := scanPesanWA(db.QueryRow(selectPesanWA+` WHERE pw.id_pesan = ?`, idPesan))
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPreferensiWA mengambil status langganan WhatsApp user dan nomor yang akan dipakai
+func GetPreferensiWA(db *sql.DB, userID int) (*models.PreferensiWA, error) {
+	var optOut sql.NullTime
+	var noWA sql.NullString
+	err := db.QueryRow(`
+		SELECT u.wa_opt_out_at,
+			(SELECT p.no_wa FROM pendaftar p WHERE p.user_id = u.id_user ORDER BY p.created_at DESC LIMIT 1)
+		FROM users u
+		WHERE u.id_user = ?
+	`, userID).Scan(&optOut, &noWA)
+	if err != nil {
+		return nil, err
+	}
+
+	pref := &models.PreferensiWA{Aktif: !optOut.Valid}
+	if optOut.Valid {
+		pref.OptOutAt = &optOut.Time
+	}
+	if noWA.Valid && noWA.String != "" {
+		nomor := noWA.String
+		if e164, err := utils.NormalisasiNomorIndonesia(nomor); err == nil {
+			nomor = e164
+			pref.NomorValid = true
+		}
+		pref.Nomor = &nomor
+	}
+	return pref, nil
+}
+
+// SetPreferensiWA mengaktifkan atau menghentikan pesan WhatsApp untuk user
+func SetPreferensiWA(db *sql.DB, userID int, aktif bool) error {
+	query := `UPDATE users SET wa_opt_out_at = NULL WHERE id_user = ?`
+	if !aktif {
+		query = `UPDATE users SET wa_opt_out_at = COALESCE(wa_opt_out_at, UTC_TIMESTAMP()) WHERE id_user = ?`
+	}
+	_, err := db.Exec(query, userID)
+	return err
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	StatusPesertaTerdaftar = "terdaftar"
+	StatusPesertaWaitlist  = "waitlist"
+)
+
+var (
+	ErrBukanJadwalUmum     = errors.New("jadwal bukan jadwal umum")
+	ErrPendaftaranDitutup  = errors.New("pendaftaran jadwal sudah ditutup")
+	ErrSudahTerdaftarAcara = errors.New("user sudah terdaftar pada jadwal ini")
+
+	ErrKapasitasDiBawahPeserta = errors.New("kapasitas lebih kecil dari jumlah peserta terdaftar")
+)
+
+type jadwalTerkunci struct {
+	jenis            string
+	kapasitas        sql.NullInt64
+	batasPendaftaran sql.NullTime
+	sudahMulai       bool
+}
+
+func kunciJadwal(tx *sql.Tx, idJadwal int) (*jadwalTerkunci, error) {
+	var j jadwalTerkunci
+	err := tx.QueryRow(`
+		SELECT jenis_jadwal, kapasitas, batas_pendaftaran, waktu_mulai <= UTC_TIMESTAMP()
+		FROM jadwal
+		WHERE id_jadwal = ?
+		FOR UPDATE
+	`, idJadwal).Scan(&j.jenis, &j.kapasitas, &j.batasPendaftaran, &j.sudahMulai)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+func hitungPesertaTerdaftar(tx *sql.Tx, idJadwal int) (int, error) {
+	var n int
+	err := tx.QueryRow(
+		`SELECT COUNT(*) FROM peserta_jadwal WHERE id_jadwal = ? AND status = ?`,
+		idJadwal, StatusPesertaTerdaftar,
+	).Scan(&n)
+	return n, err
+}
+
+// promosikanWaitlist memindahkan peserta waitlist terlama ke terdaftar selama kapasitas masih ada
+func promosikanWaitlist(tx *sql.Tx, idJadwal int, kapasitas sql.NullInt64) ([]int, error) {
+	terdaftar, err := hitungPesertaTerdaftar(tx, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+
+	var promoted []int
+	for !kapasitas.Valid || int64(terdaftar) < kapasitas.Int64 {
+		var idPeserta, userID int
+		err := tx.QueryRow(`
+			SELECT id_peserta, user_id FROM peserta_jadwal
+			WHERE id_jadwal = ? AND status = ?
+			ORDER BY created_at ASC, id_peserta ASC
+			LIMIT 1
+		`, idJadwal, StatusPesertaWaitlist).Scan(&idPeserta, &userID)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(`UPDATE peserta_jadwal SET status = ? WHERE id_peserta = ?`, StatusPesertaTerdaftar, idPeserta); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, userID)
+		terdaftar++
+	}
+	return promoted, nil
+}
+
+// DaftarJadwalUmum mendaftarkan user ke jadwal umum; jika kapasitas penuh user masuk waitlist
+func DaftarJadwalUmum(db *sql.DB, idJadwal, userID int) (string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	j, err := kunciJadwal(tx, idJadwal)
+	if err != nil {
+		return "", err
+	}
+	if j.jenis != "umum" {
+		return "", ErrBukanJadwalUmum
+	}
+	if j.sudahMulai || (j.batasPendaftaran.Valid && time.Now().After(j.batasPendaftaran.Time)) {
+		return "", ErrPendaftaranDitutup
+	}
+
+	var exists bool
+	err = tx.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM peserta_jadwal WHERE id_jadwal = ? AND user_id = ?)`,
+		idJadwal, userID,
+	).Scan(&exists)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", ErrSudahTerdaftarAcara
+	}
+
+	terdaftar, err := hitungPesertaTerdaftar(tx, idJadwal)
+	if err != nil {
+		return "", err
+	}
+
+	status := StatusPesertaTerdaftar
+	if j.kapasitas.Valid && int64(terdaftar) >= j.kapasitas.Int64 {
+		status = StatusPesertaWaitlist
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO peserta_jadwal (id_jadwal, user_id, status) VALUES (?, ?, ?)`,
+		idJadwal, userID, status,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return status, tx.Commit()
+}
+
+// BatalDaftarJadwalUmum membatalkan pendaftaran dan mengembalikan user waitlist yang naik menjadi terdaftar
+func BatalDaftarJadwalUmum(db *sql.DB, idJadwal, userID int) ([]int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	j, err := kunciJadwal(tx, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	if j.sudahMulai {
+		return nil, ErrPendaftaranDitutup
+	}
+
+	res, err := tx.Exec(`DELETE FROM peserta_jadwal WHERE id_jadwal = ? AND user_id = ?`, idJadwal, userID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	promoted, err := promosikanWaitlist(tx, idJadwal, j.kapasitas)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, tx.Commit()
+}
+
+// PromosikanWaitlistJadwal dipanggil setelah kapasitas jadwal berubah
+func PromosikanWaitlistJadwal(db *sql.DB, idJadwal int) ([]int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	j, err := kunciJadwal(tx, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	if j.sudahMulai {
+		return nil, nil
+	}
+
+	promoted, err := promosikanWaitlist(tx, idJadwal, j.kapasitas)
+	if err != nil {
+		return nil, err
+	}
+
+	return promoted, tx.Commit()
+}
+
+// GetPesertaJadwal mengambil daftar peserta jadwal umum, terdaftar lebih dulu lalu waitlist
+func GetPesertaJadwal(db *sql.DB, idJadwal int) ([]models.PesertaJadwal, error) {
+	query := `
+		SELECT
+			pj.id_peserta, pj.id_jadwal, pj.user_id, pj.status, pj.created_at,
+			u.full_name, u.email,
+			(SELECT p.no_wa FROM pendaftar p WHERE p.user_id = u.id_user ORDER BY p.created_at DESC LIMIT 1)
+		FROM peserta_jadwal pj
+		INNER JOIN users u ON pj.user_id = u.id_user
+		WHERE pj.id_jadwal = ?
+		ORDER BY FIELD(pj.status, 'terdaftar', 'waitlist'), pj.created_at ASC, pj.id_peserta ASC
+	`
+	rows, err := db.Query(query, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var peserta []models.PesertaJadwal
+	for rows.Next() {
+		var p models.PesertaJadwal
+		var noWA sql.NullString
+		err := rows.Scan(&p.IDPeserta, &p.IDJadwal, &p.UserID, &p.Status, &p.CreatedAt, &p.Nama, &p.Email, &noWA)
+		if err != nil {
+			return nil, err
+		}
+		if noWA.Valid {
+			p.NoWA = noWA.String
+		}
+		peserta = append(peserta, p)
+	}
+	return peserta, rows.Err()
+}
+
+// GetJumlahPesertaJadwal menghitung peserta terdaftar dan waitlist pada jadwal
+func GetJumlahPesertaJadwal(db *sql.DB, idJadwal int) (int, int, error) {
+	var terdaftar, waitlist int
+	err := db.QueryRow(`
+		SELECT
+			COALESCE(SUM(status = 'terdaftar'), 0),
+			COALESCE(SUM(status = 'waitlist'), 0)
+		FROM peserta_jadwal
+		WHERE id_jadwal = ?
+	`, idJadwal).Scan(&terdaftar, &waitlist)
+	return terdaftar, waitlist, err
+}
+
+// GetStatusPesertaUser mengambil status pendaftaran user pada jadwal; string kosong jika belum mendaftar
+func GetStatusPesertaUser(db *sql.DB, idJadwal, userID int) (string, error) {
+	var status string
+	err := db.QueryRow(
+		`SELECT status FROM peserta_jadwal WHERE id_jadwal = ? AND user_id = ?`,
+		idJadwal, userID,
+	).Scan(&status)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return status, err
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MaxDimensiPsikotes = 10
+	MaxPanjangKode     = 10
+	// MaxPanjangTipeKepribadian sama dengan panjang kolom hasil_test.tipe_kepribadian
+	MaxPanjangTipeKepribadian = 50
+)
+
+var ErrModelPsikotesTidakValid = errors.New("model penilaian psikotes tidak valid")
+
+// GetModelPsikotes mengambil dimensi psikotes sebuah tes beserta bobot pilihannya
+func GetModelPsikotes(db *sql.DB, idTest int) ([]models.DimensiPsikotes, error) {
+	rows, err := db.Query(`
+		SELECT id_dimensi, id_test, urutan, kode_a, nama_a, kode_b, nama_b
+		FROM dimensi_psikotes
+		WHERE id_test = ?
+		ORDER BY urutan
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	dimensi := []models.DimensiPsikotes{}
+	indeks := map[int]int{}
+	for rows.Next() {
+		d := models.DimensiPsikotes{Bobot: []models.BobotPsikotes{}}
+		if err := rows.Scan(&d.IDDimensi, &d.IDTest, &d.Urutan, &d.KodeA, &d.NamaA, &d.KodeB, &d.NamaB); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		indeks[d.IDDimensi] = len(dimensi)
+		dimensi = append(dimensi, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.Query(`
+		SELECT b.id_dimensi, b.id_soal, v.versi, b.label, b.bobot
+		FROM bobot_psikotes b
+		INNER JOIN dimensi_psikotes d ON d.id_dimensi = b.id_dimensi
+		INNER JOIN versi_soal v ON v.id_versi = b.id_versi
+		WHERE d.id_test = ?
+		ORDER BY b.id_dimensi, b.id_soal, v.versi, b.label
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idDimensi int
+		var b models.BobotPsikotes
+		if err := rows.Scan(&idDimensi, &b.IDSoal, &b.Versi, &b.Label, &b.Bobot); err != nil {
+			return nil, err
+		}
+		if i, ok := indeks[idDimensi]; ok {
+			dimensi[i].Bobot = append(dimensi[i].Bobot, b)
+		}
+	}
+	return dimensi, rows.Err()
+}
+
+// validasiModelPsikotes memastikan kode kutub unik dan setiap bobot merujuk pilihan versi soal psikotes
+// di tes. versi berisi versi soal tes, dengan kunci id soal dan nomor versi.
+func validasiModelPsikotes(dimensi []models.DimensiPsikotes, soals []models.SoalTest, versi map[[2]int]*models.VersiSoal) error {
+	if len(dimensi) > MaxDimensiPsikotes {
+		return fmt.Errorf("%w: maksimal %d dimensi", ErrModelPsikotesTidakValid, MaxDimensiPsikotes)
+	}
+	soalMap := make(map[int]*models.SoalTest, len(soals))
+	for i := range soals {
+		soalMap[soals[i].IDSoal] = &soals[i]
+	}
+
+	kode := map[string]bool{}
+	panjangTipe := 0
+	for i, d := range dimensi {
+		panjangDimensi := 0
+		for _, k := range []string{d.KodeA, d.KodeB} {
+			k = strings.ToUpper(strings.TrimSpace(k))
+			n := utf8.RuneCountInString(k)
+			panjangDimensi = max(panjangDimensi, n)
+			if k == "" || n > MaxPanjangKode {
+				return fmt.Errorf("%w: kode kutub dimensi %d wajib diisi (maks %d karakter)", ErrModelPsikotesTidakValid, i+1, MaxPanjangKode)
+			}
+			if kode[k] {
+				return fmt.Errorf("%w: kode kutub %q dipakai lebih dari sekali", ErrModelPsikotesTidakValid, k)
+			}
+			kode[k] = true
+		}
+		// Tipe kepribadian menggabungkan satu kode per dimensi, jadi kode terpanjang yang menentukan
+		panjangTipe += panjangDimensi
+		if panjangTipe > MaxPanjangTipeKepribadian {
+			return fmt.Errorf("%w: gabungan kode kutub terpanjang tiap dimensi maksimal %d karakter",
+				ErrModelPsikotesTidakValid, MaxPanjangTipeKepribadian)
+		}
+
+		type pilihanSoal struct {
+			idSoal, versi int
+			label         string
+		}
+		pilihan := map[pilihanSoal]bool{}
+		for _, b := range d.Bobot {
+			if _, ok := soalMap[b.IDSoal]; !ok {
+				return fmt.Errorf("%w: soal %d tidak ada pada tes ini", ErrModelPsikotesTidakValid, b.IDSoal)
+			}
+			v, ok := versi[[2]int{b.IDSoal, b.Versi}]
+			if !ok {
+				return fmt.Errorf("%w: soal %d tidak memiliki versi %d", ErrModelPsikotesTidakValid, b.IDSoal, b.Versi)
+			}
+			if v.Tipe != TipeSoalPsikotes {
+				return fmt.Errorf("%w: soal %d versi %d bukan soal psikotes", ErrModelPsikotesTidakValid, b.IDSoal, b.Versi)
+			}
+			if _, err := parseLabelPilihan(b.Label, len(v.Pilihan)); err != nil || len(b.Label) != 1 {
+				return fmt.Errorf("%w: pilihan %q tidak ada pada soal %d versi %d", ErrModelPsikotesTidakValid, b.Label, b.IDSoal, b.Versi)
+			}
+			if b.Bobot == 0 {
+				return fmt.Errorf("%w: bobot soal %d pilihan %s tidak boleh 0", ErrModelPsikotesTidakValid, b.IDSoal, b.Label)
+			}
+			k := pilihanSoal{b.IDSoal, b.Versi, strings.ToUpper(b.Label)}
+			if pilihan[k] {
+				return fmt.Errorf("%w: bobot soal %d versi %d pilihan %s diisi lebih dari sekali pada dimensi %d",
+					ErrModelPsikotesTidakValid, b.IDSoal, b.Versi, b.Label, i+1)
+			}
+			pilihan[k] = true
+		}
+	}
+	return nil
+}
+
+// SimpanModelPsikotes mengganti seluruh model penilaian psikotes sebuah tes, lalu menghitung ulang
+// profil setiap sesi tes tersebut dengan model baru. Bobot tanpa versi berlaku untuk versi soal saat
+// ini; bobot versi lama yang tidak dikirim ulang ikut terhapus.
+func SimpanModelPsikotes(db *sql.DB, idTest int, dimensi []models.DimensiPsikotes) error {
+	soals, err := GetSoalByTest(db, idTest)
+	if err != nil {
+		return err
+	}
+	versiSekarang := make(map[int]int, len(soals))
+	for _, s := range soals {
+		versiSekarang[s.IDSoal] = s.Versi
+	}
+	for i := range dimensi {
+		for k := range dimensi[i].Bobot {
+			b := &dimensi[i].Bobot[k]
+			if b.Versi == 0 {
+				b.Versi = versiSekarang[b.IDSoal]
+			}
+		}
+	}
+
+	daftarVersi, err := queryVersiSoal(db, ` WHERE v.id_soal IN (SELECT id_soal FROM test_soal WHERE id_test = ?)`, idTest)
+	if err != nil {
+		return err
+	}
+	versi := make(map[[2]int]*models.VersiSoal, len(daftarVersi))
+	for i := range daftarVersi {
+		v := &daftarVersi[i]
+		versi[[2]int{v.IDSoal, v.Versi}] = v
+	}
+	if err := validasiModelPsikotes(dimensi, soals, versi); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Profil lama ikut terhapus lewat cascade
+	if _, err := tx.Exec(`DELETE FROM dimensi_psikotes WHERE id_test = ?`, idTest); err != nil {
+		return err
+	}
+	for i, d := range dimensi {
+		res, err := tx.Exec(`
+			INSERT INTO dimensi_psikotes (id_test, urutan, kode_a, nama_a, kode_b, nama_b)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, idTest, i+1, strings.ToUpper(strings.TrimSpace(d.KodeA)), strings.TrimSpace(d.NamaA),
+			strings.ToUpper(strings.TrimSpace(d.KodeB)), strings.TrimSpace(d.NamaB))
+		if err != nil {
+			return err
+		}
+		idDimensi, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, b := range d.Bobot {
+			_, err := tx.Exec(`
+				INSERT INTO bobot_psikotes (id_dimensi, id_soal, id_versi, label, bobot) VALUES (?, ?, ?, ?, ?)
+			`, idDimensi, b.IDSoal, versi[[2]int{b.IDSoal, b.Versi}].IDVersi, strings.ToUpper(b.Label), b.Bobot)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	rows, err := tx.Query(`SELECT id_hasil FROM hasil_test WHERE id_test = ? FOR UPDATE`, idTest)
+	if err != nil {
+		return err
+	}
+	var idHasils []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		idHasils = append(idHasils, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range idHasils {
+		if _, err := hitungProfilPsikotes(tx, id, idTest); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// salinBobotPsikotesVersi membawa bobot versi lama ke versi baru soal yang masih bertipe psikotes,
+// untuk pilihan yang masih ada. Bobot versi lama tetap dipakai untuk jawaban versi lama.
+func salinBobotPsikotesVersi(tx *sql.Tx, idSoal, versiLama, versiBaru int) error {
+	_, err := tx.Exec(`
+		INSERT INTO bobot_psikotes (id_dimensi, id_soal, id_versi, label, bobot)
+		SELECT b.id_dimensi, b.id_soal, vb.id_versi, b.label, b.bobot
+		FROM bobot_psikotes b
+		INNER JOIN versi_soal vl ON vl.id_versi = b.id_versi AND vl.versi = ?
+		INNER JOIN versi_soal vb ON vb.id_soal = b.id_soal AND vb.versi = ? AND vb.tipe = ?
+		INNER JOIN pilihan_versi_soal p ON p.id_versi = vb.id_versi AND p.urutan = ASCII(b.label) - ASCII('A') + 1
+		WHERE b.id_soal = ?
+	`, versiLama, versiBaru, TipeSoalPsikotes, idSoal)
+	return err
+}
+
+// hitungProfilPsikotes menjumlahkan bobot pilihan yang dijawab ke setiap dimensi tes, menyimpan
+// profilnya, dan mengembalikan tipe kepribadian (kutub dominan tiap dimensi, seri ke kutub A).
+// Tes tanpa dimensi, atau sesi tanpa jawaban berbobot, tidak memiliki tipe.
+func hitungProfilPsikotes(tx *sql.Tx, idHasil, idTest int) (*string, error) {
+	rows, err := tx.Query(`
+		SELECT d.id_dimensi, d.kode_a, d.kode_b,
+			COALESCE(SUM(GREATEST(b.bobot, 0)), 0), COALESCE(SUM(GREATEST(-b.bobot, 0)), 0)
+		FROM dimensi_psikotes d
+		LEFT JOIN jawaban_user ju ON ju.id_hasil = ?
+		LEFT JOIN test_soal ts ON ts.id_test = d.id_test AND ts.id_soal = ju.id_soal
+		LEFT JOIN bobot_psikotes b ON b.id_dimensi = d.id_dimensi
+			AND b.id_soal = ts.id_soal AND b.id_versi = ju.id_versi AND b.label = ju.jawaban_user
+		WHERE d.id_test = ?
+		GROUP BY d.id_dimensi, d.urutan, d.kode_a, d.kode_b
+		ORDER BY d.urutan
+	`, idHasil, idTest)
+	if err != nil {
+		return nil, err
+	}
+	type skor struct {
+		idDimensi    int
+		skorA, skorB float64
+	}
+	var daftar []skor
+	var kode strings.Builder
+	ada := false
+	for rows.Next() {
+		var s skor
+		var kodeA, kodeB string
+		if err := rows.Scan(&s.idDimensi, &kodeA, &kodeB, &s.skorA, &s.skorB); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if s.skorA >= s.skorB {
+			kode.WriteString(kodeA)
+		} else {
+			kode.WriteString(kodeB)
+		}
+		ada = ada || s.skorA > 0 || s.skorB > 0
+		daftar = append(daftar, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM profil_psikotes WHERE id_hasil = ?`, idHasil); err != nil {
+		return nil, err
+	}
+	for _, s := range daftar {
+		_, err := tx.Exec(`
+			INSERT INTO profil_psikotes (id_hasil, id_dimensi, skor_a, skor_b) VALUES (?, ?, ?, ?)
+		`, idHasil, s.idDimensi, s.skorA, s.skorB)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tipe *string
+	if ada {
+		t := kode.String()
+		tipe = &t
+	}
+	if _, err := tx.Exec(`UPDATE hasil_test SET tipe_kepribadian = ? WHERE id_hasil = ?`, tipe, idHasil); err != nil {
+		return nil, err
+	}
+	return tipe, nil
+}
+
+// GetProfilPsikotesTest mengambil profil psikotes setiap sesi sebuah tes, per id_hasil
+func GetProfilPsikotesTest(db *sql.DB, idTest int) (map[int]*models.ProfilPsikotes, error) {
+	rows, err := db.Query(`
+		SELECT ht.id_hasil, ht.tipe_kepribadian,
+			d.id_dimensi, d.kode_a, d.nama_a, d.kode_b, d.nama_b, p.skor_a, p.skor_b
+		FROM profil_psikotes p
+		INNER JOIN hasil_test ht ON ht.id_hasil = p.id_hasil
+		INNER JOIN dimensi_psikotes d ON d.id_dimensi = p.id_dimensi
+		WHERE ht.id_test = ?
+		ORDER BY ht.id_hasil, d.urutan
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profil := map[int]*models.ProfilPsikotes{}
+	for rows.Next() {
+		var idHasil int
+		var tipe sql.NullString
+		var s models.SkorDimensiPsikotes
+		err := rows.Scan(&idHasil, &tipe, &s.IDDimensi, &s.KodeA, &s.NamaA, &s.KodeB, &s.NamaB, &s.SkorA, &s.SkorB)
+		if err != nil {
+			return nil, err
+		}
+		s.PersenA = 50
+		if total := s.SkorA + s.SkorB; total > 0 {
+			s.PersenA = s.SkorA / total * 100
+		}
+		s.Dominan = s.KodeA
+		if s.SkorB > s.SkorA {
+			s.Dominan = s.KodeB
+		}
+
+		p, ok := profil[idHasil]
+		if !ok {
+			p = &models.ProfilPsikotes{IDHasil: idHasil, TipeKepribadian: nullStringPtr(tipe)}
+			profil[idHasil] = p
+		}
+		p.Dimensi = append(p.Dimensi, s)
+	}
+	return profil, rows.Err()
+}
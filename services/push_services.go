@@ -0,0 +1,279 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"cocopen-backend/utils"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Lama (detik) push service menyimpan pesan untuk perangkat yang sedang offline
+	TTLWebPush = 24 * 60 * 60
+
+	// Jumlah pekerja Web Push, yaitu batas pengiriman yang berjalan bersamaan untuk seluruh notifikasi
+	paralelWebPush = 8
+
+	// Panjang antrean Web Push; pengirim menunggu bila antrean penuh
+	kapasitasAntreanWebPush = 1000
+
+	// Isi notifikasi dipotong agar payload terenkripsi tetap di bawah batas push service
+	maxIsiWebPush = 1000
+)
+
+// tugasWebPush adalah satu payload yang menunggu dikirim ke satu langganan
+type tugasWebPush struct {
+	db        *sql.DB
+	langganan models.LanggananPush
+	payload   []byte
+}
+
+var (
+	antreanWebPush      = make(chan tugasWebPush, kapasitasAntreanWebPush)
+	mulaiPekerjaWebPush sync.Once
+)
+
+// payloadWebPush adalah data yang diterima service worker pada event push
+type payloadWebPush struct {
+	IDNotifikasi int64     `json:"id_notifikasi"`
+	Kategori     string    `json:"kategori"`
+	Judul        string    `json:"judul"`
+	Isi          string    `json:"isi"`
+	Waktu        time.Time `json:"waktu"`
+}
+
+// SimpanLanggananPush mendaftarkan PushSubscription milik user. Endpoint yang sudah terdaftar
+// diperbarui kuncinya dan dipindahkan ke user ini.
+func SimpanLanggananPush(db *sql.DB, l models.LanggananPush) error {
+	if err := utils.ValidasiLanggananPush(l.Endpoint, l.P256dh, l.Auth); err != nil {
+		return err
+	}
+	_, err := db.Exec(`
+		INSERT INTO langganan_push (user_id, endpoint, p256dh, auth, user_agent)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			user_id = VALUES(user_id),
+			p256dh = VALUES(p256dh),
+			auth = VALUES(auth),
+			user_agent = VALUES(user_agent)
+	`, l.UserID, l.Endpoint, l.P256dh, l.Auth, l.UserAgent)
+	return err
+}
+
+// HapusLanggananPush menghapus langganan milik user (sql.ErrNoRows bila tidak ada)
+func HapusLanggananPush(db *sql.DB, userID int, endpoint string) error {
+	res, err := db.Exec(`DELETE FROM langganan_push WHERE user_id = ? AND endpoint = ?`, userID, endpoint)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// langgananPushNotifikasi mengambil langganan push semua penerima notifikasi n
+func langgananPushNotifikasi(db *sql.DB, n *models.Notifikasi) ([]models.LanggananPush, error) {
+	query := `
+		SELECT lp.id_langganan, lp.user_id, lp.endpoint, lp.p256dh, lp.auth
+		FROM langganan_push lp
+		INNER JOIN users u ON u.id_user = lp.user_id
+	`
+	var args []any
+	switch n.Target {
+	case TargetNotifikasiUser:
+		query += ` WHERE lp.user_id = ?`
+		args = append(args, *n.UserID)
+	case TargetNotifikasiRole:
+		query += ` WHERE u.role = ?`
+		args = append(args, *n.Role)
+	}
+
+	return queryLanggananPush(db, query, args...)
+}
+
+// langgananPushUsers mengambil langganan push milik user-user pada userIDs
+func langgananPushUsers(db *sql.DB, userIDs []int) ([]models.LanggananPush, error) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(userIDs)), ",")
+	args := make([]any, 0, len(userIDs))
+	for _, userID := range userIDs {
+		args = append(args, userID)
+	}
+	return queryLanggananPush(db, `
+		SELECT lp.id_langganan, lp.user_id, lp.endpoint, lp.p256dh, lp.auth
+		FROM langganan_push lp
+		WHERE lp.user_id IN (`+placeholders+`)
+	`, args...)
+}
+
+func queryLanggananPush(db *sql.DB, query string, args ...any) ([]models.LanggananPush, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var daftar []models.LanggananPush
+	for rows.Next() {
+		var l models.LanggananPush
+		if err := rows.Scan(&l.IDLangganan, &l.UserID, &l.Endpoint, &l.P256dh, &l.Auth); err != nil {
+			return nil, err
+		}
+		daftar = append(daftar, l)
+	}
+	return daftar, rows.Err()
+}
+
+// susunPayloadWebPush membentuk payload JSON notifikasi. Isi dipotong ke maxIsiWebPush karakter,
+// lalu dipendekkan lagi (judul juga bila perlu) sampai muat di utils.MaxPayloadWebPush byte;
+// karakter multibyte dan escape JSON bisa membuat isi 1000 karakter melebihi batas.
+func susunPayloadWebPush(n models.Notifikasi) ([]byte, error) {
+	judul, isi := []rune(n.Judul), []rune(n.Isi)
+	if len(isi) > maxIsiWebPush {
+		isi = append(isi[:maxIsiWebPush-1], '…')
+	}
+	for {
+		payload, err := json.Marshal(payloadWebPush{
+			IDNotifikasi: n.IDNotifikasi,
+			Kategori:     n.Kategori,
+			Judul:        string(judul),
+			Isi:          string(isi),
+			Waktu:        n.CreatedAt,
+		})
+		if err != nil || len(payload) <= utils.MaxPayloadWebPush {
+			return payload, err
+		}
+		switch {
+		case len(isi) > 1:
+			isi = append(isi[:len(isi)/2], '…')
+		case len(judul) > 1:
+			judul = append(judul[:len(judul)/2], '…')
+		default:
+			return nil, fmt.Errorf("payload %d byte melebihi %d byte", len(payload), utils.MaxPayloadWebPush)
+		}
+	}
+}
+
+// antrekanWebPush memasukkan pengiriman ke antrean yang dikerjakan paralelWebPush pekerja.
+// Pekerja dijalankan saat pengiriman pertama.
+func antrekanWebPush(db *sql.DB, l models.LanggananPush, payload []byte) {
+	mulaiPekerjaWebPush.Do(func() {
+		for i := 0; i < paralelWebPush; i++ {
+			go func() {
+				for t := range antreanWebPush {
+					kirimWebPushLangganan(t.db, t.langganan, t.payload)
+				}
+			}()
+		}
+	})
+	antreanWebPush <- tugasWebPush{db: db, langganan: l, payload: payload}
+}
+
+// kirimWebPushLangganan mengirim payload ke satu langganan. Langganan yang dijawab 404/410 oleh
+// push service, atau endpoint-nya tidak lagi diizinkan, dihapus.
+func kirimWebPushLangganan(db *sql.DB, l models.LanggananPush, payload []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Pengiriman web push ke langganan %d panic: %v", l.IDLangganan, r)
+		}
+	}()
+
+	err := utils.KirimWebPush(l.Endpoint, l.P256dh, l.Auth, payload, TTLWebPush)
+	switch {
+	case err == nil:
+		if _, err := db.Exec(`UPDATE langganan_push SET terakhir_terkirim = UTC_TIMESTAMP() WHERE id_langganan = ?`, l.IDLangganan); err != nil {
+			log.Printf("Gagal mencatat pengiriman web push %d: %v", l.IDLangganan, err)
+		}
+	case errors.Is(err, utils.ErrLanggananPushKedaluwarsa), errors.Is(err, utils.ErrLanggananPushTidakValid):
+		if _, err := db.Exec(`DELETE FROM langganan_push WHERE id_langganan = ?`, l.IDLangganan); err != nil {
+			log.Printf("Gagal menghapus langganan push %d: %v", l.IDLangganan, err)
+		}
+	default:
+		log.Printf("Gagal kirim web push ke langganan %d user %d: %v", l.IDLangganan, l.UserID, err)
+	}
+}
+
+// kirimPushNotifikasi memasukkan notifikasi ke antrean Web Push untuk setiap langganan
+// penerimanya. Dijalankan di goroutine terpisah.
+func kirimPushNotifikasi(db *sql.DB, n models.Notifikasi) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Pengiriman web push notifikasi %d panic: %v", n.IDNotifikasi, r)
+		}
+	}()
+	if !utils.WebPushAktif() {
+		return
+	}
+
+	daftar, err := langgananPushNotifikasi(db, &n)
+	if err != nil {
+		log.Printf("Gagal ambil langganan push notifikasi %d: %v", n.IDNotifikasi, err)
+		return
+	}
+	if len(daftar) == 0 {
+		return
+	}
+
+	payload, err := susunPayloadWebPush(n)
+	if err != nil {
+		log.Printf("Gagal menyusun payload web push notifikasi %d, push tidak dikirim: %v", n.IDNotifikasi, err)
+		return
+	}
+	for _, l := range daftar {
+		antrekanWebPush(db, l, payload)
+	}
+}
+
+// kirimPushNotifikasiUsers memasukkan notifikasi per user (target user) ke antrean Web Push.
+// Langganan diambil per batchNotifikasi user. Payload disusun sekali per user; bila gagal, kegagalan
+// dicatat sekali dan langganan lain milik user itu dilewati tanpa log tambahan. Dijalankan di
+// goroutine terpisah.
+func kirimPushNotifikasiUsers(db *sql.DB, ns []models.Notifikasi) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Pengiriman web push %d notifikasi panic: %v", len(ns), r)
+		}
+	}()
+	if !utils.WebPushAktif() {
+		return
+	}
+
+	for len(ns) > 0 {
+		batch := ns[:min(len(ns), batchNotifikasi)]
+		ns = ns[len(batch):]
+
+		perUser := make(map[int]models.Notifikasi, len(batch))
+		userIDs := make([]int, 0, len(batch))
+		for _, n := range batch {
+			perUser[*n.UserID] = n
+			userIDs = append(userIDs, *n.UserID)
+		}
+		daftar, err := langgananPushUsers(db, userIDs)
+		if err != nil {
+			log.Printf("Gagal ambil langganan push %d user: %v", len(userIDs), err)
+			continue
+		}
+
+		payloads := make(map[int][]byte)
+		for _, l := range daftar {
+			payload, ok := payloads[l.UserID]
+			if !ok {
+				n := perUser[l.UserID]
+				payload, err = susunPayloadWebPush(n)
+				if err != nil {
+					log.Printf("Gagal menyusun payload web push notifikasi %d, push tidak dikirim: %v", n.IDNotifikasi, err)
+				}
+				payloads[l.UserID] = payload
+			}
+			if payload != nil {
+				antrekanWebPush(db, l, payload)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"time"
+)
+
+// Kebijakan rilis hasil tes ke peserta
+const (
+	RilisHasilLangsung       = "langsung"
+	RilisHasilSetelahJendela = "setelah_jendela"
+	RilisHasilManual         = "manual"
+)
+
+// WaktuRilisHasil mengembalikan kapan hasil tes terbuka bagi peserta, atau nil bila belum
+// ditentukan (manual yang belum dirilis, atau setelah_jendela tanpa waktu_selesai).
+// Rilis manual oleh admin selalu berlaku, apa pun kebijakannya.
+func WaktuRilisHasil(t *models.Test, waktuSelesaiSesi time.Time) *time.Time {
+	if t.HasilDirilisAt != nil {
+		return t.HasilDirilisAt
+	}
+	switch t.RilisHasil {
+	case RilisHasilLangsung:
+		return &waktuSelesaiSesi
+	case RilisHasilSetelahJendela:
+		// Menunggu jendela akomodasi terakhir agar hasil tidak bocor ke peserta yang masih mengerjakan
+		return t.WaktuSelesaiTerakhir
+	}
+	return nil
+}
+
+// HasilDirilis menandai apakah peserta sudah boleh melihat hasil sesinya
+func HasilDirilis(t *models.Test, h *models.HasilTest, now time.Time) bool {
+	if h.Status != StatusHasilSelesai {
+		return false
+	}
+	selesai := h.WaktuMulai
+	if h.WaktuSelesai != nil {
+		selesai = *h.WaktuSelesai
+	}
+	rilis := WaktuRilisHasil(t, selesai)
+	return rilis != nil && !now.Before(*rilis)
+}
+
+// PembahasanTerbuka menandai apakah peserta boleh melihat kunci dan pembahasan per soal.
+// Kunci berlaku untuk semua peserta, jadi selain hasil sudah dirilis, jendela tes termasuk
+// akomodasi harus sudah tutup walau kebijakannya langsung. Tes tanpa waktu_selesai baru
+// membuka pembahasan lewat rilis manual admin.
+func PembahasanTerbuka(t *models.Test, h *models.HasilTest, now time.Time) bool {
+	if !t.TampilkanPembahasan || !HasilDirilis(t, h, now) {
+		return false
+	}
+	if t.HasilDirilisAt != nil {
+		return true
+	}
+	return t.WaktuSelesaiTerakhir != nil && !now.Before(*t.WaktuSelesaiTerakhir)
+}
+
+// SetRilisHasilTest merilis (atau menarik kembali) hasil tes secara manual. baru bernilai true bila
+// hasil baru saja dirilis (sebelumnya belum), agar peserta hanya diberi tahu sekali.
+func SetRilisHasilTest(db *sql.DB, idTest int, rilis bool) (baru bool, err error) {
+	query := `UPDATE test SET hasil_dirilis_at = NULL WHERE id_test = ?`
+	if rilis {
+		query = `UPDATE test SET hasil_dirilis_at = UTC_TIMESTAMP() WHERE id_test = ? AND hasil_dirilis_at IS NULL`
+	}
+	res, err := db.Exec(query, idTest)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return rilis && n > 0, err
+}
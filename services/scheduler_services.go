@@ -0,0 +1,57 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DaftarkanJob memastikan job ada di tabel job_terjadwal tanpa menimpa jadwal yang sudah tersimpan
+func DaftarkanJob(db *sql.DB, nama string, interval time.Duration) error {
+	query := `
+		INSERT INTO job_terjadwal (nama, interval_detik, berikutnya)
+		VALUES (?, ?, NOW())
+		ON DUPLICATE KEY UPDATE interval_detik = VALUES(interval_detik)
+	`
+	_, err := db.Exec(query, nama, int(interval.Seconds()))
+	return err
+}
+
+// KlaimJob mengunci job yang sudah jatuh tempo agar hanya satu instance yang menjalankannya
+func KlaimJob(db *sql.DB, nama string, lama time.Duration) (bool, error) {
+	query := `
+		UPDATE job_terjadwal
+		SET terkunci_sampai = NOW() + INTERVAL ? SECOND
+		WHERE nama = ?
+		  AND berikutnya <= NOW()
+		  AND (terkunci_sampai IS NULL OR terkunci_sampai < NOW())
+	`
+	res, err := db.Exec(query, int(lama.Seconds()), nama)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SelesaikanJob mencatat hasil eksekusi dan menghitung waktu jalan berikutnya
+func SelesaikanJob(db *sql.DB, nama string, errJob error) error {
+	var pesan *string
+	if errJob != nil {
+		s := errJob.Error()
+		pesan = &s
+	}
+
+	query := `
+		UPDATE job_terjadwal
+		SET terakhir_jalan = NOW(),
+			berikutnya = NOW() + INTERVAL interval_detik SECOND,
+			terkunci_sampai = NULL,
+			error_terakhir = ?
+		WHERE nama = ?
+	`
+	_, err := db.Exec(query, pesan, nama)
+	return err
+}
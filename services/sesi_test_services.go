@@ -0,0 +1,379 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	StatusHasilBerlangsung = "berlangsung"
+	StatusHasilSelesai     = "selesai"
+
+	// TenggangSubmit memberi kelonggaran latensi jaringan setelah batas waktu sesi habis
+	TenggangSubmit = 2 * time.Minute
+
+	// Panjang maksimum header Idempotency-Key (kolom hasil_test.kunci_idempotensi)
+	MaxPanjangKunciIdempotensi = 100
+)
+
+var ErrSesiSudahSelesai = errors.New("tes sudah selesai dikerjakan")
+
+// MulaiSesiTest membuat sesi hasil_test berstatus berlangsung dengan batas waktu dari server
+// beserta urutan soal teracak. Jika sesi sudah ada dan masih berlangsung, sesi tersebut dikembalikan.
+// Percobaan yang sudah selesai hanya bisa diulang bila admin memberi izin ulang.
+func MulaiSesiTest(db *sql.DB, userID, pendaftarID int, t *models.Test) (*models.HasilTest, error) {
+	mulai := time.Now()
+	batas := batasSesi(mulai, t)
+
+	sesi, err := GetHasilByUserID(db, userID, t.IDTest)
+	switch {
+	case err == sql.ErrNoRows:
+		// INSERT IGNORE: dua permintaan mulai yang bersamaan tetap menghasilkan satu sesi
+		_, err = db.Exec(`
+			INSERT IGNORE INTO hasil_test (user_id, pendaftar_id, id_test, status, waktu_mulai, batas_waktu)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, userID, pendaftarID, t.IDTest, StatusHasilBerlangsung, mulai, batas)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case sesi.Status != StatusHasilSelesai:
+		return sesi, SiapkanUrutanSesi(db, sesi.IDHasil, t.IDTest)
+	default:
+		if err := pakaiIzinUlang(db, userID, pendaftarID, t.IDTest, sesi.Percobaan+1, mulai, batas); err != nil {
+			return nil, err
+		}
+	}
+
+	sesi, err = GetHasilByUserID(db, userID, t.IDTest)
+	if err != nil {
+		return nil, err
+	}
+	if sesi.Status == StatusHasilSelesai {
+		return nil, ErrSesiSudahSelesai
+	}
+	return sesi, SiapkanUrutanSesi(db, sesi.IDHasil, t.IDTest)
+}
+
+// pakaiIzinUlang membuat percobaan baru bila ada izin ulang yang belum terpakai. Tanpa izin
+// tidak terjadi apa-apa; pemanggil menilai ulang dari percobaan terakhir.
+func pakaiIzinUlang(db *sql.DB, userID, pendaftarID, idTest, percobaan int, mulai, batas time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var idIzin int
+	err = tx.QueryRow(`
+		SELECT id_izin FROM izin_ulang_test
+		WHERE user_id = ? AND id_test = ? AND id_hasil_dipakai IS NULL
+		ORDER BY id_izin
+		LIMIT 1
+		FOR UPDATE
+	`, userID, idTest).Scan(&idIzin)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		INSERT IGNORE INTO hasil_test (user_id, pendaftar_id, id_test, percobaan, status, waktu_mulai, batas_waktu)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, userID, pendaftarID, idTest, percobaan, StatusHasilBerlangsung, mulai, batas)
+	if err != nil {
+		return err
+	}
+	// Percobaan yang sama sudah dibuat permintaan mulai lain yang bersamaan
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return err
+	}
+	idHasil, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE izin_ulang_test SET id_hasil_dipakai = ?, dipakai_at = UTC_TIMESTAMP() WHERE id_izin = ?`,
+		idHasil, idIzin,
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SesiKedaluwarsa memeriksa apakah sesi sudah melewati batas waktu ditambah tenggang
+func SesiKedaluwarsa(h *models.HasilTest, now time.Time) bool {
+	return h.BatasWaktu != nil && now.After(h.BatasWaktu.Add(TenggangSubmit))
+}
+
+// SisaDetikSesi menghitung sisa waktu pengerjaan dalam detik (tidak pernah negatif)
+func SisaDetikSesi(h *models.HasilTest, now time.Time) int {
+	if h.BatasWaktu == nil || !now.Before(*h.BatasWaktu) {
+		return 0
+	}
+	return int(h.BatasWaktu.Sub(now).Seconds())
+}
+
+// kunciSesiBerlangsung mengunci baris hasil_test dan memastikan sesinya belum selesai
+func kunciSesiBerlangsung(tx *sql.Tx, idHasil int) (int, error) {
+	var idTest int
+	var status string
+	err := tx.QueryRow(
+		`SELECT id_test, status FROM hasil_test WHERE id_hasil = ? FOR UPDATE`,
+		idHasil,
+	).Scan(&idTest, &status)
+	if err != nil {
+		return 0, err
+	}
+	if status == StatusHasilSelesai {
+		return 0, ErrSesiSudahSelesai
+	}
+	return idTest, nil
+}
+
+func simpanJawaban(tx *sql.Tx, idHasil int, j models.JawabanUser) error {
+	// id_versi: versi soal yang dipakai menilai jawaban ini (j.Versi dari NilaiJawaban)
+	_, err := tx.Exec(`
+		INSERT INTO jawaban_user (id_hasil, id_soal, id_versi, jawaban_user, is_benar, poin, status_penilaian)
+		VALUES (?, ?, (SELECT id_versi FROM versi_soal WHERE id_soal = ? AND versi = ?), ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			id_versi = VALUES(id_versi),
+			jawaban_user = VALUES(jawaban_user),
+			is_benar = VALUES(is_benar),
+			poin = VALUES(poin),
+			status_penilaian = VALUES(status_penilaian)
+	`, idHasil, j.IDSoal, j.IDSoal, j.Versi, j.JawabanUser, j.IsBenar, j.Poin, j.StatusPenilaian)
+	return err
+}
+
+// hitungUlangHasil menghitung poin tertimbang sesi: nilai = poin diperoleh / total bobot soal * 100.
+// Bobot dan tipe soal yang dijawab diambil dari versi yang dijawab, sehingga edit soal tidak
+// mengubah hasil lama. Soal esai tidak dihitung sebagai benar/salah; poinnya masuk setelah dinilai manual.
+// Penyesuaian poin dari admin ikut dijumlahkan, dengan hasil akhir dibatasi 0..total bobot.
+// Profil psikotes (bila tes memiliki dimensi) ikut dihitung ulang.
+func hitungUlangHasil(tx *sql.Tx, idHasil, idTest int) (*models.HasilTest, error) {
+	h := &models.HasilTest{IDHasil: idHasil, IDTest: idTest}
+
+	var jumlahSoalOtomatis int
+	err := tx.QueryRow(`
+		SELECT
+			COALESCE(SUM(COALESCE(v.poin, s.poin)), 0),
+			COALESCE(SUM(COALESCE(v.tipe, s.tipe) NOT IN ('esai', 'psikotes')), 0)
+		FROM test_soal ts
+		INNER JOIN soal_test s ON ts.id_soal = s.id_soal
+		LEFT JOIN jawaban_user ju ON ju.id_hasil = ? AND ju.id_soal = ts.id_soal
+		LEFT JOIN versi_soal v ON v.id_versi = ju.id_versi
+		WHERE ts.id_test = ?
+	`, idHasil, idTest).Scan(&h.TotalPoin, &jumlahSoalOtomatis)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(ju.poin), 0), COALESCE(SUM(ju.is_benar = TRUE), 0)
+		FROM jawaban_user ju
+		INNER JOIN test_soal ts ON ts.id_soal = ju.id_soal AND ts.id_test = ?
+		WHERE ju.id_hasil = ?
+	`, idTest, idHasil).Scan(&h.Poin, &h.SkorBenar)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`SELECT penyesuaian_poin FROM hasil_test WHERE id_hasil = ?`, idHasil).Scan(&h.PenyesuaianPoin)
+	if err != nil {
+		return nil, err
+	}
+	h.Poin = max(0, min(h.Poin+h.PenyesuaianPoin, h.TotalPoin))
+
+	h.SkorSalah = jumlahSoalOtomatis - h.SkorBenar
+	if h.TotalPoin > 0 {
+		h.Nilai = h.Poin / h.TotalPoin * 100
+	}
+
+	_, err = tx.Exec(`
+		UPDATE hasil_test SET
+			skor_benar = ?,
+			skor_salah = ?,
+			poin = ?,
+			total_poin = ?,
+			nilai = ?
+		WHERE id_hasil = ?
+	`, h.SkorBenar, h.SkorSalah, h.Poin, h.TotalPoin, h.Nilai, idHasil)
+	if err != nil {
+		return nil, err
+	}
+
+	h.TipeKepribadian, err = hitungProfilPsikotes(tx, idHasil, idTest)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// SimpanJawabanSesi menyimpan (autosave) satu jawaban selama sesi masih berlangsung
+func SimpanJawabanSesi(db *sql.DB, idHasil int, j models.JawabanUser) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := kunciSesiBerlangsung(tx, idHasil); err != nil {
+		return err
+	}
+	if err := simpanJawaban(tx, idHasil, j); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SelesaikanSesiTest menyimpan jawaban terakhir lalu menilai seluruh jawaban tersimpan
+// terhadap soal tes dan menutup sesi. Dijalankan dalam satu transaksi dengan baris hasil dikunci.
+func SelesaikanSesiTest(db *sql.DB, idHasil int, jawabans []models.JawabanUser, waktuSelesai time.Time) (*models.HasilTest, error) {
+	hasil, _, err := SubmitSesiTest(db, idHasil, "", jawabans, waktuSelesai)
+	return hasil, err
+}
+
+// SubmitSesiTest sama dengan SelesaikanSesiTest dengan kunci idempotensi dari klien. Bila sesi
+// sudah diselesaikan dengan kunci yang sama (retry), hasil tersimpan dikembalikan dengan ulangan=true.
+func SubmitSesiTest(db *sql.DB, idHasil int, kunci string, jawabans []models.JawabanUser, waktuSelesai time.Time) (hasil *models.HasilTest, ulangan bool, err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var idTest int
+	var status string
+	var kunciTersimpan sql.NullString
+	err = tx.QueryRow(
+		`SELECT id_test, status, kunci_idempotensi FROM hasil_test WHERE id_hasil = ? FOR UPDATE`,
+		idHasil,
+	).Scan(&idTest, &status, &kunciTersimpan)
+	if err != nil {
+		return nil, false, err
+	}
+	if status == StatusHasilSelesai {
+		if kunci != "" && kunciTersimpan.Valid && kunciTersimpan.String == kunci {
+			tx.Rollback()
+			hasil, err = GetHasilByID(db, idHasil)
+			return hasil, true, err
+		}
+		return nil, false, ErrSesiSudahSelesai
+	}
+
+	for _, j := range jawabans {
+		if err := simpanJawaban(tx, idHasil, j); err != nil {
+			return nil, false, err
+		}
+	}
+
+	hasil, err = hitungUlangHasil(tx, idHasil, idTest)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE hasil_test SET status = ?, waktu_selesai = ?, kunci_idempotensi = NULLIF(?, '') WHERE id_hasil = ?`,
+		StatusHasilSelesai, waktuSelesai, kunci, idHasil,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	hasil.Status = StatusHasilSelesai
+	hasil.WaktuSelesai = &waktuSelesai
+
+	return hasil, false, tx.Commit()
+}
+
+// GetHasilByKunciIdempotensi mengambil hasil sesi selesai milik user yang disubmit dengan kunci tersebut
+func GetHasilByKunciIdempotensi(db *sql.DB, userID, idTest int, kunci string) (*models.HasilTest, error) {
+	return scanHasilTest(db.QueryRow(
+		selectHasilTest+` WHERE user_id = ? AND id_test = ? AND status = ? AND kunci_idempotensi = ?`,
+		userID, idTest, StatusHasilSelesai, kunci,
+	))
+}
+
+// ResetSesiTest menghapus percobaan tes (beserta jawaban, urutan soal, dan event integritasnya)
+// sehingga peserta dapat memulai ulang. Dipakai admin untuk percobaan yang rusak; jejaknya
+// tetap tercatat di audit. Izin ulang yang dipakai percobaan ini kembali bisa dipakai.
+func ResetSesiTest(db *sql.DB, idHasil int, alasan string, adminID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var h models.HasilTest
+	err = tx.QueryRow(`
+		SELECT id_hasil, id_test, user_id, nilai FROM hasil_test WHERE id_hasil = ? FOR UPDATE
+	`, idHasil).Scan(&h.IDHasil, &h.IDTest, &h.UserID, &h.Nilai)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM hasil_test WHERE id_hasil = ?`, idHasil); err != nil {
+		return err
+	}
+	err = catatAudit(tx, models.AuditHasilTest{
+		IDTest:        h.IDTest,
+		IDHasil:       &h.IDHasil,
+		UserID:        &h.UserID,
+		Aksi:          AuditResetSesi,
+		NilaiLama:     &h.Nilai,
+		Alasan:        alasan,
+		DilakukanOleh: &adminID,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FinalisasiSesiKedaluwarsa menilai sesi yang ditinggalkan melewati batas waktu dan tenggang
+// berdasarkan jawaban yang sudah tersimpan; waktu selesai dicatat sama dengan batas waktu
+func FinalisasiSesiKedaluwarsa(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT id_hasil, batas_waktu
+		FROM hasil_test
+		WHERE status = ? AND batas_waktu < UTC_TIMESTAMP() - INTERVAL ? SECOND
+	`, StatusHasilBerlangsung, int(TenggangSubmit.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	type sesiKedaluwarsa struct {
+		idHasil int
+		batas   time.Time
+	}
+	var daftar []sesiKedaluwarsa
+	for rows.Next() {
+		var s sesiKedaluwarsa
+		if err := rows.Scan(&s.idHasil, &s.batas); err != nil {
+			rows.Close()
+			return err
+		}
+		daftar = append(daftar, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, s := range daftar {
+		_, err := SelesaikanSesiTest(db, s.idHasil, nil, s.batas)
+		if err != nil && err != ErrSesiSudahSelesai {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
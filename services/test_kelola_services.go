@@ -0,0 +1,321 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+)
+
+const (
+	TargetPesertaSemua = "semua"
+	TargetPesertaPilih = "pilih"
+)
+
+var ErrTestSudahDikerjakan = errors.New("tes sudah dikerjakan peserta dan tidak dapat dihapus")
+
+var ErrTestDipakaiPengumuman = errors.New("tes masih menjadi sasaran pengumuman; ubah atau hapus pengumumannya terlebih dahulu")
+
+// ErrSoalTestTerkunci: daftar soal tidak boleh diganti setelah ada hasil, karena regrade
+// menghitung ulang hasil lama terhadap test_soal yang berlaku
+var ErrSoalTestTerkunci = errors.New("tes sudah dikerjakan peserta sehingga daftar soal tidak dapat diubah; buat tes baru")
+
+const selectTest = `
+	SELECT
+		t.id_test, t.judul, t.deskripsi, t.durasi_menit,
+		t.waktu_mulai, t.waktu_selesai, t.aktif, t.target_peserta,
+		t.rilis_hasil, t.tampilkan_pembahasan, t.hasil_dirilis_at,
+		t.created_at, t.updated_at,
+		(SELECT COUNT(*) FROM test_soal ts WHERE ts.id_test = t.id_test) AS jumlah_soal,
+		(SELECT COUNT(*) FROM akomodasi_test a WHERE a.id_test = t.id_test) AS jumlah_akomodasi,
+		(SELECT MAX(a.waktu_selesai) FROM akomodasi_test a WHERE a.id_test = t.id_test) AS akomodasi_selesai
+	FROM test t
+`
+
+func scanTest(row interface{ Scan(...any) error }) (*models.Test, error) {
+	var t models.Test
+	var deskripsi sql.NullString
+	var waktuMulai, waktuSelesai, dirilisAt, akomodasiSelesai sql.NullTime
+
+	err := row.Scan(
+		&t.IDTest,
+		&t.Judul,
+		&deskripsi,
+		&t.DurasiMenit,
+		&waktuMulai,
+		&waktuSelesai,
+		&t.Aktif,
+		&t.TargetPeserta,
+		&t.RilisHasil,
+		&t.TampilkanPembahasan,
+		&dirilisAt,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.JumlahSoal,
+		&t.JumlahAkomodasi,
+		&akomodasiSelesai,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deskripsi.Valid {
+		t.Deskripsi = &deskripsi.String
+	}
+	if waktuMulai.Valid {
+		t.WaktuMulai = &waktuMulai.Time
+	}
+	if waktuSelesai.Valid {
+		t.WaktuSelesai = &waktuSelesai.Time
+		t.WaktuSelesaiTerakhir = &waktuSelesai.Time
+		if akomodasiSelesai.Valid && akomodasiSelesai.Time.After(waktuSelesai.Time) {
+			t.WaktuSelesaiTerakhir = &akomodasiSelesai.Time
+		}
+	}
+	if dirilisAt.Valid {
+		t.HasilDirilisAt = &dirilisAt.Time
+	}
+	return &t, nil
+}
+
+func queryTests(db *sql.DB, query string, args ...any) ([]models.Test, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tests []models.Test
+	for rows.Next() {
+		t, err := scanTest(rows)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, *t)
+	}
+	return tests, rows.Err()
+}
+
+func CreateTest(db *sql.DB, t models.Test) (int, error) {
+	if t.TargetPeserta == "" {
+		t.TargetPeserta = TargetPesertaSemua
+	}
+	if t.RilisHasil == "" {
+		t.RilisHasil = RilisHasilLangsung
+	}
+	res, err := db.Exec(`
+		INSERT INTO test (judul, deskripsi, durasi_menit, waktu_mulai, waktu_selesai, aktif, target_peserta,
+			rilis_hasil, tampilkan_pembahasan)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.Judul, t.Deskripsi, t.DurasiMenit, t.WaktuMulai, t.WaktuSelesai, t.Aktif, t.TargetPeserta,
+		t.RilisHasil, t.TampilkanPembahasan)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	return int(id), err
+}
+
+func GetAllTest(db *sql.DB) ([]models.Test, error) {
+	return queryTests(db, selectTest+` ORDER BY t.created_at DESC`)
+}
+
+func GetTestByID(db *sql.DB, idTest int) (*models.Test, error) {
+	return scanTest(db.QueryRow(selectTest+` WHERE t.id_test = ?`, idTest))
+}
+
+func UpdateTest(db *sql.DB, t *models.Test) error {
+	_, err := db.Exec(`
+		UPDATE test SET
+			judul = ?,
+			deskripsi = ?,
+			durasi_menit = ?,
+			waktu_mulai = ?,
+			waktu_selesai = ?,
+			aktif = ?,
+			target_peserta = ?,
+			rilis_hasil = ?,
+			tampilkan_pembahasan = ?,
+			updated_at = NOW()
+		WHERE id_test = ?
+	`, t.Judul, t.Deskripsi, t.DurasiMenit, t.WaktuMulai, t.WaktuSelesai, t.Aktif, t.TargetPeserta,
+		t.RilisHasil, t.TampilkanPembahasan, t.IDTest)
+	return err
+}
+
+// DeleteTest menghapus tes yang belum pernah dikerjakan dan tidak menjadi sasaran pengumuman
+func DeleteTest(db *sql.DB, idTest int) error {
+	var dikerjakan bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM hasil_test WHERE id_test = ?)`, idTest).Scan(&dikerjakan)
+	if err != nil {
+		return err
+	}
+	if dikerjakan {
+		return ErrTestSudahDikerjakan
+	}
+	var diumumkan bool
+	err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pengumuman WHERE id_test = ?)`, idTest).Scan(&diumumkan)
+	if err != nil {
+		return err
+	}
+	if diumumkan {
+		return ErrTestDipakaiPengumuman
+	}
+	_, err = db.Exec(`DELETE FROM test WHERE id_test = ?`, idTest)
+	return err
+}
+
+// GetSoalByTest mengambil soal milik sebuah tes sesuai urutannya
+func GetSoalByTest(db *sql.DB, idTest int) ([]models.SoalTest, error) {
+	rows, err := db.Query(selectSoal+`, ts.urutan
+		FROM test_soal ts
+		INNER JOIN soal_test s ON ts.id_soal = s.id_soal
+		WHERE ts.id_test = ?
+		ORDER BY ts.urutan ASC
+	`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var soals []models.SoalTest
+	for rows.Next() {
+		var urutan int
+		s, err := scanSoal(rows, &urutan)
+		if err != nil {
+			return nil, err
+		}
+		s.Urutan = urutan
+		soals = append(soals, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return soals, lampirkanPilihan(db, soals)
+}
+
+// SetSoalTest mengganti daftar soal sebuah tes; urutan mengikuti urutan idSoals
+func SetSoalTest(db *sql.DB, idTest int, idSoals []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Kunci baris tes agar pemeriksaan hasil tidak balapan dengan perubahan lain pada tes ini
+	if _, err := tx.Exec(`SELECT id_test FROM test WHERE id_test = ? FOR UPDATE`, idTest); err != nil {
+		return err
+	}
+	var adaHasil bool
+	if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM hasil_test WHERE id_test = ?)`, idTest).Scan(&adaHasil); err != nil {
+		return err
+	}
+	if adaHasil {
+		return ErrSoalTestTerkunci
+	}
+
+	if _, err := tx.Exec(`DELETE FROM test_soal WHERE id_test = ?`, idTest); err != nil {
+		return err
+	}
+	for i, idSoal := range idSoals {
+		_, err := tx.Exec(
+			`INSERT INTO test_soal (id_test, id_soal, urutan) VALUES (?, ?, ?)`,
+			idTest, idSoal, i+1,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetPesertaTest mengatur target peserta tes; daftar pendaftar hanya dipakai untuk target "pilih"
+func SetPesertaTest(db *sql.DB, idTest int, target string, pendaftarIDs []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE test SET target_peserta = ?, updated_at = NOW() WHERE id_test = ?`, target, idTest); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM test_peserta WHERE id_test = ?`, idTest); err != nil {
+		return err
+	}
+	if target == TargetPesertaPilih {
+		for _, id := range pendaftarIDs {
+			_, err := tx.Exec(`INSERT IGNORE INTO test_peserta (id_test, pendaftar_id) VALUES (?, ?)`, idTest, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPesertaTest mengambil id pendaftar yang ditugaskan secara eksplisit pada tes
+func GetPesertaTest(db *sql.DB, idTest int) ([]int, error) {
+	rows, err := db.Query(`SELECT pendaftar_id FROM test_peserta WHERE id_test = ? ORDER BY pendaftar_id`, idTest)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// kondisiTestDitugaskan membatasi tes (alias t) pada yang ditugaskan ke pendaftar (parameter ?)
+const kondisiTestDitugaskan = `
+	(t.target_peserta = 'semua' OR EXISTS (
+		SELECT 1 FROM test_peserta tp WHERE tp.id_test = t.id_test AND tp.pendaftar_id = ?
+	))
+`
+
+// GetTestDitugaskan mengambil tes aktif yang ditugaskan ke pendaftar dan belum ditutup,
+// dengan jendela dan durasi yang sudah disesuaikan akomodasinya
+func GetTestDitugaskan(db *sql.DB, pendaftarID int) ([]models.Test, error) {
+	tests, err := queryTests(db, selectTest+`
+		LEFT JOIN akomodasi_test ak ON ak.id_test = t.id_test AND ak.pendaftar_id = ?
+		WHERE t.aktif = TRUE
+		  AND (COALESCE(ak.waktu_selesai, t.waktu_selesai) IS NULL OR COALESCE(ak.waktu_selesai, t.waktu_selesai) > NOW())
+		  AND `+kondisiTestDitugaskan+`
+		ORDER BY COALESCE(ak.waktu_mulai, t.waktu_mulai, t.created_at) ASC
+	`, pendaftarID, pendaftarID)
+	if err != nil {
+		return nil, err
+	}
+	akomodasi, err := GetAkomodasiPendaftar(db, pendaftarID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tests {
+		TerapkanAkomodasi(&tests[i], akomodasi[tests[i].IDTest])
+	}
+	return tests, nil
+}
+
+// GetTestUntukPendaftar mengambil satu tes aktif jika ditugaskan ke pendaftar, dengan
+// jendela dan durasi yang sudah disesuaikan akomodasinya
+func GetTestUntukPendaftar(db *sql.DB, idTest, pendaftarID int) (*models.Test, error) {
+	t, err := scanTest(db.QueryRow(selectTest+`
+		WHERE t.id_test = ? AND t.aktif = TRUE AND `+kondisiTestDitugaskan,
+		idTest, pendaftarID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	a, err := GetAkomodasi(db, idTest, pendaftarID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	TerapkanAkomodasi(t, a)
+	return t, nil
+}
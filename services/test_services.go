@@ -5,33 +5,54 @@ import (
 	"database/sql"
 )
 
-func CreateSoal(db *sql.DB, soal models.SoalTest) error {
-	query := `
-		INSERT INTO soal_test (nomor, pertanyaan, pilihan_a, pilihan_b, pilihan_c, pilihan_d, jawaban_benar)
+// CreateSoal menyimpan soal baru sebagai versi 1
+func CreateSoal(db *sql.DB, soal models.SoalTest, adminID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertSoal(tx, soal, adminID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertSoal menyimpan soal beserta pilihan dan versi pertamanya di dalam transaksi pemanggil
+func insertSoal(tx *sql.Tx, soal models.SoalTest, adminID int) error {
+	res, err := tx.Exec(`
+		INSERT INTO soal_test (nomor, pertanyaan, tipe, poin, kunci_isian, mode_isian, pembahasan)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`
-	_, err := db.Exec(
-		query,
+	`,
 		soal.Nomor,
 		soal.Pertanyaan,
-		soal.PilihanA,
-		soal.PilihanB,
-		soal.PilihanC,
-		soal.PilihanD,
-		soal.JawabanBenar,
+		soal.Tipe,
+		soal.Poin,
+		soal.KunciIsian,
+		soal.ModeIsian,
+		soal.Pembahasan,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	idSoal, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := simpanPilihan(tx, int(idSoal), soal.Pilihan); err != nil {
+		return err
+	}
+	return simpanVersiSoal(tx, int(idSoal), 1, &soal, adminID)
 }
 
-func GetAllSoal(db *sql.DB) ([]models.SoalTest, error) {
-	query := `
-		SELECT 
-			id_soal, nomor, pertanyaan, pilihan_a, pilihan_b, pilihan_c, pilihan_d, 
-			jawaban_benar, created_at, updated_at
-		FROM soal_test
-		ORDER BY nomor ASC
-	`
-	rows, err := db.Query(query)
+// GetAllSoal mengambil bank soal; soal yang diarsipkan hanya ikut bila denganArsip
+func GetAllSoal(db *sql.DB, denganArsip bool) ([]models.SoalTest, error) {
+	rows, err := db.Query(selectSoal+`
+		FROM soal_test s
+		WHERE ? OR s.diarsipkan_at IS NULL
+		ORDER BY s.nomor ASC
+	`, denganArsip)
 	if err != nil {
 		return nil, err
 	}
@@ -39,130 +60,102 @@ func GetAllSoal(db *sql.DB) ([]models.SoalTest, error) {
 
 	var soals []models.SoalTest
 	for rows.Next() {
-		var s models.SoalTest
-		err := rows.Scan(
-			&s.IDSoal,
-			&s.Nomor,
-			&s.Pertanyaan,
-			&s.PilihanA,
-			&s.PilihanB,
-			&s.PilihanC,
-			&s.PilihanD,
-			&s.JawabanBenar,
-			&s.CreatedAt,
-			&s.UpdatedAt,
-		)
+		s, err := scanSoal(rows)
 		if err != nil {
 			return nil, err
 		}
 		soals = append(soals, s)
 	}
-	return soals, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return soals, lampirkanPilihan(db, soals)
 }
 
 func GetSoalByID(db *sql.DB, idSoal int) (*models.SoalTest, error) {
-	query := `
-		SELECT 
-			id_soal, nomor, pertanyaan, pilihan_a, pilihan_b, pilihan_c, pilihan_d, 
-			jawaban_benar, created_at, updated_at
-		FROM soal_test
-		WHERE id_soal = ?
-	`
-	row := db.QueryRow(query, idSoal)
-
-	var s models.SoalTest
-	err := row.Scan(
-		&s.IDSoal,
-		&s.Nomor,
-		&s.Pertanyaan,
-		&s.PilihanA,
-		&s.PilihanB,
-		&s.PilihanC,
-		&s.PilihanD,
-		&s.JawabanBenar,
-		&s.CreatedAt,
-		&s.UpdatedAt,
-	)
+	s, err := scanSoal(db.QueryRow(selectSoal+` FROM soal_test s WHERE s.id_soal = ?`, idSoal))
 	if err != nil {
 		return nil, err
 	}
-	return &s, nil
+
+	soals := []models.SoalTest{s}
+	if err := lampirkanPilihan(db, soals); err != nil {
+		return nil, err
+	}
+	return &soals[0], nil
 }
 
-func UpdateSoal(db *sql.DB, soal *models.SoalTest) error {
-	query := `
+// UpdateSoal menyimpan perubahan soal. Perubahan isi membuat versi baru (soal.Versi diperbarui);
+// jawaban yang sudah tersimpan tetap merujuk versi yang dijawab. Perubahan nomor saja tidak membuat versi.
+func UpdateSoal(db *sql.DB, soal *models.SoalTest, adminID int) error {
+	lama, err := GetSoalByID(db, soal.IDSoal)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var versi int
+	var diarsipkan sql.NullTime
+	err = tx.QueryRow(
+		`SELECT versi, diarsipkan_at FROM soal_test WHERE id_soal = ? FOR UPDATE`, soal.IDSoal,
+	).Scan(&versi, &diarsipkan)
+	if err != nil {
+		return err
+	}
+	if diarsipkan.Valid {
+		return ErrSoalDiarsipkan
+	}
+	// Versi yang berubah sejak soal lama dibaca berarti ada edit bersamaan: selalu buat versi baru
+	if versi != lama.Versi || !samaIsiSoal(lama, soal) {
+		versi++
+		if err := simpanVersiSoal(tx, soal.IDSoal, versi, soal, adminID); err != nil {
+			return err
+		}
+		if err := salinBobotPsikotesVersi(tx, soal.IDSoal, versi-1, versi); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
 		UPDATE soal_test SET
 			nomor = ?,
 			pertanyaan = ?,
-			pilihan_a = ?,
-			pilihan_b = ?,
-			pilihan_c = ?,
-			pilihan_d = ?,
-			jawaban_benar = ?,
+			tipe = ?,
+			poin = ?,
+			kunci_isian = ?,
+			mode_isian = ?,
+			pembahasan = ?,
+			versi = ?,
 			updated_at = NOW()
 		WHERE id_soal = ?
-	`
-	_, err := db.Exec(
-		query,
+	`,
 		soal.Nomor,
 		soal.Pertanyaan,
-		soal.PilihanA,
-		soal.PilihanB,
-		soal.PilihanC,
-		soal.PilihanD,
-		soal.JawabanBenar,
+		soal.Tipe,
+		soal.Poin,
+		soal.KunciIsian,
+		soal.ModeIsian,
+		soal.Pembahasan,
+		versi,
 		soal.IDSoal,
 	)
-	return err
-}
-
-func DeleteSoal(db *sql.DB, idSoal int) error {
-	query := `DELETE FROM soal_test WHERE id_soal = ?`
-	_, err := db.Exec(query, idSoal)
-	return err
-}
-
-func GetTestConfig(db *sql.DB) (*models.Test, error) {
-	query := `
-		SELECT 
-			id_test, judul, deskripsi, durasi_menit, 
-			waktu_mulai, waktu_selesai, aktif, created_at, updated_at
-		FROM test
-		WHERE aktif = TRUE
-		LIMIT 1
-	`
-	row := db.QueryRow(query)
-
-	var t models.Test
-	var deskripsi sql.NullString
-	var waktuMulai, waktuSelesai sql.NullTime
-
-	err := row.Scan(
-		&t.IDTest,
-		&t.Judul,
-		&deskripsi,
-		&t.DurasiMenit,
-		&waktuMulai,
-		&waktuSelesai,
-		&t.Aktif,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if deskripsi.Valid {
-		t.Deskripsi = &deskripsi.String
+	if err := simpanPilihan(tx, soal.IDSoal, soal.Pilihan); err != nil {
+		return err
 	}
-	if waktuMulai.Valid {
-		t.WaktuMulai = &waktuMulai.Time
-	}
-	if waktuSelesai.Valid {
-		t.WaktuSelesai = &waktuSelesai.Time
+	if err := tx.Commit(); err != nil {
+		return err
 	}
-
-	return &t, nil
+	soal.Versi = versi
+	return nil
 }
 
 func GetUserPendaftarID(db *sql.DB, userID int) (int, error) {
@@ -171,85 +164,78 @@ func GetUserPendaftarID(db *sql.DB, userID int) (int, error) {
 	return pendaftarID, err
 }
 
+// HasUserTakenTest memeriksa apakah user sudah menyelesaikan (submit) tes
 func HasUserTakenTest(db *sql.DB, userID, idTest int) (bool, error) {
 	var exists bool
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM hasil_test 
-			WHERE user_id = ? AND id_test = ?
+			WHERE user_id = ? AND id_test = ? AND status = 'selesai'
 		)
 	`
 	err := db.QueryRow(query, userID, idTest).Scan(&exists)
 	return exists, err
 }
 
-func GetHasilIDByUserAndTest(db *sql.DB, userID, idTest int) (int, error) {
-	var idHasil int
-	query := `
-		SELECT id_hasil 
-		FROM hasil_test 
-		WHERE user_id = ? AND id_test = ? 
-		ORDER BY created_at DESC 
-		LIMIT 1
-	`
-	err := db.QueryRow(query, userID, idTest).Scan(&idHasil)
-	return idHasil, err
-}
+const selectHasilTest = `
+	SELECT 
+		id_hasil, user_id, pendaftar_id, id_test, percobaan,
+		skor_benar, skor_salah, poin, penyesuaian_poin, total_poin, nilai, tipe_kepribadian, status,
+		waktu_mulai, batas_waktu, waktu_selesai,
+		durasi_menit,
+		created_at, updated_at,
+		(SELECT COUNT(*) FROM jawaban_user ju
+		 WHERE ju.id_hasil = hasil_test.id_hasil AND ju.status_penilaian = 'menunggu') AS menunggu_penilaian
+	FROM hasil_test
+`
 
-func CreateHasilTest(db *sql.DB, hasil models.HasilTest) error {
-	query := `
-		INSERT INTO hasil_test (user_id, pendaftar_id, id_test, waktu_mulai, waktu_selesai, skor_benar, skor_salah, nilai)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`
-	_, err := db.Exec(
-		query,
-		hasil.UserID,
-		hasil.PendaftarID,
-		hasil.IDTest,
-		hasil.WaktuMulai,
-		hasil.WaktuSelesai,
-		hasil.SkorBenar,
-		hasil.SkorSalah,
-		hasil.Nilai,
-	)
-	return err
+// GetHasilByUserID mengambil percobaan terakhir user pada sebuah tes
+func GetHasilByUserID(db *sql.DB, userID, idTest int) (*models.HasilTest, error) {
+	return scanHasilTest(db.QueryRow(
+		selectHasilTest+` WHERE user_id = ? AND id_test = ? ORDER BY percobaan DESC LIMIT 1`,
+		userID, idTest,
+	))
 }
 
-func GetHasilByUserID(db *sql.DB, userID, idTest int) (*models.HasilTest, error) {
-	query := `
-		SELECT 
-			id_hasil, user_id, pendaftar_id, id_test,
-			skor_benar, skor_salah, nilai,
-			waktu_mulai, waktu_selesai,
-			durasi_menit,
-			created_at, updated_at
-		FROM hasil_test
-		WHERE user_id = ? AND id_test = ?
-	`
-	row := db.QueryRow(query, userID, idTest)
+func GetHasilByID(db *sql.DB, idHasil int) (*models.HasilTest, error) {
+	return scanHasilTest(db.QueryRow(selectHasilTest+` WHERE id_hasil = ?`, idHasil))
+}
 
+func scanHasilTest(row *sql.Row) (*models.HasilTest, error) {
 	var h models.HasilTest
-	var waktuSelesai sql.NullTime
+	var batasWaktu, waktuSelesai sql.NullTime
 	var durasiMenit sql.NullInt64
+	var tipeKepribadian sql.NullString
 
 	err := row.Scan(
 		&h.IDHasil,
 		&h.UserID,
 		&h.PendaftarID,
 		&h.IDTest,
+		&h.Percobaan,
 		&h.SkorBenar,
 		&h.SkorSalah,
+		&h.Poin,
+		&h.PenyesuaianPoin,
+		&h.TotalPoin,
 		&h.Nilai,
+		&tipeKepribadian,
+		&h.Status,
 		&h.WaktuMulai,
+		&batasWaktu,
 		&waktuSelesai,
 		&durasiMenit,
 		&h.CreatedAt,
 		&h.UpdatedAt,
+		&h.MenungguPenilaian,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	if batasWaktu.Valid {
+		h.BatasWaktu = &batasWaktu.Time
+	}
 	if waktuSelesai.Valid {
 		h.WaktuSelesai = &waktuSelesai.Time
 	}
@@ -257,31 +243,62 @@ func GetHasilByUserID(db *sql.DB, userID, idTest int) (*models.HasilTest, error)
 		min := int(durasiMenit.Int64)
 		h.DurasiMenit = &min
 	}
+	h.TipeKepribadian = nullStringPtr(tipeKepribadian)
 
 	return &h, nil
 }
 
-func CreateJawabanUser(db *sql.DB, jawaban models.JawabanUser) error {
+// GetHasilByPendaftarID mengambil semua hasil tes milik seorang pendaftar
+func GetHasilByPendaftarID(db *sql.DB, pendaftarID int) ([]models.HasilTest, error) {
 	query := `
-		INSERT INTO jawaban_user (id_hasil, id_soal, jawaban_user, is_benar)
-		VALUES (?, ?, ?, ?)
+		SELECT
+			ht.id_hasil, ht.user_id, ht.pendaftar_id, ht.id_test,
+			ht.skor_benar, ht.skor_salah, ht.nilai, ht.tipe_kepribadian,
+			ht.waktu_mulai, ht.waktu_selesai,
+			t.judul
+		FROM hasil_test ht
+		INNER JOIN test t ON ht.id_test = t.id_test
+		WHERE ht.pendaftar_id = ? AND ht.status = 'selesai'
+		ORDER BY ht.waktu_mulai ASC
 	`
-	_, err := db.Exec(
-		query,
-		jawaban.IDHasil,
-		jawaban.IDSoal,
-		jawaban.JawabanUser,
-		jawaban.IsBenar,
-	)
-	return err
+	rows, err := db.Query(query, pendaftarID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.HasilTest
+	for rows.Next() {
+		var h models.HasilTest
+		var waktuSelesai sql.NullTime
+		var tipeKepribadian sql.NullString
+		err := rows.Scan(
+			&h.IDHasil, &h.UserID, &h.PendaftarID, &h.IDTest,
+			&h.SkorBenar, &h.SkorSalah, &h.Nilai, &tipeKepribadian,
+			&h.WaktuMulai, &waktuSelesai,
+			&h.JudulTest,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if waktuSelesai.Valid {
+			h.WaktuSelesai = &waktuSelesai.Time
+		}
+		h.TipeKepribadian = nullStringPtr(tipeKepribadian)
+		list = append(list, h)
+	}
+	return list, rows.Err()
 }
 
 func GetJawabanByHasilID(db *sql.DB, idHasil int) ([]models.JawabanUser, error) {
 	query := `
-		SELECT id_jawaban, id_hasil, id_soal, jawaban_user, is_benar, created_at
-		FROM jawaban_user
-		WHERE id_hasil = ?
-		ORDER BY id_soal
+		SELECT
+			ju.id_jawaban, ju.id_hasil, ju.id_soal, COALESCE(v.versi, 0), ju.jawaban_user, ju.is_benar, ju.poin,
+			ju.status_penilaian, ju.catatan_penilai, ju.dinilai_oleh, ju.dinilai_at, ju.created_at, ju.updated_at
+		FROM jawaban_user ju
+		LEFT JOIN versi_soal v ON v.id_versi = ju.id_versi
+		WHERE ju.id_hasil = ?
+		ORDER BY ju.id_soal
 	`
 	rows, err := db.Query(query, idHasil)
 	if err != nil {
@@ -292,108 +309,46 @@ func GetJawabanByHasilID(db *sql.DB, idHasil int) ([]models.JawabanUser, error)
 	var jawabans []models.JawabanUser
 	for rows.Next() {
 		var j models.JawabanUser
+		var isBenar sql.NullBool
+		var poin sql.NullFloat64
+		var catatan sql.NullString
+		var dinilaiOleh sql.NullInt64
+		var dinilaiAt sql.NullTime
 		err := rows.Scan(
 			&j.IDJawaban,
 			&j.IDHasil,
 			&j.IDSoal,
+			&j.Versi,
 			&j.JawabanUser,
-			&j.IsBenar,
+			&isBenar,
+			&poin,
+			&j.StatusPenilaian,
+			&catatan,
+			&dinilaiOleh,
+			&dinilaiAt,
 			&j.CreatedAt,
+			&j.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if isBenar.Valid {
+			j.IsBenar = &isBenar.Bool
+		}
+		if poin.Valid {
+			j.Poin = &poin.Float64
+		}
+		if catatan.Valid {
+			j.CatatanPenilai = &catatan.String
+		}
+		if dinilaiOleh.Valid {
+			id := int(dinilaiOleh.Int64)
+			j.DinilaiOleh = &id
+		}
+		if dinilaiAt.Valid {
+			j.DinilaiAt = &dinilaiAt.Time
+		}
 		jawabans = append(jawabans, j)
 	}
 	return jawabans, rows.Err()
 }
-
-func UpdateHasilTest(db *sql.DB, hasil *models.HasilTest) error {
-	query := `
-		UPDATE hasil_test SET
-			skor_benar = ?,
-			skor_salah = ?,
-			nilai = ?,
-			waktu_selesai = ?,
-			updated_at = NOW()
-		WHERE id_hasil = ?
-	`
-	_, err := db.Exec(
-		query,
-		hasil.SkorBenar,
-		hasil.SkorSalah,
-		hasil.Nilai,
-		hasil.WaktuSelesai,
-		hasil.IDHasil,
-	)
-	return err
-}
-
-func GetAllHasilTestByTestID(db *sql.DB, idTest int) (*sql.Rows, error) {
-    query := `
-    SELECT 
-        id_hasil, user_id, pendaftar_id,
-        skor_benar, skor_salah, nilai,
-        waktu_mulai, waktu_selesai,
-        durasi_menit
-    FROM hasil_test
-    WHERE id_test = ?
-    ORDER BY nilai DESC, waktu_mulai ASC
-`
-    return db.Query(query, idTest)
-}
-
-
-func UpsertTestConfig(db *sql.DB, config models.Test) error {
-    existing, err := GetTestConfig(db)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return CreateTestConfig(db, config)
-        }
-        return err
-    }
-
-    query := `
-        UPDATE test 
-        SET 
-            judul = ?, 
-            deskripsi = ?, 
-            durasi_menit = ?, 
-            waktu_mulai = ?, 
-            waktu_selesai = ?, 
-            aktif = ?, 
-            updated_at = CURRENT_TIMESTAMP 
-        WHERE id_test = ?
-    `
-
-    _, err = db.Exec(
-        query,
-        config.Judul,
-        config.Deskripsi,
-        config.DurasiMenit,
-        config.WaktuMulai,
-        config.WaktuSelesai,
-        config.Aktif,
-        existing.IDTest,
-    )
-    return err
-}
-
-
-func CreateTestConfig(db *sql.DB, config models.Test) error {
-    query := `
-        INSERT INTO test 
-        (judul, deskripsi, durasi_menit, waktu_mulai, waktu_selesai, aktif) 
-        VALUES (?, ?, ?, ?, ?, ?)
-    `
-    _, err := db.Exec(
-        query,
-        config.Judul,
-        config.Deskripsi,
-        config.DurasiMenit,
-        config.WaktuMulai,
-        config.WaktuSelesai,
-        config.Aktif,
-    )
-    return err
-}
\ No newline at end of file
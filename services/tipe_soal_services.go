@@ -0,0 +1,298 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	TipeSoalPilihanGanda      = "pilihan_ganda"
+	TipeSoalPilihanGandaMulti = "pilihan_ganda_multi"
+	TipeSoalBenarSalah        = "benar_salah"
+	TipeSoalIsian             = "isian"
+	TipeSoalEsai              = "esai"
+	// Psikotes: tanpa jawaban benar; pilihan diberi bobot ke dimensi sifat (lihat psikotes_services)
+	TipeSoalPsikotes = "psikotes"
+
+	ModeIsianPersis = "persis"
+	ModeIsianRegex  = "regex"
+
+	StatusPenilaianOtomatis = "otomatis"
+	StatusPenilaianMenunggu = "menunggu"
+	StatusPenilaianDinilai  = "dinilai"
+
+	MinPilihanSoal = 2
+	MaxPilihanSoal = 10
+)
+
+var ErrJawabanTidakValid = errors.New("format jawaban tidak valid untuk tipe soal ini")
+
+const selectSoal = `
+	SELECT
+		s.id_soal, s.nomor, s.pertanyaan, s.tipe, s.poin, s.kunci_isian, s.mode_isian,
+		s.pembahasan, s.created_at, s.updated_at, s.versi, s.diarsipkan_at
+`
+
+// scanSoal membaca kolom selectSoal; kolom tambahan (mis. urutan) dibaca ke extra
+func scanSoal(row interface{ Scan(...any) error }, extra ...any) (models.SoalTest, error) {
+	var s models.SoalTest
+	var kunci, pembahasan sql.NullString
+	var diarsipkan sql.NullTime
+	dest := append([]any{
+		&s.IDSoal, &s.Nomor, &s.Pertanyaan, &s.Tipe, &s.Poin, &kunci, &s.ModeIsian,
+		&pembahasan, &s.CreatedAt, &s.UpdatedAt, &s.Versi, &diarsipkan,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return s, err
+	}
+	if diarsipkan.Valid {
+		s.DiarsipkanAt = &diarsipkan.Time
+	}
+	if kunci.Valid {
+		s.KunciIsian = &kunci.String
+	}
+	if pembahasan.Valid {
+		s.Pembahasan = &pembahasan.String
+	}
+	return s, nil
+}
+
+// LabelPilihan mengubah urutan pilihan (1, 2, ...) menjadi huruf (A, B, ...)
+func LabelPilihan(urutan int) string {
+	return string(rune('A' + urutan - 1))
+}
+
+// TipeBerpilihan menandai tipe soal yang dijawab dengan memilih pilihan
+func TipeBerpilihan(tipe string) bool {
+	return tipe == TipeSoalPilihanGanda || tipe == TipeSoalPilihanGandaMulti || tipe == TipeSoalBenarSalah ||
+		tipe == TipeSoalPsikotes
+}
+
+// lampirkanPilihan mengisi Pilihan untuk setiap soal dengan satu query
+func lampirkanPilihan(db *sql.DB, soals []models.SoalTest) error {
+	if len(soals) == 0 {
+		return nil
+	}
+
+	index := make(map[int]int, len(soals))
+	placeholders := make([]string, 0, len(soals))
+	args := make([]any, 0, len(soals))
+	for i, s := range soals {
+		index[s.IDSoal] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, s.IDSoal)
+	}
+
+	rows, err := db.Query(`
+		SELECT id_soal, urutan, teks, is_benar
+		FROM pilihan_soal
+		WHERE id_soal IN (`+strings.Join(placeholders, ",")+`)
+		ORDER BY id_soal, urutan
+	`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idSoal int
+		var p models.PilihanSoal
+		if err := rows.Scan(&idSoal, &p.Urutan, &p.Teks, &p.IsBenar); err != nil {
+			return err
+		}
+		p.Label = LabelPilihan(p.Urutan)
+		i := index[idSoal]
+		soals[i].Pilihan = append(soals[i].Pilihan, p)
+	}
+	return rows.Err()
+}
+
+// simpanPilihan mengganti seluruh pilihan soal; urutan mengikuti posisi di slice
+func simpanPilihan(tx *sql.Tx, idSoal int, pilihan []models.PilihanSoal) error {
+	if _, err := tx.Exec(`DELETE FROM pilihan_soal WHERE id_soal = ?`, idSoal); err != nil {
+		return err
+	}
+	for i, p := range pilihan {
+		_, err := tx.Exec(
+			`INSERT INTO pilihan_soal (id_soal, urutan, teks, is_benar) VALUES (?, ?, ?, ?)`,
+			idSoal, i+1, p.Teks, p.IsBenar,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidasiSoal memeriksa kelengkapan soal sesuai tipenya. Poin soal psikotes selalu dijadikan 0
+// karena jawabannya membentuk profil, bukan nilai.
+func ValidasiSoal(s *models.SoalTest) error {
+	if s.Poin < 0 {
+		return errors.New("poin soal tidak boleh negatif")
+	}
+
+	jumlahBenar := 0
+	for _, p := range s.Pilihan {
+		if strings.TrimSpace(p.Teks) == "" {
+			return errors.New("teks pilihan tidak boleh kosong")
+		}
+		if p.IsBenar {
+			jumlahBenar++
+		}
+	}
+
+	switch s.Tipe {
+	case TipeSoalPilihanGanda, TipeSoalPilihanGandaMulti, TipeSoalBenarSalah:
+		min, max := MinPilihanSoal, MaxPilihanSoal
+		if s.Tipe == TipeSoalBenarSalah {
+			max = 2
+		}
+		if len(s.Pilihan) < min || len(s.Pilihan) > max {
+			return fmt.Errorf("soal %s harus memiliki %d sampai %d pilihan", s.Tipe, min, max)
+		}
+		if s.Tipe == TipeSoalPilihanGandaMulti {
+			if jumlahBenar == 0 {
+				return errors.New("tandai minimal satu pilihan benar")
+			}
+		} else if jumlahBenar != 1 {
+			return errors.New("tandai tepat satu pilihan benar")
+		}
+
+	case TipeSoalIsian:
+		if len(s.Pilihan) > 0 {
+			return errors.New("soal isian tidak memakai pilihan")
+		}
+		if s.KunciIsian == nil || strings.TrimSpace(*s.KunciIsian) == "" {
+			return errors.New("kunci_isian wajib diisi untuk soal isian")
+		}
+		if s.ModeIsian == ModeIsianRegex {
+			if _, err := regexp.Compile(*s.KunciIsian); err != nil {
+				return fmt.Errorf("pola regex kunci_isian tidak valid: %v", err)
+			}
+		}
+
+	case TipeSoalEsai:
+		if len(s.Pilihan) > 0 {
+			return errors.New("soal esai tidak memakai pilihan")
+		}
+
+	case TipeSoalPsikotes:
+		if len(s.Pilihan) < MinPilihanSoal || len(s.Pilihan) > MaxPilihanSoal {
+			return fmt.Errorf("soal %s harus memiliki %d sampai %d pilihan", s.Tipe, MinPilihanSoal, MaxPilihanSoal)
+		}
+		if jumlahBenar > 0 {
+			return errors.New("soal psikotes tidak memiliki pilihan benar")
+		}
+		s.Poin = 0
+
+	default:
+		return fmt.Errorf("tipe soal %q tidak dikenal", s.Tipe)
+	}
+	return nil
+}
+
+// parseLabelPilihan membaca jawaban huruf ("A" atau "A,C") menjadi daftar huruf unik terurut
+func parseLabelPilihan(jawaban string, jumlahPilihan int) ([]string, error) {
+	var labels []string
+	seen := map[string]bool{}
+	for _, part := range strings.Split(strings.ToUpper(jawaban), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if len(part) != 1 || part[0] < 'A' || int(part[0]-'A') >= jumlahPilihan {
+			return nil, ErrJawabanTidakValid
+		}
+		if !seen[part] {
+			seen[part] = true
+			labels = append(labels, part)
+		}
+	}
+	if len(labels) == 0 {
+		return nil, ErrJawabanTidakValid
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// NilaiJawaban menormalkan jawaban kanonik dan menilainya secara otomatis.
+// Soal esai dikembalikan dengan status menunggu tanpa poin.
+func NilaiJawaban(s *models.SoalTest, kanonik string) (models.JawabanUser, error) {
+	j := models.JawabanUser{IDSoal: s.IDSoal, Versi: s.Versi, StatusPenilaian: StatusPenilaianOtomatis}
+
+	var benar bool
+	switch s.Tipe {
+	case TipeSoalPilihanGanda, TipeSoalPilihanGandaMulti, TipeSoalBenarSalah:
+		labels, err := parseLabelPilihan(kanonik, len(s.Pilihan))
+		if err != nil {
+			return j, err
+		}
+		if s.Tipe != TipeSoalPilihanGandaMulti && len(labels) != 1 {
+			return j, ErrJawabanTidakValid
+		}
+
+		var kunci []string
+		for _, p := range s.Pilihan {
+			if p.IsBenar {
+				kunci = append(kunci, p.Label)
+			}
+		}
+		j.JawabanUser = strings.Join(labels, ",")
+		benar = j.JawabanUser == strings.Join(kunci, ",")
+
+	case TipeSoalIsian:
+		j.JawabanUser = strings.TrimSpace(kanonik)
+		benar = cocokIsian(s, j.JawabanUser)
+
+	case TipeSoalEsai:
+		j.JawabanUser = strings.TrimSpace(kanonik)
+		j.StatusPenilaian = StatusPenilaianMenunggu
+		return j, nil
+
+	case TipeSoalPsikotes:
+		// Tidak benar/salah dan tanpa poin; pilihan dipakai untuk profil psikotes
+		labels, err := parseLabelPilihan(kanonik, len(s.Pilihan))
+		if err != nil {
+			return j, err
+		}
+		if len(labels) != 1 {
+			return j, ErrJawabanTidakValid
+		}
+		j.JawabanUser = labels[0]
+		return j, nil
+
+	default:
+		return j, ErrJawabanTidakValid
+	}
+
+	poin := 0.0
+	if benar {
+		poin = s.Poin
+	}
+	j.IsBenar = &benar
+	j.Poin = &poin
+	return j, nil
+}
+
+// cocokIsian membandingkan jawaban isian: mode persis tidak peka huruf besar/kecil dan spasi tepi,
+// mode regex harus cocok dengan seluruh jawaban
+func cocokIsian(s *models.SoalTest, jawaban string) bool {
+	if s.KunciIsian == nil {
+		return false
+	}
+	if s.ModeIsian == ModeIsianRegex {
+		re, err := regexp.Compile(`^(?:` + *s.KunciIsian + `)$`)
+		return err == nil && re.MatchString(jawaban)
+	}
+	for _, kunci := range strings.Split(*s.KunciIsian, "\n") {
+		if kunci = strings.TrimSpace(kunci); kunci != "" && strings.EqualFold(kunci, jawaban) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Jenis baris pada perbandingan teks antarversi
+const (
+	BarisSama   = "sama"
+	BarisHapus  = "hapus"
+	BarisTambah = "tambah"
+)
+
+var ErrSoalDiarsipkan = errors.New("soal sudah diarsipkan")
+
+// simpanVersiSoal menyalin isi soal sebagai versi baru di dalam transaksi pemanggil
+func simpanVersiSoal(tx *sql.Tx, idSoal, versi int, s *models.SoalTest, adminID int) error {
+	res, err := tx.Exec(`
+		INSERT INTO versi_soal (id_soal, versi, pertanyaan, tipe, poin, kunci_isian, mode_isian, pembahasan, dibuat_oleh)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0))
+	`, idSoal, versi, s.Pertanyaan, s.Tipe, s.Poin, s.KunciIsian, s.ModeIsian, s.Pembahasan, adminID)
+	if err != nil {
+		return err
+	}
+	idVersi, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	for i, p := range s.Pilihan {
+		_, err := tx.Exec(
+			`INSERT INTO pilihan_versi_soal (id_versi, urutan, teks, is_benar) VALUES (?, ?, ?, ?)`,
+			idVersi, i+1, p.Teks, p.IsBenar,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// samaIsiSoal membandingkan bagian soal yang diversikan; nomor urut bank soal tidak termasuk
+func samaIsiSoal(a, b *models.SoalTest) bool {
+	if a.Pertanyaan != b.Pertanyaan || a.Tipe != b.Tipe || a.Poin != b.Poin || a.ModeIsian != b.ModeIsian ||
+		teksOpsional(a.KunciIsian) != teksOpsional(b.KunciIsian) ||
+		teksOpsional(a.Pembahasan) != teksOpsional(b.Pembahasan) ||
+		len(a.Pilihan) != len(b.Pilihan) {
+		return false
+	}
+	for i := range a.Pilihan {
+		if a.Pilihan[i].Teks != b.Pilihan[i].Teks || a.Pilihan[i].IsBenar != b.Pilihan[i].IsBenar {
+			return false
+		}
+	}
+	return true
+}
+
+func teksOpsional(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// ArsipkanSoal mengarsipkan soal sebagai pengganti hapus. Soal dilepas dari tes yang belum pernah
+// dikerjakan; tes yang sudah memiliki hasil tetap memakainya agar hasil lama tidak berubah.
+// Mengembalikan jumlah tes tempat soal dilepas.
+func ArsipkanSoal(db *sql.DB, idSoal int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE soal_test SET diarsipkan_at = UTC_TIMESTAMP() WHERE id_soal = ? AND diarsipkan_at IS NULL
+	`, idSoal)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, ErrSoalDiarsipkan
+	}
+
+	res, err = tx.Exec(`
+		DELETE FROM test_soal
+		WHERE id_soal = ? AND id_test NOT IN (SELECT DISTINCT id_test FROM hasil_test)
+	`, idSoal)
+	if err != nil {
+		return 0, err
+	}
+	dilepas, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(dilepas), tx.Commit()
+}
+
+// PulihkanSoal mengembalikan soal dari arsip ke bank soal; tes yang sudah melepasnya tidak berubah
+func PulihkanSoal(db *sql.DB, idSoal int) error {
+	_, err := db.Exec(`UPDATE soal_test SET diarsipkan_at = NULL WHERE id_soal = ?`, idSoal)
+	return err
+}
+
+const selectVersiSoal = `
+	SELECT
+		v.id_versi, v.id_soal, v.versi, v.pertanyaan, v.tipe, v.poin, v.kunci_isian, v.mode_isian,
+		v.pembahasan, v.dibuat_oleh, u.full_name, v.created_at,
+		(SELECT COUNT(*) FROM jawaban_user jv WHERE jv.id_versi = v.id_versi) AS jumlah_jawaban
+	FROM versi_soal v
+	LEFT JOIN users u ON u.id_user = v.dibuat_oleh
+`
+
+// queryVersiSoal menjalankan selectVersiSoal dengan lanjutan query lalu melampirkan pilihan tiap versi
+func queryVersiSoal(db *sql.DB, lanjutan string, args ...any) ([]models.VersiSoal, error) {
+	rows, err := db.Query(selectVersiSoal+lanjutan, args...)
+	if err != nil {
+		return nil, err
+	}
+	daftar := []models.VersiSoal{}
+	indeks := map[int]int{}
+	for rows.Next() {
+		var v models.VersiSoal
+		var kunci, pembahasan, namaPembuat sql.NullString
+		var dibuatOleh sql.NullInt64
+		err := rows.Scan(&v.IDVersi, &v.IDSoal, &v.Versi, &v.Pertanyaan, &v.Tipe, &v.Poin, &kunci, &v.ModeIsian,
+			&pembahasan, &dibuatOleh, &namaPembuat, &v.CreatedAt, &v.JumlahJawaban)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		v.KunciIsian = nullStringPtr(kunci)
+		v.Pembahasan = nullStringPtr(pembahasan)
+		v.DibuatOleh = nullIntPtr(dibuatOleh)
+		v.NamaPembuat = nullStringPtr(namaPembuat)
+		indeks[v.IDVersi] = len(daftar)
+		daftar = append(daftar, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(daftar) == 0 {
+		return daftar, nil
+	}
+
+	placeholders := make([]string, 0, len(daftar))
+	idArgs := make([]any, 0, len(daftar))
+	for _, v := range daftar {
+		placeholders = append(placeholders, "?")
+		idArgs = append(idArgs, v.IDVersi)
+	}
+	rows, err = db.Query(`
+		SELECT id_versi, urutan, teks, is_benar
+		FROM pilihan_versi_soal
+		WHERE id_versi IN (`+strings.Join(placeholders, ",")+`)
+		ORDER BY id_versi, urutan
+	`, idArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idVersi int
+		var p models.PilihanSoal
+		if err := rows.Scan(&idVersi, &p.Urutan, &p.Teks, &p.IsBenar); err != nil {
+			return nil, err
+		}
+		p.Label = LabelPilihan(p.Urutan)
+		i := indeks[idVersi]
+		daftar[i].Pilihan = append(daftar[i].Pilihan, p)
+	}
+	return daftar, rows.Err()
+}
+
+// GetVersiSoal mengambil riwayat versi sebuah soal, terbaru lebih dulu
+func GetVersiSoal(db *sql.DB, idSoal int) ([]models.VersiSoal, error) {
+	return queryVersiSoal(db, ` WHERE v.id_soal = ? ORDER BY v.versi DESC`, idSoal)
+}
+
+// GetSatuVersiSoal mengambil satu versi soal (sql.ErrNoRows bila tidak ada)
+func GetSatuVersiSoal(db *sql.DB, idSoal, versi int) (*models.VersiSoal, error) {
+	daftar, err := queryVersiSoal(db, ` WHERE v.id_soal = ? AND v.versi = ?`, idSoal, versi)
+	if err != nil {
+		return nil, err
+	}
+	if len(daftar) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return &daftar[0], nil
+}
+
+// terapkanVersiJawaban mengganti isi soal yang sudah dijawab pada sesi dengan versi yang dijawab
+// peserta, sehingga pembahasan menampilkan soal persis seperti saat dikerjakan
+func terapkanVersiJawaban(db *sql.DB, idHasil int, soals []models.SoalTest) error {
+	daftar, err := queryVersiSoal(db, `
+		INNER JOIN jawaban_user ju ON ju.id_versi = v.id_versi
+		WHERE ju.id_hasil = ?`, idHasil)
+	if err != nil {
+		return err
+	}
+	versi := make(map[int]*models.VersiSoal, len(daftar))
+	for i := range daftar {
+		versi[daftar[i].IDSoal] = &daftar[i]
+	}
+	for i := range soals {
+		s := &soals[i]
+		v, ok := versi[s.IDSoal]
+		if !ok || v.Versi == s.Versi {
+			continue
+		}
+		s.Versi = v.Versi
+		s.Pertanyaan = v.Pertanyaan
+		s.Tipe = v.Tipe
+		s.Poin = v.Poin
+		s.Pilihan = v.Pilihan
+		s.KunciIsian = v.KunciIsian
+		s.ModeIsian = v.ModeIsian
+		s.Pembahasan = v.Pembahasan
+	}
+	return nil
+}
+
+// BandingkanVersiSoal mengembalikan bagian soal yang berbeda dari versi lama ke versi baru
+func BandingkanVersiSoal(lama, baru *models.VersiSoal) []models.PerbedaanSoal {
+	perbedaan := []models.PerbedaanSoal{}
+	bandingkan := func(bagian string, a, b *string, perBaris bool) {
+		if teksOpsional(a) == teksOpsional(b) {
+			return
+		}
+		p := models.PerbedaanSoal{Bagian: bagian, Lama: a, Baru: b}
+		if perBaris {
+			p.Baris = diffBaris(teksOpsional(a), teksOpsional(b))
+		}
+		perbedaan = append(perbedaan, p)
+	}
+	teks := func(s string) *string { return &s }
+	angka := func(f float64) *string { return teks(strconv.FormatFloat(f, 'f', -1, 64)) }
+
+	bandingkan("pertanyaan", &lama.Pertanyaan, &baru.Pertanyaan, true)
+	bandingkan("tipe", &lama.Tipe, &baru.Tipe, false)
+	bandingkan("poin", angka(lama.Poin), angka(baru.Poin), false)
+	bandingkan("kunci_isian", lama.KunciIsian, baru.KunciIsian, true)
+	bandingkan("mode_isian", &lama.ModeIsian, &baru.ModeIsian, false)
+
+	jumlah := max(len(lama.Pilihan), len(baru.Pilihan))
+	for i := 0; i < jumlah; i++ {
+		var a, b *string
+		if i < len(lama.Pilihan) {
+			a = &lama.Pilihan[i].Teks
+		}
+		if i < len(baru.Pilihan) {
+			b = &baru.Pilihan[i].Teks
+		}
+		bandingkan("pilihan "+LabelPilihan(i+1), a, b, true)
+	}
+	bandingkan("jawaban_benar", labelBenar(lama.Pilihan), labelBenar(baru.Pilihan), false)
+
+	bandingkan("pembahasan", lama.Pembahasan, baru.Pembahasan, true)
+	return perbedaan
+}
+
+// labelBenar mengembalikan huruf pilihan benar berpisah koma, atau nil bila tidak ada
+func labelBenar(pilihan []models.PilihanSoal) *string {
+	var labels []string
+	for _, p := range pilihan {
+		if p.IsBenar {
+			labels = append(labels, p.Label)
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	s := strings.Join(labels, ",")
+	return &s
+}
+
+// diffBaris membandingkan dua teks per baris memakai subbarisan bersama terpanjang (LCS)
+func diffBaris(lama, baru string) []models.BarisDiff {
+	a := strings.Split(lama, "\n")
+	b := strings.Split(baru, "\n")
+	if lama == "" {
+		a = nil
+	}
+	if baru == "" {
+		b = nil
+	}
+
+	// lcs[i][j] = panjang LCS a[i:] dan b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var baris []models.BarisDiff
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			baris = append(baris, models.BarisDiff{Jenis: BarisSama, Teks: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			baris = append(baris, models.BarisDiff{Jenis: BarisHapus, Teks: a[i]})
+			i++
+		default:
+			baris = append(baris, models.BarisDiff{Jenis: BarisTambah, Teks: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		baris = append(baris, models.BarisDiff{Jenis: BarisHapus, Teks: a[i]})
+	}
+	for ; j < len(b); j++ {
+		baris = append(baris, models.BarisDiff{Jenis: BarisTambah, Teks: b[j]})
+	}
+	return baris
+}
@@ -0,0 +1,488 @@
+package services
+
+import (
+	"cocopen-backend/models"
+	"database/sql"
+	"errors"
+)
+
+var ErrRubrikDipakai = errors.New("rubrik sudah dipakai untuk penilaian")
+
+func insertKriteria(tx *sql.Tx, idRubrik int, kriteria []models.KriteriaWawancara) error {
+	query := `
+		INSERT INTO kriteria_wawancara (id_rubrik, nama, deskripsi, bobot, skor_maks, urutan)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for i, k := range kriteria {
+		_, err := tx.Exec(query, idRubrik, k.Nama, k.Deskripsi, k.Bobot, k.SkorMaks, i+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRubrik menyimpan rubrik beserta kriterianya; rubrik aktif baru menonaktifkan rubrik lain
+func CreateRubrik(db *sql.DB, r models.RubrikWawancara) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if r.Aktif {
+		if _, err := tx.Exec(`UPDATE rubrik_wawancara SET aktif = FALSE`); err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := tx.Exec(
+		`INSERT INTO rubrik_wawancara (nama, deskripsi, aktif) VALUES (?, ?, ?)`,
+		r.Nama, r.Deskripsi, r.Aktif,
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertKriteria(tx, int(id), r.Kriteria); err != nil {
+		return 0, err
+	}
+
+	return int(id), tx.Commit()
+}
+
+func getKriteriaByRubrik(db *sql.DB, idRubrik int) ([]models.KriteriaWawancara, error) {
+	query := `
+		SELECT id_kriteria, id_rubrik, nama, deskripsi, bobot, skor_maks, urutan
+		FROM kriteria_wawancara
+		WHERE id_rubrik = ?
+		ORDER BY urutan ASC, id_kriteria ASC
+	`
+	rows, err := db.Query(query, idRubrik)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var kriteria []models.KriteriaWawancara
+	for rows.Next() {
+		var k models.KriteriaWawancara
+		var deskripsi sql.NullString
+		if err := rows.Scan(&k.IDKriteria, &k.IDRubrik, &k.Nama, &deskripsi, &k.Bobot, &k.SkorMaks, &k.Urutan); err != nil {
+			return nil, err
+		}
+		if deskripsi.Valid {
+			k.Deskripsi = &deskripsi.String
+		}
+		kriteria = append(kriteria, k)
+	}
+	return kriteria, rows.Err()
+}
+
+func scanRubrik(scanner interface{ Scan(...any) error }) (*models.RubrikWawancara, error) {
+	var r models.RubrikWawancara
+	var deskripsi sql.NullString
+	if err := scanner.Scan(&r.IDRubrik, &r.Nama, &deskripsi, &r.Aktif, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if deskripsi.Valid {
+		r.Deskripsi = &deskripsi.String
+	}
+	return &r, nil
+}
+
+// GetAllRubrik mengambil semua rubrik lengkap dengan kriteria; hanyaAktif untuk pewawancara
+func GetAllRubrik(db *sql.DB, hanyaAktif bool) ([]models.RubrikWawancara, error) {
+	query := `
+		SELECT id_rubrik, nama, deskripsi, aktif, created_at, updated_at
+		FROM rubrik_wawancara
+		WHERE (? = FALSE OR aktif = TRUE)
+		ORDER BY aktif DESC, created_at DESC
+	`
+	rows, err := db.Query(query, hanyaAktif)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rubriks []models.RubrikWawancara
+	for rows.Next() {
+		r, err := scanRubrik(rows)
+		if err != nil {
+			return nil, err
+		}
+		rubriks = append(rubriks, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range rubriks {
+		kriteria, err := getKriteriaByRubrik(db, rubriks[i].IDRubrik)
+		if err != nil {
+			return nil, err
+		}
+		rubriks[i].Kriteria = kriteria
+	}
+	return rubriks, nil
+}
+
+// GetRubrikByID mengambil satu rubrik lengkap dengan kriteria
+func GetRubrikByID(db *sql.DB, idRubrik int) (*models.RubrikWawancara, error) {
+	query := `
+		SELECT id_rubrik, nama, deskripsi, aktif, created_at, updated_at
+		FROM rubrik_wawancara
+		WHERE id_rubrik = ?
+	`
+	r, err := scanRubrik(db.QueryRow(query, idRubrik))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Kriteria, err = getKriteriaByRubrik(db, r.IDRubrik)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRubrikAktif mengambil rubrik yang sedang dipakai untuk penilaian baru
+func GetRubrikAktif(db *sql.DB) (*models.RubrikWawancara, error) {
+	var id int
+	err := db.QueryRow(`SELECT id_rubrik FROM rubrik_wawancara WHERE aktif = TRUE ORDER BY updated_at DESC LIMIT 1`).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return GetRubrikByID(db, id)
+}
+
+func isRubrikDipakai(tx *sql.Tx, idRubrik int) (bool, error) {
+	var dipakai bool
+	err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM penilaian_wawancara WHERE id_rubrik = ?)`, idRubrik).Scan(&dipakai)
+	return dipakai, err
+}
+
+// UpdateRubrik memperbarui rubrik; kriteria hanya boleh diganti jika rubrik belum dipakai menilai
+func UpdateRubrik(db *sql.DB, r *models.RubrikWawancara, gantiKriteria bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if gantiKriteria {
+		dipakai, err := isRubrikDipakai(tx, r.IDRubrik)
+		if err != nil {
+			return err
+		}
+		if dipakai {
+			return ErrRubrikDipakai
+		}
+		if _, err := tx.Exec(`DELETE FROM kriteria_wawancara WHERE id_rubrik = ?`, r.IDRubrik); err != nil {
+			return err
+		}
+		if err := insertKriteria(tx, r.IDRubrik, r.Kriteria); err != nil {
+			return err
+		}
+	}
+
+	if r.Aktif {
+		if _, err := tx.Exec(`UPDATE rubrik_wawancara SET aktif = FALSE WHERE id_rubrik <> ?`, r.IDRubrik); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(
+		`UPDATE rubrik_wawancara SET nama = ?, deskripsi = ?, aktif = ?, updated_at = NOW() WHERE id_rubrik = ?`,
+		r.Nama, r.Deskripsi, r.Aktif, r.IDRubrik,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteRubrik menghapus rubrik yang belum pernah dipakai menilai
+func DeleteRubrik(db *sql.DB, idRubrik int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	dipakai, err := isRubrikDipakai(tx, idRubrik)
+	if err != nil {
+		return err
+	}
+	if dipakai {
+		return ErrRubrikDipakai
+	}
+
+	res, err := tx.Exec(`DELETE FROM rubrik_wawancara WHERE id_rubrik = ?`, idRubrik)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+// SetPewawancaraJadwal mengganti daftar pewawancara sebuah jadwal
+func SetPewawancaraJadwal(db *sql.DB, idJadwal int, userIDs []int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM pewawancara_jadwal WHERE id_jadwal = ?`, idJadwal); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		_, err := tx.Exec(`INSERT IGNORE INTO pewawancara_jadwal (id_jadwal, user_id) VALUES (?, ?)`, idJadwal, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPewawancaraJadwal mengambil pewawancara yang ditugaskan pada jadwal
+func GetPewawancaraJadwal(db *sql.DB, idJadwal int) ([]models.User, error) {
+	query := `
+		SELECT u.id_user, u.username, u.full_name, u.email
+		FROM pewawancara_jadwal pj
+		INNER JOIN users u ON pj.user_id = u.id_user
+		WHERE pj.id_jadwal = ?
+		ORDER BY u.full_name ASC
+	`
+	rows, err := db.Query(query, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.IDUser, &u.Username, &u.FullName, &u.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// IsPewawancaraJadwal memeriksa apakah user ditugaskan mewawancarai pada jadwal tertentu
+func IsPewawancaraJadwal(db *sql.DB, idJadwal, userID int) (bool, error) {
+	var ok bool
+	err := db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM pewawancara_jadwal WHERE id_jadwal = ? AND user_id = ?)`,
+		idJadwal, userID,
+	).Scan(&ok)
+	return ok, err
+}
+
+// IsPewawancara memeriksa apakah user ditugaskan mewawancarai pada setidaknya satu jadwal
+func IsPewawancara(db *sql.DB, userID int) (bool, error) {
+	var ok bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM pewawancara_jadwal WHERE user_id = ?)`, userID).Scan(&ok)
+	return ok, err
+}
+
+// GetJadwalByPewawancara mengambil jadwal yang ditugaskan kepada seorang pewawancara
+func GetJadwalByPewawancara(db *sql.DB, userID int) ([]models.Jadwal, error) {
+	query := `
+		SELECT
+			j.id_jadwal, j.user_id, j.pendaftar_id,
+			j.waktu_mulai, j.waktu_selesai, j.zona_waktu,
+			j.tempat, j.konfirmasi_jadwal,
+			j.catatan, j.pengajuan_perubahan, j.alasan_perubahan,
+			j.waktu_mulai_diajukan, j.waktu_selesai_diajukan,
+			j.jenis_jadwal, j.kapasitas, j.batas_pendaftaran,
+			j.created_at, j.updated_at,
+			u.full_name AS user_nama
+		FROM jadwal j
+		INNER JOIN pewawancara_jadwal pj ON pj.id_jadwal = j.id_jadwal
+		LEFT JOIN users u ON j.user_id = u.id_user
+		WHERE pj.user_id = ?
+		ORDER BY j.waktu_mulai DESC
+	`
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanJadwalRows(rows)
+}
+
+// HitungNilaiWawancara menghitung nilai 0-100 dari skor per kriteria berbobot
+func HitungNilaiWawancara(kriteria []models.KriteriaWawancara, skor map[int]int) float64 {
+	var totalBobot, total float64
+	for _, k := range kriteria {
+		totalBobot += k.Bobot
+		if s, ok := skor[k.IDKriteria]; ok && k.SkorMaks > 0 {
+			total += float64(s) / float64(k.SkorMaks) * k.Bobot
+		}
+	}
+	if totalBobot == 0 {
+		return 0
+	}
+	return total / totalBobot * 100
+}
+
+// SimpanPenilaianWawancara menyimpan atau memperbarui penilaian seorang pewawancara pada jadwal
+func SimpanPenilaianWawancara(db *sql.DB, p models.PenilaianWawancara) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO penilaian_wawancara (id_jadwal, pewawancara_id, pendaftar_id, id_rubrik, nilai, catatan)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			pendaftar_id = VALUES(pendaftar_id),
+			id_rubrik = VALUES(id_rubrik),
+			nilai = VALUES(nilai),
+			catatan = VALUES(catatan),
+			updated_at = NOW()
+	`, p.IDJadwal, p.PewawancaraID, p.PendaftarID, p.IDRubrik, p.Nilai, p.Catatan)
+	if err != nil {
+		return err
+	}
+
+	var idPenilaian int
+	err = tx.QueryRow(
+		`SELECT id_penilaian FROM penilaian_wawancara WHERE id_jadwal = ? AND pewawancara_id = ?`,
+		p.IDJadwal, p.PewawancaraID,
+	).Scan(&idPenilaian)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM penilaian_wawancara_detail WHERE id_penilaian = ?`, idPenilaian); err != nil {
+		return err
+	}
+	for _, d := range p.Detail {
+		_, err := tx.Exec(
+			`INSERT INTO penilaian_wawancara_detail (id_penilaian, id_kriteria, skor, catatan) VALUES (?, ?, ?, ?)`,
+			idPenilaian, d.IDKriteria, d.Skor, d.Catatan,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func getDetailPenilaian(db *sql.DB, idPenilaian int) ([]models.PenilaianWawancaraDetail, error) {
+	rows, err := db.Query(
+		`SELECT id_kriteria, skor, catatan FROM penilaian_wawancara_detail WHERE id_penilaian = ? ORDER BY id_kriteria`,
+		idPenilaian,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var detail []models.PenilaianWawancaraDetail
+	for rows.Next() {
+		var d models.PenilaianWawancaraDetail
+		var catatan sql.NullString
+		if err := rows.Scan(&d.IDKriteria, &d.Skor, &catatan); err != nil {
+			return nil, err
+		}
+		if catatan.Valid {
+			d.Catatan = &catatan.String
+		}
+		detail = append(detail, d)
+	}
+	return detail, rows.Err()
+}
+
+// GetPenilaianByJadwal mengambil semua penilaian pewawancara untuk satu jadwal
+func GetPenilaianByJadwal(db *sql.DB, idJadwal int) ([]models.PenilaianWawancara, error) {
+	query := `
+		SELECT
+			pw.id_penilaian, pw.id_jadwal, pw.pewawancara_id, pw.pendaftar_id,
+			pw.id_rubrik, pw.nilai, pw.catatan, pw.created_at, pw.updated_at,
+			u.full_name
+		FROM penilaian_wawancara pw
+		LEFT JOIN users u ON pw.pewawancara_id = u.id_user
+		WHERE pw.id_jadwal = ?
+		ORDER BY pw.created_at ASC
+	`
+	rows, err := db.Query(query, idJadwal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []models.PenilaianWawancara
+	for rows.Next() {
+		var p models.PenilaianWawancara
+		var pendaftarID sql.NullInt64
+		var catatan, nama sql.NullString
+		err := rows.Scan(
+			&p.IDPenilaian, &p.IDJadwal, &p.PewawancaraID, &pendaftarID,
+			&p.IDRubrik, &p.Nilai, &catatan, &p.CreatedAt, &p.UpdatedAt,
+			&nama,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if pendaftarID.Valid {
+			id := int(pendaftarID.Int64)
+			p.PendaftarID = &id
+		}
+		if catatan.Valid {
+			p.Catatan = &catatan.String
+		}
+		if nama.Valid {
+			p.PewawancaraNama = nama.String
+		}
+		list = append(list, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range list {
+		detail, err := getDetailPenilaian(db, list[i].IDPenilaian)
+		if err != nil {
+			return nil, err
+		}
+		list[i].Detail = detail
+	}
+	return list, nil
+}
+
+// GetRingkasanWawancaraPendaftar menghitung jumlah dan rata-rata nilai wawancara seorang pendaftar
+func GetRingkasanWawancaraPendaftar(db *sql.DB, pendaftarID int) (int, *float64, error) {
+	var jumlah int
+	var rata sql.NullFloat64
+	err := db.QueryRow(
+		`SELECT COUNT(*), AVG(nilai) FROM penilaian_wawancara WHERE pendaftar_id = ?`,
+		pendaftarID,
+	).Scan(&jumlah, &rata)
+	if err != nil {
+		return 0, nil, err
+	}
+	if !rata.Valid {
+		return jumlah, nil, nil
+	}
+	return jumlah, &rata.Float64, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+)
+
+var ErrTokenCheckinTidakValid = errors.New("token check-in tidak valid")
+
+// checkinSignature menandatangani payload token check-in dengan JWT secret
+func checkinSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, Secret)
+	mac.Write([]byte("checkin:" + payload))
+	return mac.Sum(nil)
+}
+
+// GenerateCheckinToken membuat token bertanda tangan untuk check-in user pada jadwal
+func GenerateCheckinToken(idJadwal, userID int, kedaluwarsa time.Time) string {
+	payload := fmt.Sprintf("%d.%d.%d", idJadwal, userID, kedaluwarsa.Unix())
+	sig := base64.RawURLEncoding.EncodeToString(checkinSignature(payload))
+	return payload + "." + sig
+}
+
+// VerifyCheckinToken memvalidasi tanda tangan dan masa berlaku token check-in
+func VerifyCheckinToken(token string) (idJadwal, userID int, err error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 4 {
+		return 0, 0, ErrTokenCheckinTidakValid
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	sig, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || !hmac.Equal(sig, checkinSignature(payload)) {
+		return 0, 0, ErrTokenCheckinTidakValid
+	}
+
+	var exp int64
+	if _, err := fmt.Sscanf(payload, "%d.%d.%d", &idJadwal, &userID, &exp); err != nil {
+		return 0, 0, ErrTokenCheckinTidakValid
+	}
+	if time.Now().Unix() > exp {
+		return 0, 0, errors.New("token check-in sudah kedaluwarsa")
+	}
+	return idJadwal, userID, nil
+}
+
+// GenerateQRPNG merender teks menjadi gambar QR berformat PNG
+func GenerateQRPNG(isi string, ukuran int) ([]byte, error) {
+	return qrcode.Encode(isi, qrcode.Medium, ukuran)
+}
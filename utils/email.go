@@ -3,6 +3,8 @@ package utils
 
 import (
 	"fmt"
+	"html"
+	"mime"
 	"net/smtp"
 	"os"
 )
@@ -48,16 +50,16 @@ func SendResetPasswordEmail(toEmail, token string) error {
 }
 
 func SendVerificationEmail(toEmail, token string) error {
-    SMTPHost := os.Getenv("SMTP_HOST")
+	SMTPHost := os.Getenv("SMTP_HOST")
 	SMTPPort := os.Getenv("SMTP_PORT")
 	EmailSender := os.Getenv("SMTP_USER")
 	EmailPassword := os.Getenv("SMTP_PASS")
 	FrontendURL := os.Getenv("FRONTEND_URL")
 
-    verificationLink := fmt.Sprintf("%s/verify?token=%s", FrontendURL, token)
+	verificationLink := fmt.Sprintf("%s/verify?token=%s", FrontendURL, token)
 
-    subject := "Verifikasi Email Anda"
-    body := fmt.Sprintf(`
+	subject := "Verifikasi Email Anda"
+	body := fmt.Sprintf(`
         <h2>Verifikasi Akun</h2>
         <p>Klik link berikut untuk verifikasi akun Anda:</p>
         <a href="%s" style="color: #007BFF;">Verifikasi Akun</a>
@@ -65,15 +67,49 @@ func SendVerificationEmail(toEmail, token string) error {
         <p>Jika Anda tidak mendaftar, abaikan email ini.</p>
     `, verificationLink)
 
-    msg := fmt.Sprintf("From: %s\r\n"+
-        "To: %s\r\n"+
-        "Subject: %s\r\n"+
-        "MIME-Version: 1.0\r\n"+
-        "Content-Type: text/html; charset=UTF-8\r\n\r\n"+
-        "%s",
-        EmailSender, toEmail, subject, body)
+	msg := fmt.Sprintf("From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=UTF-8\r\n\r\n"+
+		"%s",
+		EmailSender, toEmail, subject, body)
+
+	auth := smtp.PlainAuth("", EmailSender, EmailPassword, SMTPHost)
+
+	return smtp.SendMail(SMTPHost+":"+SMTPPort, auth, EmailSender, []string{toEmail}, []byte(msg))
+}
+
+// sendHTMLEmail mengirim email HTML; subject di-encode (RFC 2047) agar judul non-ASCII tetap terbaca
+func sendHTMLEmail(toEmail, subject, body string) error {
+	SMTPHost := os.Getenv("SMTP_HOST")
+	SMTPPort := os.Getenv("SMTP_PORT")
+	EmailSender := os.Getenv("SMTP_USER")
+	EmailPassword := os.Getenv("SMTP_PASS")
+
+	msg := fmt.Sprintf("From: %s\r\n"+
+		"To: %s\r\n"+
+		"Subject: %s\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/html; charset=UTF-8\r\n\r\n"+
+		"%s",
+		EmailSender, toEmail, mime.QEncoding.Encode("UTF-8", subject), body)
+
+	auth := smtp.PlainAuth("", EmailSender, EmailPassword, SMTPHost)
+
+	return smtp.SendMail(SMTPHost+":"+SMTPPort, auth, EmailSender, []string{toEmail}, []byte(msg))
+}
 
-    auth := smtp.PlainAuth("", EmailSender, EmailPassword, SMTPHost)
+func SendPengingatEmail(toEmail, judul, isi string) error {
+	body := fmt.Sprintf(`
+		<h2>%s</h2>
+		<p>%s</p>
+		<br>
+		<p>Salam,<br>Tim COCONUT</p>
+	`, html.EscapeString(judul), html.EscapeString(isi))
 
-    return smtp.SendMail(SMTPHost+":"+SMTPPort, auth, EmailSender, []string{toEmail}, []byte(msg))
+	if err := sendHTMLEmail(toEmail, judul, body); err != nil {
+		return fmt.Errorf("gagal kirim email pengingat: %v", err)
+	}
+	return nil
 }
@@ -48,3 +48,16 @@ func HapusFoto(uploadDir, filename string) error {
 	}
 	return os.Remove(path)
 }
+
+// SimpanBerkas menulis isi berkas (mis. gambar hasil impor) ke uploadDir dengan nama unik
+func SimpanBerkas(data []byte, namaAsli, uploadDir string) (string, error) {
+	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	newName := fmt.Sprintf("%d%s", time.Now().UnixNano(), filepath.Ext(namaAsli))
+	if err := os.WriteFile(filepath.Join(uploadDir, newName), data, 0o644); err != nil {
+		return "", err
+	}
+	return newName, nil
+}
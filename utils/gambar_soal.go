@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// GambarSoalPath sengaja di luar uploads/ agar tidak ikut dilayani file server publik
+const GambarSoalPath = "private/gambar_soal"
+
+var ErrTokenGambarTidakValid = errors.New("token gambar tidak valid")
+
+// gambarSignature menandatangani payload token gambar dengan JWT secret
+func gambarSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, Secret)
+	mac.Write([]byte("gambar:" + payload))
+	return mac.Sum(nil)
+}
+
+// GenerateGambarToken membuat token bertanda tangan untuk melihat gambar selama sesi tes berlangsung
+func GenerateGambarToken(idGambar, idHasil int, kedaluwarsa time.Time) string {
+	payload := fmt.Sprintf("%d.%d.%d", idGambar, idHasil, kedaluwarsa.Unix())
+	sig := base64.RawURLEncoding.EncodeToString(gambarSignature(payload))
+	return payload + "." + sig
+}
+
+// VerifyGambarToken memvalidasi tanda tangan dan masa berlaku token gambar
+func VerifyGambarToken(token string) (idGambar, idHasil int, err error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 4 {
+		return 0, 0, ErrTokenGambarTidakValid
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	sig, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil || !hmac.Equal(sig, gambarSignature(payload)) {
+		return 0, 0, ErrTokenGambarTidakValid
+	}
+
+	var exp int64
+	if _, err := fmt.Sscanf(payload, "%d.%d.%d", &idGambar, &idHasil, &exp); err != nil {
+		return 0, 0, ErrTokenGambarTidakValid
+	}
+	if time.Now().Unix() > exp {
+		return 0, 0, errors.New("token gambar sudah kedaluwarsa")
+	}
+	return idGambar, idHasil, nil
+}
+
+// URLGambarSesi membentuk URL gambar bertoken; BACKEND_URL (opsional) dipakai bila frontend beda origin
+func URLGambarSesi(idGambar, idHasil int, kedaluwarsa time.Time) string {
+	base := strings.TrimRight(os.Getenv("BACKEND_URL"), "/")
+	return base + "/test/gambar?token=" + url.QueryEscape(GenerateGambarToken(idGambar, idHasil, kedaluwarsa))
+}
@@ -29,4 +29,4 @@ func ParseAndValidate(r *http.Request, v interface{}) error {
 
 func ValidateStruct(v interface{}) error {
 	return validate.Struct(v)
-}
\ No newline at end of file
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// SkemaGambarSoal adalah awalan tujuan gambar di Markdown soal, mis. ![diagram](gambar:12)
+const SkemaGambarSoal = "gambar:"
+
+var (
+	// HTML mentah di Markdown tidak dirender (opsi unsafe goldmark tidak diaktifkan)
+	markdownSoal = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	kebijakanHTMLSoal = func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+		return p
+	}()
+
+	polaGambarSoal = regexp.MustCompile(`(\(\s*` + SkemaGambarSoal + `)(\d+)`)
+)
+
+// IDGambarMarkdown mengembalikan id gambar yang dirujuk teks Markdown
+func IDGambarMarkdown(src string) []int {
+	var ids []int
+	for _, m := range polaGambarSoal.FindAllStringSubmatch(src, -1) {
+		if id, err := strconv.Atoi(m[2]); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// GantiIDGambarMarkdown mengganti rujukan gambar:ID lama dengan id baru sesuai peta;
+// rujukan yang tidak ada di peta dibiarkan
+func GantiIDGambarMarkdown(src string, peta map[int]int) string {
+	return polaGambarSoal.ReplaceAllStringFunc(src, func(m string) string {
+		bagian := polaGambarSoal.FindStringSubmatch(m)
+		id, err := strconv.Atoi(bagian[2])
+		if err != nil {
+			return m
+		}
+		baru, ok := peta[id]
+		if !ok {
+			return m
+		}
+		return bagian[1] + strconv.Itoa(baru)
+	})
+}
+
+// RenderMarkdownSoal merender Markdown soal menjadi HTML yang sudah disanitasi.
+// Rujukan gambar:ID diganti dengan hasil urlGambar; bila urlGambar nil atau
+// mengembalikan "", gambar dibuang oleh sanitizer.
+func RenderMarkdownSoal(src string, urlGambar func(idGambar int) string) string {
+	sumber := []byte(src)
+	doc := markdownSoal.Parser().Parse(text.NewReader(sumber))
+
+	ast.Walk(doc, func(n ast.Node, masuk bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !masuk || !ok {
+			return ast.WalkContinue, nil
+		}
+		tujuan := string(img.Destination)
+		if !strings.HasPrefix(tujuan, SkemaGambarSoal) {
+			return ast.WalkContinue, nil
+		}
+		url := ""
+		if id, err := strconv.Atoi(strings.TrimPrefix(tujuan, SkemaGambarSoal)); err == nil && urlGambar != nil {
+			url = urlGambar(id)
+		}
+		img.Destination = []byte(url)
+		return ast.WalkContinue, nil
+	})
+
+	var buf bytes.Buffer
+	if err := markdownSoal.Renderer().Render(&buf, sumber, doc); err != nil {
+		return ""
+	}
+	return kebijakanHTMLSoal.Sanitize(buf.String())
+}
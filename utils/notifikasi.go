@@ -1,17 +1,121 @@
 // utils/notifikasi.go
 package utils
 
-import "cocopen-backend/dto"
-
-var NotifikasiChan = make(chan dto.NotifikasiEvent, 100)
-
-func BroadcastNotifikasi(judul, isi string) {
-    event := dto.NotifikasiEvent{
-        Judul: judul,
-        Isi:   isi,
-    }
-    select {
-    case NotifikasiChan <- event:
-    default:
-    }
-}
\ No newline at end of file
+import (
+	"cocopen-backend/dto"
+	"sync"
+	"time"
+)
+
+// Nama event SSE notifikasi
+const (
+	EventNotifikasi = "notifikasi"
+	EventPengumuman = "pengumuman"
+)
+
+const (
+	// Jumlah event terakhir yang disimpan untuk diputar ulang lewat Last-Event-ID
+	KapasitasRiwayatNotifikasi = 500
+
+	// Pelanggan yang tertinggal sebanyak ini diputus; klien tersambung ulang dan memutar ulang
+	// event yang terlewat dari riwayat, sehingga tidak ada event yang hilang diam-diam
+	bufferPelangganNotifikasi = 64
+)
+
+type pelangganNotifikasi struct {
+	userID int
+	role   string
+	ch     chan dto.NotifikasiEvent
+}
+
+// BrokerNotifikasi menyebarkan setiap event ke semua koneksi yang berhak menerimanya. Setiap
+// pelanggan punya channel sendiri; event untuk satu user (UserID != 0) hanya dikirim ke koneksi user itu,
+// dan event untuk satu role (Role != "") hanya ke koneksi user dengan role tersebut.
+type BrokerNotifikasi struct {
+	mu         sync.Mutex
+	idTerakhir int64
+	pelanggan  map[*pelangganNotifikasi]struct{}
+	riwayat    []dto.NotifikasiEvent
+}
+
+func NewBrokerNotifikasi() *BrokerNotifikasi {
+	return &BrokerNotifikasi{
+		// ID berbasis waktu agar tetap naik setelah server dimulai ulang
+		idTerakhir: time.Now().UnixMicro(),
+		pelanggan:  make(map[*pelangganNotifikasi]struct{}),
+	}
+}
+
+// Notifikasi adalah broker yang dipakai stream /notifikasi-stream
+var Notifikasi = NewBrokerNotifikasi()
+
+func untukPelanggan(e dto.NotifikasiEvent, p *pelangganNotifikasi) bool {
+	return (e.UserID == 0 || e.UserID == p.userID) && (e.Role == "" || e.Role == p.role)
+}
+
+// Langganan mendaftarkan koneksi milik userID dengan role tersebut. Event di riwayat dengan ID setelah sejakID
+// dikembalikan untuk dikirim lebih dulu (sejakID 0: tanpa pemutaran ulang). Channel ditutup bila
+// pelanggan tertinggal terlalu jauh; batal wajib dipanggil saat koneksi berakhir.
+func (b *BrokerNotifikasi) Langganan(userID int, role string, sejakID int64) (<-chan dto.NotifikasiEvent, []dto.NotifikasiEvent, func()) {
+	p := &pelangganNotifikasi{userID: userID, role: role, ch: make(chan dto.NotifikasiEvent, bufferPelangganNotifikasi)}
+
+	b.mu.Lock()
+	b.pelanggan[p] = struct{}{}
+	var putarUlang []dto.NotifikasiEvent
+	if sejakID > 0 {
+		for _, e := range b.riwayat {
+			if e.ID > sejakID && untukPelanggan(e, p) {
+				putarUlang = append(putarUlang, e)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	batal := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.lepas(p)
+	}
+	return p.ch, putarUlang, batal
+}
+
+// lepas menghapus pelanggan dan menutup channelnya; b.mu harus sudah dikunci
+func (b *BrokerNotifikasi) lepas(p *pelangganNotifikasi) {
+	if _, ok := b.pelanggan[p]; ok {
+		delete(b.pelanggan, p)
+		close(p.ch)
+	}
+}
+
+// Terbitkan memberi event ID dan waktu, menyimpannya di riwayat, lalu mengirimkannya ke setiap
+// pelanggan yang berhak tanpa menunggu pelanggan yang lambat
+func (b *BrokerNotifikasi) Terbitkan(e dto.NotifikasiEvent) dto.NotifikasiEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.idTerakhir++
+	e.ID = b.idTerakhir
+	if e.Jenis == "" {
+		e.Jenis = EventNotifikasi
+	}
+	if e.Waktu.IsZero() {
+		e.Waktu = time.Now().UTC()
+	}
+
+	b.riwayat = append(b.riwayat, e)
+	if len(b.riwayat) > KapasitasRiwayatNotifikasi {
+		b.riwayat = b.riwayat[len(b.riwayat)-KapasitasRiwayatNotifikasi:]
+	}
+
+	for p := range b.pelanggan {
+		if !untukPelanggan(e, p) {
+			continue
+		}
+		select {
+		case p.ch <- e:
+		default:
+			b.lepas(p)
+		}
+	}
+	return e
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	proxyTepercaya     []*net.IPNet
+	muatProxyTepercaya sync.Once
+)
+
+// ipProxyTepercaya membaca TRUSTED_PROXIES (IP atau CIDR dipisah koma, mis. "10.0.0.0/8,100.64.0.0/10")
+func ipProxyTepercaya(ip net.IP) bool {
+	muatProxyTepercaya.Do(func() {
+		for _, part := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			if !strings.Contains(part, "/") {
+				if strings.Contains(part, ":") {
+					part += "/128"
+				} else {
+					part += "/32"
+				}
+			}
+			_, jaringan, err := net.ParseCIDR(part)
+			if err != nil {
+				log.Printf("TRUSTED_PROXIES %q tidak valid, diabaikan", part)
+				continue
+			}
+			proxyTepercaya = append(proxyTepercaya, jaringan)
+		}
+	})
+	for _, jaringan := range proxyTepercaya {
+		if jaringan.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP mengambil IP klien dari RemoteAddr. X-Forwarded-For hanya dibaca bila koneksi datang dari
+// proxy di TRUSTED_PROXIES (mis. Railway), dan yang dipakai adalah hop paling kanan yang bukan proxy
+// tepercaya; entri di kirinya bisa diisi sembarang oleh klien.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote := net.ParseIP(host)
+	if remote == nil || !ipProxyTepercaya(remote) {
+		return PotongTeks(host, 45)
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		if !ipProxyTepercaya(ip) {
+			return ip.String()
+		}
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return PotongTeks(host, 45)
+}
+
+// UserAgent mengambil user agent yang dipotong sesuai panjang kolom
+func UserAgent(r *http.Request) string {
+	return PotongTeks(r.UserAgent(), 255)
+}
+
+// PotongTeks memotong s menjadi paling banyak n karakter agar muat di kolom VARCHAR(n),
+// tanpa memotong di tengah karakter multibyte
+func PotongTeks(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	jumlah := 0
+	for i := range s {
+		if jumlah == n {
+			return s[:i]
+		}
+		jumlah++
+	}
+	return s
+}
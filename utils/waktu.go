@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+	_ "time/tzdata" // zona IANA tetap tersedia walau image tidak punya /usr/share/zoneinfo
+)
+
+// DefaultZonaWaktu dipakai bila jadwal tidak menyebutkan zona waktu
+const DefaultZonaWaktu = "Asia/Makassar"
+
+var (
+	zonaCache    sync.Map
+	ErrZonaWaktu = errors.New("zona waktu tidak dikenal")
+)
+
+// LoadZonaWaktu memuat lokasi IANA (mis. Asia/Jakarta); string kosong berarti DefaultZonaWaktu
+func LoadZonaWaktu(nama string) (*time.Location, error) {
+	if nama == "" {
+		nama = DefaultZonaWaktu
+	}
+	if loc, ok := zonaCache.Load(nama); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(nama)
+	if err != nil || nama == "Local" {
+		return nil, ErrZonaWaktu
+	}
+	zonaCache.Store(nama, loc)
+	return loc, nil
+}
+
+// ZonaWaktuOrUTC seperti LoadZonaWaktu tetapi jatuh ke UTC bila zona tidak valid
+func ZonaWaktuOrUTC(nama string) *time.Location {
+	loc, err := LoadZonaWaktu(nama)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WaktuLokalKeUTC mengubah tanggal (2006-01-02) dan jam (15:04:05) di zona tertentu menjadi instan UTC
+func WaktuLokalKeUTC(tanggal, jam string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", tanggal+" "+jam, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
+
+// FormatWaktuLokal menampilkan instan dalam zona jadwal, mis. "02-01-2006 15:04 WITA"
+func FormatWaktuLokal(t time.Time, zona string) string {
+	return t.In(ZonaWaktuOrUTC(zona)).Format("02-01-2006 15:04 MST")
+}
@@ -0,0 +1,339 @@
+// utils/webpush.go
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Web Push (RFC 8030) dengan payload terenkripsi aes128gcm (RFC 8291) dan autentikasi VAPID
+// (RFC 8292). Kunci VAPID dibaca dari VAPID_PUBLIC_KEY dan VAPID_PRIVATE_KEY (base64url, mis.
+// hasil `npx web-push generate-vapid-keys`) serta VAPID_SUBJECT (mailto: atau https: kontak
+// pengelola). Bila kunci belum diatur, Web Push dinonaktifkan dan pengiriman dilewati.
+
+const (
+	// Batas payload sebelum enkripsi agar satu record aes128gcm muat di batas 4096 byte push service
+	MaxPayloadWebPush = 3992
+
+	// Masa berlaku token VAPID; push service menolak token lebih dari 24 jam
+	masaBerlakuVAPID = 12 * time.Hour
+
+	ukuranRecordWebPush = 4096
+)
+
+var (
+	// ErrLanggananPushKedaluwarsa dikembalikan bila push service menjawab 404/410: langganan
+	// sudah tidak berlaku dan harus dihapus
+	ErrLanggananPushKedaluwarsa = errors.New("langganan push sudah tidak berlaku")
+	ErrLanggananPushTidakValid  = errors.New("langganan push tidak valid")
+	ErrWebPushNonaktif          = errors.New("web push belum dikonfigurasi")
+)
+
+type konfigurasiVAPID struct {
+	kunciPublik string
+	kunciPrivat *ecdsa.PrivateKey
+	subjek      string
+}
+
+var (
+	vapidOnce sync.Once
+	vapid     *konfigurasiVAPID
+
+	// Redirect tidak diikuti agar endpoint yang lolos allowlist tidak bisa dialihkan ke host lain
+	klienWebPush = &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Host push service browser yang dikenal; endpoint lain ditolak agar server tidak bisa dipakai
+	// mengirim request ke alamat sembarang (SSRF)
+	hostPushService = []string{
+		"fcm.googleapis.com",
+		"updates.push.services.mozilla.com",
+		".push.services.mozilla.com",
+		".notify.windows.com",
+		"web.push.apple.com",
+		".push.apple.com",
+	}
+)
+
+// hostPushDikenal memeriksa host endpoint terhadap hostPushService (awalan "." berarti subdomain)
+func hostPushDikenal(u *url.URL) bool {
+	if u.Scheme != "https" || u.User != nil || (u.Port() != "" && u.Port() != "443") {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range hostPushService {
+		if host == h || (strings.HasPrefix(h, ".") && strings.HasSuffix(host, h)) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func muatVAPID() *konfigurasiVAPID {
+	vapidOnce.Do(func() {
+		publik := os.Getenv("VAPID_PUBLIC_KEY")
+		privat := os.Getenv("VAPID_PRIVATE_KEY")
+		if publik == "" || privat == "" {
+			log.Println("VAPID_PUBLIC_KEY/VAPID_PRIVATE_KEY belum diatur, Web Push dinonaktifkan")
+			return
+		}
+
+		d, err := decodeBase64URL(privat)
+		if err != nil {
+			log.Printf("VAPID_PRIVATE_KEY tidak valid, Web Push dinonaktifkan: %v", err)
+			return
+		}
+		kunci, err := ecdh.P256().NewPrivateKey(d)
+		if err != nil {
+			log.Printf("VAPID_PRIVATE_KEY tidak valid, Web Push dinonaktifkan: %v", err)
+			return
+		}
+		// Kunci publik turunan harus sama dengan VAPID_PUBLIC_KEY yang dibagikan ke browser
+		titik := kunci.PublicKey().Bytes()
+		if p, err := decodeBase64URL(publik); err != nil || !bytes.Equal(p, titik) {
+			log.Println("VAPID_PUBLIC_KEY tidak cocok dengan VAPID_PRIVATE_KEY, Web Push dinonaktifkan")
+			return
+		}
+
+		subjek := os.Getenv("VAPID_SUBJECT")
+		if subjek == "" {
+			subjek = "mailto:" + os.Getenv("SMTP_USER")
+		}
+
+		vapid = &konfigurasiVAPID{
+			kunciPublik: base64.RawURLEncoding.EncodeToString(titik),
+			kunciPrivat: &ecdsa.PrivateKey{
+				PublicKey: ecdsa.PublicKey{
+					Curve: elliptic.P256(),
+					X:     new(big.Int).SetBytes(titik[1:33]),
+					Y:     new(big.Int).SetBytes(titik[33:]),
+				},
+				D: new(big.Int).SetBytes(d),
+			},
+			subjek: subjek,
+		}
+	})
+	return vapid
+}
+
+// WebPushAktif menandai apakah kunci VAPID sudah dikonfigurasi
+func WebPushAktif() bool {
+	return muatVAPID() != nil
+}
+
+// KunciPublikVAPID mengembalikan applicationServerKey untuk pushManager.subscribe di browser
+func KunciPublikVAPID() string {
+	if v := muatVAPID(); v != nil {
+		return v.kunciPublik
+	}
+	return ""
+}
+
+// ValidasiLanggananPush memeriksa endpoint (https ke push service yang dikenal) dan kunci p256dh/auth
+// dari PushSubscription browser
+func ValidasiLanggananPush(endpoint, p256dh, auth string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: endpoint harus URL https", ErrLanggananPushTidakValid)
+	}
+	if !hostPushDikenal(u) {
+		return fmt.Errorf("%w: endpoint bukan push service yang dikenal", ErrLanggananPushTidakValid)
+	}
+	kunci, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return fmt.Errorf("%w: p256dh bukan base64url", ErrLanggananPushTidakValid)
+	}
+	if _, err := ecdh.P256().NewPublicKey(kunci); err != nil {
+		return fmt.Errorf("%w: p256dh bukan kunci P-256", ErrLanggananPushTidakValid)
+	}
+	rahasia, err := decodeBase64URL(auth)
+	if err != nil || len(rahasia) != 16 {
+		return fmt.Errorf("%w: auth harus 16 byte base64url", ErrLanggananPushTidakValid)
+	}
+	return nil
+}
+
+// hkdfSHA256 menjalankan HKDF-Extract lalu HKDF-Expand sepanjang n byte
+func hkdfSHA256(salt, ikm, info []byte, n int) ([]byte, error) {
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EnkripsiWebPush mengenkripsi payload untuk satu langganan sesuai RFC 8291 (aes128gcm, satu record)
+func EnkripsiWebPush(payload []byte, p256dh, auth string) ([]byte, error) {
+	if len(payload) > MaxPayloadWebPush {
+		return nil, fmt.Errorf("payload web push melebihi %d byte", MaxPayloadWebPush)
+	}
+	uaBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("%w: p256dh bukan base64url", ErrLanggananPushTidakValid)
+	}
+	uaPublik, err := ecdh.P256().NewPublicKey(uaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: p256dh bukan kunci P-256", ErrLanggananPushTidakValid)
+	}
+	rahasiaAuth, err := decodeBase64URL(auth)
+	if err != nil || len(rahasiaAuth) != 16 {
+		return nil, fmt.Errorf("%w: auth harus 16 byte base64url", ErrLanggananPushTidakValid)
+	}
+
+	// Kunci sementara application server dan salt, baru untuk setiap pesan
+	asPrivat, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return enkripsiWebPush(payload, uaPublik, rahasiaAuth, asPrivat, salt)
+}
+
+// enkripsiWebPush adalah bagian deterministik EnkripsiWebPush: kunci sementara dan salt diberikan
+// pemanggil sehingga hasilnya bisa dicocokkan dengan vektor uji RFC 8291
+func enkripsiWebPush(payload []byte, uaPublik *ecdh.PublicKey, rahasiaAuth []byte, asPrivat *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	uaBytes := uaPublik.Bytes()
+	asPublik := asPrivat.PublicKey().Bytes()
+	rahasiaECDH, err := asPrivat.ECDH(uaPublik)
+	if err != nil {
+		return nil, err
+	}
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	info := append([]byte("WebPush: info\x00"), uaBytes...)
+	info = append(info, asPublik...)
+	ikm, err := hkdfSHA256(rahasiaAuth, rahasiaECDH, info, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, err := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	blok, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(blok)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header RFC 8188: salt(16) || rs(4) || idlen(1) || keyid(as_public)
+	body := make([]byte, 0, 16+4+1+len(asPublik)+len(payload)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, ukuranRecordWebPush)
+	body = append(body, byte(len(asPublik)))
+	body = append(body, asPublik...)
+
+	// Record terakhir diakhiri delimiter 0x02 tanpa padding tambahan
+	plain := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(body, nonce, plain, nil), nil
+}
+
+// headerVAPID membuat header Authorization VAPID untuk origin push service endpoint
+func headerVAPID(v *konfigurasiVAPID, endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(masaBerlakuVAPID).Unix(),
+		"sub": v.subjek,
+	})
+	signed, err := token.SignedString(v.kunciPrivat)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, v.kunciPublik), nil
+}
+
+// KirimWebPush mengirim payload terenkripsi ke satu langganan. ttl adalah lama (detik) push
+// service menyimpan pesan bila perangkat sedang offline. Mengembalikan
+// ErrLanggananPushKedaluwarsa bila langganan sudah tidak berlaku (404/410) dan
+// ErrLanggananPushTidakValid bila endpoint atau kuncinya tidak bisa dipakai.
+func KirimWebPush(endpoint, p256dh, auth string, payload []byte, ttl int) error {
+	v := muatVAPID()
+	if v == nil {
+		return ErrWebPushNonaktif
+	}
+	// Langganan lama tersimpan sebelum allowlist berlaku; periksa ulang sebelum mengirim
+	if u, err := url.Parse(endpoint); err != nil || !hostPushDikenal(u) {
+		return fmt.Errorf("%w: endpoint bukan push service yang dikenal", ErrLanggananPushTidakValid)
+	}
+
+	body, err := EnkripsiWebPush(payload, p256dh, auth)
+	if err != nil {
+		return err
+	}
+	otorisasi, err := headerVAPID(v, endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", otorisasi)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(ttl))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := klienWebPush.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrLanggananPushKedaluwarsa
+	case resp.StatusCode >= 300:
+		pesan, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("push service menjawab %d: %s", resp.StatusCode, strings.TrimSpace(string(pesan)))
+	}
+	return nil
+}
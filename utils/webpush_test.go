@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+// Vektor uji RFC 8291 Lampiran A
+const (
+	rfc8291Plaintext = "V2hlbiBJIGdyb3cgdXAsIEkgd2FudCB0byBiZSBhIHdhdGVybWVsb24"
+	rfc8291ASPrivat  = "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"
+	rfc8291ASPublik  = "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8"
+	rfc8291UAPrivat  = "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"
+	rfc8291UAPublik  = "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"
+	rfc8291Salt      = "DGv6ra1nlYgDCS1FRnbzlw"
+	rfc8291Auth      = "BTBZMqHH6r4Tts7J_aSIgg"
+	rfc8291Body      = "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+)
+
+func mustBase64URL(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := decodeBase64URL(s)
+	if err != nil {
+		t.Fatalf("base64url %q: %v", s, err)
+	}
+	return b
+}
+
+// dekripsiWebPush membuka body aes128gcm satu record dari sisi user agent (RFC 8291 bagian 3.4)
+func dekripsiWebPush(t *testing.T, body []byte, uaPrivat *ecdh.PrivateKey, rahasiaAuth []byte) []byte {
+	t.Helper()
+	if len(body) < 21 {
+		t.Fatalf("body terlalu pendek: %d byte", len(body))
+	}
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != ukuranRecordWebPush {
+		t.Fatalf("rs = %d, ingin %d", rs, ukuranRecordWebPush)
+	}
+	idlen := int(body[20])
+	if len(body) < 21+idlen {
+		t.Fatalf("keyid terpotong")
+	}
+	asBytes := body[21 : 21+idlen]
+	record := body[21+idlen:]
+
+	asPublik, err := ecdh.P256().NewPublicKey(asBytes)
+	if err != nil {
+		t.Fatalf("keyid bukan kunci P-256: %v", err)
+	}
+	rahasiaECDH, err := uaPrivat.ECDH(asPublik)
+	if err != nil {
+		t.Fatalf("ECDH: %v", err)
+	}
+	info := append([]byte("WebPush: info\x00"), uaPrivat.PublicKey().Bytes()...)
+	info = append(info, asBytes...)
+	ikm, err := hkdfSHA256(rahasiaAuth, rahasiaECDH, info, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cek, err := hkdfSHA256(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce, err := hkdfSHA256(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blok, err := aes.NewCipher(cek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(blok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := gcm.Open(nil, nonce, record, nil)
+	if err != nil {
+		t.Fatalf("gagal membuka record: %v", err)
+	}
+
+	// Buang padding 0x00 lalu delimiter record terakhir 0x02
+	plain = bytes.TrimRight(plain, "\x00")
+	if len(plain) == 0 || plain[len(plain)-1] != 0x02 {
+		t.Fatalf("delimiter record terakhir tidak ditemukan")
+	}
+	return plain[:len(plain)-1]
+}
+
+func TestEnkripsiWebPushVektorRFC8291(t *testing.T) {
+	asPrivat, err := ecdh.P256().NewPrivateKey(mustBase64URL(t, rfc8291ASPrivat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(asPrivat.PublicKey().Bytes(), mustBase64URL(t, rfc8291ASPublik)) {
+		t.Fatal("kunci publik application server tidak cocok dengan vektor")
+	}
+	uaPublik, err := ecdh.P256().NewPublicKey(mustBase64URL(t, rfc8291UAPublik))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := enkripsiWebPush(mustBase64URL(t, rfc8291Plaintext), uaPublik,
+		mustBase64URL(t, rfc8291Auth), asPrivat, mustBase64URL(t, rfc8291Salt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := base64.RawURLEncoding.EncodeToString(body); got != rfc8291Body {
+		t.Fatalf("body = %s\ningin  %s", got, rfc8291Body)
+	}
+}
+
+func TestEnkripsiWebPushBolakBalik(t *testing.T) {
+	uaPrivat, err := ecdh.P256().NewPrivateKey(mustBase64URL(t, rfc8291UAPrivat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rahasiaAuth := mustBase64URL(t, rfc8291Auth)
+
+	for _, payload := range [][]byte{
+		[]byte(`{"judul":"Pengumuman","isi":"Tes dimulai pukul 09.00 WIB"}`),
+		{},
+		bytes.Repeat([]byte("é"), MaxPayloadWebPush/2),
+	} {
+		body, err := EnkripsiWebPush(payload, rfc8291UAPublik, rfc8291Auth)
+		if err != nil {
+			t.Fatalf("EnkripsiWebPush %d byte: %v", len(payload), err)
+		}
+		if len(body) > ukuranRecordWebPush+21+65 {
+			t.Errorf("body %d byte melebihi satu record", len(body))
+		}
+		if got := dekripsiWebPush(t, body, uaPrivat, rahasiaAuth); !bytes.Equal(got, payload) {
+			t.Errorf("hasil dekripsi %q, ingin %q", got, payload)
+		}
+	}
+
+	// Setiap pesan memakai kunci sementara dan salt baru
+	a, _ := EnkripsiWebPush([]byte("sama"), rfc8291UAPublik, rfc8291Auth)
+	b, _ := EnkripsiWebPush([]byte("sama"), rfc8291UAPublik, rfc8291Auth)
+	if bytes.Equal(a, b) {
+		t.Error("dua enkripsi payload yang sama menghasilkan body identik")
+	}
+}
+
+func TestEnkripsiWebPushTolakInputTidakValid(t *testing.T) {
+	if _, err := EnkripsiWebPush(make([]byte, MaxPayloadWebPush+1), rfc8291UAPublik, rfc8291Auth); err == nil {
+		t.Error("payload melebihi MaxPayloadWebPush diterima")
+	}
+	if _, err := EnkripsiWebPush([]byte("x"), "bukan-kunci", rfc8291Auth); err == nil {
+		t.Error("p256dh tidak valid diterima")
+	}
+	if _, err := EnkripsiWebPush([]byte("x"), rfc8291UAPublik, "c2luZ2thdA"); err == nil {
+		t.Error("auth yang bukan 16 byte diterima")
+	}
+}
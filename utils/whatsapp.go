@@ -0,0 +1,174 @@
+// utils/whatsapp.go
+package utils
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KanalPesan adalah saluran pengiriman pesan teks ke nomor telepon (WhatsApp, SMS, ...).
+// Kirim mengembalikan ID pesan dari provider untuk pelacakan status pengiriman.
+type KanalPesan interface {
+	Nama() string
+	Kirim(tujuan, isi string) (idProvider string, err error)
+}
+
+var (
+	ErrNomorTidakValid = errors.New("nomor WhatsApp tidak valid")
+
+	// ErrPesanDitolakGateway menandai penolakan permanen (4xx) yang tidak perlu dicoba ulang
+	ErrPesanDitolakGateway = errors.New("pesan ditolak gateway")
+)
+
+// NormalisasiNomorIndonesia mengubah nomor HP Indonesia (08xx, 8xx, 628xx, +628xx, +62 (0)8xx,
+// 00628xx, boleh berisi spasi, titik, tanda hubung, atau kurung) menjadi format E.164 +628xx
+func NormalisasiNomorIndonesia(nomor string) (string, error) {
+	bersih := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(nomor))
+
+	switch {
+	case strings.HasPrefix(bersih, "+"):
+		bersih = strings.TrimPrefix(bersih, "+")
+		if !strings.HasPrefix(bersih, "62") {
+			return "", fmt.Errorf("%w: hanya nomor Indonesia (+62)", ErrNomorTidakValid)
+		}
+	case strings.HasPrefix(bersih, "00"):
+		bersih = strings.TrimPrefix(bersih, "00")
+		if !strings.HasPrefix(bersih, "62") {
+			return "", fmt.Errorf("%w: hanya nomor Indonesia (+62)", ErrNomorTidakValid)
+		}
+	}
+	for _, r := range bersih {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%w: berisi karakter selain angka", ErrNomorTidakValid)
+		}
+	}
+
+	nasional := bersih
+	switch {
+	case strings.HasPrefix(nasional, "62"):
+		// Awalan trunk 0 yang kadang ditulis setelah kode negara, mis. +62 (0)812
+		nasional = strings.TrimPrefix(nasional[2:], "0")
+	case strings.HasPrefix(nasional, "0"):
+		nasional = nasional[1:]
+	}
+	// Nomor seluler Indonesia: 8xx dengan 9-12 digit setelah kode negara
+	if !strings.HasPrefix(nasional, "8") || len(nasional) < 9 || len(nasional) > 12 {
+		return "", fmt.Errorf("%w: bukan nomor seluler Indonesia", ErrNomorTidakValid)
+	}
+	return "+62" + nasional, nil
+}
+
+// GatewayWhatsApp mengirim pesan lewat HTTP API gateway WhatsApp. Kontraknya sengaja sederhana
+// agar mudah diadaptasi ke provider mana pun (atau di-stub saat pengembangan):
+//
+//	POST {URL}/messages
+//	Authorization: Bearer {Token}
+//	{"to": "+6281234567890", "type": "text", "text": "..."}
+//	-> 2xx {"id": "<id pesan provider>", "status": "queued"}
+//
+// Perubahan status dikirim balik provider ke webhook /wa/status.
+type GatewayWhatsApp struct {
+	URL   string
+	Token string
+	klien *http.Client
+}
+
+func NewGatewayWhatsApp(url, token string) *GatewayWhatsApp {
+	return &GatewayWhatsApp{
+		URL:   strings.TrimRight(url, "/"),
+		Token: token,
+		klien: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (g *GatewayWhatsApp) Nama() string {
+	return "whatsapp"
+}
+
+func (g *GatewayWhatsApp) Kirim(tujuan, isi string) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"to":   tujuan,
+		"type": "text",
+		"text": isi,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, g.URL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := g.klien.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 300 {
+		pesan := strings.TrimSpace(string(respBody))
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return "", fmt.Errorf("%w (%d): %s", ErrPesanDitolakGateway, resp.StatusCode, pesan)
+		}
+		return "", fmt.Errorf("gateway WhatsApp menjawab %d: %s", resp.StatusCode, pesan)
+	}
+
+	var hasil struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(respBody, &hasil); err != nil || hasil.ID == "" {
+		return "", fmt.Errorf("respons gateway WhatsApp tanpa id pesan: %s", strings.TrimSpace(string(respBody)))
+	}
+	return hasil.ID, nil
+}
+
+var (
+	kanalWAOnce sync.Once
+	kanalWA     KanalPesan
+)
+
+// KanalWhatsApp mengembalikan gateway WhatsApp dari WA_GATEWAY_URL dan WA_GATEWAY_TOKEN,
+// atau nil bila belum dikonfigurasi (pengiriman WhatsApp dilewati)
+func KanalWhatsApp() KanalPesan {
+	kanalWAOnce.Do(func() {
+		url := os.Getenv("WA_GATEWAY_URL")
+		if url == "" {
+			log.Println("WA_GATEWAY_URL belum diatur, notifikasi WhatsApp dinonaktifkan")
+			return
+		}
+		kanalWA = NewGatewayWhatsApp(url, os.Getenv("WA_GATEWAY_TOKEN"))
+	})
+	return kanalWA
+}
+
+// TokenWebhookWAValid memeriksa token callback status dari gateway terhadap WA_WEBHOOK_SECRET.
+// Tanpa secret semua callback ditolak agar status pesan tidak bisa dipalsukan.
+func TokenWebhookWAValid(token string) bool {
+	secret := os.Getenv("WA_WEBHOOK_SECRET")
+	if secret == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
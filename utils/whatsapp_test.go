@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalisasiNomorIndonesia(t *testing.T) {
+	valid := []struct {
+		nomor, hasil string
+	}{
+		{"081234567890", "+6281234567890"},
+		{"81234567890", "+6281234567890"},
+		{"6281234567890", "+6281234567890"},
+		{"+6281234567890", "+6281234567890"},
+		{"+62 812-3456-7890", "+6281234567890"},
+		{"+62 (0)812 3456 7890", "+6281234567890"},
+		{"(0812) 3456.7890", "+6281234567890"},
+		{"006281234567890", "+6281234567890"},
+		{"0062 (0)812-3456-7890", "+6281234567890"},
+		{"  0812345678  ", "+62812345678"},
+		{"0812345678901", "+62812345678901"},
+	}
+	for _, tc := range valid {
+		hasil, err := NormalisasiNomorIndonesia(tc.nomor)
+		if err != nil {
+			t.Errorf("NormalisasiNomorIndonesia(%q) error: %v", tc.nomor, err)
+			continue
+		}
+		if hasil != tc.hasil {
+			t.Errorf("NormalisasiNomorIndonesia(%q) = %q, ingin %q", tc.nomor, hasil, tc.hasil)
+		}
+	}
+
+	tidakValid := []string{
+		"",
+		"0812",
+		"08123456",
+		"+62812345",
+		"08123456789012",
+		"+6581234567",
+		"0065812345678",
+		"+1 812 345 6789",
+		"0212345678",
+		"+62 21 2345678",
+		"0812abc45678",
+		"0812+3456789",
+	}
+	for _, nomor := range tidakValid {
+		hasil, err := NormalisasiNomorIndonesia(nomor)
+		if !errors.Is(err, ErrNomorTidakValid) {
+			t.Errorf("NormalisasiNomorIndonesia(%q) = %q, %v; ingin ErrNomorTidakValid", nomor, hasil, err)
+		}
+	}
+}